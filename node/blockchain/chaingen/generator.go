@@ -1360,7 +1360,7 @@ func (g *Generator) calcNextRequiredDiffASERT() uint32 {
 	anchor := g.powDiffAnchor
 	timeDelta := g.tip.Header.Timestamp.Unix() - anchor.Timestamp.Unix()
 	heightDelta := int64(g.tip.Header.Height - anchor.Height)
-	return calcASERTDiff(g.params.WorkDiffV2Blake3StartBits, g.params.PowLimit,
+	return calcASERTDiff(g.params.WorkDiffV2KawPowStartBits, g.params.PowLimit,
 		int64(g.params.TargetTimePerBlock.Seconds()), timeDelta, heightDelta,
 		g.params.WorkDiffV2HalfLifeSecs)
 }
@@ -1855,7 +1855,12 @@ func (g *Generator) IsSolved(header *wire.BlockHeader) bool {
 	case PHABlake256r14:
 		hash = header.PowHashV1()
 	case PHABlake3:
-		hash = header.PowHashV2()
+		var err error
+		hash, err = header.PowHashV2()
+		if err != nil {
+			panic(fmt.Sprintf("unable to calculate proof of work hash: %v",
+				err))
+		}
 	default:
 		panic(fmt.Sprintf("unsupported proof of work hash algorithm %d",
 			g.powHashAlgo))
@@ -1886,10 +1891,12 @@ func (g *Generator) solveBlock(header *wire.BlockHeader) bool {
 	solver := func(hdr wire.BlockHeader, startNonce, stopNonce uint64) {
 		// Choose which proof of work hash algorithm to use based on the
 		// associated state.
-		var powHashFn func() chainhash.Hash
+		var powHashFn func() (chainhash.Hash, error)
 		switch g.powHashAlgo {
 		case PHABlake256r14:
-			powHashFn = hdr.PowHashV1
+			powHashFn = func() (chainhash.Hash, error) {
+				return hdr.PowHashV1(), nil
+			}
 		case PHABlake3:
 			powHashFn = hdr.PowHashV2
 		default:
@@ -1906,7 +1913,11 @@ func (g *Generator) solveBlock(header *wire.BlockHeader) bool {
 				return
 			default:
 				hdr.Nonce = i
-				hash := powHashFn()
+				hash, err := powHashFn()
+				if err != nil {
+					panic(fmt.Sprintf("unable to calculate proof of work "+
+						"hash: %v", err))
+				}
 				if hashToBig(&hash).Cmp(targetDifficulty) <= 0 {
 					results <- sbResult{found: true, nonce: i}
 					return
@@ -117,6 +117,9 @@ var regNetParams = &chaincfg.Params{
 	MaxTxSize:            1000000,
 	TargetTimePerBlock:   time.Second,
 
+	// Blocks may not have timestamps before their parent.
+	MaxBlockTimeRegression: 0,
+
 	// Version 1 difficulty algorithm (EMA + BLAKE256) parameters.
 	WorkDiffAlpha:            1,
 	WorkDiffWindowSize:       8,
@@ -126,6 +129,7 @@ var regNetParams = &chaincfg.Params{
 
 	// Version 2 difficulty algorithm (ASERT + BLAKE3) parameters.
 	WorkDiffV2Blake3StartBits: 0x207fffff,
+	WorkDiffV2KawPowStartBits: 0x207fffff,
 	WorkDiffV2HalfLifeSecs:    6, // 6 * TimePerBlock
 
 	// Subsidy parameters.
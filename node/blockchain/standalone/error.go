@@ -29,6 +29,11 @@ const (
 	// lower than the required target difficultly.
 	ErrHighHash = ErrorKind("ErrHighHash")
 
+	// ErrBadMixDigest indicates the mix digest committed to by a block header
+	// does not match the one produced while calculating its KawPoW proof of
+	// work hash.
+	ErrBadMixDigest = ErrorKind("ErrBadMixDigest")
+
 	// ErrInvalidTSpendExpiry indicates that an invalid expiry was
 	// provided when calculating the treasury spend voting window.
 	ErrInvalidTSpendExpiry = ErrorKind("ErrInvalidTSpendExpiry")
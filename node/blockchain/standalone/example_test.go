@@ -9,8 +9,8 @@ import (
 	"fmt"
 	"math/big"
 
-	"github.com/decred/dcrd/blockchain/standalone/v2"
 	"github.com/decred/dcrd/chaincfg/chainhash"
+	"vigil.network/node/blockchain/standalone"
 )
 
 // This example demonstrates how to convert the compact "bits" in a block header
@@ -68,7 +68,7 @@ func ExampleCheckProofOfWork() {
 	}
 	bits := uint32(453115903)
 
-	if err := standalone.CheckProofOfWork(hash, bits, powLimit, nil); err != nil {
+	if err := standalone.CheckProofOfWork(hash, bits, powLimit); err != nil {
 		fmt.Printf("proof of work check failed: %v\n", err)
 		return
 	}
@@ -0,0 +1,390 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package kawpow
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DAGJobStatus describes the state of a DAG regeneration job.
+type DAGJobStatus string
+
+const (
+	// DAGJobRunning indicates the DAG is currently being regenerated.
+	DAGJobRunning DAGJobStatus = "running"
+
+	// DAGJobDone indicates the DAG was regenerated successfully.
+	DAGJobDone DAGJobStatus = "done"
+
+	// DAGJobFailed indicates the DAG regeneration failed.
+	DAGJobFailed DAGJobStatus = "failed"
+)
+
+// ErrDAGJobInProgress is returned when a DAG regeneration is requested while
+// another one is still running.
+var ErrDAGJobInProgress = errors.New("DAG regeneration already in progress")
+
+// ErrDAGDiskLimit is returned when a DAG file can't be written without
+// exceeding the configured maximum DAG disk usage or the space available on
+// disk, even after pruning the DAG files for other epochs.
+var ErrDAGDiskLimit = errors.New("insufficient disk space for DAG file")
+
+// DAGJob describes a request to regenerate the DAG for an epoch.
+type DAGJob struct {
+	// ID uniquely identifies the job for the lifetime of the manager.  IDs
+	// start at 1.
+	ID uint64
+
+	// Epoch is the epoch the DAG is being regenerated for.
+	Epoch int64
+
+	// Status is the current state of the job.
+	Status DAGJobStatus
+
+	// Err is the reason the job failed when the status is DAGJobFailed.
+	Err error
+}
+
+// DAGManager manages the DAG files cached on disk for each epoch and provides
+// the means to force a clean rebuild of them for recovery purposes.
+//
+// It is safe for concurrent access.
+type DAGManager struct {
+	// dir is the directory the DAG files are stored in.
+	dir string
+
+	// maxDiskBytes is the maximum number of bytes the DAG files are allowed
+	// to consume on disk.  A value of zero means no limit.
+	maxDiskBytes uint64
+
+	// params houses the KawPoW parameters of the network the DAGs are
+	// generated for.
+	params Params
+
+	// generate generates and stores the DAG for the provided epoch.  It is
+	// a field so the tests can avoid generating full DAGs.
+	generate func(epoch int64) error
+
+	// diskSpace returns the number of bytes available on the filesystem that
+	// contains the provided directory.  It is a field so the tests can
+	// simulate running out of disk space.
+	diskSpace func(dir string) (uint64, error)
+
+	// The following fields are protected by the embedded mutex.
+	//
+	// nextJobID is the ID to assign to the next regeneration job.
+	//
+	// lastJob is the most recently started regeneration job, if any.
+	mtx       sync.Mutex
+	nextJobID uint64
+	lastJob   *DAGJob
+}
+
+// NewDAGManager returns a new DAG manager that stores DAG files generated with
+// the provided KawPoW parameters in the provided directory.  The DAG files for
+// the oldest epochs are pruned as needed to keep the total size of the DAG
+// files at or under the provided maximum number of bytes.  A maximum of zero
+// means no limit.
+func NewDAGManager(dir string, maxDiskBytes uint64, params Params) *DAGManager {
+	m := &DAGManager{
+		dir:          dir,
+		maxDiskBytes: maxDiskBytes,
+		params:       params,
+		nextJobID:    1,
+	}
+	m.generate = m.writeDAGFile
+	m.diskSpace = availableDiskSpace
+	return m
+}
+
+// DAGFilePath returns the path of the file used to cache the DAG for the
+// provided epoch.  It is the dataset file full hashers persist the dataset for
+// the epoch to, so DAGs generated by the manager are loaded by them as is.
+func (m *DAGManager) DAGFilePath(epoch int64) string {
+	return DatasetFilePath(m.dir, epoch, EpochSeed(uint64(epoch)))
+}
+
+// dagFileSize returns the size in bytes of the file used to cache the DAG for
+// the provided epoch.
+func (m *DAGManager) dagFileSize(epoch int64) uint64 {
+	return datasetFileHeaderLen + m.params.DatasetBytes(epoch)
+}
+
+// dagFile describes a DAG file cached on disk.
+type dagFile struct {
+	epoch int64
+	path  string
+	size  uint64
+}
+
+// legacyDAGFileName returns the name of the file previous versions used to
+// cache the DAG for the provided epoch.
+func legacyDAGFileName(epoch int64) string {
+	return fmt.Sprintf("kawpow-epoch-%d.dag", epoch)
+}
+
+// dagFileEpoch returns the epoch of the DAG file with the provided name and
+// whether or not the name is that of a DAG file.  This includes the DAG files
+// in the dataset file format, the temporary files they are written to prior to
+// being renamed into place, and the DAG files used by previous versions.
+func (m *DAGManager) dagFileEpoch(name string) (int64, bool) {
+	var epoch int64
+	datasetName := strings.TrimSuffix(name, ".tmp")
+	_, err := fmt.Sscanf(datasetName, "kawpow-dataset-%d-", &epoch)
+	if err == nil && datasetName == filepath.Base(m.DAGFilePath(epoch)) {
+		return epoch, true
+	}
+	_, err = fmt.Sscanf(name, "kawpow-epoch-%d.dag", &epoch)
+	if err == nil && name == legacyDAGFileName(epoch) {
+		return epoch, true
+	}
+	return 0, false
+}
+
+// dagFiles returns the DAG files in the DAG directory sorted by ascending
+// epoch.  Files that are not named as DAG files are ignored.  See dagFileEpoch
+// for the files that are considered DAG files.
+func (m *DAGManager) dagFiles() ([]dagFile, error) {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var files []dagFile
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		name := entry.Name()
+		epoch, ok := m.dagFileEpoch(name)
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, dagFile{
+			epoch: epoch,
+			path:  filepath.Join(m.dir, name),
+			size:  uint64(info.Size()),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].epoch < files[j].epoch
+	})
+	return files, nil
+}
+
+// PruneDAGFiles removes the DAG files for the oldest epochs until the total
+// size of the DAG files in the DAG directory is at or under the provided
+// number of bytes.  It returns the total size of the remaining DAG files.
+func (m *DAGManager) PruneDAGFiles(maxBytes uint64) (uint64, error) {
+	files, err := m.dagFiles()
+	if err != nil {
+		return 0, err
+	}
+
+	var totalBytes uint64
+	for _, file := range files {
+		totalBytes += file.size
+	}
+	for _, file := range files {
+		if totalBytes <= maxBytes {
+			break
+		}
+		if err := os.Remove(file.path); err != nil {
+			return totalBytes, err
+		}
+		totalBytes -= file.size
+	}
+	return totalBytes, nil
+}
+
+// ensureDAGSpace ensures there is room to write a DAG file of the provided size
+// for the provided epoch without exceeding the maximum DAG disk usage or the
+// space available on disk.  Any existing DAG files for the epoch are removed
+// since they are about to be replaced, and the DAG files for the oldest epochs
+// are pruned as needed to make room.
+//
+// Nothing is removed when the DAG file can't fit, in which case
+// ErrDAGDiskLimit is returned.
+func (m *DAGManager) ensureDAGSpace(epoch int64, size uint64) error {
+	if err := os.MkdirAll(m.dir, 0700); err != nil {
+		return err
+	}
+	if m.maxDiskBytes != 0 && size > m.maxDiskBytes {
+		return fmt.Errorf("%w: DAG file for epoch %d requires %d bytes "+
+			"which exceeds the maximum DAG disk usage of %d bytes",
+			ErrDAGDiskLimit, epoch, size, m.maxDiskBytes)
+	}
+
+	// Determine the existing DAG files for the epoch that are about to be
+	// replaced along with the DAG files for the oldest epochs that need to be
+	// pruned to stay under the maximum DAG disk usage once the new file is
+	// written.
+	files, err := m.dagFiles()
+	if err != nil {
+		return err
+	}
+	var remove []dagFile
+	var freedBytes, otherBytes uint64
+	for _, file := range files {
+		if file.epoch == epoch {
+			remove = append(remove, file)
+			freedBytes += file.size
+			continue
+		}
+		otherBytes += file.size
+	}
+	if m.maxDiskBytes != 0 {
+		for _, file := range files {
+			if otherBytes <= m.maxDiskBytes-size {
+				break
+			}
+			if file.epoch == epoch {
+				continue
+			}
+			remove = append(remove, file)
+			freedBytes += file.size
+			otherBytes -= file.size
+		}
+	}
+
+	// Ensure the file fits in the space available on disk once the files are
+	// removed prior to removing any of them.
+	available, err := m.diskSpace(m.dir)
+	if err != nil {
+		return err
+	}
+	if size > available+freedBytes {
+		return fmt.Errorf("%w: DAG file for epoch %d requires %d bytes, but "+
+			"only %d bytes are available in %s", ErrDAGDiskLimit, epoch, size,
+			available+freedBytes, m.dir)
+	}
+
+	for _, file := range remove {
+		err := os.Remove(file.path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeDAGFile generates the DAG for the provided epoch and writes it to the
+// associated DAG file.  The DAG is the full dataset used by full hashers with
+// the KawPoW parameters of the manager, and it is written in the dataset file
+// format so full hashers load it from the file as opposed to generating it.
+func (m *DAGManager) writeDAGFile(epoch int64) error {
+	// Ensure there is room for the DAG file prior to generating the DAG.
+	if err := m.ensureDAGSpace(epoch, m.dagFileSize(epoch)); err != nil {
+		return err
+	}
+
+	// Generate the dataset in the same manner as full hashers do.
+	kp := NewLightWithParams(m.params)
+	seed := EpochSeed(uint64(epoch))
+	cache := kp.generateCache(seed, m.params.CacheBytes(epoch))
+	dataset := kp.generateDataset(cache, m.params.DatasetBytes(epoch), nil)
+	return writeDatasetFile(m.DAGFilePath(epoch), epoch, seed, dataset)
+}
+
+// loadDAG reads the DAG for the provided epoch from the associated DAG file.
+// ErrCorruptDatasetFile is returned when the file is not a valid DAG file for
+// the epoch.
+func (m *DAGManager) loadDAG(epoch int64) ([]uint64, error) {
+	seed := EpochSeed(uint64(epoch))
+	numItems := m.params.DatasetBytes(epoch) / 8
+	return readDatasetFile(m.DAGFilePath(epoch), epoch, seed, numItems)
+}
+
+// storeDAG writes the provided DAG for the provided epoch, such as one a full
+// hasher generated, to the associated DAG file once there is room for it.
+//
+// ErrDAGDiskLimit is returned when the DAG file can't fit.
+func (m *DAGManager) storeDAG(epoch int64, dataset []uint64) error {
+	if err := m.ensureDAGSpace(epoch, m.dagFileSize(epoch)); err != nil {
+		return err
+	}
+	seed := EpochSeed(uint64(epoch))
+	return writeDatasetFile(m.DAGFilePath(epoch), epoch, seed, dataset)
+}
+
+// RegenerateDAG removes the cached DAG file for the provided epoch and starts
+// regenerating it in the background.  It returns the ID of the regeneration
+// job immediately without waiting for it to complete.  The state of the job
+// may be queried with LastJob.
+//
+// ErrDAGJobInProgress is returned when another regeneration is still running.
+func (m *DAGManager) RegenerateDAG(epoch int64) (uint64, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.lastJob != nil && m.lastJob.Status == DAGJobRunning {
+		return 0, ErrDAGJobInProgress
+	}
+
+	err := os.Remove(m.DAGFilePath(epoch))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+
+	job := &DAGJob{ID: m.nextJobID, Epoch: epoch, Status: DAGJobRunning}
+	m.nextJobID++
+	m.lastJob = job
+
+	go func() {
+		err := m.generate(epoch)
+
+		m.mtx.Lock()
+		if err != nil {
+			job.Status = DAGJobFailed
+			job.Err = err
+		} else {
+			job.Status = DAGJobDone
+		}
+		m.mtx.Unlock()
+	}()
+
+	return job.ID, nil
+}
+
+// DAGReady returns whether or not the complete DAG file for the provided epoch
+// is cached on disk and is not currently being regenerated.
+func (m *DAGManager) DAGReady(epoch int64) bool {
+	m.mtx.Lock()
+	regenerating := m.lastJob != nil && m.lastJob.Epoch == epoch &&
+		m.lastJob.Status == DAGJobRunning
+	m.mtx.Unlock()
+	if regenerating {
+		return false
+	}
+
+	info, err := os.Stat(m.DAGFilePath(epoch))
+	return err == nil && info.Mode().IsRegular() &&
+		uint64(info.Size()) == m.dagFileSize(epoch)
+}
+
+// LastJob returns a copy of the most recently started DAG regeneration job.
+// The returned flag is false when no job has been started.
+func (m *DAGManager) LastJob() (DAGJob, bool) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.lastJob == nil {
+		return DAGJob{}, false
+	}
+	return *m.lastJob, true
+}
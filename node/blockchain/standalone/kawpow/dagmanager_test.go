@@ -0,0 +1,372 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package kawpow
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testDAGParams are tiny KawPoW parameters used by the DAG manager tests so
+// that generating the DAG for an epoch is fast.
+var testDAGParams = Params{
+	EpochLength:        10,
+	DatasetInitBytes:   128 * 8,
+	DatasetGrowthBytes: 128,
+	CacheInitBytes:     1024,
+	CacheRounds:        3,
+}
+
+// TestRegenerateDAG ensures forcing regeneration of the DAG removes the cached
+// DAG file for the epoch, triggers regeneration in the background, and tracks
+// the state of the job.
+func TestRegenerateDAG(t *testing.T) {
+	const epoch = 3
+	m := NewDAGManager(t.TempDir(), 0, testDAGParams)
+
+	// Replace the generation function with one that signals the epoch it was
+	// invoked with and blocks until released to avoid generating a full DAG.
+	generated := make(chan int64, 1)
+	release := make(chan error)
+	m.generate = func(epoch int64) error {
+		generated <- epoch
+		return <-release
+	}
+
+	// Ensure there is no job prior to requesting regeneration.
+	if _, ok := m.LastJob(); ok {
+		t.Fatal("unexpected job prior to requesting regeneration")
+	}
+
+	// Create a stale DAG file for the epoch.
+	dagPath := m.DAGFilePath(epoch)
+	if err := os.WriteFile(dagPath, []byte("corrupt"), 0600); err != nil {
+		t.Fatalf("unable to create DAG file: %v", err)
+	}
+
+	// Ensure the DAG file is removed and regeneration is triggered for the
+	// requested epoch.
+	jobID, err := m.RegenerateDAG(epoch)
+	if err != nil {
+		t.Fatalf("unexpected error requesting regeneration: %v", err)
+	}
+	if jobID != 1 {
+		t.Fatalf("unexpected job ID -- got %d, want 1", jobID)
+	}
+	if _, err := os.Stat(dagPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("stale DAG file was not removed (stat err: %v)", err)
+	}
+	select {
+	case gotEpoch := <-generated:
+		if gotEpoch != epoch {
+			t.Fatalf("regenerated unexpected epoch -- got %d, want %d",
+				gotEpoch, epoch)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for regeneration to be triggered")
+	}
+
+	// Ensure the job is reported as running and another regeneration is
+	// rejected while it is.
+	job, ok := m.LastJob()
+	if !ok || job.ID != jobID || job.Epoch != epoch ||
+		job.Status != DAGJobRunning {
+
+		t.Fatalf("unexpected running job state: %+v (ok: %v)", job, ok)
+	}
+	if _, err := m.RegenerateDAG(epoch); !errors.Is(err, ErrDAGJobInProgress) {
+		t.Fatalf("unexpected error for concurrent regeneration -- got %v, "+
+			"want %v", err, ErrDAGJobInProgress)
+	}
+
+	// Ensure the job is reported as done once regeneration completes.
+	release <- nil
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, _ = m.LastJob()
+		if job.Status != DAGJobRunning {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for regeneration to complete")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if job.Status != DAGJobDone || job.Err != nil {
+		t.Fatalf("unexpected completed job state: %+v", job)
+	}
+
+	// Ensure a new regeneration is assigned the next job ID and regeneration
+	// failures are reported.
+	jobID, err = m.RegenerateDAG(epoch + 1)
+	if err != nil {
+		t.Fatalf("unexpected error requesting regeneration: %v", err)
+	}
+	if jobID != 2 {
+		t.Fatalf("unexpected job ID -- got %d, want 2", jobID)
+	}
+	<-generated
+	errGenerate := errors.New("generate failed")
+	release <- errGenerate
+	deadline = time.Now().Add(5 * time.Second)
+	for {
+		job, _ = m.LastJob()
+		if job.Status != DAGJobRunning {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for regeneration to complete")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if job.Status != DAGJobFailed || !errors.Is(job.Err, errGenerate) {
+		t.Fatalf("unexpected failed job state: %+v", job)
+	}
+}
+
+// TestDAGDiskLimit ensures the DAG files for the oldest epochs are pruned to
+// keep the DAG disk usage under the configured maximum and that generating a
+// DAG that can't fit fails gracefully.
+func TestDAGDiskLimit(t *testing.T) {
+	const fileSize = 100
+	m := NewDAGManager(t.TempDir(), 250, testDAGParams)
+
+	// Create DAG files for several epochs along with a file that is not a DAG
+	// file to ensure it is not considered.
+	for epoch := int64(1); epoch <= 3; epoch++ {
+		data := make([]byte, fileSize)
+		if err := os.WriteFile(m.DAGFilePath(epoch), data, 0600); err != nil {
+			t.Fatalf("unable to create DAG file: %v", err)
+		}
+	}
+	otherPath := filepath.Join(m.dir, "other.dat")
+	if err := os.WriteFile(otherPath, make([]byte, 1000), 0600); err != nil {
+		t.Fatalf("unable to create file: %v", err)
+	}
+
+	// Ensure making room for the DAG file for a new epoch prunes the oldest
+	// epochs until it fits under the limit.
+	if err := m.ensureDAGSpace(4, fileSize); err != nil {
+		t.Fatalf("unexpected error making room for DAG file: %v", err)
+	}
+	for epoch, wantExists := range map[int64]bool{1: false, 2: false, 3: true} {
+		_, err := os.Stat(m.DAGFilePath(epoch))
+		if exists := err == nil; exists != wantExists {
+			t.Fatalf("unexpected DAG file state for epoch %d -- got exists "+
+				"%v, want %v", epoch, exists, wantExists)
+		}
+	}
+	if _, err := os.Stat(otherPath); err != nil {
+		t.Fatalf("unexpected error for non-DAG file: %v", err)
+	}
+
+	// Ensure pruning to a given size reports the remaining size.
+	remaining, err := m.PruneDAGFiles(fileSize)
+	if err != nil {
+		t.Fatalf("unexpected error pruning DAG files: %v", err)
+	}
+	if remaining != fileSize {
+		t.Fatalf("unexpected remaining DAG file size -- got %d, want %d",
+			remaining, fileSize)
+	}
+
+	// Ensure a DAG file that can never fit under the limit is rejected
+	// without pruning.
+	err = m.ensureDAGSpace(5, 251)
+	if !errors.Is(err, ErrDAGDiskLimit) {
+		t.Fatalf("unexpected error -- got %v, want %v", err, ErrDAGDiskLimit)
+	}
+	if _, err := os.Stat(m.DAGFilePath(3)); err != nil {
+		t.Fatalf("unexpected pruning of DAG file: %v", err)
+	}
+
+	// Ensure regenerating a full DAG fails gracefully since it can't fit.
+	if _, err := m.RegenerateDAG(6); err != nil {
+		t.Fatalf("unexpected error requesting regeneration: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	var job DAGJob
+	for {
+		job, _ = m.LastJob()
+		if job.Status != DAGJobRunning {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for regeneration to complete")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if job.Status != DAGJobFailed || !errors.Is(job.Err, ErrDAGDiskLimit) {
+		t.Fatalf("unexpected failed job state: %+v", job)
+	}
+}
+
+// TestDAGSpaceAccounting ensures all DAG files, including temporary and legacy
+// DAG files, are considered when making room for a new DAG file, and that no
+// files are removed when the new DAG file can't fit on disk.
+func TestDAGSpaceAccounting(t *testing.T) {
+	const fileSize = 100
+	m := NewDAGManager(t.TempDir(), 250, testDAGParams)
+
+	// Create a legacy DAG file for epoch 1, a temporary DAG file for epoch 2,
+	// and a DAG file for epoch 3.
+	legacyPath := filepath.Join(m.dir, legacyDAGFileName(1))
+	tmpPath := m.DAGFilePath(2) + ".tmp"
+	dagPath := m.DAGFilePath(3)
+	for _, path := range []string{legacyPath, tmpPath, dagPath} {
+		if err := os.WriteFile(path, make([]byte, fileSize), 0600); err != nil {
+			t.Fatalf("unable to create DAG file: %v", err)
+		}
+	}
+	exists := func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+
+	// Ensure all of the DAG files are counted.
+	total, err := m.PruneDAGFiles(1000)
+	if err != nil {
+		t.Fatalf("unexpected error pruning DAG files: %v", err)
+	}
+	if total != 3*fileSize {
+		t.Fatalf("unexpected total DAG file size -- got %d, want %d", total,
+			3*fileSize)
+	}
+
+	// Ensure nothing is removed when the new DAG file for an epoch does not
+	// fit on disk even once the existing DAG file for the epoch is removed.
+	// Note that the maximum DAG disk usage is removed so no files need to be
+	// pruned.
+	m.maxDiskBytes = 0
+	m.diskSpace = func(string) (uint64, error) { return 0, nil }
+	err = m.ensureDAGSpace(3, 150)
+	if !errors.Is(err, ErrDAGDiskLimit) {
+		t.Fatalf("unexpected error -- got %v, want %v", err, ErrDAGDiskLimit)
+	}
+	for _, path := range []string{legacyPath, tmpPath, dagPath} {
+		if !exists(path) {
+			t.Fatalf("DAG file %s was removed without room for the new one",
+				path)
+		}
+	}
+
+	// Ensure the space used by the existing DAG file for the epoch is taken
+	// into account and the oldest DAG file, which is the legacy one, is
+	// pruned to stay under the maximum DAG disk usage.
+	m.maxDiskBytes = 250
+	m.diskSpace = func(string) (uint64, error) { return 50, nil }
+	if err := m.ensureDAGSpace(3, 150); err != nil {
+		t.Fatalf("unexpected error making room for DAG file: %v", err)
+	}
+	if exists(legacyPath) || exists(dagPath) || !exists(tmpPath) {
+		t.Fatalf("unexpected DAG files -- legacy %v, dag %v, temp %v",
+			exists(legacyPath), exists(dagPath), exists(tmpPath))
+	}
+}
+
+// TestDAGReady ensures the DAG for an epoch is only reported as ready when the
+// complete DAG file is cached on disk and it is not being regenerated.
+func TestDAGReady(t *testing.T) {
+	const epoch = 2
+	m := NewDAGManager(t.TempDir(), 0, testDAGParams)
+	generated := make(chan int64, 1)
+	release := make(chan error)
+	m.generate = func(epoch int64) error {
+		generated <- epoch
+		return <-release
+	}
+
+	// Ensure the DAG is not ready when there is no DAG file.
+	if m.DAGReady(epoch) {
+		t.Fatal("DAG reported ready without a DAG file")
+	}
+
+	// Ensure the DAG is not ready when the DAG file is incomplete.
+	dagPath := m.DAGFilePath(epoch)
+	if err := os.WriteFile(dagPath, []byte("partial"), 0600); err != nil {
+		t.Fatalf("unable to create DAG file: %v", err)
+	}
+	if m.DAGReady(epoch) {
+		t.Fatal("DAG reported ready with an incomplete DAG file")
+	}
+
+	// Ensure the DAG is ready once the DAG file is complete.  Note that the
+	// file is truncated to the full size so it is sparse on most filesystems.
+	if err := os.Truncate(dagPath, int64(m.dagFileSize(epoch))); err != nil {
+		t.Fatalf("unable to extend DAG file: %v", err)
+	}
+	if !m.DAGReady(epoch) {
+		t.Fatal("DAG not reported ready with a complete DAG file")
+	}
+	if m.DAGReady(epoch + 1) {
+		t.Fatal("DAG reported ready for an epoch without a DAG file")
+	}
+
+	// Ensure the DAG is not ready while it is being regenerated.
+	if _, err := m.RegenerateDAG(epoch); err != nil {
+		t.Fatalf("unexpected error requesting regeneration: %v", err)
+	}
+	<-generated
+	if err := os.WriteFile(dagPath, nil, 0600); err != nil {
+		t.Fatalf("unable to create DAG file: %v", err)
+	}
+	if err := os.Truncate(dagPath, int64(m.dagFileSize(epoch))); err != nil {
+		t.Fatalf("unable to extend DAG file: %v", err)
+	}
+	if m.DAGReady(epoch) {
+		t.Fatal("DAG reported ready while being regenerated")
+	}
+	release <- nil
+	deadline := time.Now().Add(5 * time.Second)
+	for !m.DAGReady(epoch) {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for DAG to become ready")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestWriteDAGFile ensures the DAG file written for an epoch contains the full
+// dataset used by full hashers and that full hashers load it as opposed to
+// generating the dataset again.
+func TestWriteDAGFile(t *testing.T) {
+	const epoch = 2
+	m := NewDAGManager(t.TempDir(), 0, testDAGParams)
+	if err := m.writeDAGFile(epoch); err != nil {
+		t.Fatalf("unexpected error writing DAG file: %v", err)
+	}
+	if !m.DAGReady(epoch) {
+		t.Fatal("DAG not reported ready after writing the DAG file")
+	}
+
+	// Ensure the DAG file contains the dataset generated by a full hasher.
+	kp := NewWithParams(testDAGParams)
+	if err := kp.GenerateDAG(epoch * uint64(testDAGParams.EpochLength)); err != nil {
+		t.Fatalf("unexpected error generating DAG: %v", err)
+	}
+	numItems := testDAGParams.DatasetBytes(epoch) / 8
+	dataset, err := readDatasetFile(m.DAGFilePath(epoch), epoch,
+		EpochSeed(epoch), numItems)
+	if err != nil {
+		t.Fatalf("unexpected error reading DAG file: %v", err)
+	}
+	if !reflect.DeepEqual(dataset, kp.dataset) {
+		t.Fatal("DAG file does not contain the full hasher dataset")
+	}
+
+	// Ensure a full hasher with the manager loads the DAG file.
+	loader := NewLightWithParams(testDAGParams)
+	loader.SetDAGManager(m)
+	if err := loader.LoadDAG(epoch * uint64(testDAGParams.EpochLength)); err != nil {
+		t.Fatalf("unexpected error loading DAG: %v", err)
+	}
+	if loader.cache != nil || !reflect.DeepEqual(loader.dataset, dataset) {
+		t.Fatal("DAG was not loaded from the DAG file")
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package kawpow
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+
+	"vigil.network/node/chaincfg/chainhash"
+)
+
+const (
+	// datasetFileVersion is the current version of the dataset file format.
+	datasetFileVersion = 1
+
+	// datasetFileHeaderLen is the length of the header of a dataset file.  It
+	// consists of the magic bytes, the file version, the epoch, the seed hash
+	// the dataset was generated from, the number of dataset items, and the
+	// CRC-32C checksum of the dataset items.
+	datasetFileHeaderLen = 4 + 4 + 8 + chainhash.HashSize + 8 + 4
+
+	// datasetFileChunkLen is the number of bytes of dataset items that are
+	// read or written at a time.
+	datasetFileChunkLen = 1 << 16
+)
+
+// datasetFileMagic identifies a file as a KawPoW dataset file.
+var datasetFileMagic = [4]byte{'K', 'P', 'D', 'S'}
+
+// ErrCorruptDatasetFile is returned when a dataset file does not contain a
+// valid dataset for the epoch and seed hash it is loaded for.
+var ErrCorruptDatasetFile = errors.New("corrupt dataset file")
+
+// crc32cTable is the table used to calculate the CRC-32C checksums of the
+// dataset items.
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// DatasetFilePath returns the path of the file in the provided directory that
+// is used to persist the full dataset for the provided epoch that is generated
+// from the provided seed hash.
+func DatasetFilePath(dir string, epoch int64, seed chainhash.Hash) string {
+	name := fmt.Sprintf("kawpow-dataset-%d-%x.dat", epoch, seed[:8])
+	return filepath.Join(dir, name)
+}
+
+// writeDatasetFile writes the provided dataset for the provided epoch and seed
+// hash to the provided path.  The dataset is written to a temporary file that
+// is renamed into place once complete so a partially written dataset file is
+// never observed.
+func writeDatasetFile(path string, epoch int64, seed chainhash.Hash, dataset []uint64) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	// Calculate the checksum of the dataset items for the header.
+	var buf [datasetFileChunkLen]byte
+	crc := crc32.New(crc32cTable)
+	err := forEachDatasetChunk(dataset, buf[:], func(chunk []byte) error {
+		_, err := crc.Write(chunk)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var header [datasetFileHeaderLen]byte
+	copy(header[0:4], datasetFileMagic[:])
+	binary.LittleEndian.PutUint32(header[4:8], datasetFileVersion)
+	binary.LittleEndian.PutUint64(header[8:16], uint64(epoch))
+	copy(header[16:48], seed[:])
+	binary.LittleEndian.PutUint64(header[48:56], uint64(len(dataset)))
+	binary.LittleEndian.PutUint32(header[56:60], crc.Sum32())
+
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if _, err := w.Write(header[:]); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	err = forEachDatasetChunk(dataset, buf[:], func(chunk []byte) error {
+		_, err := w.Write(chunk)
+		return err
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// forEachDatasetChunk serializes the provided dataset items in chunks using the
+// provided buffer and invokes the provided function with each chunk.
+func forEachDatasetChunk(dataset []uint64, buf []byte, f func([]byte) error) error {
+	itemsPerChunk := len(buf) / 8
+	for i := 0; i < len(dataset); i += itemsPerChunk {
+		end := i + itemsPerChunk
+		if end > len(dataset) {
+			end = len(dataset)
+		}
+		chunk := buf[:(end-i)*8]
+		for j, item := range dataset[i:end] {
+			binary.LittleEndian.PutUint64(chunk[j*8:], item)
+		}
+		if err := f(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDatasetFile reads the dataset with the provided number of items for the
+// provided epoch and seed hash from the provided path.
+//
+// ErrCorruptDatasetFile is returned when the file is not a dataset file, is
+// for a different epoch, seed hash, or number of items, or the dataset items
+// do not match the checksum in the header.
+func readDatasetFile(path string, epoch int64, seed chainhash.Hash, numItems uint64) ([]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+
+	var header [datasetFileHeaderLen]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("%w: unable to read header: %v",
+			ErrCorruptDatasetFile, err)
+	}
+	if !bytes.Equal(header[0:4], datasetFileMagic[:]) {
+		return nil, fmt.Errorf("%w: invalid magic bytes %x",
+			ErrCorruptDatasetFile, header[0:4])
+	}
+	if version := binary.LittleEndian.Uint32(header[4:8]); version != datasetFileVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d",
+			ErrCorruptDatasetFile, version)
+	}
+	if fileEpoch := int64(binary.LittleEndian.Uint64(header[8:16])); fileEpoch != epoch {
+		return nil, fmt.Errorf("%w: file is for epoch %d instead of %d",
+			ErrCorruptDatasetFile, fileEpoch, epoch)
+	}
+	var fileSeed chainhash.Hash
+	copy(fileSeed[:], header[16:48])
+	if fileSeed != seed {
+		return nil, fmt.Errorf("%w: file is for seed %s instead of %s",
+			ErrCorruptDatasetFile, fileSeed, seed)
+	}
+	if fileItems := binary.LittleEndian.Uint64(header[48:56]); fileItems != numItems {
+		return nil, fmt.Errorf("%w: file has %d items instead of %d",
+			ErrCorruptDatasetFile, fileItems, numItems)
+	}
+	wantChecksum := binary.LittleEndian.Uint32(header[56:60])
+
+	// Read the dataset items while calculating their checksum.
+	dataset := make([]uint64, numItems)
+	crc := crc32.New(crc32cTable)
+	var buf [datasetFileChunkLen]byte
+	const itemsPerChunk = datasetFileChunkLen / 8
+	for i := uint64(0); i < numItems; i += itemsPerChunk {
+		end := i + itemsPerChunk
+		if end > numItems {
+			end = numItems
+		}
+		chunk := buf[:(end-i)*8]
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return nil, fmt.Errorf("%w: unable to read items: %v",
+				ErrCorruptDatasetFile, err)
+		}
+		crc.Write(chunk)
+		for j := range dataset[i:end] {
+			dataset[i+uint64(j)] = binary.LittleEndian.Uint64(chunk[j*8:])
+		}
+	}
+	if _, err := r.ReadByte(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: unexpected trailing data",
+			ErrCorruptDatasetFile)
+	}
+	if checksum := crc.Sum32(); checksum != wantChecksum {
+		return nil, fmt.Errorf("%w: checksum %08x does not match %08x",
+			ErrCorruptDatasetFile, checksum, wantChecksum)
+	}
+	return dataset, nil
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package kawpow
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// TestLoadDAG ensures loading the DAG persists the generated dataset for the
+// epoch, later loads make use of the persisted dataset, and corrupt or
+// mismatched dataset files are regenerated as opposed to trusted.
+func TestLoadDAG(t *testing.T) {
+	params := Params{
+		EpochLength:        10,
+		DatasetInitBytes:   128 * 8,
+		DatasetGrowthBytes: 128,
+		CacheInitBytes:     1024,
+		CacheRounds:        3,
+	}
+	dir := t.TempDir()
+	dags := NewDAGManager(dir, 0, params)
+
+	// wantDataset returns the dataset generated for the provided epoch.
+	wantDataset := func(epoch uint64) []uint64 {
+		kp := NewLightWithParams(params)
+		kp.loadEpoch(epoch, nil)
+		return kp.dataset
+	}
+
+	// loadDAG returns a new hasher that persists datasets to the test
+	// directory via the DAG manager after loading the DAG for the provided block number with it.
+	loadDAG := func(blockNum uint64) *KawPow {
+		t.Helper()
+		kp := NewLightWithParams(params)
+		kp.SetDAGManager(dags)
+		if err := kp.LoadDAG(blockNum); err != nil {
+			t.Fatalf("block %d: unexpected error loading DAG: %v", blockNum,
+				err)
+		}
+		if kp.cacheGen != uint64(params.Epoch(int64(blockNum))) {
+			t.Fatalf("block %d: unexpected dataset epoch %d", blockNum,
+				kp.cacheGen)
+		}
+		return kp
+	}
+
+	// Ensure loading the DAG when there is no dataset file generates the
+	// dataset and persists it.
+	kp := loadDAG(15)
+	if kp.cache == nil {
+		t.Fatal("dataset was not generated")
+	}
+	if !reflect.DeepEqual(kp.dataset, wantDataset(1)) {
+		t.Fatal("generated dataset does not match")
+	}
+	path := DatasetFilePath(dir, 1, EpochSeed(1))
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("dataset file was not persisted: %v", err)
+	}
+
+	// Ensure a new hasher loads the persisted dataset instead of generating
+	// it.
+	kp = loadDAG(15)
+	if kp.cache != nil {
+		t.Fatal("persisted dataset was not used")
+	}
+	if !reflect.DeepEqual(kp.dataset, wantDataset(1)) {
+		t.Fatal("persisted dataset does not match")
+	}
+
+	// Ensure a corrupt dataset file is detected and regenerated.
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read dataset file: %v", err)
+	}
+	contents[len(contents)-1] ^= 0x01
+	if err := os.WriteFile(path, contents, 0600); err != nil {
+		t.Fatalf("unable to write dataset file: %v", err)
+	}
+	_, err = readDatasetFile(path, 1, EpochSeed(1), uint64(len(kp.dataset)))
+	if !errors.Is(err, ErrCorruptDatasetFile) {
+		t.Fatalf("unexpected error -- got %v, want %v", err,
+			ErrCorruptDatasetFile)
+	}
+	kp = loadDAG(15)
+	if kp.cache == nil {
+		t.Fatal("corrupt dataset file was trusted")
+	}
+	if !reflect.DeepEqual(kp.dataset, wantDataset(1)) {
+		t.Fatal("regenerated dataset does not match")
+	}
+	_, err = readDatasetFile(path, 1, EpochSeed(1), uint64(len(kp.dataset)))
+	if err != nil {
+		t.Fatalf("regenerated dataset file was not persisted: %v", err)
+	}
+
+	// Ensure a dataset file for a different epoch is detected and
+	// regenerated.
+	contents[len(contents)-1] ^= 0x01
+	wrongPath := DatasetFilePath(dir, 2, EpochSeed(2))
+	if err := os.WriteFile(wrongPath, contents, 0600); err != nil {
+		t.Fatalf("unable to write dataset file: %v", err)
+	}
+	numItems := params.DatasetBytes(2) / 8
+	_, err = readDatasetFile(wrongPath, 2, EpochSeed(2), numItems)
+	if !errors.Is(err, ErrCorruptDatasetFile) {
+		t.Fatalf("unexpected error -- got %v, want %v", err,
+			ErrCorruptDatasetFile)
+	}
+	kp = loadDAG(25)
+	if kp.cache == nil {
+		t.Fatal("dataset file for wrong epoch was trusted")
+	}
+	if !reflect.DeepEqual(kp.dataset, wantDataset(2)) {
+		t.Fatal("regenerated dataset does not match")
+	}
+
+	// Ensure a dataset file for a different seed is rejected.
+	_, err = readDatasetFile(path, 1, EpochSeed(3), params.DatasetBytes(1)/8)
+	if !errors.Is(err, ErrCorruptDatasetFile) {
+		t.Fatalf("unexpected error -- got %v, want %v", err,
+			ErrCorruptDatasetFile)
+	}
+
+	// Ensure loading the DAG for a resident dataset persists it when there is
+	// not already a dataset file for it.
+	kp = NewWithParams(params)
+	kp.SetDAGManager(dags)
+	if err := kp.LoadDAG(0); err != nil {
+		t.Fatalf("unexpected error loading DAG: %v", err)
+	}
+	numItems = params.DatasetBytes(0) / 8
+	path = DatasetFilePath(dir, 0, EpochSeed(0))
+	if _, err := readDatasetFile(path, 0, EpochSeed(0), numItems); err != nil {
+		t.Fatalf("resident dataset was not persisted: %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+//go:build !linux && !darwin && !freebsd
+
+package kawpow
+
+import "math"
+
+// availableDiskSpace returns the number of bytes available on the filesystem
+// that contains the provided directory.
+//
+// Determining the available space is not supported on this platform, so the
+// maximum possible value is returned to defer to the configured maximum DAG
+// disk usage instead.
+func availableDiskSpace(dir string) (uint64, error) {
+	return math.MaxUint64, nil
+}
@@ -0,0 +1,19 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+//go:build linux || darwin || freebsd
+
+package kawpow
+
+import "syscall"
+
+// availableDiskSpace returns the number of bytes available to unprivileged
+// users on the filesystem that contains the provided directory.
+func availableDiskSpace(dir string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -7,5 +7,5 @@ require (
 )
 
 replace (
-	vigil.network/node/chaincfg/chainhash => ../../../../chaincfg/chainhash
+	vigil.network/node/chaincfg/chainhash => ../../../chaincfg/chainhash
 )
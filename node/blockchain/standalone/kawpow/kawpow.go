@@ -7,58 +7,404 @@ package kawpow
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"math/bits"
+	"os"
 	"sync"
-	"time"
 
 	"vigil.network/node/chaincfg/chainhash"
 )
 
 const (
-	// KawPowEpochLength is the number of blocks before the seed needs to be regenerated
+	// KawPowEpochLength is the number of blocks before the seed needs to be
+	// regenerated for the default parameters.
 	KawPowEpochLength = 7500
 
-	// Cache sizes for different memory requirements
-	cacheSize   = 16 * 1024 * 1024  // 16MB
-	datasetSize = 2 * 1024 * 1024 * 1024  // 2GB
-	cacheRounds = 3  // Number of rounds for cache generation
+	// The target sizes of the cache and dataset for the first epoch and the
+	// number of bytes they grow by each epoch for the default parameters.
+	// The growth rates are the ones used by ethash.
+	cacheInitBytes     = 16 * 1024 * 1024       // 16MB
+	cacheGrowthBytes   = 128 * 1024             // 128KB
+	datasetInitBytes   = 2 * 1024 * 1024 * 1024 // 2GB
+	datasetGrowthBytes = 8 * 1024 * 1024        // 8MB
+
+	// cacheRounds is the number of rounds used to generate the cache for the
+	// default parameters.
+	cacheRounds = 3
 )
 
+// ErrDatasetSizeMismatch is returned when the dataset loaded by a full hasher
+// does not have the size required by the epoch of the block being hashed.
+var ErrDatasetSizeMismatch = errors.New("dataset size does not match epoch")
+
+// ErrSeedMismatch is returned when a full hasher is asked to hash with a seed
+// hash other than the one its dataset for the epoch of the block is built from.
+var ErrSeedMismatch = errors.New("seed hash does not match epoch")
+
+// ErrHeaderTooShort is returned when the header provided to be hashed is too
+// short to contain the fields KawPoW makes use of.
+var ErrHeaderTooShort = errors.New("header too short")
+
+// ErrInvalidHashInput is returned when the inputs to the KawPoW hash function
+// are malformed, such as a header hash of the wrong length or a dataset size
+// that is not a whole number of dataset entries.
+var ErrInvalidHashInput = errors.New("invalid hash input")
+
+const (
+	// headerHeightOffset is the offset of the block height in the serialized
+	// block headers provided to be hashed.  It follows the version, the
+	// previous block, merkle, and stake root hashes, the vote bits, the final
+	// state, the number of voters, fresh stake, and revocations, the pool
+	// size, the bits, and the stake difficulty.
+	headerHeightOffset = 4 + 32*3 + 2 + 6 + 2 + 1 + 1 + 4 + 4 + 8
+
+	// headerTimestampOffset is the offset of the block timestamp in the
+	// serialized block headers provided to be hashed.  It follows the height
+	// and the block size.
+	headerTimestampOffset = headerHeightOffset + 4 + 4
+
+	// minHeaderLen is the minimum length of the headers provided to be
+	// hashed, which must be large enough to contain the height and
+	// timestamp.
+	minHeaderLen = headerTimestampOffset + 4
+)
+
+// maxPreparedCaches is the maximum number of verification caches prepared via
+// PrepareCache that a hasher keeps resident.  This allows the caches for the
+// current and next epochs to remain resident along with the cache for the
+// previous epoch to handle reorganizations across an epoch boundary.
+const maxPreparedCaches = 3
+
+// Logger is the interface a hasher emits trace output through.  It is
+// satisfied by slog.Logger so callers are able to provide a subsystem logger.
+type Logger interface {
+	Tracef(format string, params ...interface{})
+}
+
+// disabledLogger is a Logger that discards all output.  It is the logger used
+// by hashers that have not been provided one via SetLogger.
+type disabledLogger struct{}
+
+// Tracef discards the provided output.
+//
+// This is part of the Logger interface.
+func (disabledLogger) Tracef(format string, params ...interface{}) {}
+
 // KawPow is a hasher implementing the KawPoW proof-of-work algorithm.
 type KawPow struct {
-	cache    []uint32
-	dataset  []uint64
-	cacheGen uint64
+	// The following fields are protected by the epoch mutex.
+	//
+	// cache and dataset house the cache and full dataset for the epoch given
+	// by cacheGen.  They are only regenerated when a block from a different
+	// epoch is hashed by a full hasher.
+	//
+	// lightSeed and lightCache house the most recently generated verification
+	// cache of a light hasher keyed by the seed hash it was generated from so
+	// that repeated hashes for the same seed do not regenerate it.
+	//
+	// dags is the DAG manager full datasets are persisted through and loaded
+	// from.  Datasets are not persisted when it is nil.
+	epochMtx   sync.Mutex
+	cache      []uint32
+	dataset    []uint64
+	cacheGen   uint64
+	lightSeed  chainhash.Hash
+	lightCache []uint32
+	dags       *DAGManager
+
+	// light indicates the hasher only makes use of the verification cache
+	// and computes any required dataset items on demand as opposed to
+	// generating the full dataset.
+	light bool
+
+	// params houses the KawPoW parameters the hasher uses.
+	params Params
+
+	// log is the logger trace output is emitted through.  It discards all
+	// output by default.
+	log Logger
+
+	// hashRateFn is invoked with the hash rate while searching for a nonce
+	// when it is set.
+	hashRateFn func(hashesPerSecond float64)
+
+	// The following fields are protected by the cache mutex.
+	//
+	// preparedCaches houses the verification caches prepared ahead of time
+	// keyed by the seed hash they were generated from.
+	//
+	// preparedSeeds tracks the order the caches were prepared in so the
+	// oldest one is evicted once the maximum number of caches is exceeded.
+	cacheMtx       sync.Mutex
+	preparedCaches map[chainhash.Hash][]uint32
+	preparedSeeds  []chainhash.Hash
 }
 
-// New creates a new KawPow hasher.
+// New creates a new KawPow hasher that uses the default parameters.
 func New() *KawPow {
+	return NewWithParams(DefaultParams())
+}
+
+// NewWithParams creates a new KawPow hasher that uses the provided parameters.
+// This allows networks to make use of epoch lengths, dataset sizes, and cache
+// sizes that differ from the defaults.
+func NewWithParams(params Params) *KawPow {
 	kp := &KawPow{
-		cache:   make([]uint32, cacheSize/4),
-		dataset:  make([]uint64, datasetSize/8),
-		cacheGen: uint64(time.Now().Unix() / 300),
+		cacheGen: 0,
+		params:   params,
+		log:      disabledLogger{},
 	}
 
-	// Generate initial cache
-	seed := make([]byte, 32)
-	binary.LittleEndian.PutUint64(seed, kp.cacheGen)
+	// Generate the initial cache and dataset from the seed for the first
+	// epoch so that all hashers start from the same state regardless of when
+	// they are created.
+	kp.loadEpoch(kp.cacheGen, nil)
 
-	var seedHash chainhash.Hash
-	copy(seedHash[:], seed)
+	return kp
+}
 
-	// Generate cache and dataset
-	kp.cache = kp.generateCache(seedHash)
-	kp.dataset = kp.generateDataset(kp.cache)
+// loadEpoch generates the cache and full dataset for the provided epoch from
+// the seed for the epoch and makes them the ones used by the hasher.  The
+// provided progress callback, which may be nil, is invoked as the dataset
+// items are generated.  See GenerateDAGWithProgress for details.
+//
+// This function MUST be called with the epoch mutex held (for writes) or prior
+// to the hasher being shared.
+func (k *KawPow) loadEpoch(epoch uint64, progress func(done, total int)) {
+	// Release the existing dataset prior to generating the new one so both of
+	// them are not resident at the same time.
+	k.cache = nil
+	k.dataset = nil
+
+	k.cache = k.generateCache(EpochSeed(epoch),
+		k.params.CacheBytes(int64(epoch)))
+	k.dataset = k.generateDataset(k.cache, k.params.DatasetBytes(int64(epoch)),
+		progress)
+	k.cacheGen = epoch
+}
 
-	return kp
+// SetDAGManager sets the DAG manager the full datasets for each epoch are
+// persisted through once generated and loaded from when they are needed again,
+// such as after a restart.  This ensures the datasets are stored in the DAG
+// files of the manager subject to its disk usage limits.  Datasets are not
+// persisted when the manager is nil, which is the default.
+//
+// The manager must make use of the same KawPoW parameters as the hasher.
+//
+// This function is safe for concurrent access.
+func (k *KawPow) SetDAGManager(m *DAGManager) {
+	k.epochMtx.Lock()
+	k.dags = m
+	k.epochMtx.Unlock()
+}
+
+// loadPersistedEpoch makes the full dataset for the provided epoch the one used
+// by the hasher.  The dataset is loaded from the DAG file for the epoch of the
+// DAG manager when it is valid.  Otherwise, it is generated and then persisted
+// to the file, replacing any corrupt file.  An error is only returned when the
+// generated dataset can't be persisted, in which case the dataset is still
+// loaded.
+//
+// The provided progress callback, which may be nil, is invoked as the dataset
+// items are generated, or once with all items done when the dataset is loaded
+// from the file instead.
+//
+// This function MUST be called with the epoch mutex held (for writes).
+func (k *KawPow) loadPersistedEpoch(epoch uint64, progress func(done, total int)) error {
+	if k.dags == nil {
+		k.log.Tracef("Generating dataset for epoch %d", epoch)
+		k.loadEpoch(epoch, progress)
+		return nil
+	}
+
+	dataset, err := k.dags.loadDAG(int64(epoch))
+	if err == nil {
+		k.cache = nil
+		k.dataset = dataset
+		k.cacheGen = epoch
+		k.log.Tracef("Loaded dataset for epoch %d from %s", epoch,
+			k.dags.DAGFilePath(int64(epoch)))
+		reportProgress(progress, len(dataset), len(dataset))
+		return nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		k.log.Tracef("Regenerating dataset for epoch %d: %v", epoch, err)
+	}
+
+	k.log.Tracef("Generating dataset for epoch %d", epoch)
+	k.loadEpoch(epoch, progress)
+	return k.dags.storeDAG(int64(epoch), k.dataset)
+}
+
+// epochDataset returns the full dataset for the provided epoch.  The dataset is
+// loaded when the hasher does not already have the dataset for the epoch
+// resident, which replaces the dataset for any other epoch.
+//
+// This function is safe for concurrent access.
+func (k *KawPow) epochDataset(epoch uint64) []uint64 {
+	k.epochMtx.Lock()
+	defer k.epochMtx.Unlock()
+	if k.dataset == nil || k.cacheGen != epoch {
+		if err := k.loadPersistedEpoch(epoch, nil); err != nil {
+			k.log.Tracef("Unable to persist dataset for epoch %d: %v",
+				epoch, err)
+		}
+	}
+	return k.dataset
+}
+
+// verificationCache returns the verification cache of the provided size in
+// bytes for the provided seed hash.  Prepared caches are used when available.
+// Otherwise, the most recently generated cache is reused when it is for the
+// same seed hash and size and a new one is generated and retained in its place
+// when it is not.
+//
+// This function is safe for concurrent access.
+func (k *KawPow) verificationCache(seed chainhash.Hash, cacheBytes uint64) []uint32 {
+	numWords := int(cacheBytes / 4)
+	if cache, ok := k.preparedCache(seed); ok && len(cache) == numWords {
+		return cache
+	}
+
+	k.epochMtx.Lock()
+	if k.lightCache != nil && k.lightSeed == seed &&
+		len(k.lightCache) == numWords {
+
+		cache := k.lightCache
+		k.epochMtx.Unlock()
+		return cache
+	}
+	k.epochMtx.Unlock()
+
+	// Generate the cache without holding the mutex so hashes for other seeds
+	// are not blocked while it is generated.
+	k.log.Tracef("Generating verification cache for seed %s", seed)
+	cache := k.generateCache(seed, cacheBytes)
+
+	k.epochMtx.Lock()
+	k.lightSeed = seed
+	k.lightCache = cache
+	k.epochMtx.Unlock()
+	return cache
+}
+
+// NewLight creates a new KawPow hasher that only makes use of the verification
+// cache for the epoch of each header it hashes and computes the dataset items
+// required by the hash on demand instead of generating the full dataset.
+//
+// Setting up a light hasher for a new epoch is substantially cheaper than
+// generating the full dataset at the expense of slower individual hashes, so
+// it is well suited to validating historical blocks that span many epochs.
+// Mining should make use of New instead.
+func NewLight() *KawPow {
+	return NewLightWithParams(DefaultParams())
+}
+
+// NewLightWithParams creates a new light KawPow hasher, as described by
+// NewLight, that uses the provided parameters.
+func NewLightWithParams(params Params) *KawPow {
+	return &KawPow{light: true, params: params, log: disabledLogger{}}
 }
 
-// generateCache generates the cache for the given seed.
-func (k *KawPow) generateCache(seed chainhash.Hash) []uint32 {
-	size := cacheSize / 4
+// SetLogger sets the logger the hasher emits trace output through, such as
+// when caches and datasets are generated, loaded, and persisted.  The hasher
+// produces no output by default.  Passing nil disables the output again.
+//
+// This function MUST be called prior to the hasher being shared since it is
+// not safe for concurrent access.
+func (k *KawPow) SetLogger(logger Logger) {
+	if logger == nil {
+		logger = disabledLogger{}
+	}
+	k.log = logger
+}
+
+// Params returns the KawPoW parameters the hasher uses.
+func (k *KawPow) Params() Params {
+	return k.params
+}
+
+// Close releases the cache, dataset, and any prepared verification caches held
+// by the hasher so the garbage collector is able to promptly reclaim them.
+// This is important for full hashers since their datasets are multiple
+// gigabytes.
+//
+// The hasher must not be used to hash concurrently with or after closing it
+// unless the caller is prepared to incur the cost of regenerating them.
+//
+// This function is safe for concurrent access.
+func (k *KawPow) Close() error {
+	k.cacheMtx.Lock()
+	k.preparedCaches = nil
+	k.preparedSeeds = nil
+	k.cacheMtx.Unlock()
+
+	k.epochMtx.Lock()
+	k.cache = nil
+	k.dataset = nil
+	k.lightCache = nil
+	k.epochMtx.Unlock()
+	return nil
+}
+
+// PrepareCache ensures the verification cache for the epoch that contains the
+// provided height is generated and resident so that hashing against the seed
+// hash of the epoch does not incur the cost of generating it.  Only the cache
+// is prepared, so this is suitable for nodes that only verify blocks.
+//
+// The oldest prepared cache is evicted once more than a few are resident.
+//
+// This function is safe for concurrent access.
+func (k *KawPow) PrepareCache(height int64) {
+	// Calculating the seed hash only fails for negative heights, in which
+	// case the seed for the first epoch is used, so the error is ignored.
+	seed, _ := k.params.CalcSeedHash(height, 0)
+	cacheBytes := k.params.CacheBytes(k.params.Epoch(height))
+
+	k.cacheMtx.Lock()
+	defer k.cacheMtx.Unlock()
+	if _, ok := k.preparedCaches[seed]; ok {
+		return
+	}
+	if k.preparedCaches == nil {
+		k.preparedCaches = make(map[chainhash.Hash][]uint32)
+	}
+	if len(k.preparedSeeds) >= maxPreparedCaches {
+		delete(k.preparedCaches, k.preparedSeeds[0])
+		k.preparedSeeds = k.preparedSeeds[1:]
+	}
+	k.preparedCaches[seed] = k.generateCache(seed, cacheBytes)
+	k.preparedSeeds = append(k.preparedSeeds, seed)
+}
+
+// CacheReady returns whether the verification cache for the epoch that
+// contains the provided height has been prepared via PrepareCache and is still
+// resident.
+//
+// This function is safe for concurrent access.
+func (k *KawPow) CacheReady(height int64) bool {
+	seed, _ := k.params.CalcSeedHash(height, 0)
+	_, ok := k.preparedCache(seed)
+	return ok
+}
+
+// preparedCache returns the prepared verification cache for the provided seed
+// hash along with whether or not it is resident.
+//
+// This function is safe for concurrent access.
+func (k *KawPow) preparedCache(seed chainhash.Hash) ([]uint32, bool) {
+	k.cacheMtx.Lock()
+	cache, ok := k.preparedCaches[seed]
+	k.cacheMtx.Unlock()
+	return cache, ok
+}
+
+// generateCache generates the cache of the provided size in bytes for the given
+// seed.
+func (k *KawPow) generateCache(seed chainhash.Hash, cacheBytes uint64) []uint32 {
+	size := cacheBytes / 4
 	cache := make([]uint32, size)
 
 	// Initialize the cache with the seed
@@ -70,7 +416,7 @@ func (k *KawPow) generateCache(seed chainhash.Hash) []uint32 {
 	}
 
 	// Generate the cache
-	for i := 1; i < cacheRounds; i++ {
+	for i := 1; i < k.params.CacheRounds; i++ {
 		hash = k.keccak512(hash)
 		for j := 0; j < len(hash)/4 && i*len(hash)/4+j < len(cache); j++ {
 			cache[i*len(hash)/4+j] = binary.LittleEndian.Uint32(hash[j*4:])
@@ -80,34 +426,63 @@ func (k *KawPow) generateCache(seed chainhash.Hash) []uint32 {
 	return cache
 }
 
-// generateDataset generates the dataset for the given cache.
-func (k *KawPow) generateDataset(cache []uint32) []uint64 {
-	size := datasetSize / 8
+// generateDataset generates the dataset of the provided size in bytes for the
+// given cache.  The provided progress callback, which may be nil, is invoked as
+// the items are generated.  See GenerateDAGWithProgress for details.
+//
+// The provided cache is not modified, so it is safe to concurrently make use
+// of it elsewhere, such as for computing dataset items on demand.
+func (k *KawPow) generateDataset(srcCache []uint32, datasetBytes uint64, progress func(done, total int)) []uint64 {
+	size := datasetBytes / 8
 	dataset := make([]uint64, size)
 
+	// Generation updates the cache as it progresses, so operate on a copy.
+	cache := make([]uint32, len(srcCache))
+	copy(cache, srcCache)
+
 	// Generate the dataset using the cache
 	for i := 0; i < len(dataset); i++ {
 		// Calculate the parent index
 		parentIndex := i % len(cache)
-		
+
 		// Get the parent value from cache
 		parent := uint64(cache[parentIndex])
-		
+
 		// Calculate the new value using the parent and the cache
 		newValue := parent ^ uint64(i)
-		
+
 		// Store the new value in the dataset
 		dataset[i] = newValue
-		
+
 		// Update the cache for the next iteration
 		if i < len(cache) {
 			cache[i] = uint32((newValue * 0x5bd1e995) ^ (newValue >> 31))
 		}
+
+		reportProgress(progress, i+1, len(dataset))
 	}
 
 	return dataset
 }
 
+// calcDatasetItem computes the dataset item at the provided index directly from
+// the provided cache.  It produces the same value that generateDataset stores
+// at the index without requiring the full dataset to be generated.
+func calcDatasetItem(cache []uint32, index int) uint64 {
+	parentIndex := index % len(cache)
+	parent := uint64(cache[parentIndex])
+
+	// Items past the length of the cache are derived from the cache entry
+	// that generateDataset updates after producing the item at the parent
+	// index, so apply the same update here.
+	if index >= len(cache) {
+		value := parent ^ uint64(parentIndex)
+		parent = uint64(uint32((value * 0x5bd1e995) ^ (value >> 31)))
+	}
+
+	return parent ^ uint64(index)
+}
+
 // keccakState implements the Keccak hash interface
 type keccakState interface {
 	io.Writer
@@ -118,34 +493,76 @@ type keccakState interface {
 	Read([]byte) (int, error)
 }
 
-// keccakF1600 implements the Keccak-f[1600] permutation
+// keccakDomainByte is the domain separation byte the original Keccak
+// submission, as used by KawPoW, pads messages with.  It differs from the 0x06
+// used by the standardized SHA-3 functions.
+const keccakDomainByte = 0x01
+
+// keccakF1600 implements a Keccak sponge built on the Keccak-f[1600]
+// permutation.  Data is absorbed via Write until the first call to Read, which
+// pads the data and switches the sponge to squeezing an output stream of any
+// length.
 type keccakF1600 struct {
 	a        [25]uint64
 	rate     int
-	off      int
-	buf      []byte
+	dsbyte   byte
 	hashSize int
+
+	// buf houses the data written since the last full block was absorbed
+	// while absorbing.
+	//
+	// squeezing indicates the sponge has been padded and is producing
+	// output, in which case off is the offset of the next output byte within
+	// the rate portion of the state.
+	buf       []byte
+	squeezing bool
+	off       int
 }
 
 // NewKeccak256 creates a new Keccak-256 hash
 func NewKeccak256() keccakState {
-	return &keccakF1600{rate: 136, hashSize: 32}
+	return &keccakF1600{rate: 136, dsbyte: keccakDomainByte, hashSize: 32}
 }
 
 // NewKeccak512 creates a new Keccak-512 hash
 func NewKeccak512() keccakState {
-	return &keccakF1600{rate: 72, hashSize: 64}
+	return &keccakF1600{rate: 72, dsbyte: keccakDomainByte, hashSize: 64}
+}
+
+// keccak256Pool and keccak512Pool provide reusable Keccak states for the hot
+// hashing paths, such as cache and DAG generation, in order to avoid allocating
+// a new state and its buffer for every hash.
+var (
+	keccak256Pool = sync.Pool{New: func() interface{} {
+		return NewKeccak256()
+	}}
+	keccak512Pool = sync.Pool{New: func() interface{} {
+		return NewKeccak512()
+	}}
+)
+
+// getKeccakState returns a Keccak state from the provided pool that has been
+// reset to its initial state.
+func getKeccakState(pool *sync.Pool) *keccakF1600 {
+	h := pool.Get().(*keccakF1600)
+	h.Reset()
+	return h
 }
 
 // Reset resets the hash to its initial state
 func (k *keccakF1600) Reset() {
 	k.a = [25]uint64{}
-	k.off = 0
 	k.buf = k.buf[:0]
+	k.squeezing = false
+	k.off = 0
 }
 
-// Write adds more data to the running hash
+// Write adds more data to the running hash.  It panics if any output has
+// already been read.
 func (k *keccakF1600) Write(p []byte) (int, error) {
+	if k.squeezing {
+		panic("keccak: Write after Read")
+	}
 	if k.buf == nil {
 		k.buf = make([]byte, 0, k.rate)
 	}
@@ -153,35 +570,50 @@ func (k *keccakF1600) Write(p []byte) (int, error) {
 	n := len(p)
 	k.buf = append(k.buf, p...)
 
-	// Process full blocks
+	// Process full blocks.  Any remaining data is moved to the start of the
+	// buffer so its capacity is retained for reuse.
 	for len(k.buf) >= k.rate {
 		k.absorb(k.buf[:k.rate])
-		k.buf = k.buf[k.rate:]
+		n := copy(k.buf, k.buf[k.rate:])
+		k.buf = k.buf[:n]
 	}
 
 	return n, nil
 }
 
-// Sum appends the current hash to b and returns the resulting slice
+// Sum appends the current hash to b and returns the resulting slice.  It does
+// not change the underlying hash state.  It panics if any output has already
+// been read.
 func (k *keccakF1600) Sum(b []byte) []byte {
-	hash := make([]byte, k.hashSize)
-	k.finalize(hash)
-	return append(b, hash...)
+	if k.squeezing {
+		panic("keccak: Sum after Read")
+	}
+
+	// Squeeze a copy of the state so more data may still be written.  The
+	// copy shares the buffered data, but padding never modifies it.
+	dup := *k
+	var hash [64]byte
+	dup.Read(hash[:k.hashSize])
+	return append(b, hash[:k.hashSize]...)
 }
 
-// finalize completes the hash and writes the result to hash
-func (k *keccakF1600) finalize(hash []byte) {
-	// Pad with 1 bit and 0 bits up to rate bytes
-	k.buf = append(k.buf, 0x01)
-	for len(k.buf) < k.rate {
-		k.buf = append(k.buf, 0)
-	}
-	k.absorb(k.buf[:k.rate])
+// padAndPermute pads the buffered data, absorbs the final block, and switches
+// the sponge to squeezing.
+func (k *keccakF1600) padAndPermute() {
+	// Apply the pad10*1 rule with the domain separation byte by appending
+	// the domain byte, zero-filling up to the rate, and setting the high bit
+	// of the final byte of the block.  The domain byte and the high bit share
+	// the same byte when there is only one byte of room left.  Write always
+	// absorbs full blocks, so there is always room for the domain byte.
+	var block [200]byte
+	n := copy(block[:], k.buf)
+	block[n] = k.dsbyte
+	block[k.rate-1] |= 0x80
+	k.absorb(block[:k.rate])
 
-	// Squeeze the state into the hash
-	for i := 0; i < k.hashSize/8; i++ {
-		binary.LittleEndian.PutUint64(hash[i*8:], k.a[i])
-	}
+	k.buf = k.buf[:0]
+	k.squeezing = true
+	k.off = 0
 }
 
 // Size returns the number of bytes Sum will return
@@ -194,19 +626,24 @@ func (k *keccakF1600) BlockSize() int {
 	return k.rate
 }
 
-// Read reads more data from the hash
+// Read squeezes more output from the hash.  The first call pads the data
+// written so far, after which no more data may be written.  The state is
+// permuted each time the rate portion of it has been fully output, so any
+// amount of output may be read.  It never returns an error.
 func (k *keccakF1600) Read(p []byte) (int, error) {
-	if len(p) == 0 {
-		return 0, nil
+	if !k.squeezing {
+		k.padAndPermute()
 	}
 
-	if len(k.buf) == 0 {
-		return 0, io.EOF
+	for i := range p {
+		if k.off == k.rate {
+			k.permute()
+			k.off = 0
+		}
+		p[i] = byte(k.a[k.off/8] >> (8 * (k.off % 8)))
+		k.off++
 	}
-
-	n := copy(p, k.buf)
-	k.buf = k.buf[n:]
-	return n, nil
+	return len(p), nil
 }
 
 // absorb absorbs a full block of data into the state
@@ -260,168 +697,270 @@ func (k *keccakF1600) permute() {
 
 // keccak256 computes the Keccak-256 hash of the input.
 func (k *KawPow) keccak256(data []byte) []byte {
-	h := NewKeccak256()
+	h := getKeccakState(&keccak256Pool)
 	h.Write(data)
-	return h.Sum(nil)
+	hash := h.Sum(make([]byte, 0, 32))
+	keccak256Pool.Put(h)
+	return hash
 }
 
 // keccak512 computes the Keccak-512 hash of the input.
 func (k *KawPow) keccak512(data []byte) []byte {
-	h := NewKeccak512()
+	h := getKeccakState(&keccak512Pool)
 	h.Write(data)
-	return h.Sum(nil)
+	hash := h.Sum(make([]byte, 0, 64))
+	keccak512Pool.Put(h)
+	return hash
 }
 
-// hashimoto implements the KawPoW hash function.
-func (k *KawPow) hashimoto(headerHash []byte, nonce, datasetSize uint64) ([]byte, []byte) {
-	if len(headerHash) != 32 {
-		panic(fmt.Sprintf("invalid header hash length: %d", len(headerHash)))
-	}
-	if datasetSize == 0 || datasetSize%128 != 0 {
-		panic(fmt.Sprintf("invalid dataset size: %d", datasetSize))
+// hashimoto implements the KawPoW hash function for a block at the provided
+// height.  The provided lookup function is used to retrieve dataset items by
+// index which allows the same algorithm to operate on either the full dataset
+// or dataset items computed on demand from the cache.
+//
+// ErrInvalidHashInput is returned when the header hash is not 32 bytes or the
+// dataset size is not a multiple of 128 that is large enough to hold at least
+// one dataset entry.
+func (k *KawPow) hashimoto(headerHash []byte, nonce uint64, height int64, datasetSize uint64, lookup func(index int) uint64) ([]byte, []byte, error) {
+	if err := checkHashInput(headerHash, datasetSize); err != nil {
+		return nil, nil, err
 	}
-	// Implementation of the KawPoW hash function
-	// This is a more complete implementation that uses the DAG for mixing
-
-	// Create a buffer with header + nonce
-	headerNonce := make([]byte, len(headerHash)+8)
-	copy(headerNonce, headerHash)
-	binary.LittleEndian.PutUint64(headerNonce[len(headerHash):], nonce)
-
-	// Calculate initial mix hash (keccak512 of header + nonce)
-	mix := k.keccak512(headerNonce)
-
-
-	// Number of mixing rounds (KawPoW uses 64 rounds)
-	const mixRounds = 64
-
-	// Calculate the number of 32-byte words in the DAG
-	words := datasetSize / 32
-
-	// Calculate the number of 32-byte words per mix (KawPoW uses 128 words)
-	const mixBytes = 128 * 32 // 128 words * 32 bytes per word
-	const mixWords = mixBytes / 4 // 1024 32-bit words
-
-	// Create a buffer for the mix
-	mixBuffer := make([]uint32, mixWords)
+	mixHash, result := kawpowHash(headerHash, nonce, uint64(height),
+		datasetSize, datasetWordLookup(lookup))
+	return mixHash, result, nil
+}
 
-	// Initialize mix buffer with the initial hash
-	for i := 0; i < len(mixBuffer); i++ {
-		if i*4+4 <= len(mix) {
-			mixBuffer[i] = binary.LittleEndian.Uint32(mix[i*4:])
-		}
+// hashimotoMix computes only the KawPoW mix digest for a block at the provided
+// height in the same manner as hashimoto without the final Keccak pass that
+// produces the hash.
+func (k *KawPow) hashimotoMix(headerHash []byte, nonce uint64, height int64, datasetSize uint64, lookup func(index int) uint64) ([32]byte, error) {
+	if err := checkHashInput(headerHash, datasetSize); err != nil {
+		return [32]byte{}, err
 	}
+	return kawpowMixDigest(headerHash, nonce, uint64(height), datasetSize,
+		datasetWordLookup(lookup)), nil
+}
 
-	// Main mixing loop
-	for i := 0; i < mixRounds; i++ {
-		// Calculate the new mix data index
-		parent := fnv(uint32(i)^mixBuffer[i%len(mixBuffer)], mixBuffer[(i+1)%len(mixBuffer)]) % uint32(words)
-		
-		// Get the parent data from the DAG
-		parentData := make([]byte, 64) // 64 bytes per DAG item
-		for j := 0; j < 16; j++ { // 16 uint32s = 64 bytes
-			if int(parent)*16+j < len(k.dataset) {
-				binary.LittleEndian.PutUint32(parentData[j*4:], uint32(k.dataset[int(parent)*16+j]))
-			}
-		}
-
-		// Mix the parent data with the current mix
-		for j := 0; j < len(mixBuffer); j++ {
-			if j*4+4 <= len(parentData) {
-				mixBuffer[j] = fnv(mixBuffer[j], binary.LittleEndian.Uint32(parentData[j*4:]))
-			}
-		}
+// checkHashInput returns ErrInvalidHashInput when the provided header hash is
+// not 32 bytes or the provided dataset size is not a multiple of 128 that is
+// large enough to hold at least one dataset entry.
+func checkHashInput(headerHash []byte, datasetSize uint64) error {
+	if len(headerHash) != 32 {
+		return fmt.Errorf("%w: header hash is %d bytes instead of 32",
+			ErrInvalidHashInput, len(headerHash))
 	}
-
-	// Compress the mix
-	compressedMix := make([]byte, 32)
-	for i := 0; i < len(mixBuffer) && i < 8; i++ {
-		binary.LittleEndian.PutUint32(compressedMix[i*4:], mixBuffer[i])
+	if datasetSize < progPowDAGEntryBytes || datasetSize%128 != 0 {
+		return fmt.Errorf("%w: dataset size of %d bytes is not a multiple "+
+			"of 128 of at least %d bytes", ErrInvalidHashInput, datasetSize,
+			progPowDAGEntryBytes)
 	}
+	return nil
+}
 
-	// Calculate the final hash (keccak256 of header + compressed mix + nonce)
-	hashInput := make([]byte, len(headerHash)+len(compressedMix)+8)
-	copy(hashInput, headerHash)
-	copy(hashInput[len(headerHash):], compressedMix)
-	binary.LittleEndian.PutUint64(hashInput[len(headerHash)+len(compressedMix):], nonce)
-
-	result := k.keccak256(hashInput)
-
-	return compressedMix, result
+// datasetWordLookup returns a function that returns the 32-bit word at the
+// provided index of the dataset whose 64-bit items are returned by the
+// provided lookup function.  The ProgPoW loop operates on these words, which
+// are the halves of the dataset items in little-endian order.
+func datasetWordLookup(lookup func(index int) uint64) func(index uint64) uint32 {
+	return func(index uint64) uint32 {
+		item := lookup(int(index / 2))
+		return uint32(item >> (32 * (index % 2)))
+	}
 }
 
-// fnv implements the Fowler-Noll-Vo hash function
-func fnv(a, b uint32) uint32 {
-	return (a * 0x01000193) ^ b
+// EpochBoundaryHeights returns all heights in the provided inclusive range of
+// heights that are the first block of an epoch for the default parameters.
+// These are the heights at which miners must switch to the DAG for the new
+// epoch.
+//
+// A nil slice is returned when the range does not contain any epoch
+// boundaries.
+func EpochBoundaryHeights(fromHeight, toHeight int64) []int64 {
+	params := DefaultParams()
+	return params.EpochBoundaryHeights(fromHeight, toHeight)
 }
 
-// CalcSeedHash calculates the seed hash for a given block height and timestamp
+// CalcSeedHash calculates the seed hash for a given block height using the
+// epoch length of the default parameters.  See Params.CalcSeedHash for
+// details.
 func CalcSeedHash(height int64, timestamp int64) (chainhash.Hash, error) {
-	// Calculate epoch number
-	epoch := height / KawPowEpochLength
+	params := DefaultParams()
+	return params.CalcSeedHash(height, timestamp)
+}
 
-	// Generate seed hash for this epoch
-	seed := make([]byte, 32)
-	binary.LittleEndian.PutUint64(seed[:8], uint64(epoch))
-	binary.LittleEndian.PutUint64(seed[8:16], uint64(timestamp))
+// HeaderHeight returns the block height encoded in the provided serialized
+// block header, which is expected to be in the form provided to Hash.
+//
+// ErrHeaderTooShort is returned when the header is too short to be hashed.
+func HeaderHeight(headerBytes []byte) (uint32, error) {
+	if len(headerBytes) < minHeaderLen {
+		return 0, fmt.Errorf("%w: got %d bytes, want at least %d",
+			ErrHeaderTooShort, len(headerBytes), minHeaderLen)
+	}
+	return binary.LittleEndian.Uint32(headerBytes[headerHeightOffset:]), nil
+}
 
-	// Use Keccak-256 for the seed hash (as specified in KawPoW)
-	hash := newKeccak256()
-	hash.Write(seed)
-	return chainhash.HashH(hash.Sum(nil)), nil
+// HeaderTimestamp returns the block timestamp, in seconds since the Unix epoch,
+// encoded in the provided serialized block header, which is expected to be in
+// the form provided to Hash.
+//
+// ErrHeaderTooShort is returned when the header is too short to be hashed.
+func HeaderTimestamp(headerBytes []byte) (uint32, error) {
+	if len(headerBytes) < minHeaderLen {
+		return 0, fmt.Errorf("%w: got %d bytes, want at least %d",
+			ErrHeaderTooShort, len(headerBytes), minHeaderLen)
+	}
+	return binary.LittleEndian.Uint32(headerBytes[headerTimestampOffset:]), nil
 }
+
+// Hash computes the KawPoW hash for the given header and nonce using the cache
+// or dataset for the epoch of the height encoded in the header.
 // It returns the mix hash and the final hash.
+//
+// Malformed inputs, such as headers that are too short, result in an error as
+// opposed to a panic since headers are untrusted data received from the
+// network.  ErrHeaderTooShort is returned for headers that are too short.
+//
+// This function is safe for concurrent access.
 func (k *KawPow) Hash(headerBytes []byte, nonce uint64) ([]byte, []byte, error) {
-	log.Printf("KawPow.Hash called with header length: %d, nonce: %d", len(headerBytes), nonce)
-	
-	if len(headerBytes) < 172 { // Ensure header is large enough for height and timestamp
-		return nil, nil, fmt.Errorf("header too short (got %d, want at least 172)", len(headerBytes))
+	height, err := HeaderHeight(headerBytes)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	height := binary.LittleEndian.Uint32(headerBytes[152:156])
-	timestamp := binary.LittleEndian.Uint32(headerBytes[168:172])
-	log.Printf("Extracted height: %d, timestamp: %d", height, timestamp)
-
-	log.Println("Calculating seed hash...")
-	seedHash, err := CalcSeedHash(int64(height), int64(timestamp))
+	seedHash, err := k.params.CalcSeedHash(int64(height), 0)
 	if err != nil {
-		log.Printf("Error calculating seed hash: %v", err)
 		return nil, nil, err
 	}
-	log.Printf("Seed hash: %x", seedHash)
 
-	log.Println("Generating cache...")
-	cache := k.generateCache(seedHash)
-	log.Printf("Generated cache with %d items", len(cache))
+	return k.hashWithSeed(headerBytes, nonce, int64(height), seedHash)
+}
 
-	if k.dataset == nil {
-		log.Println("Generating dataset...")
-		k.dataset = k.generateDataset(cache)
-	}
+// hashWithSeed computes the KawPoW hash for the given header and nonce using
+// the cache and dataset built from the provided seed hash.  The provided
+// height determines the size of the dataset.
+// It returns the mix hash and the final hash.
+func (k *KawPow) hashWithSeed(headerBytes []byte, nonce uint64, height int64, seedHash chainhash.Hash) ([]byte, []byte, error) {
+	return k.hashWithSeedMode(headerBytes, nonce, height, seedHash, k.light)
+}
 
-	if len(k.dataset) == 0 {
-		err := fmt.Errorf("empty dataset generated")
-		log.Println(err)
+// hashWithSeedMode computes the KawPoW hash for the given header and nonce in
+// the same manner as hashWithSeed except the provided flag determines whether
+// only the verification cache is used as opposed to the full dataset.
+func (k *KawPow) hashWithSeedMode(headerBytes []byte, nonce uint64, height int64, seedHash chainhash.Hash, light bool) ([]byte, []byte, error) {
+	hashFn, err := k.epochHashFunc(height, seedHash, light)
+	if err != nil {
 		return nil, nil, err
 	}
 
-	log.Println("Hashing header with Keccak-256...")
 	headerHash := k.keccak256(headerBytes)
-	log.Printf("Header hash: %x", headerHash)
-
-	log.Println("Running hashimoto...")
-	mixHash, result := k.hashimoto(headerHash, nonce, uint64(len(k.dataset)*8))
+	mixHash, result, err := hashFn(headerHash, nonce)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	if len(mixHash) == 0 || len(result) == 0 {
-		err := fmt.Errorf("empty hash result from hashimoto")
-		log.Println(err)
-		return nil, nil, err
+		return nil, nil, fmt.Errorf("empty hash result from hashimoto")
 	}
 
-	log.Printf("Hashimoto completed. Mix hash: %x, Result: %x", mixHash, result)
 	return mixHash, result, nil
 }
 
+// epochLookup returns a function that returns the dataset item at the provided
+// index for a block at the provided height using the cache and dataset built
+// from the provided seed hash along with the size of the dataset in bytes.  The
+// provided flag determines whether only the verification cache is used to
+// compute the items on demand as opposed to using the full dataset.
+func (k *KawPow) epochLookup(height int64, seedHash chainhash.Hash, light bool) (func(index int) uint64, uint64, error) {
+	// Light hashes compute the dataset items they need from the verification
+	// cache for the seed, while full hashes use the full dataset for the
+	// epoch of the block, which is only regenerated when the epoch changes.
+	datasetBytes := k.params.DAGSizeBytes(height)
+	if light {
+		cacheBytes := k.params.CacheBytes(k.params.Epoch(height))
+		cache := k.verificationCache(seedHash, cacheBytes)
+		return func(index int) uint64 {
+			return calcDatasetItem(cache, index)
+		}, datasetBytes, nil
+	}
+
+	// The full dataset is always built from the seed for the epoch of the
+	// block, so reject any other seed as opposed to silently hashing with a
+	// dataset that was not built from it.
+	epoch := uint64(k.params.Epoch(height))
+	if wantSeed := EpochSeed(epoch); seedHash != wantSeed {
+		return nil, 0, fmt.Errorf("%w: seed %s is not the seed %s for epoch "+
+			"%d", ErrSeedMismatch, seedHash, wantSeed, epoch)
+	}
+	dataset := k.epochDataset(epoch)
+	if len(dataset) == 0 {
+		return nil, 0, fmt.Errorf("empty dataset generated")
+	}
+
+	// The size of the dataset is dictated by the epoch of the block being
+	// hashed, so ensure the loaded dataset matches it as opposed to hashing
+	// against whatever dataset the hasher happened to build last.
+	if loadedBytes := uint64(len(dataset)) * 8; loadedBytes != datasetBytes {
+		return nil, 0, fmt.Errorf("%w: loaded dataset is %d bytes, but "+
+			"height %d requires %d bytes", ErrDatasetSizeMismatch,
+			loadedBytes, height, datasetBytes)
+	}
+	return func(index int) uint64 {
+		return dataset[index]
+	}, datasetBytes, nil
+}
+
+// epochHashFunc returns a function that computes the KawPoW hash of a header
+// hash and nonce for a block at the provided height using the cache and
+// dataset built from the provided seed hash.  The provided flag determines
+// whether only the verification cache is used as opposed to the full dataset.
+//
+// The cache or dataset is only resolved once, so the returned function allows
+// hashing many nonces, such as when searching for a solution, without
+// revisiting them for every nonce.
+func (k *KawPow) epochHashFunc(height int64, seedHash chainhash.Hash, light bool) (func(headerHash []byte, nonce uint64) ([]byte, []byte, error), error) {
+	lookup, datasetBytes, err := k.epochLookup(height, seedHash, light)
+	if err != nil {
+		return nil, err
+	}
+	return func(headerHash []byte, nonce uint64) ([]byte, []byte, error) {
+		return k.hashimoto(headerHash, nonce, height, datasetBytes, lookup)
+	}, nil
+}
+
+// ComputeMixDigest computes only the KawPoW mix digest for the given header and
+// nonce using the cache or dataset for the epoch of the height encoded in the
+// header.  It is the first value Hash returns, which is the digest committed to
+// by the mix digest field of block headers.  It is NOT the proof of work hash
+// that is compared against the target difficulty, which is the final hash Hash
+// returns.
+//
+// Computing the mix digest requires the entire ProgPoW loop, so it costs
+// essentially the same as Hash.  It only skips the final Keccak pass and is
+// intended for callers, such as a stratum server, that only need to validate a
+// submitted mix digest.
+//
+// ErrHeaderTooShort is returned for headers that are too short.
+//
+// This function is safe for concurrent access.
+func (k *KawPow) ComputeMixDigest(headerBytes []byte, nonce uint64) ([32]byte, error) {
+	height, err := HeaderHeight(headerBytes)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	seedHash, err := k.params.CalcSeedHash(int64(height), 0)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	lookup, datasetBytes, err := k.epochLookup(int64(height), seedHash,
+		k.light)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	headerHash := k.keccak256(headerBytes)
+	return k.hashimotoMix(headerHash, nonce, int64(height), datasetBytes,
+		lookup)
+}
+
 // Verify computes the KawPoW hash and mix digest for the given block data
 // Verify verifies the nonce of a block's header.
 func (k *KawPow) Verify(headerBytes []byte, nonce uint64, mixDigest, hash []byte) (bool, error) {
@@ -443,76 +982,227 @@ func (k *KawPow) Verify(headerBytes []byte, nonce uint64, mixDigest, hash []byte
 	return true, nil
 }
 
-// dagItem represents an item in the DAG
-type dagItem struct {
-	data [32]byte
-}
+// VerifyLight verifies the nonce of a block's header in the same manner as
+// Verify except it only makes use of the verification cache for the epoch of
+// the block and computes the few dataset items referenced while hashing from it
+// on demand.  This is the case even when the hasher is a full hasher, so it
+// never requires the full dataset to be generated.
+//
+// This allows nodes that only validate blocks to verify them with megabytes of
+// memory as opposed to the gigabytes required by the full dataset while
+// producing identical results.
+func (k *KawPow) VerifyLight(headerBytes []byte, nonce uint64, mixDigest, hash []byte) (bool, error) {
+	height, err := HeaderHeight(headerBytes)
+	if err != nil {
+		return false, err
+	}
+	seed, err := k.params.CalcSeedHash(int64(height), 0)
+	if err != nil {
+		return false, err
+	}
 
-// dagCache holds the generated DAG for a specific epoch
-type dagCache struct {
-	epoch   int64
-	items   []dagItem
-	created time.Time
+	computedMix, computedHash, err := k.hashWithSeedMode(headerBytes, nonce,
+		int64(height), seed, true)
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(computedMix, mixDigest) {
+		return false, nil
+	}
+	return bytes.Equal(computedHash, hash), nil
 }
 
-var (
-	// dagCacheLock protects access to dagCaches
-	dagCacheLock sync.Mutex
-	// dagCaches contains all active DAG caches
-	dagCaches = make(map[int64]*dagCache)
-)
+// VerifyWithSeed verifies the nonce of a block's header using the provided
+// seed hash to build the cache and dataset as opposed to deriving the seed
+// hash from the header as Verify does.  This is useful for external verifiers,
+// such as independent pool checkers, that already know the height and seed
+// hash of the block being verified.
+//
+// An error is returned when the provided height does not match the height
+// encoded in the header.  Full hashers only have the dataset built from the
+// seed for the epoch of the block, so ErrSeedMismatch is returned by them when
+// the provided seed hash is any other seed.
+func (k *KawPow) VerifyWithSeed(headerBytes []byte, height int64, seed chainhash.Hash, nonce uint64, mixDigest, hash []byte) (bool, error) {
+	headerHeight, err := HeaderHeight(headerBytes)
+	if err != nil {
+		return false, err
+	}
+	if int64(headerHeight) != height {
+		return false, fmt.Errorf("height %d does not match header height %d",
+			height, headerHeight)
+	}
 
-const KawPowDatasetItems = 16777216
+	computedMix, computedHash, err := k.hashWithSeed(headerBytes, nonce,
+		height, seed)
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(computedMix, mixDigest) {
+		return false, nil
+	}
+	return bytes.Equal(computedHash, hash), nil
+}
 
-// getDAG returns the DAG for the given epoch
-func getDAG(epoch int64, seed chainhash.Hash) (*dagCache, error) {
-	// TODO: Implement proper DAG caching
-	// For now, generate a new DAG each time
-	dag := &dagCache{
-		epoch:   epoch,
-		items:   make([]dagItem, KawPowDatasetItems),
-		created: time.Now(),
+// dagProgressSteps is the number of times the progress of generating a DAG is
+// reported over the course of generating it.
+const dagProgressSteps = 100
+
+// reportProgress invokes the provided progress callback, when it is not nil,
+// with the provided number of completed items and total number of items when
+// the completed items reach the next step of the progress, as defined by
+// dagProgressSteps, or the total.
+func reportProgress(progress func(done, total int), done, total int) {
+	if progress == nil {
+		return
 	}
+	interval := (total + dagProgressSteps - 1) / dagProgressSteps
+	if interval == 0 {
+		interval = 1
+	}
+	if done%interval == 0 || done == total {
+		progress(done, total)
+	}
+}
 
-	// Generate DAG items
-	h := newKeccak512()
-	seedBytes := seed[:]
+// GenerateDAG generates the full dataset needed for mining for the epoch that
+// contains the provided block number and makes it the one used by the hasher.
+// The dataset is not regenerated when it is already resident.
+//
+// This function is safe for concurrent access.
+func (k *KawPow) GenerateDAG(blockNum uint64) error {
+	return k.GenerateDAGWithProgress(blockNum, nil)
+}
 
-	for i := 0; i < KawPowDatasetItems; i++ {
-		h.Reset()
-		h.Write(seedBytes)
-		binary.Write(h, binary.LittleEndian, uint32(i))
-		itemHash := h.Sum(nil)
+// GenerateDAGWithProgress generates the full dataset needed for mining in the
+// same manner as GenerateDAG while invoking the provided progress callback with
+// the number of items generated so far along with the total number of items.
+// The callback is invoked roughly every one percent of the items and always
+// once all items are done, including when the dataset is already resident, so
+// callers such as user interfaces are able to display the progress.
+//
+// The callback is invoked from the goroutine that generates the dataset.  It
+// may be nil, in which case no progress is reported.
+//
+// This function is safe for concurrent access.
+func (k *KawPow) GenerateDAGWithProgress(blockNum uint64, progress func(done, total int)) error {
+	height := int64(blockNum)
+	if height < 0 {
+		return fmt.Errorf("%w: %d", ErrNegativeHeight, height)
+	}
+	epoch := uint64(k.params.Epoch(height))
 
-		copy(dag.items[i].data[:], itemHash)
+	k.epochMtx.Lock()
+	defer k.epochMtx.Unlock()
+	if k.dataset != nil && k.cacheGen == epoch {
+		reportProgress(progress, len(k.dataset), len(k.dataset))
+		return nil
 	}
+	k.log.Tracef("Generating dataset for epoch %d", epoch)
+	k.loadEpoch(epoch, progress)
+	return nil
+}
 
-	return dag, nil
+// LoadDAG ensures the full dataset for the epoch that contains the provided
+// block number is resident.  When a DAG manager is set via SetDAGManager, the
+// dataset is loaded from the DAG file for the epoch of the manager when present
+// and valid.  Otherwise, the dataset is generated and persisted to the file so
+// that later loads, such as after a restart, do not need to generate it again.
+//
+// Dataset files that are corrupt or for a different epoch or seed hash are
+// detected via the header and checksum stored in them and are regenerated as
+// opposed to trusted.
+//
+// This function is safe for concurrent access.
+func (k *KawPow) LoadDAG(blockNum uint64) error {
+	return k.LoadDAGWithProgress(blockNum, nil)
 }
 
-// GenerateDAG generates the DAG needed for mining.
-func (k *KawPow) GenerateDAG(blockNum uint64) error {
-	epoch := int64(blockNum / KawPowEpochLength)
-	seedHash, err := CalcSeedHash(int64(blockNum), 0)
-	if err != nil {
+// LoadDAGWithProgress ensures the full dataset for the epoch that contains the
+// provided block number is resident in the same manner as LoadDAG while
+// invoking the provided progress callback with the number of dataset items
+// generated so far along with the total number of items.  See
+// GenerateDAGWithProgress for details regarding the callback.
+//
+// This function is safe for concurrent access.
+func (k *KawPow) LoadDAGWithProgress(blockNum uint64, progress func(done, total int)) error {
+	height := int64(blockNum)
+	if height < 0 {
+		return fmt.Errorf("%w: %d", ErrNegativeHeight, height)
+	}
+	epoch := uint64(k.params.Epoch(height))
+
+	k.epochMtx.Lock()
+	defer k.epochMtx.Unlock()
+	if k.dataset == nil || k.cacheGen != epoch {
+		return k.loadPersistedEpoch(epoch, progress)
+	}
+	reportProgress(progress, len(k.dataset), len(k.dataset))
+
+	// Persist the resident dataset when there is not already a file for it,
+	// such as when it was generated prior to setting the DAG manager.
+	if k.dags == nil {
+		return nil
+	}
+	path := k.dags.DAGFilePath(int64(epoch))
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
 		return err
 	}
-	_, err = getDAG(epoch, seedHash)
-	return err
+	return k.dags.storeDAG(int64(epoch), k.dataset)
 }
 
-// LoadDAG loads the DAG from disk or generates it if it doesn't exist.
-func (k *KawPow) LoadDAG(blockNum uint64) error {
-	// TODO: Implement DAG loading/generation
-	return k.GenerateDAG(blockNum)
+// GetSeedHash returns the seed hash for the given block number using the epoch
+// length of the default parameters.  See EpochSeed for details.
+func GetSeedHash(blockNum uint64) []byte {
+	seed := EpochSeed(blockNum / KawPowEpochLength)
+	return seed[:]
 }
 
-// GetSeedHash returns the seed hash for the given block number.
-func GetSeedHash(blockNum uint64) []byte {
-	seed := make([]byte, 32)
-	for i := uint64(0); i < blockNum/KawPowEpochLength; i++ {
-		seed = chainhash.HashB(seed)
+// maxCachedEpochSeeds is the maximum number of epoch seeds retained by the
+// epoch seed cache.  This is large enough to cover the epochs of any
+// reasonable chain while limiting the memory the cache is able to consume to
+// 2 MiB when seeds for absurdly high epochs are requested.
+const maxCachedEpochSeeds = 1 << 16
+
+var (
+	// epochSeedsMtx protects access to epochSeeds.
+	epochSeedsMtx sync.Mutex
+
+	// epochSeeds houses the previously calculated seed for each epoch indexed
+	// by the epoch so seeds for later epochs only need to be calculated
+	// forward from the seed for the highest cached epoch.
+	epochSeeds = []chainhash.Hash{{}}
+)
+
+// EpochSeed returns the seed hash for the provided epoch.  The seed for the
+// first epoch is all zeros and the seed for each subsequent epoch is the
+// Keccak-256 hash of the seed for the previous one.
+//
+// The seeds are cached, so only the seeds for epochs after the highest epoch
+// previously requested need to be calculated.
+//
+// This function is safe for concurrent access.
+func EpochSeed(epoch uint64) chainhash.Hash {
+	epochSeedsMtx.Lock()
+	defer epochSeedsMtx.Unlock()
+
+	numCached := uint64(len(epochSeeds))
+	if epoch < numCached {
+		return epochSeeds[epoch]
+	}
+
+	// Hash forward from the seed for the highest cached epoch while caching
+	// the intermediate seeds up to the maximum.
+	seed := epochSeeds[numCached-1]
+	h := getKeccakState(&keccak256Pool)
+	for i := numCached; i <= epoch; i++ {
+		h.Reset()
+		h.Write(seed[:])
+		h.Sum(seed[:0])
+		if i < maxCachedEpochSeeds {
+			epochSeeds = append(epochSeeds, seed)
+		}
 	}
+	keccak256Pool.Put(h)
 	return seed
 }
 
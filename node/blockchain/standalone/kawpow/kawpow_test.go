@@ -5,7 +5,19 @@
 package kawpow
 
 import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
+
+	"vigil.network/node/chaincfg/chainhash"
 )
 
 // TestBasicHash verifies the basic KawPoW hashing functionality
@@ -41,39 +53,1517 @@ func TestBasicHash(t *testing.T) {
 	t.Log("Verification successful")
 }
 
-// TestSeedHash verifies the seed hash calculation.
+// TestNewDeterministicCache ensures hashers created at different times start
+// from identical caches and datasets that are derived from the seed for the
+// first epoch as opposed to the time they were created.
+func TestNewDeterministicCache(t *testing.T) {
+	// Ensure the seed for the first epoch is all zeros and that the seed for
+	// the next epoch is the hash of it.
+	var zeroSeed chainhash.Hash
+	if seed := EpochSeed(0); seed != zeroSeed {
+		t.Fatalf("unexpected epoch 0 seed: got %s, want %s", seed, zeroSeed)
+	}
+	var want chainhash.Hash
+	copy(want[:], new(KawPow).keccak256(zeroSeed[:]))
+	if seed := EpochSeed(1); seed != want {
+		t.Fatalf("unexpected epoch 1 seed: got %s, want %s", seed, want)
+	}
+
+	// Create a hasher and retain its cache along with a sample of its dataset
+	// before releasing it so the full datasets for both hashers do not need to
+	// be held in memory at the same time.
+	firstCache, firstDataset := func() ([]uint32, []uint64) {
+		kp := New()
+		if kp.cacheGen != 0 {
+			t.Fatalf("unexpected cache epoch: got %d, want 0", kp.cacheGen)
+		}
+		return kp.cache, append([]uint64(nil), kp.dataset[:1024]...)
+	}()
+	runtime.GC()
+
+	// Ensure a hasher created later starts from the same state.
+	time.Sleep(time.Second)
+	second := New()
+	if second.cacheGen != 0 {
+		t.Fatalf("unexpected cache epoch: got %d, want 0", second.cacheGen)
+	}
+	if !reflect.DeepEqual(second.cache, firstCache) {
+		t.Fatal("caches for hashers created at different times differ")
+	}
+	if !reflect.DeepEqual(second.dataset[:1024], firstDataset) {
+		t.Fatal("datasets for hashers created at different times differ")
+	}
+}
+
+// TestSeedHash ensures the seed hash is derived purely from the epoch of the
+// height by iterating Keccak-256 starting from all zeros for the first epoch.
 func TestSeedHash(t *testing.T) {
 	tests := []struct {
-		name     string
-		height   int64
-		time     int64
-		expected string
+		name     string // test description
+		height   int64  // block height
+		time     int64  // block timestamp
+		expected string // expected seed hash in raw byte order
+	}{{
+		name:     "epoch 0 first block",
+		height:   0,
+		time:     0x5f5e100,
+		expected: "0000000000000000000000000000000000000000000000000000000000000000",
+	}, {
+		name:     "epoch 0 last block",
+		height:   KawPowEpochLength - 1,
+		time:     0x61c402e0,
+		expected: "0000000000000000000000000000000000000000000000000000000000000000",
+	}, {
+		name:     "epoch 1",
+		height:   KawPowEpochLength,
+		time:     0x5f5e101,
+		expected: "290decd9548b62a8d60345a988386fc84ba6bc95484008f6362f93160ef3e563",
+	}, {
+		name:     "epoch 1 different timestamp",
+		height:   KawPowEpochLength + 1,
+		time:     0,
+		expected: "290decd9548b62a8d60345a988386fc84ba6bc95484008f6362f93160ef3e563",
+	}, {
+		name:     "epoch 2",
+		height:   2*KawPowEpochLength + 5,
+		time:     0x61c402e0,
+		expected: "510e4e770828ddbf7f7b00ab00a9f6adaf81c0dc9cc85f1f8249c256942d61d9",
+	}}
+
+	for _, test := range tests {
+		seed, err := CalcSeedHash(test.height, test.time)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.name, err)
+			continue
+		}
+		if got := hex.EncodeToString(seed[:]); got != test.expected {
+			t.Errorf("%q: unexpected seed hash -- got %s, want %s", test.name,
+				got, test.expected)
+			continue
+		}
+
+		// Ensure the seed hash matches the other methods of deriving it.
+		epoch := uint64(test.height / KawPowEpochLength)
+		if epochSeed := EpochSeed(epoch); epochSeed != seed {
+			t.Errorf("%q: mismatched epoch seed -- got %s, want %s",
+				test.name, epochSeed, seed)
+		}
+		legacySeed := GetSeedHash(uint64(test.height))
+		if !bytes.Equal(legacySeed, seed[:]) {
+			t.Errorf("%q: mismatched legacy seed -- got %x, want %x",
+				test.name, legacySeed, seed[:])
+		}
+	}
+}
+
+// TestEpochSeedCache ensures seeds for epochs beyond the highest cached epoch
+// are calculated forward from it and cached, that the cached seeds match the
+// seeds calculated from the first epoch, and that seed hashes for negative
+// heights are rejected.
+func TestEpochSeedCache(t *testing.T) {
+	// calcSeed calculates the seed for the provided epoch from the first
+	// epoch without making use of the cache.
+	kp := new(KawPow)
+	calcSeed := func(epoch int) chainhash.Hash {
+		var seed chainhash.Hash
+		for i := 0; i < epoch; i++ {
+			copy(seed[:], kp.keccak256(seed[:]))
+		}
+		return seed
+	}
+
+	// Start from a cache that only contains the first epoch while restoring
+	// the original cache once the test completes.
+	epochSeedsMtx.Lock()
+	origSeeds := epochSeeds
+	epochSeeds = []chainhash.Hash{{}}
+	epochSeedsMtx.Unlock()
+	defer func() {
+		epochSeedsMtx.Lock()
+		epochSeeds = origSeeds
+		epochSeedsMtx.Unlock()
+	}()
+
+	// Ensure requesting a seed caches the seeds for all epochs up to it and
+	// requesting one for an earlier epoch does not change the cache.
+	for _, epoch := range []int{5, 3, 12} {
+		if got, want := EpochSeed(uint64(epoch)), calcSeed(epoch); got != want {
+			t.Fatalf("epoch %d: unexpected seed -- got %s, want %s", epoch,
+				got, want)
+		}
+	}
+	if len(epochSeeds) != 13 {
+		t.Fatalf("unexpected number of cached seeds -- got %d, want 13",
+			len(epochSeeds))
+	}
+	for epoch, seed := range epochSeeds {
+		if want := calcSeed(epoch); seed != want {
+			t.Fatalf("epoch %d: unexpected cached seed -- got %s, want %s",
+				epoch, seed, want)
+		}
+	}
+
+	// Ensure seed hashes for negative heights are rejected with the seed for
+	// the first epoch.
+	params := DefaultParams()
+	seed, err := params.CalcSeedHash(-1, 0)
+	if !errors.Is(err, ErrNegativeHeight) {
+		t.Fatalf("unexpected error -- got %v, want %v", err,
+			ErrNegativeHeight)
+	}
+	if seed != (chainhash.Hash{}) {
+		t.Fatalf("unexpected seed for negative height -- got %s, want %s",
+			seed, chainhash.Hash{})
+	}
+}
+
+// TestLightVerifyAcrossEpochs ensures that headers for a chain that crosses a
+// KawPoW epoch boundary are accepted when verified with the light hasher even
+// though the epoch DAG for the earlier blocks differs from that of the tip.
+func TestLightVerifyAcrossEpochs(t *testing.T) {
+	// makeHeader returns a serialized header for the provided height with the
+	// height and timestamp at the offsets the hasher extracts them from.
+	makeHeader := func(height uint32) []byte {
+		header := make([]byte, 180)
+		copy(header, "Test header for light verification")
+		binary.LittleEndian.PutUint32(header[headerHeightOffset:], height)
+		binary.LittleEndian.PutUint32(header[headerTimestampOffset:], 0x61c402e0+height*300)
+		return header
+	}
+
+	// Produce a proof for each block spanning the last two blocks of the
+	// first epoch and the first two blocks of the second one.
+	type proof struct {
+		header    []byte
+		nonce     uint64
+		mixDigest []byte
+		hash      []byte
+	}
+	var proofs []proof
+	miner := NewLight()
+	for height := uint32(KawPowEpochLength - 2); height < KawPowEpochLength+2; height++ {
+		header := makeHeader(height)
+		nonce := uint64(height) * 7
+		mixDigest, hash, err := miner.Hash(header, nonce)
+		if err != nil {
+			t.Fatalf("height %d: unexpected hash error: %v", height, err)
+		}
+		proofs = append(proofs, proof{header, nonce, mixDigest, hash})
+	}
+
+	// Ensure the epochs actually differ by hashing the same header data and
+	// nonce with a height from each epoch.
+	if bytes.Equal(proofs[1].hash, proofs[2].hash) {
+		t.Fatal("hashes for blocks in different epochs unexpectedly match")
+	}
+
+	// Verify all blocks with a separate light hasher as a syncing node would.
+	verifier := NewLight()
+	for i, p := range proofs {
+		valid, err := verifier.Verify(p.header, p.nonce, p.mixDigest, p.hash)
+		if err != nil {
+			t.Fatalf("proof %d: unexpected verify error: %v", i, err)
+		}
+		if !valid {
+			t.Fatalf("proof %d: valid proof was rejected", i)
+		}
+	}
+
+	// Ensure a proof is rejected when the mix digest does not match.
+	badMix := append([]byte(nil), proofs[0].mixDigest...)
+	badMix[0] ^= 0x01
+	valid, err := verifier.Verify(proofs[0].header, proofs[0].nonce, badMix,
+		proofs[0].hash)
+	if err != nil {
+		t.Fatalf("unexpected verify error: %v", err)
+	}
+	if valid {
+		t.Fatal("proof with invalid mix digest was accepted")
+	}
+}
+
+// TestCalcDatasetItem ensures dataset items computed on demand from the cache
+// match those produced by the same process generateDataset uses to produce the
+// full dataset.
+func TestCalcDatasetItem(t *testing.T) {
+	cache := []uint32{0x01234567, 0x89abcdef, 0xdeadbeef, 0x0badf00d}
+
+	// Build a small dataset in the same manner as generateDataset.
+	mutCache := append([]uint32(nil), cache...)
+	dataset := make([]uint64, len(cache)*4)
+	for i := range dataset {
+		value := uint64(mutCache[i%len(mutCache)]) ^ uint64(i)
+		dataset[i] = value
+		if i < len(mutCache) {
+			mutCache[i] = uint32((value * 0x5bd1e995) ^ (value >> 31))
+		}
+	}
+
+	for i, want := range dataset {
+		if got := calcDatasetItem(cache, i); got != want {
+			t.Errorf("item %d: got %x, want %x", i, got, want)
+		}
+	}
+}
+
+// TestEpochBoundaryHeights ensures the heights that begin a new epoch are
+// correctly identified within a range of heights.
+func TestEpochBoundaryHeights(t *testing.T) {
+	const epochLen = KawPowEpochLength
+	tests := []struct {
+		name string
+		from int64
+		to   int64
+		want []int64
+	}{{
+		name: "range spanning two boundaries",
+		from: epochLen - 10,
+		to:   2*epochLen + 10,
+		want: []int64{epochLen, 2 * epochLen},
+	}, {
+		name: "range starting and ending on boundaries",
+		from: epochLen,
+		to:   3 * epochLen,
+		want: []int64{epochLen, 2 * epochLen, 3 * epochLen},
+	}, {
+		name: "range within a single epoch",
+		from: epochLen + 1,
+		to:   2*epochLen - 1,
+		want: nil,
+	}, {
+		name: "range including genesis",
+		from: 0,
+		to:   epochLen - 1,
+		want: []int64{0},
+	}, {
+		name: "negative start height",
+		from: -epochLen,
+		to:   epochLen,
+		want: []int64{0, epochLen},
+	}, {
+		name: "inverted range",
+		from: 2 * epochLen,
+		to:   epochLen,
+		want: nil,
+	}}
+
+	for _, test := range tests {
+		got := EpochBoundaryHeights(test.from, test.to)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: unexpected boundary heights -- got %v, want %v",
+				test.name, got, test.want)
+		}
+	}
+}
+
+// TestVerifyLight ensures verifying proofs with only the verification cache
+// produces identical results to verifying them with the full dataset and does
+// not require the full dataset.
+func TestVerifyLight(t *testing.T) {
+	params := Params{
+		EpochLength:        10,
+		DatasetInitBytes:   128 * 8,
+		DatasetGrowthBytes: 128,
+		CacheInitBytes:     1024,
+		CacheRounds:        3,
+	}
+	const nonce = 0x0102030405060708
+	makeHeader := func(height uint32) []byte {
+		header := make([]byte, 180)
+		copy(header, "Test header for light verification")
+		binary.LittleEndian.PutUint32(header[headerHeightOffset:], height)
+		return header
+	}
+
+	// Produce proofs on both sides of an epoch boundary with a full hasher
+	// and ensure they are accepted by both methods of verification.
+	miner := NewWithParams(params)
+	for _, height := range []uint32{9, 10} {
+		header := makeHeader(height)
+		mixDigest, hash, err := miner.Hash(header, nonce)
+		if err != nil {
+			t.Fatalf("height %d: unexpected hash error: %v", height, err)
+		}
+		valid, err := miner.Verify(header, nonce, mixDigest, hash)
+		if err != nil || !valid {
+			t.Fatalf("height %d: Verify rejected valid proof (valid %v, "+
+				"err %v)", height, valid, err)
+		}
+
+		// Ensure light verification with the full hasher does not result in
+		// the dataset being regenerated.
+		dataset := &miner.dataset[0]
+		valid, err = miner.VerifyLight(header, nonce, mixDigest, hash)
+		if err != nil || !valid {
+			t.Fatalf("height %d: VerifyLight rejected valid proof (valid %v, "+
+				"err %v)", height, valid, err)
+		}
+		if &miner.dataset[0] != dataset {
+			t.Fatalf("height %d: dataset regenerated by light verification",
+				height)
+		}
+
+		// Ensure a light hasher that never generates the dataset accepts the
+		// proof and rejects a tampered one.
+		verifier := NewLightWithParams(params)
+		valid, err = verifier.VerifyLight(header, nonce, mixDigest, hash)
+		if err != nil || !valid {
+			t.Fatalf("height %d: VerifyLight rejected valid proof (valid %v, "+
+				"err %v)", height, valid, err)
+		}
+		if verifier.dataset != nil {
+			t.Fatalf("height %d: light hasher generated the dataset", height)
+		}
+		badMix := append([]byte(nil), mixDigest...)
+		badMix[0] ^= 0x01
+		valid, err = verifier.VerifyLight(header, nonce, badMix, hash)
+		if err != nil {
+			t.Fatalf("height %d: unexpected verify error: %v", height, err)
+		}
+		if valid {
+			t.Fatalf("height %d: proof with tampered mix digest was "+
+				"accepted", height)
+		}
+	}
+
+	// Ensure a header that is too short is rejected.
+	_, err := miner.VerifyLight(make([]byte, minHeaderLen-1), nonce, nil, nil)
+	if !errors.Is(err, ErrHeaderTooShort) {
+		t.Fatal("short header was not rejected")
+	}
+}
+
+// TestVerifyWithSeed ensures verifying a proof with an explicitly provided
+// height and seed hash agrees with verifying it with the seed hash derived from
+// the header.
+func TestVerifyWithSeed(t *testing.T) {
+	const height = KawPowEpochLength + 5
+	const timestamp = 0x61c402e0
+	header := make([]byte, 180)
+	copy(header, "Test header for seed verification")
+	binary.LittleEndian.PutUint32(header[headerHeightOffset:], height)
+	binary.LittleEndian.PutUint32(header[headerTimestampOffset:], timestamp)
+	const nonce = 0x0102030405060708
+
+	kp := NewLight()
+	mixDigest, hash, err := kp.Hash(header, nonce)
+	if err != nil {
+		t.Fatalf("unexpected hash error: %v", err)
+	}
+	seed, err := CalcSeedHash(height, timestamp)
+	if err != nil {
+		t.Fatalf("unexpected seed hash error: %v", err)
+	}
+
+	// Ensure both methods of verification accept the proof.
+	valid, err := kp.Verify(header, nonce, mixDigest, hash)
+	if err != nil || !valid {
+		t.Fatalf("Verify rejected valid proof (valid %v, err %v)", valid, err)
+	}
+	valid, err = kp.VerifyWithSeed(header, height, seed, nonce, mixDigest, hash)
+	if err != nil || !valid {
+		t.Fatalf("VerifyWithSeed rejected valid proof (valid %v, err %v)",
+			valid, err)
+	}
+
+	// Ensure a tampered mix digest is rejected.
+	badMix := append([]byte(nil), mixDigest...)
+	badMix[0] ^= 0x01
+	valid, err = kp.VerifyWithSeed(header, height, seed, nonce, badMix, hash)
+	if err != nil {
+		t.Fatalf("unexpected verify error: %v", err)
+	}
+	if valid {
+		t.Fatal("proof with tampered mix digest was accepted")
+	}
+
+	// Ensure a height that does not match the header is rejected.
+	_, err = kp.VerifyWithSeed(header, height+1, seed, nonce, mixDigest, hash)
+	if err == nil {
+		t.Fatal("mismatched height was not rejected")
+	}
+}
+
+// TestVerifyWithSeedFullMismatch ensures a full hasher rejects a seed hash that
+// its dataset for the epoch of the block was not built from as opposed to
+// verifying against that dataset anyway.
+func TestVerifyWithSeedFullMismatch(t *testing.T) {
+	params := Params{
+		EpochLength:        10,
+		DatasetInitBytes:   128 * 8,
+		DatasetGrowthBytes: 128,
+		CacheInitBytes:     1024,
+		CacheRounds:        3,
+	}
+	const height = 15
+	const nonce = 0x0102030405060708
+	header := make([]byte, 180)
+	copy(header, "Test header for full seed verification")
+	binary.LittleEndian.PutUint32(header[headerHeightOffset:], height)
+
+	kp := NewWithParams(params)
+	mixDigest, hash, err := kp.Hash(header, nonce)
+	if err != nil {
+		t.Fatalf("unexpected hash error: %v", err)
+	}
+
+	// Ensure the seed for the epoch of the block is accepted.
+	seed, err := params.CalcSeedHash(height, 0)
+	if err != nil {
+		t.Fatalf("unexpected seed hash error: %v", err)
+	}
+	valid, err := kp.VerifyWithSeed(header, height, seed, nonce, mixDigest,
+		hash)
+	if err != nil || !valid {
+		t.Fatalf("VerifyWithSeed rejected valid proof (valid %v, err %v)",
+			valid, err)
+	}
+
+	// Ensure the seed for another epoch is rejected.
+	_, err = kp.VerifyWithSeed(header, height, EpochSeed(0), nonce, mixDigest,
+		hash)
+	if !errors.Is(err, ErrSeedMismatch) {
+		t.Fatalf("mismatched seed -- got err %v, want %v", err,
+			ErrSeedMismatch)
+	}
+}
+
+// TestKeccakVectors ensures the Keccak-256 and Keccak-512 hashes match the
+// published known-answer vectors for the original Keccak padding as used by
+// Ethereum and KawPoW along with messages that end right at and just before
+// the rate boundaries where the padding shares a byte or spans a full block.
+func TestKeccakVectors(t *testing.T) {
+	tests := []struct {
+		name    string // test description
+		data    []byte // data to hash
+		want256 string // expected Keccak-256 hash
+		want512 string // expected Keccak-512 hash
+	}{{
+		name:    "empty",
+		data:    nil,
+		want256: "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470",
+		want512: "0eab42de4c3ceb9235fc91acffe746b29c29a8c366b7c60e4e67c466f36a4304" +
+			"c00fa9caf9d87976ba469bcbe06713b435f091ef2769fb160cdab33d3670680e",
+	}, {
+		name:    "abc",
+		data:    []byte("abc"),
+		want256: "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45",
+		want512: "18587dc2ea106b9a1563e32b3312421ca164c7f1f07bc922a9c83d77cea3a1e5" +
+			"d0c69910739025372dc14ac9642629379540c17e2a65b19d77aa511a9d00bb96",
+	}, {
+		name:    "quick brown fox",
+		data:    []byte("The quick brown fox jumps over the lazy dog"),
+		want256: "4d741b6f1eb29cb2a9b9911c82f56fa8d73b04959d3d9d222895df6c0b28aa15",
+		want512: "d135bb84d0439dbac432247ee573a23ea7d3c9deb2a968eb31d47c4fb45f1ef4" +
+			"422d6c531b5b9bd6f449ebcc449ea94d0a8f05f62130fda612da53c79659f609",
+	}, {
+		name:    "one byte less than keccak512 rate",
+		data:    bytes.Repeat([]byte{'a'}, 71),
+		want256: "b7631e52d750457f07cdafd7015e8f85dc9dab8f68d8f6c58e7e42495711357d",
+		want512: "a57dce7da8ec781665705f3d69310beaaa5b0cae0c9c34c9b1c5b7238bbd2ce3" +
+			"85bbe2f37694d2b8e9a55eb889eecb80d74ff4f9086067b47fd3f43c16c0b506",
+	}, {
+		name:    "keccak512 rate",
+		data:    bytes.Repeat([]byte{'a'}, 72),
+		want256: "96ee2f18e47b9843ad8f6aaba3cb342428d19a722df6389ef985562b8f6fd187",
+		want512: "4cb1cecbc96415025c7a9d6fb89f82a8482773fd9664c378691a05323ff4700f" +
+			"a3e60414e6064814f98b36a61a87f62dffa7c56a2371355868dd37b8a654cf50",
+	}, {
+		name:    "one byte less than keccak256 rate",
+		data:    bytes.Repeat([]byte{'a'}, 135),
+		want256: "34367dc248bbd832f4e3e69dfaac2f92638bd0bbd18f2912ba4ef454919cf446",
+		want512: "19d8d98f48b7dec319f89a175b0e3a5dba8ee14870f56270bed6204a8151772b" +
+			"db8489b30235cabe4ca04414b22a85e30213b57c00c2e55772358956643afc6d",
+	}, {
+		name:    "keccak256 rate",
+		data:    bytes.Repeat([]byte{'a'}, 136),
+		want256: "a6c4d403279fe3e0af03729caada8374b5ca54d8065329a3ebcaeb4b60aa386e",
+		want512: "34b4e7f426372f8f083b4e176106f3e6e118a0c420b8bc1deaef3c425c071676" +
+			"9ce0495ae2c2ea6843a9c71fb79873e1614762a6b271f2ed9e56f1d68eb539a5",
+	}}
+
+	kp := NewLight()
+	for _, test := range tests {
+		got256 := hex.EncodeToString(kp.keccak256(test.data))
+		if got256 != test.want256 {
+			t.Errorf("%q: mismatched keccak256 hash -- got %s, want %s",
+				test.name, got256, test.want256)
+		}
+		got512 := hex.EncodeToString(kp.keccak512(test.data))
+		if got512 != test.want512 {
+			t.Errorf("%q: mismatched keccak512 hash -- got %s, want %s",
+				test.name, got512, test.want512)
+		}
+	}
+}
+
+// TestKeccakSqueeze ensures reading output from the Keccak sponge squeezes a
+// stream that spans multiple blocks of the rate, regardless of how the reads
+// are split up, and that it is consistent with Sum.
+func TestKeccakSqueeze(t *testing.T) {
+	// SHAKE256 only differs from the Keccak sponge used by Keccak-256 by its
+	// domain separation byte, so ensure the stream squeezed with that byte
+	// matches the published SHAKE256 output for "abc" when read in chunks
+	// that cross the block boundaries.
+	const wantShake256 = "483366601360a8771c6863080cc4114d8db44530f8f1e1ee4f" +
+		"94ea37e78b5739d5a15bef186a5386c75744c0527e1faa9f8726e462a12a4feb06" +
+		"bd8801e751e41385141204f329979fd3047a13c5657724ada64d2470157b3cdc28" +
+		"8620944d78dbcddbd912993f0913f164fb2ce95131a2d09a3e6d51cbfc622720d7" +
+		"a75c6334e8a2d7ec71a7cc29cf0ea610eeff1a588290a53000faa79932becec0bd" +
+		"3cd0b33a7e5d397fed1ada9442b99903f4dcfd8559ed3950faf40fe6f3b5d710ed" +
+		"3b677513771af6bfe11934817e8762d9896ba579d88d84ba7aa3cdc7055f6796f1" +
+		"95bd9ae788f2f5bb96100d6bbaff7fbc6eea24d4449a2477d172a5507dcc931412" +
+		"fc346b1bb39b878330e026b12ddf384af3334560ea1d363966caa7d8ddcbec7da5" +
+		"2b42215c11d5f8ee57f341"
+	for _, chunks := range [][]int{{300}, {1, 135, 1, 163}, {136, 136, 28}} {
+		shake := &keccakF1600{rate: 136, dsbyte: 0x1f}
+		shake.Write([]byte("abc"))
+		var stream []byte
+		for _, chunkLen := range chunks {
+			chunk := make([]byte, chunkLen)
+			if n, err := shake.Read(chunk); n != chunkLen || err != nil {
+				t.Fatalf("chunks %v: unexpected read result %d (err %v)",
+					chunks, n, err)
+			}
+			stream = append(stream, chunk...)
+		}
+		if got := hex.EncodeToString(stream); got != wantShake256 {
+			t.Fatalf("chunks %v: mismatched stream -- got %s, want %s",
+				chunks, got, wantShake256)
+		}
+	}
+
+	// Ensure Sum does not change the state such that more data may be
+	// written afterwards and that the squeezed stream starts with the hash.
+	for _, newState := range []func() keccakState{NewKeccak256,
+		NewKeccak512} {
+
+		want := newState()
+		want.Write([]byte("abc"))
+		wantHash := want.Sum(nil)
+
+		h := newState()
+		h.Write([]byte("ab"))
+		h.Sum(nil)
+		h.Write([]byte("c"))
+		if got := h.Sum(nil); !bytes.Equal(got, wantHash) {
+			t.Fatalf("mismatched hash after sum -- got %x, want %x", got,
+				wantHash)
+		}
+		stream := make([]byte, 3*h.BlockSize())
+		h.Read(stream)
+		if !bytes.Equal(stream[:h.Size()], wantHash) {
+			t.Fatalf("stream %x does not start with hash %x", stream,
+				wantHash)
+		}
+	}
+}
+
+// TestKeccakPoolReset ensures Keccak states obtained from the pools are fully
+// reset such that data written to a state before it was returned to the pool
+// does not affect later hashes.
+func TestKeccakPoolReset(t *testing.T) {
+	data := []byte("keccak pool reset test data")
+	want256 := NewKeccak256()
+	want256.Write(data)
+	wantHash256 := want256.Sum(nil)
+	want512 := NewKeccak512()
+	want512.Write(data)
+	wantHash512 := want512.Sum(nil)
+
+	// Leave partially-written data with a full block absorbed in pooled states
+	// without finalizing them.
+	junk := bytes.Repeat([]byte{0xa5}, 200)
+	for _, pool := range []*sync.Pool{&keccak256Pool, &keccak512Pool} {
+		h := getKeccakState(pool)
+		h.Write(junk)
+		pool.Put(h)
+	}
+
+	kp := NewLight()
+	if got := kp.keccak256(data); !bytes.Equal(got, wantHash256) {
+		t.Fatalf("mismatched keccak256 hash -- got %x, want %x", got,
+			wantHash256)
+	}
+	if got := kp.keccak512(data); !bytes.Equal(got, wantHash512) {
+		t.Fatalf("mismatched keccak512 hash -- got %x, want %x", got,
+			wantHash512)
+	}
+}
+
+// BenchmarkKeccak512DAGItem benchmarks hashing data the size of a DAG item with
+// the pooled Keccak-512 states used by the hot hashing paths.
+func BenchmarkKeccak512DAGItem(b *testing.B) {
+	kp := NewLight()
+	item := make([]byte, 64)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		kp.keccak512(item)
+	}
+}
+
+// BenchmarkKeccak512DAGItemUnpooled benchmarks hashing data the size of a DAG
+// item with a newly-allocated Keccak-512 state for every hash for comparison
+// with the pooled states.
+func BenchmarkKeccak512DAGItemUnpooled(b *testing.B) {
+	item := make([]byte, 64)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h := NewKeccak512()
+		h.Write(item)
+		h.Sum(nil)
+	}
+}
+
+// TestEpochSizes ensures the cache and dataset sizes follow the ethash growth
+// schedule for several epochs.
+func TestEpochSizes(t *testing.T) {
+	tests := []struct {
+		epoch        uint64 // epoch to calculate the sizes for
+		cacheBytes   uint64 // expected cache size
+		datasetBytes uint64 // expected dataset size
+	}{
+		{0, 16776896, 2147483264},
+		{1, 16907456, 2155872128},
+		{2, 17039296, 2164257664},
+		{10, 18087488, 2231367808},
+		{100, 29882816, 2986338944},
+		{1000, 147848768, 10536091264},
+	}
+	params := DefaultParams()
+	for _, test := range tests {
+		if got := calcCacheSize(test.epoch); got != test.cacheBytes {
+			t.Errorf("epoch %d: unexpected cache size -- got %d, want %d",
+				test.epoch, got, test.cacheBytes)
+		}
+		if got := calcDatasetSize(test.epoch); got != test.datasetBytes {
+			t.Errorf("epoch %d: unexpected dataset size -- got %d, want %d",
+				test.epoch, got, test.datasetBytes)
+		}
+		if got := params.CacheBytes(int64(test.epoch)); got != test.cacheBytes {
+			t.Errorf("epoch %d: unexpected params cache size -- got %d, "+
+				"want %d", test.epoch, got, test.cacheBytes)
+		}
+		got := params.DatasetBytes(int64(test.epoch))
+		if got != test.datasetBytes {
+			t.Errorf("epoch %d: unexpected params dataset size -- got %d, "+
+				"want %d", test.epoch, got, test.datasetBytes)
+		}
+	}
+
+	// Ensure the initial sizes and growth rates of ethash itself produce the
+	// sizes it is known to use.
+	ethash := Params{
+		DatasetInitBytes:   1 << 30,
+		DatasetGrowthBytes: 1 << 23,
+		CacheInitBytes:     1 << 24,
+		CacheGrowthBytes:   1 << 17,
+	}
+	ethashTests := []struct {
+		epoch        int64  // epoch to calculate the sizes for
+		cacheBytes   uint64 // expected cache size
+		datasetBytes uint64 // expected dataset size
 	}{
-		{
-			name:     "genesis block",
-			height:   0,
-			time:     0x5f5e100,
-			expected: "1da0af1706a31185763837b33f1d90782c0a78bbe644a59c987ab3ff9c0b346e",
-		},
-		{
-			name:     "block 1",
-			height:   1,
-			time:     0x5f5e101,
-			expected: "9a4585773ce2ccd7a585c331d60a60d1e3b7d28cbb2ede3bc55445342f12f54b",
-		},
+		{0, 16776896, 1073739904},
+		{1, 16907456, 1082130304},
+	}
+	for _, test := range ethashTests {
+		if got := ethash.CacheBytes(test.epoch); got != test.cacheBytes {
+			t.Errorf("ethash epoch %d: unexpected cache size -- got %d, "+
+				"want %d", test.epoch, got, test.cacheBytes)
+		}
+		if got := ethash.DatasetBytes(test.epoch); got != test.datasetBytes {
+			t.Errorf("ethash epoch %d: unexpected dataset size -- got %d, "+
+				"want %d", test.epoch, got, test.datasetBytes)
+		}
+	}
+}
+
+// TestParamsEpochLength ensures hashers created with custom parameters make use
+// of the epoch length defined by them when selecting the seed hash.
+func TestParamsEpochLength(t *testing.T) {
+	defaultParams := DefaultParams()
+	shortParams := DefaultParams()
+	shortParams.EpochLength = 100
+
+	// Ensure the epoch and seed hash both change at the shorter boundary for
+	// the custom parameters while remaining in the first epoch for the
+	// default parameters.
+	const timestamp = 0x61c402e0
+	height := shortParams.EpochLength
+	if epoch := shortParams.Epoch(height); epoch != 1 {
+		t.Fatalf("unexpected custom epoch: got %d, want 1", epoch)
+	}
+	if epoch := defaultParams.Epoch(height); epoch != 0 {
+		t.Fatalf("unexpected default epoch: got %d, want 0", epoch)
+	}
+	shortSeed, err := shortParams.CalcSeedHash(height, timestamp)
+	if err != nil {
+		t.Fatalf("unexpected seed hash error: %v", err)
+	}
+	shortPrevSeed, err := shortParams.CalcSeedHash(height-1, timestamp)
+	if err != nil {
+		t.Fatalf("unexpected seed hash error: %v", err)
+	}
+	defaultSeed, err := CalcSeedHash(height, timestamp)
+	if err != nil {
+		t.Fatalf("unexpected seed hash error: %v", err)
+	}
+	if shortSeed == shortPrevSeed {
+		t.Fatal("custom seed hash did not change at epoch boundary")
+	}
+	if shortPrevSeed != defaultSeed {
+		t.Fatal("custom and default seed hashes differ in first epoch")
+	}
+
+	// Ensure the boundary heights respect the epoch length.
+	got := shortParams.EpochBoundaryHeights(1, 3*shortParams.EpochLength)
+	want := []int64{100, 200, 300}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected boundary heights -- got %v, want %v", got, want)
+	}
+
+	// Ensure a light hasher created with the custom parameters hashes a header
+	// past the shorter boundary with the custom seed hash.
+	header := make([]byte, 180)
+	copy(header, "Test header for custom params")
+	binary.LittleEndian.PutUint32(header[headerHeightOffset:], uint32(height))
+	binary.LittleEndian.PutUint32(header[headerTimestampOffset:], timestamp)
+	const nonce = 0x0102030405060708
+
+	kp := NewLightWithParams(shortParams)
+	if kp.Params() != shortParams {
+		t.Fatalf("unexpected hasher params -- got %+v, want %+v",
+			kp.Params(), shortParams)
+	}
+	mixDigest, hash, err := kp.Hash(header, nonce)
+	if err != nil {
+		t.Fatalf("unexpected hash error: %v", err)
 	}
+	valid, err := kp.VerifyWithSeed(header, height, shortSeed, nonce,
+		mixDigest, hash)
+	if err != nil || !valid {
+		t.Fatalf("VerifyWithSeed rejected custom seed proof (valid %v, err %v)",
+			valid, err)
+	}
+}
+
+// TestEpochDataset ensures a full hasher reuses the dataset for all blocks in
+// the same epoch and only regenerates it, with the size required by the new
+// epoch, once a block from a different epoch is hashed.
+func TestEpochDataset(t *testing.T) {
+	// Use small parameters with a growing dataset so the full dataset is
+	// cheap to generate and the required size differs between epochs.
+	params := Params{
+		EpochLength:        10,
+		DatasetInitBytes:   128 * 8,
+		DatasetGrowthBytes: 128,
+		CacheInitBytes:     1024,
+		CacheRounds:        3,
+	}
+	if params.DAGSizeBytes(9) != params.DAGSizeBytes(0) {
+		t.Fatal("dataset size differs within the first epoch")
+	}
+	if params.DAGSizeBytes(10) == params.DAGSizeBytes(9) {
+		t.Fatal("dataset size did not change at the epoch boundary")
+	}
+
+	// The full hasher loads the dataset for the first epoch on creation.
+	kp := NewWithParams(params)
+	const timestamp = 0x61c402e0
+	const nonce = 0x0102030405060708
+	makeHeader := func(height uint32) []byte {
+		header := make([]byte, 180)
+		copy(header, "Test header for dataset size")
+		binary.LittleEndian.PutUint32(header[headerHeightOffset:], height)
+		binary.LittleEndian.PutUint32(header[headerTimestampOffset:], timestamp)
+		return header
+	}
+
+	// Ensure headers in the epoch of the loaded dataset hash without
+	// regenerating the dataset.
+	initial := &kp.dataset[0]
+	for _, height := range []uint32{0, 9} {
+		if _, _, err := kp.Hash(makeHeader(height), nonce); err != nil {
+			t.Fatalf("height %d: unexpected hash error: %v", height, err)
+		}
+		if &kp.dataset[0] != initial || kp.cacheGen != 0 {
+			t.Fatalf("height %d: dataset regenerated within the epoch", height)
+		}
+	}
+
+	// Ensure a header in the next epoch results in the dataset for that epoch
+	// with the size it requires.
+	mixDigest, hash, err := kp.Hash(makeHeader(10), nonce)
+	if err != nil {
+		t.Fatalf("unexpected hash error for next epoch: %v", err)
+	}
+	if kp.cacheGen != 1 {
+		t.Fatalf("unexpected dataset epoch -- got %d, want 1", kp.cacheGen)
+	}
+	if gotBytes := uint64(len(kp.dataset)) * 8; gotBytes != params.DAGSizeBytes(10) {
+		t.Fatalf("unexpected dataset size -- got %d, want %d", gotBytes,
+			params.DAGSizeBytes(10))
+	}
+
+	// Ensure the regenerated dataset produces the same results as a hasher
+	// that generated the dataset for the epoch directly.
+	other := NewWithParams(params)
+	other.loadEpoch(1, nil)
+	wantMix, wantHash, err := other.Hash(makeHeader(10), nonce)
+	if err != nil {
+		t.Fatalf("unexpected hash error: %v", err)
+	}
+	if !bytes.Equal(mixDigest, wantMix) || !bytes.Equal(hash, wantHash) {
+		t.Fatal("regenerated dataset produced a different hash")
+	}
+}
+
+// TestLightCacheReuse ensures a light hasher reuses the verification cache it
+// generated for a seed hash across hashes with the same seed hash and only
+// generates a new one when the seed hash changes.
+func TestLightCacheReuse(t *testing.T) {
+	params := Params{
+		EpochLength:        10,
+		DatasetInitBytes:   128 * 8,
+		DatasetGrowthBytes: 128,
+		CacheInitBytes:     1024,
+		CacheRounds:        3,
+	}
+	header := make([]byte, 180)
+	copy(header, "Test header for cache reuse")
+	binary.LittleEndian.PutUint32(header[headerHeightOffset:], 5)
+
+	// Ensure hashing several nonces for the same header reuses the cache and
+	// produces the same results as a hasher that generates it.
+	kp := NewLightWithParams(params)
+	if _, _, err := kp.Hash(header, 0); err != nil {
+		t.Fatalf("unexpected hash error: %v", err)
+	}
+	cache := &kp.lightCache[0]
+	for nonce := uint64(1); nonce < 4; nonce++ {
+		mixDigest, hash, err := kp.Hash(header, nonce)
+		if err != nil {
+			t.Fatalf("nonce %d: unexpected hash error: %v", nonce, err)
+		}
+		if &kp.lightCache[0] != cache {
+			t.Fatalf("nonce %d: cache regenerated for the same seed", nonce)
+		}
+		wantMix, wantHash, err := NewLightWithParams(params).Hash(header, nonce)
+		if err != nil {
+			t.Fatalf("nonce %d: unexpected hash error: %v", nonce, err)
+		}
+		if !bytes.Equal(mixDigest, wantMix) || !bytes.Equal(hash, wantHash) {
+			t.Fatalf("nonce %d: reused cache produced a different hash", nonce)
+		}
+	}
+
+	// Ensure a header with a different seed hash results in a new cache.
+	binary.LittleEndian.PutUint32(header[headerHeightOffset:], 15)
+	if _, _, err := kp.Hash(header, 0); err != nil {
+		t.Fatalf("unexpected hash error: %v", err)
+	}
+	wantSeed, _ := params.CalcSeedHash(15, 0)
+	if kp.lightSeed != wantSeed || &kp.lightCache[0] == cache {
+		t.Fatal("cache was not regenerated for a new seed")
+	}
+}
+
+// TestPrepareCache ensures verification caches prepared ahead of an epoch
+// boundary are resident, produce the same results as caches generated on
+// demand, and that the oldest prepared cache is evicted once the maximum
+// number of caches is exceeded.
+func TestPrepareCache(t *testing.T) {
+	params := Params{
+		EpochLength:        10,
+		DatasetInitBytes:   128 * 8,
+		DatasetGrowthBytes: 128,
+		CacheInitBytes:     1024,
+		CacheRounds:        3,
+	}
+	const nonce = 0x0102030405060708
+	makeHeader := func(height uint32) []byte {
+		header := make([]byte, 180)
+		copy(header, "Test header for prepared caches")
+		binary.LittleEndian.PutUint32(header[headerHeightOffset:], height)
+		return header
+	}
+
+	// Prepare the caches for the current epoch and the next one as a
+	// verifying node would as the tip approaches the epoch boundary.
+	verifier := NewLightWithParams(params)
+	verifier.PrepareCache(8)
+	verifier.PrepareCache(10)
+	for _, height := range []int64{0, 9, 10, 19} {
+		if !verifier.CacheReady(height) {
+			t.Fatalf("cache for height %d is not ready", height)
+		}
+	}
+	if verifier.CacheReady(20) {
+		t.Fatal("cache for unprepared epoch is unexpectedly ready")
+	}
+
+	// Ensure proofs produced with caches generated on demand on both sides of
+	// the boundary verify against the prepared caches.
+	miner := NewLightWithParams(params)
+	for _, height := range []int64{9, 10} {
+		seed, _ := params.CalcSeedHash(height, 0)
+		header := makeHeader(uint32(height))
+		mixDigest, hash, err := miner.hashWithSeed(header, nonce, height, seed)
+		if err != nil {
+			t.Fatalf("height %d: unexpected hash error: %v", height, err)
+		}
+		valid, err := verifier.VerifyWithSeed(header, height, seed, nonce,
+			mixDigest, hash)
+		if err != nil || !valid {
+			t.Fatalf("height %d: valid proof rejected (valid %v, err %v)",
+				height, valid, err)
+		}
+	}
+
+	// Ensure preparing a cache that is already resident does not evict any
+	// caches and that the oldest cache is evicted once the maximum is
+	// exceeded.
+	verifier.PrepareCache(15)
+	verifier.PrepareCache(20)
+	verifier.PrepareCache(30)
+	if verifier.CacheReady(0) {
+		t.Fatal("oldest cache was not evicted")
+	}
+	for _, height := range []int64{10, 20, 30} {
+		if !verifier.CacheReady(height) {
+			t.Fatalf("cache for height %d was unexpectedly evicted", height)
+		}
+	}
+}
+
+// TestClose ensures closing a hasher releases its cache, dataset, and prepared
+// verification caches such that the garbage collector reclaims their memory.
+func TestClose(t *testing.T) {
+	// Use parameters with a dataset that is large enough to be clearly
+	// observable in the heap statistics while still being cheap to generate.
+	const datasetBytes = 64 * 1024 * 1024
+	params := Params{
+		EpochLength:        10,
+		DatasetInitBytes:   datasetBytes,
+		DatasetGrowthBytes: 0,
+		CacheInitBytes:     1024 * 1024,
+		CacheRounds:        3,
+	}
+
+	// heapAlloc returns the number of bytes allocated on the heap after
+	// running the garbage collector.
+	heapAlloc := func() uint64 {
+		runtime.GC()
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		return stats.HeapAlloc
+	}
+
+	// Create a full hasher with prepared verification caches and ensure the
+	// dataset is resident.
+	before := heapAlloc()
+	kp := NewWithParams(params)
+	kp.PrepareCache(0)
+	kp.PrepareCache(10)
+	loaded := heapAlloc()
+	if loaded < before+datasetBytes {
+		t.Fatalf("dataset not resident -- heap grew from %d to %d bytes",
+			before, loaded)
+	}
+
+	// Ensure closing the hasher releases all of its buffers and that the
+	// garbage collector reclaims the memory.
+	if err := kp.Close(); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+	if kp.cache != nil || kp.dataset != nil {
+		t.Fatal("cache or dataset not released")
+	}
+	if kp.CacheReady(0) || kp.CacheReady(10) {
+		t.Fatal("prepared caches not released")
+	}
+	closed := heapAlloc()
+	if closed > loaded-datasetBytes {
+		t.Fatalf("dataset memory not reclaimed -- heap went from %d to %d "+
+			"bytes", loaded, closed)
+	}
+	runtime.KeepAlive(kp)
+}
+
+// TestCheckASICResistance ensures parameters that specify an initial dataset
+// smaller than the minimum required to remain ASIC resistant are rejected.
+func TestCheckASICResistance(t *testing.T) {
+	tests := []struct {
+		name             string
+		datasetInitBytes uint64
+		err              error
+	}{{
+		name:             "default parameters",
+		datasetInitBytes: DefaultParams().DatasetInitBytes,
+		err:              nil,
+	}, {
+		name:             "exactly the minimum",
+		datasetInitBytes: MinASICResistantDatasetBytes,
+		err:              nil,
+	}, {
+		name:             "one cache line below the minimum",
+		datasetInitBytes: MinASICResistantDatasetBytes - 128,
+		err:              ErrDatasetTooSmall,
+	}, {
+		name:             "tiny dataset",
+		datasetInitBytes: 128 * 8,
+		err:              ErrDatasetTooSmall,
+	}}
 
 	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			hash, err := CalcSeedHash(test.height, test.time)
-			if err != nil {
-				t.Fatalf("CalcSeedHash failed: %v", err)
+		params := DefaultParams()
+		params.DatasetInitBytes = test.datasetInitBytes
+		err := params.CheckASICResistance()
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: unexpected error -- got %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
+
+// TestDAGProgress ensures generating and loading the DAG report their progress
+// roughly every percent of the items, always report completion, including when
+// the DAG is already resident, and accept a nil progress callback.
+func TestDAGProgress(t *testing.T) {
+	params := Params{
+		EpochLength:        10,
+		DatasetInitBytes:   128 * 128,
+		DatasetGrowthBytes: 256,
+		CacheInitBytes:     1024,
+		CacheRounds:        3,
+	}
+
+	// record is a progress callback that records the progress it is invoked
+	// with.
+	type progressReport struct{ done, total int }
+	var reports []progressReport
+	record := func(done, total int) {
+		reports = append(reports, progressReport{done, total})
+	}
+
+	// assertProgress ensures the recorded progress covers the provided total
+	// number of items in increasing steps, at most one per percent, that end
+	// with all items done.
+	assertProgress := func(desc string, total int) {
+		t.Helper()
+		if len(reports) == 0 || len(reports) > dagProgressSteps+1 {
+			t.Fatalf("%s: unexpected number of reports %d", desc,
+				len(reports))
+		}
+		prevDone := 0
+		for _, report := range reports {
+			if report.total != total || report.done <= prevDone ||
+				report.done > total {
+
+				t.Fatalf("%s: unexpected reports %v", desc, reports)
+			}
+			prevDone = report.done
+		}
+		if prevDone != total {
+			t.Fatalf("%s: final report %d of %d", desc, prevDone, total)
+		}
+		reports = nil
+	}
+
+	// Ensure generating the dataset for a new epoch reports its progress
+	// and loading it again once resident reports completion.
+	kp := NewWithParams(params)
+	dags := NewDAGManager(t.TempDir(), 0, params)
+	kp.SetDAGManager(dags)
+	if err := kp.LoadDAGWithProgress(15, record); err != nil {
+		t.Fatalf("unexpected error loading DAG: %v", err)
+	}
+	numItems := int(params.DatasetBytes(1) / 8)
+	if len(reports) < dagProgressSteps/2 {
+		t.Fatalf("too few progress reports %d", len(reports))
+	}
+	assertProgress("generated dataset", numItems)
+	if err := kp.LoadDAGWithProgress(15, record); err != nil {
+		t.Fatalf("unexpected error loading DAG: %v", err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("unexpected resident dataset reports %v", reports)
+	}
+	assertProgress("resident dataset", numItems)
+
+	// Ensure loading the persisted dataset with a new hasher reports
+	// completion.
+	other := NewLightWithParams(params)
+	other.SetDAGManager(dags)
+	if err := other.LoadDAGWithProgress(15, record); err != nil {
+		t.Fatalf("unexpected error loading DAG: %v", err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("unexpected persisted dataset reports %v", reports)
+	}
+	assertProgress("persisted dataset", numItems)
+
+	// Ensure a nil callback is accepted.
+	if err := kp.LoadDAGWithProgress(25, nil); err != nil {
+		t.Fatalf("unexpected error loading DAG: %v", err)
+	}
+
+	// Ensure generating the dataset for a new epoch reports its progress and
+	// generating it again once resident reports completion.
+	if err := kp.GenerateDAGWithProgress(35, record); err != nil {
+		t.Fatalf("unexpected error generating DAG: %v", err)
+	}
+	numItems = int(params.DatasetBytes(3) / 8)
+	if len(reports) < dagProgressSteps/2 {
+		t.Fatalf("too few progress reports %d", len(reports))
+	}
+	assertProgress("generated DAG", numItems)
+	if err := kp.GenerateDAGWithProgress(35, record); err != nil {
+		t.Fatalf("unexpected error generating DAG: %v", err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("unexpected resident DAG reports %v", reports)
+	}
+	assertProgress("resident DAG", numItems)
+	if err := kp.GenerateDAGWithProgress(45, nil); err != nil {
+		t.Fatalf("unexpected error generating DAG: %v", err)
+	}
+}
+
+// TestComputeMixDigest ensures computing only the mix digest produces the same
+// mix digest as the full hash with both full and light hashers across epochs.
+func TestComputeMixDigest(t *testing.T) {
+	params := Params{
+		EpochLength:        10,
+		DatasetInitBytes:   128 * 8,
+		DatasetGrowthBytes: 128,
+		CacheInitBytes:     1024,
+		CacheRounds:        3,
+	}
+	header := make([]byte, 180)
+	copy(header, "Test header for mix digests")
+
+	for _, kp := range []*KawPow{NewWithParams(params),
+		NewLightWithParams(params)} {
+
+		for _, height := range []uint32{5, 15, 25} {
+			binary.LittleEndian.PutUint32(header[headerHeightOffset:], height)
+			for _, nonce := range []uint64{0, 1, 0x0102030405060708} {
+				wantMix, _, err := kp.Hash(header, nonce)
+				if err != nil {
+					t.Fatalf("light %v, height %d: unexpected hash error: %v",
+						kp.light, height, err)
+				}
+				mixDigest, err := kp.ComputeMixDigest(header, nonce)
+				if err != nil {
+					t.Fatalf("light %v, height %d: unexpected mix digest "+
+						"error: %v", kp.light, height, err)
+				}
+				if !bytes.Equal(mixDigest[:], wantMix) {
+					t.Fatalf("light %v, height %d, nonce %d: mismatched mix "+
+						"digest -- got %x, want %x", kp.light, height, nonce,
+						mixDigest, wantMix)
+				}
 			}
+		}
+	}
+}
+
+// TestConcurrentHash ensures hashing concurrently with the same hasher, both
+// within the same epoch and across epochs that require the dataset to be
+// switched, produces the same results as hashing sequentially.
+func TestConcurrentHash(t *testing.T) {
+	params := Params{
+		EpochLength:        10,
+		DatasetInitBytes:   128 * 8,
+		DatasetGrowthBytes: 128,
+		CacheInitBytes:     1024,
+		CacheRounds:        3,
+	}
+	makeHeader := func(height uint32) []byte {
+		header := make([]byte, 180)
+		copy(header, "Test header for concurrent hashing")
+		binary.LittleEndian.PutUint32(header[headerHeightOffset:], height)
+		return header
+	}
+
+	// Calculate the expected results sequentially with separate hashers.
+	type hashInput struct {
+		height uint32
+		nonce  uint64
+	}
+	type hashResult struct {
+		mixDigest []byte
+		hash      []byte
+	}
+	var inputs []hashInput
+	for _, height := range []uint32{1, 9, 10, 25} {
+		for nonce := uint64(0); nonce < 4; nonce++ {
+			inputs = append(inputs, hashInput{height, nonce})
+		}
+	}
+	want := make([]hashResult, len(inputs))
+	for i, input := range inputs {
+		kp := NewLightWithParams(params)
+		mixDigest, hash, err := kp.Hash(makeHeader(input.height), input.nonce)
+		if err != nil {
+			t.Fatalf("input %d: unexpected hash error: %v", i, err)
+		}
+		want[i] = hashResult{mixDigest, hash}
+	}
+
+	// Ensure concurrently hashing all of the inputs several times with a
+	// single full hasher and a single light hasher produces the same results.
+	for _, kp := range []*KawPow{NewWithParams(params),
+		NewLightWithParams(params)} {
 
-			hexStr := hash.String()
-			if hexStr != test.expected {
-				t.Errorf("unexpected seed hash: got %s, want %s", hexStr, test.expected)
+		const numRounds = 8
+		var wg sync.WaitGroup
+		errs := make(chan error, numRounds*len(inputs))
+		for round := 0; round < numRounds; round++ {
+			for i, input := range inputs {
+				wg.Add(1)
+				go func(i int, input hashInput) {
+					defer wg.Done()
+					header := makeHeader(input.height)
+					mixDigest, hash, err := kp.Hash(header, input.nonce)
+					if err != nil {
+						errs <- err
+						return
+					}
+					if !bytes.Equal(mixDigest, want[i].mixDigest) ||
+						!bytes.Equal(hash, want[i].hash) {
+
+						errs <- fmt.Errorf("input %d (height %d, nonce %d): "+
+							"mismatched hash", i, input.height, input.nonce)
+					}
+				}(i, input)
 			}
-		})
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Errorf("light %v: %v", kp.light, err)
+		}
 	}
-}
\ No newline at end of file
+}
+
+// recordingLogger is a Logger that records the trace output emitted through it.
+type recordingLogger struct {
+	mtx   sync.Mutex
+	lines []string
+}
+
+// Tracef records the formatted output.
+//
+// This is part of the Logger interface.
+func (l *recordingLogger) Tracef(format string, params ...interface{}) {
+	l.mtx.Lock()
+	l.lines = append(l.lines, fmt.Sprintf(format, params...))
+	l.mtx.Unlock()
+}
+
+// TestLogger ensures hashing, verifying, and generating the DAG produce no
+// output by default and that trace output is only emitted through the logger
+// provided to the hasher.
+func TestLogger(t *testing.T) {
+	params := Params{
+		EpochLength:        10,
+		DatasetInitBytes:   128 * 8,
+		DatasetGrowthBytes: 128,
+		CacheInitBytes:     1024,
+		CacheRounds:        3,
+	}
+	header := make([]byte, 180)
+	copy(header, "Test header for logging")
+	binary.LittleEndian.PutUint32(header[headerHeightOffset:], 15)
+
+	// Capture anything written via the standard logger while restoring the
+	// original state once the test completes.
+	var stdOutput bytes.Buffer
+	origWriter := log.Writer()
+	log.SetOutput(&stdOutput)
+	defer log.SetOutput(origWriter)
+
+	// Ensure hashing and verifying with both full and light hashers as well
+	// as generating the DAG produce no output by default.
+	for _, kp := range []*KawPow{NewWithParams(params),
+		NewLightWithParams(params)} {
+
+		mixDigest, hash, err := kp.Hash(header, 1)
+		if err != nil {
+			t.Fatalf("light %v: unexpected hash error: %v", kp.light, err)
+		}
+		valid, err := kp.Verify(header, 1, mixDigest, hash)
+		if err != nil || !valid {
+			t.Fatalf("light %v: unexpected verify result %v (err %v)",
+				kp.light, valid, err)
+		}
+		if err := kp.GenerateDAG(15); err != nil {
+			t.Fatalf("light %v: unexpected DAG error: %v", kp.light, err)
+		}
+	}
+	if stdOutput.Len() != 0 {
+		t.Fatalf("unexpected output: %q", stdOutput.String())
+	}
+
+	// Ensure loading the dataset for a new epoch and generating a new
+	// verification cache emit trace output through the provided logger and
+	// that setting a nil logger disables it again.
+	for _, kp := range []*KawPow{NewWithParams(params),
+		NewLightWithParams(params)} {
+
+		var logger recordingLogger
+		kp.SetLogger(&logger)
+		if _, _, err := kp.Hash(header, 1); err != nil {
+			t.Fatalf("light %v: unexpected hash error: %v", kp.light, err)
+		}
+		if len(logger.lines) == 0 {
+			t.Fatalf("light %v: no trace output", kp.light)
+		}
+
+		kp.SetLogger(nil)
+		numLines := len(logger.lines)
+		binary.LittleEndian.PutUint32(header[headerHeightOffset:], 25)
+		if _, _, err := kp.Hash(header, 1); err != nil {
+			t.Fatalf("light %v: unexpected hash error: %v", kp.light, err)
+		}
+		binary.LittleEndian.PutUint32(header[headerHeightOffset:], 15)
+		if len(logger.lines) != numLines {
+			t.Fatalf("light %v: trace output after disabling logger",
+				kp.light)
+		}
+	}
+	if stdOutput.Len() != 0 {
+		t.Fatalf("unexpected output: %q", stdOutput.String())
+	}
+}
+
+// TestMalformedInputs ensures hashing and verifying truncated headers, as well
+// as hashing with malformed inputs, returns an error as opposed to panicking.
+func TestMalformedInputs(t *testing.T) {
+	params := Params{
+		EpochLength:        10,
+		DatasetInitBytes:   128 * 8,
+		DatasetGrowthBytes: 128,
+		CacheInitBytes:     1024,
+		CacheRounds:        3,
+	}
+	header := make([]byte, minHeaderLen)
+	copy(header, "Test header for malformed inputs")
+	binary.LittleEndian.PutUint32(header[headerHeightOffset:], 5)
+
+	// Ensure truncated headers are rejected by every method that hashes a
+	// header with both full and light hashers, while headers of the minimum
+	// length are not.
+	for _, kp := range []*KawPow{NewWithParams(params),
+		NewLightWithParams(params)} {
+
+		mixDigest, hash, err := kp.Hash(header, 1)
+		if err != nil {
+			t.Fatalf("light %v: unexpected hash error: %v", kp.light, err)
+		}
+		for _, headerLen := range []int{0, 1, 32, headerHeightOffset,
+			headerHeightOffset + 4, minHeaderLen - 1} {
+			truncated := header[:headerLen]
+			_, _, err := kp.Hash(truncated, 1)
+			if !errors.Is(err, ErrHeaderTooShort) {
+				t.Fatalf("light %v, len %d: unexpected hash error -- got "+
+					"%v, want %v", kp.light, headerLen, err,
+					ErrHeaderTooShort)
+			}
+			_, err = kp.Verify(truncated, 1, mixDigest, hash)
+			if !errors.Is(err, ErrHeaderTooShort) {
+				t.Fatalf("light %v, len %d: unexpected verify error -- "+
+					"got %v, want %v", kp.light, headerLen, err,
+					ErrHeaderTooShort)
+			}
+			_, err = kp.VerifyLight(truncated, 1, mixDigest, hash)
+			if !errors.Is(err, ErrHeaderTooShort) {
+				t.Fatalf("light %v, len %d: unexpected light verify error "+
+					"-- got %v, want %v", kp.light, headerLen, err,
+					ErrHeaderTooShort)
+			}
+			_, err = kp.VerifyWithSeed(truncated, 5, EpochSeed(0), 1,
+				mixDigest, hash)
+			if !errors.Is(err, ErrHeaderTooShort) {
+				t.Fatalf("light %v, len %d: unexpected seed verify error "+
+					"-- got %v, want %v", kp.light, headerLen, err,
+					ErrHeaderTooShort)
+			}
+			_, err = kp.ComputeMixDigest(truncated, 1)
+			if !errors.Is(err, ErrHeaderTooShort) {
+				t.Fatalf("light %v, len %d: unexpected mix digest error "+
+					"-- got %v, want %v", kp.light, headerLen, err,
+					ErrHeaderTooShort)
+			}
+		}
+	}
+
+	// Ensure the height and timestamp are extracted from headers of at least
+	// the minimum length and truncated headers are rejected.
+	binary.LittleEndian.PutUint32(header[headerTimestampOffset:], 0x61c402e0)
+	if height, err := HeaderHeight(header); err != nil || height != 5 {
+		t.Fatalf("unexpected header height -- got %d (err %v), want 5",
+			height, err)
+	}
+	timestamp, err := HeaderTimestamp(header)
+	if err != nil || timestamp != 0x61c402e0 {
+		t.Fatalf("unexpected header timestamp -- got %x (err %v), want %x",
+			timestamp, err, 0x61c402e0)
+	}
+	if _, err := HeaderHeight(header[:minHeaderLen-1]); !errors.Is(err,
+		ErrHeaderTooShort) {
+
+		t.Fatalf("unexpected header height error -- got %v, want %v", err,
+			ErrHeaderTooShort)
+	}
+	if _, err := HeaderTimestamp(header[:minHeaderLen-1]); !errors.Is(err,
+		ErrHeaderTooShort) {
+
+		t.Fatalf("unexpected header timestamp error -- got %v, want %v", err,
+			ErrHeaderTooShort)
+	}
+
+	// Ensure malformed header hashes and dataset sizes are rejected.
+	kp := NewLightWithParams(params)
+	lookup := func(index int) uint64 { return uint64(index) }
+	tests := []struct {
+		name         string // test description
+		headerHash   []byte // header hash to hash
+		datasetBytes uint64 // size of the dataset in bytes
+	}{{
+		name:         "short header hash",
+		headerHash:   make([]byte, 31),
+		datasetBytes: 1024,
+	}, {
+		name:         "long header hash",
+		headerHash:   make([]byte, 33),
+		datasetBytes: 1024,
+	}, {
+		name:         "zero dataset size",
+		headerHash:   make([]byte, 32),
+		datasetBytes: 0,
+	}, {
+		name:         "dataset smaller than an entry",
+		headerHash:   make([]byte, 32),
+		datasetBytes: 128,
+	}, {
+		name:         "dataset size not multiple of 128",
+		headerHash:   make([]byte, 32),
+		datasetBytes: 1000,
+	}}
+	for _, test := range tests {
+		_, _, err := kp.hashimoto(test.headerHash, 1, 5, test.datasetBytes,
+			lookup)
+		if !errors.Is(err, ErrInvalidHashInput) {
+			t.Errorf("%q: unexpected error -- got %v, want %v", test.name,
+				err, ErrInvalidHashInput)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package kawpow
+
+import (
+	"errors"
+	"fmt"
+
+	"vigil.network/node/chaincfg/chainhash"
+)
+
+// MinASICResistantDatasetBytes is the minimum size in bytes of the dataset for
+// the first epoch that keeps KawPoW memory hard.  A dataset smaller than this
+// could reasonably fit into the on-die memory of specialized hardware, which
+// would defeat the ASIC resistance the algorithm is chosen for.
+const MinASICResistantDatasetBytes = 1024 * 1024 * 1024 // 1 GiB
+
+const (
+	// cacheItemBytes is the size in bytes of the items the verification cache
+	// consists of.  The cache holds a prime number of them.
+	cacheItemBytes = 64
+
+	// datasetItemBytes is the size in bytes of the items the dataset consists
+	// of.  The dataset holds a prime number of them.
+	datasetItemBytes = 128
+)
+
+// ErrDatasetTooSmall is returned when the parameters specify an initial dataset
+// that is too small for KawPoW to remain ASIC resistant.
+var ErrDatasetTooSmall = errors.New("dataset too small to be ASIC resistant")
+
+// ErrNegativeHeight is returned when a seed hash is requested for a negative
+// block height.
+var ErrNegativeHeight = errors.New("negative block height")
+
+// Params houses the tunable parameters of the KawPoW algorithm that are
+// permitted to differ between networks.
+type Params struct {
+	// EpochLength is the number of blocks in each epoch.  The seed hash, and
+	// therefore the cache and dataset, changes at every epoch boundary.
+	EpochLength int64
+
+	// DatasetInitBytes is the target size of the dataset in bytes for the
+	// first epoch.  It must be a multiple of 128.  See DatasetBytes for how
+	// the actual size is derived from the target size.
+	DatasetInitBytes uint64
+
+	// DatasetGrowthBytes is the number of bytes the target size of the
+	// dataset grows by for each epoch after the first one.  It must be a
+	// multiple of 128.
+	DatasetGrowthBytes uint64
+
+	// CacheInitBytes is the target size of the verification cache in bytes
+	// for the first epoch.  It must be a multiple of 64.  See CacheBytes for
+	// how the actual size is derived from the target size.
+	CacheInitBytes uint64
+
+	// CacheGrowthBytes is the number of bytes the target size of the
+	// verification cache grows by for each epoch after the first one.  It
+	// must be a multiple of 64.
+	CacheGrowthBytes uint64
+
+	// CacheRounds is the number of rounds used to generate the cache.
+	CacheRounds int
+}
+
+// DefaultParams returns the KawPoW parameters used by the hashers created by
+// New and NewLight.
+func DefaultParams() Params {
+	return Params{
+		EpochLength:        KawPowEpochLength,
+		DatasetInitBytes:   datasetInitBytes,
+		DatasetGrowthBytes: datasetGrowthBytes,
+		CacheInitBytes:     cacheInitBytes,
+		CacheGrowthBytes:   cacheGrowthBytes,
+		CacheRounds:        cacheRounds,
+	}
+}
+
+// CheckASICResistance returns ErrDatasetTooSmall when the initial dataset size
+// specified by the parameters is less than MinASICResistantDatasetBytes.
+func (p *Params) CheckASICResistance() error {
+	if p.DatasetInitBytes < MinASICResistantDatasetBytes {
+		return fmt.Errorf("%w: initial dataset size of %d bytes is less than "+
+			"the minimum of %d bytes", ErrDatasetTooSmall,
+			p.DatasetInitBytes, uint64(MinASICResistantDatasetBytes))
+	}
+	return nil
+}
+
+// Epoch returns the epoch that contains the provided block height.
+func (p *Params) Epoch(height int64) int64 {
+	return height / p.EpochLength
+}
+
+// isPrime returns whether or not the provided number is prime.
+func isPrime(n uint64) bool {
+	if n < 2 {
+		return false
+	}
+	for d := uint64(2); d*d <= n; d++ {
+		if n%d == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// epochSize returns the size in bytes for the provided epoch of a structure
+// that consists of items of the provided size and whose target size starts at
+// the provided initial size and grows linearly by the provided number of bytes
+// each epoch.
+//
+// Following ethash, the size is the largest prime number of items that fits
+// below the target size so that accesses modulo the number of items do not
+// favor any particular items.
+func epochSize(initBytes, growthBytes uint64, epoch int64, itemBytes uint64) uint64 {
+	if epoch < 0 {
+		epoch = 0
+	}
+	target := initBytes + uint64(epoch)*growthBytes
+	if target < itemBytes {
+		return 0
+	}
+	size := target - itemBytes
+	for size >= 2*itemBytes && !isPrime(size/itemBytes) {
+		size -= 2 * itemBytes
+	}
+	return size
+}
+
+// CacheBytes returns the size of the verification cache in bytes for the
+// provided epoch.  It is the largest prime number of 64-byte items below the
+// target size, which is CacheInitBytes grown by CacheGrowthBytes for each
+// epoch after the first one.
+func (p *Params) CacheBytes(epoch int64) uint64 {
+	return epochSize(p.CacheInitBytes, p.CacheGrowthBytes, epoch,
+		cacheItemBytes)
+}
+
+// DatasetBytes returns the size of the dataset in bytes for the provided
+// epoch.  It is the largest prime number of 128-byte items below the target
+// size, which is DatasetInitBytes grown by DatasetGrowthBytes for each epoch
+// after the first one.
+func (p *Params) DatasetBytes(epoch int64) uint64 {
+	return epochSize(p.DatasetInitBytes, p.DatasetGrowthBytes, epoch,
+		datasetItemBytes)
+}
+
+// calcCacheSize returns the size of the verification cache in bytes for the
+// provided epoch with the default parameters.
+func calcCacheSize(epoch uint64) uint64 {
+	return epochSize(cacheInitBytes, cacheGrowthBytes, int64(epoch),
+		cacheItemBytes)
+}
+
+// calcDatasetSize returns the size of the dataset in bytes for the provided
+// epoch with the default parameters.
+func calcDatasetSize(epoch uint64) uint64 {
+	return epochSize(datasetInitBytes, datasetGrowthBytes, int64(epoch),
+		datasetItemBytes)
+}
+
+// DAGSizeBytes returns the size of the dataset in bytes that is used to hash
+// the block at the provided height.
+func (p *Params) DAGSizeBytes(height int64) uint64 {
+	return p.DatasetBytes(p.Epoch(height))
+}
+
+// EpochBoundaryHeights returns all heights in the provided inclusive range of
+// heights that are the first block of an epoch according to the parameters.
+// These are the heights at which miners must switch to the DAG for the new
+// epoch.
+//
+// A nil slice is returned when the range does not contain any epoch
+// boundaries.
+func (p *Params) EpochBoundaryHeights(fromHeight, toHeight int64) []int64 {
+	if fromHeight < 0 {
+		fromHeight = 0
+	}
+	if toHeight < fromHeight {
+		return nil
+	}
+
+	var heights []int64
+	epochLen := p.EpochLength
+	first := (fromHeight + epochLen - 1) / epochLen * epochLen
+	for height := first; height <= toHeight; height += epochLen {
+		heights = append(heights, height)
+	}
+	return heights
+}
+
+// CalcSeedHash calculates the seed hash for a given block height using the
+// epoch length defined by the parameters.  The seed hash is derived purely from
+// the epoch that contains the height as described by EpochSeed so that all
+// nodes agree on it regardless of when it is calculated.
+//
+// The timestamp is not used.  It is only accepted for compatibility with
+// existing callers.
+//
+// ErrNegativeHeight is returned along with the seed hash for the first epoch
+// when the provided height is negative.
+func (p *Params) CalcSeedHash(height int64, timestamp int64) (chainhash.Hash, error) {
+	if height < 0 {
+		return EpochSeed(0), fmt.Errorf("%w: %d", ErrNegativeHeight, height)
+	}
+	return EpochSeed(uint64(p.Epoch(height))), nil
+}
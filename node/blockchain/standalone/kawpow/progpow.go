@@ -0,0 +1,409 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package kawpow
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	// progPowPeriodLength is the number of blocks the random program of math
+	// and merge operations remains the same for.
+	progPowPeriodLength = 3
+
+	// progPowLanes is the number of parallel lanes that coordinate to
+	// calculate a single hash instance.
+	progPowLanes = 16
+
+	// progPowRegs is the number of 32-bit registers in the mix of each lane.
+	progPowRegs = 32
+
+	// progPowDAGLoads is the number of 32-bit words each lane loads from the
+	// dataset entry accessed each round.
+	progPowDAGLoads = 4
+
+	// progPowDAGEntryBytes is the size in bytes of the dataset entries
+	// accessed each round.
+	progPowDAGEntryBytes = progPowLanes * progPowDAGLoads * 4
+
+	// progPowCacheWords is the number of 32-bit words at the start of the
+	// dataset that make up the cache randomly accessed by each round.
+	progPowCacheWords = 16 * 1024 / 4
+
+	// progPowCntDAG is the number of rounds, each of which accesses the
+	// dataset.
+	progPowCntDAG = 64
+
+	// progPowCntCache is the number of cache accesses per round.
+	progPowCntCache = 11
+
+	// progPowCntMath is the number of math operations per round.
+	progPowCntMath = 18
+
+	// fnvOffsetBasis is the offset basis of the 32-bit FNV-1a hash.
+	fnvOffsetBasis = 0x811c9dc5
+)
+
+// kawpowPadding is the input constraint that pads the Keccak-f[800] state for
+// the initial and final Keccak passes of KawPoW.  It is the string
+// "rAVENCOINKAWPOW" with each character expanded to a 32-bit word.
+var kawpowPadding = [15]uint32{
+	0x00000072, 0x00000041, 0x00000056, 0x00000045, 0x0000004e,
+	0x00000043, 0x0000004f, 0x00000049, 0x0000004e, 0x0000004b,
+	0x00000041, 0x00000057, 0x00000050, 0x0000004f, 0x00000057,
+}
+
+// keccakF800RC houses the round constants of the Keccak-f[800] permutation,
+// which are the lower 32 bits of the Keccak-f[1600] round constants.
+var keccakF800RC = [22]uint32{
+	0x00000001, 0x00008082, 0x0000808a, 0x80008000, 0x0000808b, 0x80000001,
+	0x80008081, 0x00008009, 0x0000008a, 0x00000088, 0x80008009, 0x8000000a,
+	0x8000808b, 0x0000008b, 0x00008089, 0x00008003, 0x00008002, 0x00000080,
+	0x0000800a, 0x8000000a, 0x80008081, 0x00008080,
+}
+
+// keccakF800 applies the 22 round Keccak-f[800] permutation to the provided
+// state.
+func keccakF800(a *[25]uint32) {
+	var bc [5]uint32
+	for round := 0; round < len(keccakF800RC); round++ {
+		// Theta step
+		for x := 0; x < 5; x++ {
+			bc[x] = a[x] ^ a[x+5] ^ a[x+10] ^ a[x+15] ^ a[x+20]
+		}
+		for x := 0; x < 5; x++ {
+			t := bc[(x+4)%5] ^ bits.RotateLeft32(bc[(x+1)%5], 1)
+			for y := 0; y < 25; y += 5 {
+				a[x+y] ^= t
+			}
+		}
+
+		// Rho and Pi steps
+		t := a[1]
+		x, y := 1, 0
+		for i := 0; i < 24; i++ {
+			x, y = y, (2*x+3*y)%5
+			t, a[x+5*y] = a[x+5*y], bits.RotateLeft32(t, int(rhoOffset[i]%32))
+		}
+
+		// Chi step
+		for y := 0; y < 25; y += 5 {
+			for x := 0; x < 5; x++ {
+				bc[x] = a[x+y]
+			}
+			for x := 0; x < 5; x++ {
+				a[x+y] = bc[x] ^ (^bc[(x+1)%5] & bc[(x+2)%5])
+			}
+		}
+
+		// Iota step
+		a[0] ^= keccakF800RC[round]
+	}
+}
+
+// fnv1a implements the 32-bit FNV-1a hash step.
+func fnv1a(u, v uint32) uint32 {
+	return (u ^ v) * 0x01000193
+}
+
+// kiss99 is the KISS99 pseudorandom number generator that selects the random
+// sequence of operations for a program.
+type kiss99 struct {
+	z, w, jsr, jcong uint32
+}
+
+// next returns the next pseudorandom number from the generator.
+func (k *kiss99) next() uint32 {
+	k.z = 36969*(k.z&65535) + (k.z >> 16)
+	k.w = 18000*(k.w&65535) + (k.w >> 16)
+	k.jcong = 69069*k.jcong + 1234567
+	k.jsr ^= k.jsr << 17
+	k.jsr ^= k.jsr >> 13
+	k.jsr ^= k.jsr << 5
+	return (((k.z << 16) + k.w) ^ k.jcong) + k.jsr
+}
+
+// randomMath returns the result of the math operation on the provided values
+// chosen by the provided selector.
+func randomMath(a, b, selector uint32) uint32 {
+	switch selector % 11 {
+	case 1:
+		return a * b
+	case 2:
+		hi, _ := bits.Mul32(a, b)
+		return hi
+	case 3:
+		if a < b {
+			return a
+		}
+		return b
+	case 4:
+		return bits.RotateLeft32(a, int(b%32))
+	case 5:
+		return bits.RotateLeft32(a, -int(b%32))
+	case 6:
+		return a & b
+	case 7:
+		return a | b
+	case 8:
+		return a ^ b
+	case 9:
+		return uint32(bits.LeadingZeros32(a) + bits.LeadingZeros32(b))
+	case 10:
+		return uint32(bits.OnesCount32(a) + bits.OnesCount32(b))
+	default:
+		return a + b
+	}
+}
+
+// randomMerge merges the provided value into the provided register using the
+// merge operation chosen by the provided selector.  The operations are chosen
+// such that the entropy of the register is retained.
+func randomMerge(a *uint32, b, selector uint32) {
+	// Additional non-zero rotation amount from the higher bits.
+	x := int((selector>>16)%31 + 1)
+	switch selector % 4 {
+	case 0:
+		*a = *a*33 + b
+	case 1:
+		*a = (*a ^ b) * 33
+	case 2:
+		*a = bits.RotateLeft32(*a, x) ^ b
+	case 3:
+		*a = bits.RotateLeft32(*a, -x) ^ b
+	}
+}
+
+// progPowProgram houses the state that determines the random program executed
+// by each round of the ProgPoW loop for a given period.  Every round starts
+// from the same state, so it is copied by value for each round.
+type progPowProgram struct {
+	rng        kiss99
+	dstSeq     [progPowRegs]uint32
+	srcSeq     [progPowRegs]uint32
+	dstCounter uint32
+	srcCounter uint32
+}
+
+// newProgPowProgram returns the program for the provided period, which is
+// the block height divided by the period length.
+func newProgPowProgram(period uint64) progPowProgram {
+	seedLo, seedHi := uint32(period), uint32(period>>32)
+	var p progPowProgram
+	p.rng.z = fnv1a(fnvOffsetBasis, seedLo)
+	p.rng.w = fnv1a(p.rng.z, seedHi)
+	p.rng.jsr = fnv1a(p.rng.w, seedLo)
+	p.rng.jcong = fnv1a(p.rng.jsr, seedHi)
+
+	// Create random permutations of the mix destinations and sources so
+	// every register is merged into and read from each round.
+	for i := uint32(0); i < progPowRegs; i++ {
+		p.dstSeq[i] = i
+		p.srcSeq[i] = i
+	}
+	for i := uint32(progPowRegs); i > 1; i-- {
+		j := p.rng.next() % i
+		p.dstSeq[i-1], p.dstSeq[j] = p.dstSeq[j], p.dstSeq[i-1]
+		j = p.rng.next() % i
+		p.srcSeq[i-1], p.srcSeq[j] = p.srcSeq[j], p.srcSeq[i-1]
+	}
+	return p
+}
+
+// nextDst returns the next register to merge into.
+func (p *progPowProgram) nextDst() uint32 {
+	dst := p.dstSeq[p.dstCounter%progPowRegs]
+	p.dstCounter++
+	return dst
+}
+
+// nextSrc returns the next register to read from.
+func (p *progPowProgram) nextSrc() uint32 {
+	src := p.srcSeq[p.srcCounter%progPowRegs]
+	p.srcCounter++
+	return src
+}
+
+// progPowMix houses the registers of every lane.
+type progPowMix [progPowLanes][progPowRegs]uint32
+
+// initProgPowMix fills the registers of every lane from KISS99 generators
+// seeded by the provided hash seed and the lane.
+func initProgPowMix(seed [2]uint32) progPowMix {
+	z := fnv1a(fnvOffsetBasis, seed[0])
+	w := fnv1a(z, seed[1])
+	var mix progPowMix
+	for l := range mix {
+		jsr := fnv1a(w, uint32(l))
+		jcong := fnv1a(jsr, uint32(l))
+		rng := kiss99{z: z, w: w, jsr: jsr, jcong: jcong}
+		for i := range mix[l] {
+			mix[l][i] = rng.next()
+		}
+	}
+	return mix
+}
+
+// progPowLoop executes the provided round of the ProgPoW loop on the mix.  Each
+// round executes the random program of cache accesses and math operations and
+// then merges the dataset entry selected by the mix into every lane.
+//
+// The provided lookup function returns the 32-bit word at the provided index
+// of the dataset and the provided number of entries is the number of dataset
+// entries that may be accessed.
+func progPowLoop(mix *progPowMix, round uint32, prog progPowProgram, cache []uint32, numEntries uint32, lookup func(index uint64) uint32) {
+	entry := mix[round%progPowLanes][0] % numEntries
+
+	// There are more math operations than cache accesses, so the cache
+	// accesses are interleaved with the first math operations.
+	for i := 0; i < progPowCntMath; i++ {
+		if i < progPowCntCache {
+			// Random access to the cache.
+			src := prog.nextSrc()
+			dst := prog.nextDst()
+			sel := prog.rng.next()
+			for l := range mix {
+				offset := mix[l][src] % uint32(len(cache))
+				randomMerge(&mix[l][dst], cache[offset], sel)
+			}
+		}
+
+		// Random math on two unique source registers.
+		srcRnd := prog.rng.next() % (progPowRegs * (progPowRegs - 1))
+		src1 := srcRnd % progPowRegs
+		src2 := srcRnd / progPowRegs
+		if src2 >= src1 {
+			src2++
+		}
+		sel1 := prog.rng.next()
+		dst := prog.nextDst()
+		sel2 := prog.rng.next()
+		for l := range mix {
+			data := randomMath(mix[l][src1], mix[l][src2], sel1)
+			randomMerge(&mix[l][dst], data, sel2)
+		}
+	}
+
+	// Merge the dataset entry into the lanes.  The first word is always
+	// merged into the first register since it determines the entry accessed
+	// by later rounds.
+	var dsts, sels [progPowDAGLoads]uint32
+	for i := range dsts {
+		if i != 0 {
+			dsts[i] = prog.nextDst()
+		}
+		sels[i] = prog.rng.next()
+	}
+	base := uint64(entry) * (progPowDAGEntryBytes / 4)
+	for l := range mix {
+		offset := uint64((uint32(l)^round)%progPowLanes) * progPowDAGLoads
+		for i := range dsts {
+			word := lookup(base + offset + uint64(i))
+			randomMerge(&mix[l][dsts[i]], word, sels[i])
+		}
+	}
+}
+
+// progPowHashMix executes all rounds of the ProgPoW loop for the provided
+// block height starting from the mix for the provided hash seed and reduces
+// the result to the 32-byte mix hash.
+//
+// The provided lookup function returns the 32-bit word at the provided index
+// of the dataset, which consists of the provided number of bytes.
+func progPowHashMix(height uint64, seed [2]uint32, datasetBytes uint64, lookup func(index uint64) uint32) [8]uint32 {
+	// The cache randomly accessed by each round is the start of the dataset.
+	// The entire dataset is used when it is smaller than the cache.
+	numCacheWords := uint64(progPowCacheWords)
+	if datasetBytes/4 < numCacheWords {
+		numCacheWords = datasetBytes / 4
+	}
+	cache := make([]uint32, numCacheWords)
+	for i := range cache {
+		cache[i] = lookup(uint64(i))
+	}
+
+	mix := initProgPowMix(seed)
+	prog := newProgPowProgram(height / progPowPeriodLength)
+	numEntries := uint32(datasetBytes / progPowDAGEntryBytes)
+	for round := uint32(0); round < progPowCntDAG; round++ {
+		progPowLoop(&mix, round, prog, cache, numEntries, lookup)
+	}
+
+	// Reduce the registers of each lane to a single value and then reduce
+	// the lanes to the mix hash.
+	var mixHash [8]uint32
+	for i := range mixHash {
+		mixHash[i] = fnvOffsetBasis
+	}
+	for l := range mix {
+		laneHash := uint32(fnvOffsetBasis)
+		for _, reg := range mix[l] {
+			laneHash = fnv1a(laneHash, reg)
+		}
+		mixHash[l%len(mixHash)] = fnv1a(mixHash[l%len(mixHash)], laneHash)
+	}
+	return mixHash
+}
+
+// kawpowMix computes the result of the initial Keccak pass and the KawPoW mix
+// hash for the provided header hash, nonce, and block height.  The provided
+// lookup function returns the 32-bit word at the provided index of the
+// dataset, which consists of the provided number of bytes.
+func kawpowMix(headerHash []byte, nonce, height, datasetBytes uint64, lookup func(index uint64) uint32) ([8]uint32, [8]uint32) {
+	// Absorb the header hash, the nonce, and the padding in the initial
+	// Keccak pass, which seeds the mix.
+	var state [25]uint32
+	for i := 0; i < 8; i++ {
+		state[i] = binary.LittleEndian.Uint32(headerHash[i*4:])
+	}
+	state[8] = uint32(nonce)
+	state[9] = uint32(nonce >> 32)
+	copy(state[10:], kawpowPadding[:])
+	keccakF800(&state)
+	var initial [8]uint32
+	copy(initial[:], state[:8])
+
+	mixHash := progPowHashMix(height, [2]uint32{initial[0], initial[1]},
+		datasetBytes, lookup)
+	return initial, mixHash
+}
+
+// kawpowMixDigest computes the KawPoW mix digest for the provided header hash,
+// nonce, and block height without the final Keccak pass.  See kawpowMix for
+// details regarding the lookup function.
+func kawpowMixDigest(headerHash []byte, nonce, height, datasetBytes uint64, lookup func(index uint64) uint32) [32]byte {
+	_, mixHash := kawpowMix(headerHash, nonce, height, datasetBytes, lookup)
+
+	var mixDigest [32]byte
+	for i := 0; i < 8; i++ {
+		binary.LittleEndian.PutUint32(mixDigest[i*4:], mixHash[i])
+	}
+	return mixDigest
+}
+
+// kawpowHash computes the KawPoW mix hash and final hash for the provided
+// header hash, nonce, and block height.  See kawpowMix for details regarding
+// the lookup function.
+func kawpowHash(headerHash []byte, nonce, height, datasetBytes uint64, lookup func(index uint64) uint32) ([]byte, []byte) {
+	initial, mixHash := kawpowMix(headerHash, nonce, height, datasetBytes,
+		lookup)
+
+	// Absorb the result of the initial pass, the mix hash, and the padding
+	// in the final Keccak pass.
+	var state [25]uint32
+	copy(state[:8], initial[:])
+	copy(state[8:16], mixHash[:])
+	copy(state[16:], kawpowPadding[:9])
+	keccakF800(&state)
+
+	mixDigest := make([]byte, 32)
+	result := make([]byte, 32)
+	for i := 0; i < 8; i++ {
+		binary.LittleEndian.PutUint32(mixDigest[i*4:], mixHash[i])
+		binary.LittleEndian.PutUint32(result[i*4:], state[i])
+	}
+	return mixDigest, result
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package kawpow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// TestKiss99 ensures the KISS99 generator produces the sequence from the
+// ProgPoW specification.
+func TestKiss99(t *testing.T) {
+	rng := kiss99{z: 362436069, w: 521288629, jsr: 123456789, jcong: 380116160}
+	want := map[int]uint32{
+		1:      769445856,
+		2:      742012328,
+		3:      2121196314,
+		4:      2805620942,
+		100000: 941074834,
+	}
+	for i := 1; i <= 100000; i++ {
+		got := rng.next()
+		if wantVal, ok := want[i]; ok && got != wantVal {
+			t.Fatalf("value %d: got %d, want %d", i, got, wantVal)
+		}
+	}
+}
+
+// TestFnv1a ensures the FNV-1a hash step produces the results from the ProgPoW
+// specification.
+func TestFnv1a(t *testing.T) {
+	tests := []struct {
+		u, v, want uint32
+	}{
+		{0x811c9dc5, 0xddd0a47b, 0xd37ee61a},
+		{0xd37ee61a, 0xee304846, 0xdedc7ad4},
+		{0xdedc7ad4, 0x00000000, 0xa9155bbc},
+	}
+	for i, test := range tests {
+		if got := fnv1a(test.u, test.v); got != test.want {
+			t.Errorf("test #%d: got %08x, want %08x", i, got, test.want)
+		}
+	}
+}
+
+// TestRandomMath ensures every random math operation produces the results from
+// the ProgPoW specification.
+func TestRandomMath(t *testing.T) {
+	tests := []struct {
+		name      string // test description
+		a, b, sel uint32 // operands and selector
+		want      uint32 // expected result
+	}{
+		{"add", 0x8626bb1f, 0xbbdfbc4e, 0x883e5b49, 0x4206776d},
+		{"mul", 0x3f4bdfac, 0xd79e414f, 0x36b71236, 0x4c5cb214},
+		{"mul_hi", 0x6d175b7e, 0xc4e89d4c, 0x944ecabb, 0x53e9023f},
+		{"min a", 0x2eddd94c, 0x7e70cb54, 0x3f472a85, 0x2eddd94c},
+		{"min b", 0x61ae0e62, 0xe0596b32, 0x3f472a85, 0x61ae0e62},
+		{"rotl", 0x8a81e396, 0x3f4bdfac, 0xcec46e67, 0x1e3968a8},
+		{"rotr", 0x8a81e396, 0x7e70cb54, 0xdbe71ff7, 0x1e3968a8},
+		{"and", 0xa7352f36, 0xa0eb7045, 0x59e7b9d8, 0xa0212004},
+		{"or", 0xc89805af, 0x64291e2f, 0x1bdc84a9, 0xecb91faf},
+		{"xor", 0x760726d3, 0x79fc6a48, 0xc675cac5, 0x0ffb4c9b},
+		{"clz", 0x75551d43, 0x3383ba34, 0x2863ad31, 0x00000003},
+		{"popcount", 0xea260841, 0xe92c44b7, 0xf83ffe7d, 0x0000001b},
+	}
+	for _, test := range tests {
+		got := randomMath(test.a, test.b, test.sel)
+		if got != test.want {
+			t.Errorf("%q: got %08x, want %08x", test.name, got, test.want)
+		}
+	}
+}
+
+// TestRandomMerge ensures every random merge operation produces the results
+// from the ProgPoW specification.
+func TestRandomMerge(t *testing.T) {
+	tests := []struct {
+		name      string // test description
+		a, b, sel uint32 // register, value, and selector
+		want      uint32 // expected register
+	}{
+		{"mul add", 0x3b0bb37d, 0xa0212004, 0x9bd26ab0, 0x3ca34321},
+		{"xor mul", 0x10c02f0d, 0x870fa227, 0xd4f45515, 0x91c1326a},
+		{"rotl xor", 0x24d2bae4, 0x0ffb4c9b, 0x7fdbc2f2, 0x2eddd94c},
+		{"rotr xor", 0xda39e821, 0x089c4008, 0x8b6cd8c3, 0x8a81e396},
+	}
+	for _, test := range tests {
+		a := test.a
+		randomMerge(&a, test.b, test.sel)
+		if a != test.want {
+			t.Errorf("%q: got %08x, want %08x", test.name, a, test.want)
+		}
+	}
+}
+
+// TestKeccakF800 ensures the Keccak-f[800] permutation of the all zero state
+// produces the published result.
+func TestKeccakF800(t *testing.T) {
+	want := [25]uint32{
+		0xe531d45d, 0xf404c6fb, 0x23a0bf99, 0xf1f8452f, 0x51ffd042,
+		0xe539f578, 0xf00b80a7, 0xaf973664, 0xbf5af34c, 0x227a2424,
+		0x88172715, 0x9f685884, 0xb15cd054, 0x1bf4fc0e, 0x6166fa91,
+		0x1a9e599a, 0xa3970a1f, 0xab659687, 0xafab8d68, 0xe74b1015,
+		0x34001a98, 0x4119eff3, 0x930a0e76, 0x87b28070, 0x11efe996,
+	}
+	var state [25]uint32
+	keccakF800(&state)
+	if state != want {
+		t.Fatalf("mismatched state -- got %08x, want %08x", state, want)
+	}
+}
+
+// TestProgPowProgram ensures the random program changes once per period and
+// that it, along with the nonce, affects the resulting hash.
+func TestProgPowProgram(t *testing.T) {
+	// Use a small synthetic dataset.
+	const datasetBytes = 64 * 1024
+	lookup := func(index uint64) uint32 {
+		return uint32(index)*0x9e3779b9 ^ 0x5bd1e995
+	}
+	headerHash := bytes.Repeat([]byte{0x11}, 32)
+	hash := func(nonce, height uint64) []byte {
+		_, result := kawpowHash(headerHash, nonce, height, datasetBytes,
+			lookup)
+		return result
+	}
+
+	// Ensure heights within the same period share the same program while
+	// heights in different periods do not.
+	if !bytes.Equal(hash(1, 0), hash(1, progPowPeriodLength-1)) {
+		t.Fatal("hash changed within a period")
+	}
+	if bytes.Equal(hash(1, 0), hash(1, progPowPeriodLength)) {
+		t.Fatal("hash did not change across periods")
+	}
+	if newProgPowProgram(0) == newProgPowProgram(1) {
+		t.Fatal("program did not change across periods")
+	}
+
+	// Ensure the nonce affects the hash.
+	if bytes.Equal(hash(1, 0), hash(2, 0)) {
+		t.Fatal("hash did not change with the nonce")
+	}
+
+	// Ensure the mix digest is the result of the mix and the hash is the
+	// final Keccak-f[800] pass over it.
+	mixDigest, result := kawpowHash(headerHash, 1, 0, datasetBytes, lookup)
+	var state [25]uint32
+	for i := 0; i < 8; i++ {
+		state[i] = binary.LittleEndian.Uint32(headerHash[i*4:])
+	}
+	state[8] = 1
+	copy(state[10:], kawpowPadding[:])
+	keccakF800(&state)
+	initial := state
+	state = [25]uint32{}
+	copy(state[:8], initial[:8])
+	for i := 0; i < 8; i++ {
+		state[8+i] = binary.LittleEndian.Uint32(mixDigest[i*4:])
+	}
+	copy(state[16:], kawpowPadding[:9])
+	keccakF800(&state)
+	for i := 0; i < 8; i++ {
+		if got := binary.LittleEndian.Uint32(result[i*4:]); got != state[i] {
+			t.Fatalf("mismatched final hash word %d -- got %08x, want %08x",
+				i, got, state[i])
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package kawpow
+
+import (
+	"math/big"
+	"time"
+)
+
+// hashRateReportInterval is the minimum amount of time between the hash rate
+// reports made while searching for a nonce.
+const hashRateReportInterval = 2 * time.Second
+
+// hashRateCheckInterval is the number of nonces that are tried between checks
+// of whether the hash rate should be reported.
+const hashRateCheckInterval = 64
+
+// SetHashRateCallback sets a function that is invoked with the number of hashes
+// per second periodically while searching for a nonce via Search as well as
+// once the search completes.  The hash rate is not reported by default.
+// Passing nil disables it again.
+//
+// This function MUST be called prior to the hasher being shared since it is
+// not safe for concurrent access.
+func (k *KawPow) SetHashRateCallback(fn func(hashesPerSecond float64)) {
+	k.hashRateFn = fn
+}
+
+// Search searches for a nonce for the provided header that results in a KawPoW
+// hash that does not exceed the provided target when interpreted as a
+// little-endian unsigned integer, which is how the proof of work hash of a
+// block is compared to its target difficulty.
+//
+// The search starts from the provided nonce and increments it until either a
+// solution is found, the entire nonce space has been tried, or the provided
+// abort channel is closed or sent to.  The nonce and mix digest of the solution
+// are returned along with true when one is found.  Otherwise, false is
+// returned, which includes when the header is too short to be hashed.
+//
+// The cache or dataset for the epoch of the header is only resolved once prior
+// to searching, so it is not regenerated for every nonce.
+//
+// This function is safe for concurrent access.
+func (k *KawPow) Search(headerBytes []byte, target *big.Int, startNonce uint64, abort <-chan struct{}) (uint64, [32]byte, bool) {
+	var mixDigest [32]byte
+	height, err := HeaderHeight(headerBytes)
+	if err != nil {
+		return 0, mixDigest, false
+	}
+	seedHash, err := k.params.CalcSeedHash(int64(height), 0)
+	if err != nil {
+		return 0, mixDigest, false
+	}
+	hashFn, err := k.epochHashFunc(int64(height), seedHash, k.light)
+	if err != nil {
+		return 0, mixDigest, false
+	}
+	headerHash := k.keccak256(headerBytes)
+
+	// Report the hash rate periodically and once the search completes when
+	// requested.
+	var numHashes uint64
+	start := time.Now()
+	lastReport, lastReportHashes := start, uint64(0)
+	reportHashRate := func(now time.Time, sinceTime time.Time, sinceHashes uint64) {
+		if elapsed := now.Sub(sinceTime).Seconds(); elapsed > 0 {
+			k.hashRateFn(float64(numHashes-sinceHashes) / elapsed)
+		}
+	}
+	if k.hashRateFn != nil {
+		defer func() { reportHashRate(time.Now(), start, 0) }()
+	}
+
+	var hashNum big.Int
+	var reversed [32]byte
+	for nonce := startNonce; ; nonce++ {
+		select {
+		case <-abort:
+			return 0, mixDigest, false
+		default:
+		}
+
+		mixHash, result, err := hashFn(headerHash, nonce)
+		if err != nil {
+			return 0, mixDigest, false
+		}
+		numHashes++
+
+		// The hash is a little-endian unsigned integer, but the big package
+		// wants the bytes in big-endian, so reverse them.
+		for i := range reversed {
+			reversed[i] = result[len(result)-1-i]
+		}
+		if hashNum.SetBytes(reversed[:]).Cmp(target) <= 0 {
+			copy(mixDigest[:], mixHash)
+			return nonce, mixDigest, true
+		}
+
+		if k.hashRateFn != nil && numHashes%hashRateCheckInterval == 0 {
+			if now := time.Now(); now.Sub(lastReport) >= hashRateReportInterval {
+				reportHashRate(now, lastReport, lastReportHashes)
+				lastReport, lastReportHashes = now, numHashes
+			}
+		}
+
+		// Stop once every nonce has been tried.
+		if nonce+1 == startNonce {
+			return 0, mixDigest, false
+		}
+	}
+}
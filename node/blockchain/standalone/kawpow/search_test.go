@@ -0,0 +1,128 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package kawpow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// TestSearch ensures searching for a nonce finds the first nonce from the
+// starting nonce that results in a hash that does not exceed the target,
+// returns the associated mix digest, reports the hash rate, only loads the
+// dataset once, and stops when aborted.
+func TestSearch(t *testing.T) {
+	params := Params{
+		EpochLength:        10,
+		DatasetInitBytes:   128 * 8,
+		DatasetGrowthBytes: 128,
+		CacheInitBytes:     1024,
+		CacheRounds:        3,
+	}
+	header := make([]byte, 180)
+	copy(header, "Test header for searching")
+	binary.LittleEndian.PutUint32(header[headerHeightOffset:], 15)
+
+	// hashToBig interprets the provided hash as a little-endian unsigned
+	// integer.
+	hashToBig := func(hash []byte) *big.Int {
+		reversed := make([]byte, len(hash))
+		for i := range hash {
+			reversed[i] = hash[len(hash)-1-i]
+		}
+		return new(big.Int).SetBytes(reversed)
+	}
+
+	// Use a target that roughly one in sixteen hashes satisfies.
+	maxHash := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256),
+		big.NewInt(1))
+	target := new(big.Int).Rsh(maxHash, 4)
+
+	for _, kp := range []*KawPow{NewWithParams(params),
+		NewLightWithParams(params)} {
+
+		var logger recordingLogger
+		kp.SetLogger(&logger)
+		var hashRates []float64
+		kp.SetHashRateCallback(func(hashesPerSecond float64) {
+			hashRates = append(hashRates, hashesPerSecond)
+		})
+
+		const startNonce = 1000
+		nonce, mixDigest, found := kp.Search(header, target, startNonce, nil)
+		if !found {
+			t.Fatalf("light %v: no solution found", kp.light)
+		}
+
+		// Ensure the solution satisfies the target and every nonce tried
+		// prior to it does not.
+		wantMix, hash, err := kp.Hash(header, nonce)
+		if err != nil {
+			t.Fatalf("light %v: unexpected hash error: %v", kp.light, err)
+		}
+		if !bytes.Equal(mixDigest[:], wantMix) {
+			t.Fatalf("light %v: mismatched mix digest -- got %x, want %x",
+				kp.light, mixDigest, wantMix)
+		}
+		if hashToBig(hash).Cmp(target) > 0 {
+			t.Fatalf("light %v: nonce %d does not satisfy the target",
+				kp.light, nonce)
+		}
+		for n := uint64(startNonce); n < nonce; n++ {
+			_, hash, err := kp.Hash(header, n)
+			if err != nil {
+				t.Fatalf("light %v: unexpected hash error: %v", kp.light,
+					err)
+			}
+			if hashToBig(hash).Cmp(target) <= 0 {
+				t.Fatalf("light %v: earlier nonce %d satisfies the target",
+					kp.light, n)
+			}
+		}
+
+		// Ensure the hash rate was reported once the search completed and
+		// that the cache or dataset was only generated once.
+		if len(hashRates) != 1 || hashRates[0] <= 0 {
+			t.Fatalf("light %v: unexpected hash rate reports %v", kp.light,
+				hashRates)
+		}
+		var numGenerated int
+		for _, line := range logger.lines {
+			if strings.HasPrefix(line, "Generating") {
+				numGenerated++
+			}
+		}
+		if numGenerated != 1 {
+			t.Fatalf("light %v: generated %d times: %v", kp.light,
+				numGenerated, logger.lines)
+		}
+
+		// Ensure an aborted search does not find a solution even when the
+		// target is satisfied by every hash.
+		abort := make(chan struct{})
+		close(abort)
+		_, _, found = kp.Search(header, maxHash, 0, abort)
+		if found {
+			t.Fatalf("light %v: aborted search found a solution", kp.light)
+		}
+
+		// Ensure the maximum target is satisfied by the starting nonce,
+		// including when it is the maximum nonce.
+		nonce, _, found = kp.Search(header, maxHash, ^uint64(0), nil)
+		if !found || nonce != ^uint64(0) {
+			t.Fatalf("light %v: unexpected result for max target -- got "+
+				"%d (found %v)", kp.light, nonce, found)
+		}
+
+		// Ensure a truncated header does not find a solution.
+		_, _, found = kp.Search(header[:minHeaderLen-1], maxHash, 0, nil)
+		if found {
+			t.Fatalf("light %v: truncated header found a solution", kp.light)
+		}
+	}
+}
@@ -126,6 +126,17 @@ func CalcTxTreeMerkleRoot(transactions []*wire.MsgTx) chainhash.Hash {
 	return CalcMerkleRootInPlace(leaves)
 }
 
+// CalcStakeMerkleRoot calculates and returns the merkle root for the provided
+// stake transaction tree that the StakeRoot field of a block header commits
+// to.  The stake tree consists of the tickets, votes, and revocations in the
+// block.
+//
+// See CalcTxTreeMerkleRoot for more details on how the merkle root is
+// calculated.
+func CalcStakeMerkleRoot(stakeTxns []*wire.MsgTx) chainhash.Hash {
+	return CalcTxTreeMerkleRoot(stakeTxns)
+}
+
 // CalcCombinedTxTreeMerkleRoot calculates and returns the combined merkle root
 // for the provided regular and stake transaction trees in accordance with
 // DCP0005.
@@ -6,11 +6,14 @@
 package standalone
 
 import (
-	"encoding/binary"
+	"bytes"
+	"context"
 	"fmt"
+	"math"
 	"math/big"
 
-	"vigil.network/node/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
 	"vigil.network/node/blockchain/standalone/kawpow"
 )
 
@@ -209,39 +212,129 @@ func CheckProofOfWorkHash(powHash *chainhash.Hash, difficultyBits uint32) error
 // target difficulty and that the target difficulty is in min/max range per the
 // provided proof-of-work limit.
 //
-// For KawPoW blocks, this also verifies the mix hash matches the expected value.
-//
 // This is semantically equivalent to and slightly more efficient than calling
 // CheckProofOfWorkRange followed by CheckProofOfWorkHash.
-func CheckProofOfWork(powHash *chainhash.Hash, difficultyBits uint32, powLimit *big.Int, mixDigest *[32]byte) error {
+func CheckProofOfWork(powHash *chainhash.Hash, difficultyBits uint32, powLimit *big.Int) error {
 	target := CompactToBig(difficultyBits)
 	if err := checkProofOfWorkRange(target, powLimit); err != nil {
 		return err
 	}
 
-	// For KawPoW, we need to verify the mix digest matches the expected value
-	if mixDigest != nil {
-		// Create a new KawPoW hasher
-		kp := kawpow.New()
-		
-		// The header bytes are in powHash (from PowHashV2)
-	headerBytes := powHash.CloneBytes()
-		
-		// The nonce is the last 8 bytes of the header
-		nonce := binary.LittleEndian.Uint64(headerBytes[len(headerBytes)-8:])
-		
-		// Verify the mix digest
-		valid, err := kp.Verify(headerBytes, nonce, mixDigest[:])
+	// The proof of work hash must be less than the target difficulty.
+	return checkProofOfWorkHash(powHash, target)
+}
+
+// KawPowHasher is the interface that wraps the Hash method of a KawPoW hasher.
+//
+// Hash returns the mix digest and final proof of work hash for the provided
+// serialized block header, which must have the nonce and mix digest zeroed,
+// along with the provided nonce.
+type KawPowHasher interface {
+	Hash(headerBytes []byte, nonce uint64) ([]byte, []byte, error)
+}
+
+// CheckKawPoWProof ensures the KawPoW proof of work hash of the provided block
+// header calculated with the provided hasher is less than the target difficulty
+// claimed by its bits, that the target difficulty is in min/max range per the
+// provided proof-of-work limit, and that the mix digest committed to by the
+// header is the one produced while calculating the hash.
+//
+// The target difficulty is checked prior to calculating the proof of work hash
+// so that work claiming a target easier than the proof-of-work limit is
+// rejected without incurring the cost of hashing.
+//
+// The hasher defines the KawPoW parameters the hash is calculated with, so
+// callers must provide one for the parameters of the network the header
+// belongs to.
+//
+// Any error encountered while calculating the proof of work hash, such as for a
+// malformed header, is returned as is.
+func CheckKawPoWProof(header *wire.BlockHeader, powLimit *big.Int, kp KawPowHasher) error {
+	target := CompactToBig(header.Bits)
+	if err := checkProofOfWorkRange(target, powLimit); err != nil {
+		return err
+	}
+
+	mix, result, err := kp.Hash(header.BytesNoNonce(), header.Nonce)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(mix, header.MixDigest[:]) {
+		str := fmt.Sprintf("mix digest %x does not match the calculated mix "+
+			"digest %x", header.MixDigest[:], mix)
+		return ruleError(ErrBadMixDigest, str)
+	}
+
+	var powHash chainhash.Hash
+	copy(powHash[:], result)
+	return checkProofOfWorkHash(&powHash, target)
+}
+
+// calcKawPowHeaderHash calculates the KawPoW proof of work hash and mix digest
+// for the provided serialized block header, which must have the nonce and mix
+// digest zeroed, along with the provided nonce with the provided hasher.  The
+// header bytes are hashed in the same manner as the proof of work hash of the
+// header is calculated during validation.
+func calcKawPowHeaderHash(headerBytes []byte, nonce uint64, kp *kawpow.KawPow) (chainhash.Hash, [32]byte, error) {
+	var powHash chainhash.Hash
+	var mixDigest [32]byte
+	mix, result, err := kp.Hash(headerBytes, nonce)
+	if err != nil {
+		return powHash, mixDigest, err
+	}
+	copy(powHash[:], result)
+	copy(mixDigest[:], mix)
+	return powHash, mixDigest, nil
+}
+
+// SolveHeader attempts to find a nonce for the provided block header that
+// results in a KawPoW proof of work hash that is less than the target
+// difficulty claimed by the header bits.
+//
+// The search starts from the current nonce of the header and increments it
+// until either a solution is found, the nonce space is exhausted, or the
+// provided context is cancelled.  The mix digest of the header is set from the
+// hasher for every nonce that is tried, so the header is only guaranteed to
+// pass the proof of work checks when true is returned.
+//
+// An error is returned when the target difficulty is not in min/max range per
+// the provided proof-of-work limit or the hasher fails.  Cancellation of the
+// context is not considered an error.
+//
+// This is primarily intended for tests and tooling, such as simulation network
+// setups and finding genesis block nonces, that need to grind headers with easy
+// target difficulties.  It is not suitable for mining on real networks.
+func SolveHeader(ctx context.Context, header *wire.BlockHeader, powLimit *big.Int, kp *kawpow.KawPow) (bool, error) {
+	target := CompactToBig(header.Bits)
+	if err := checkProofOfWorkRange(target, powLimit); err != nil {
+		return false, err
+	}
+
+	// The header bytes that are hashed do not depend on the nonce or mix
+	// digest, so only serialize them once.
+	headerBytes := header.BytesNoNonce()
+	for {
+		select {
+		case <-ctx.Done():
+			return false, nil
+		default:
+		}
+
+		powHash, mixDigest, err := calcKawPowHeaderHash(headerBytes,
+			header.Nonce, kp)
 		if err != nil {
-			return fmt.Errorf("error verifying mix digest: %v", err)
+			return false, err
 		}
-		if !valid {
-			return fmt.Errorf("invalid mix digest")
+		header.MixDigest = mixDigest
+		if checkProofOfWorkHash(&powHash, target) == nil {
+			return true, nil
 		}
-	}
 
-	// The proof of work hash must be less than the target difficulty.
-	return checkProofOfWorkHash(powHash, target)
+		if header.Nonce == math.MaxUint64 {
+			return false, nil
+		}
+		header.Nonce++
+	}
 }
 
 // CalcASERTDiff calculates an absolutely scheduled exponentially weighted
@@ -5,14 +5,18 @@
 package standalone
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"math/big"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"vigil.network/node/blockchain/standalone/kawpow"
 )
 
 // TestHashToBig ensures HashToBig properly converts a hash treated as a little
@@ -388,7 +392,7 @@ func TestCheckProofOfWork(t *testing.T) {
 			continue
 		}
 
-		err = CheckProofOfWork(hash, test.bits, powLimit, nil)
+		err = CheckProofOfWork(hash, test.bits, powLimit)
 		if !errors.Is(err, test.err) {
 			t.Errorf("%q: unexpected err -- got %v, want %v", test.name, err,
 				test.err)
@@ -397,6 +401,185 @@ func TestCheckProofOfWork(t *testing.T) {
 	}
 }
 
+// mockKawPowHasher provides a KawPoW hasher that returns the mix digest and
+// hash configured by the tests and tracks whether it was invoked to avoid
+// calculating real KawPoW hashes.
+type mockKawPowHasher struct {
+	mix    []byte
+	hash   []byte
+	err    error
+	hashed bool
+}
+
+// Hash returns the configured mix digest, hash, and error.
+//
+// This is part of the KawPowHasher interface.
+func (h *mockKawPowHasher) Hash(headerBytes []byte, nonce uint64) ([]byte, []byte, error) {
+	h.hashed = true
+	return h.mix, h.hash, h.err
+}
+
+// TestCheckKawPoWProof ensures the KawPoW proof of work check for block headers
+// works as expected, rejects headers that claim a target difficulty easier than
+// the proof-of-work limit prior to hashing, and rejects headers that commit to a
+// mix digest other than the calculated one.
+func TestCheckKawPoWProof(t *testing.T) {
+	mixDigest := [32]byte{0x01, 0x02, 0x03}
+	tests := []struct {
+		name       string   // test description
+		hash       string   // proof of work hash to test
+		bits       uint32   // compact target difficulty bits to test
+		powLimit   string   // proof of work limit
+		mixDigest  [32]byte // mix digest committed to by the header
+		hashErr    error    // error to return when calculating the hash
+		wantHashed bool     // whether the hash is expected to be calculated
+		err        error    // expected error
+	}{{
+		name:       "max allowed (exactly the pow limit)",
+		hash:       "0000000000001ffff00000000000000000000000000000000000000000000000",
+		bits:       0x1b01ffff,
+		powLimit:   mockMainNetPowLimit(),
+		mixDigest:  mixDigest,
+		wantHashed: true,
+		err:        nil,
+	}, {
+		name:       "high hash (pow limit + 1)",
+		hash:       "000000000001ffff000000000000000000000000000000000000000000000001",
+		bits:       0x1b01ffff,
+		powLimit:   mockMainNetPowLimit(),
+		mixDigest:  mixDigest,
+		wantHashed: true,
+		err:        ErrHighHash,
+	}, {
+		name:       "mismatched mix digest",
+		hash:       "0000000000001ffff00000000000000000000000000000000000000000000000",
+		bits:       0x1b01ffff,
+		powLimit:   mockMainNetPowLimit(),
+		mixDigest:  [32]byte{0x01, 0x02, 0x04},
+		wantHashed: true,
+		err:        ErrBadMixDigest,
+	}, {
+		name:       "bits easier than pow limit",
+		hash:       "0000000000000000000000000000000000000000000000000000000000000001",
+		bits:       0x1d010000,
+		powLimit:   mockMainNetPowLimit(),
+		mixDigest:  mixDigest,
+		wantHashed: false,
+		err:        ErrUnexpectedDifficulty,
+	}, {
+		name:       "zero target difficulty",
+		hash:       "0000000000000000000000000000000000000000000000000000000000000001",
+		bits:       0,
+		powLimit:   mockMainNetPowLimit(),
+		mixDigest:  mixDigest,
+		wantHashed: false,
+		err:        ErrUnexpectedDifficulty,
+	}, {
+		name:       "error calculating hash",
+		hash:       "0000000000000000000000000000000000000000000000000000000000000001",
+		bits:       0x1b01ffff,
+		powLimit:   mockMainNetPowLimit(),
+		mixDigest:  mixDigest,
+		hashErr:    kawpow.ErrHeaderTooShort,
+		wantHashed: true,
+		err:        kawpow.ErrHeaderTooShort,
+	}}
+
+	for _, test := range tests {
+		hash, err := chainhash.NewHashFromStr(test.hash)
+		if err != nil {
+			t.Errorf("%q: unexpected err parsing test hash: %v", test.name, err)
+			continue
+		}
+		powLimit, success := new(big.Int).SetString(test.powLimit, 16)
+		if !success {
+			t.Errorf("%q: unexpected err parsing test pow limit", test.name)
+			continue
+		}
+
+		kp := &mockKawPowHasher{
+			mix:  mixDigest[:],
+			hash: hash[:],
+			err:  test.hashErr,
+		}
+		header := &wire.BlockHeader{Bits: test.bits, MixDigest: test.mixDigest}
+		err = CheckKawPoWProof(header, powLimit, kp)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: unexpected err -- got %v, want %v", test.name, err,
+				test.err)
+			continue
+		}
+		if kp.hashed != test.wantHashed {
+			t.Errorf("%q: unexpected hashing -- got %v, want %v", test.name,
+				kp.hashed, test.wantHashed)
+			continue
+		}
+	}
+}
+
+// TestSolveHeader ensures solving a header with an easy simnet target
+// difficulty finds a nonce that results in a header that passes the proof of
+// work checks and that solving stops when the context is cancelled.
+func TestSolveHeader(t *testing.T) {
+	// Simnet proof-of-work limit and corresponding target difficulty bits.
+	powLimit := new(big.Int).Sub(new(big.Int).Lsh(bigOne, 255), bigOne)
+	const powLimitBits = 0x207fffff
+
+	header := &wire.BlockHeader{
+		Bits:      powLimitBits,
+		Height:    1,
+		Timestamp: time.Unix(1700000000, 0),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	kp := kawpow.NewLight()
+	found, err := SolveHeader(ctx, header, powLimit, kp)
+	if err != nil {
+		t.Fatalf("unexpected error solving header: %v", err)
+	}
+	if !found {
+		t.Fatal("failed to solve header with easy target difficulty")
+	}
+	if err := CheckKawPoWProof(header, powLimit, kp); err != nil {
+		t.Fatalf("solved header failed proof of work check: %v", err)
+	}
+
+	// Ensure the mix digest of the solved header is the mix digest for the
+	// nonce that was found as opposed to the proof of work hash.
+	mixDigest, err := kawpow.NewLight().ComputeMixDigest(header.BytesNoNonce(),
+		header.Nonce)
+	if err != nil {
+		t.Fatalf("unexpected error computing mix digest: %v", err)
+	}
+	if header.MixDigest != mixDigest {
+		t.Fatalf("mismatched mix digest -- got %x, want %x",
+			header.MixDigest, mixDigest)
+	}
+
+	// Ensure solving stops without error once the context is cancelled.
+	mainNetPowLimit, _ := new(big.Int).SetString(mockMainNetPowLimit(), 16)
+	header.Bits = 0x1b01ffff
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	found, err = SolveHeader(ctx, header, mainNetPowLimit, kawpow.NewLight())
+	if err != nil {
+		t.Fatalf("unexpected error solving header: %v", err)
+	}
+	if found {
+		t.Fatal("solved header after context was cancelled")
+	}
+
+	// Ensure target difficulties easier than the proof-of-work limit are
+	// rejected.
+	header.Bits = 0x1d010000
+	_, err = SolveHeader(context.Background(), header, mainNetPowLimit,
+		kawpow.NewLight())
+	if !errors.Is(err, ErrUnexpectedDifficulty) {
+		t.Fatalf("unexpected error -- got %v, want %v", err,
+			ErrUnexpectedDifficulty)
+	}
+}
+
 // TestCalcASERTDiff ensures the proof-of-work target difficulty calculation for
 // the algorithm defined by DCP0011 works as expected by using the reference
 // test vectors.
@@ -5,6 +5,7 @@
 package standalone
 
 import (
+	"math/bits"
 	"sort"
 	"sync"
 )
@@ -83,6 +84,22 @@ type SubsidyParams interface {
 	VotesPerBlock() uint16
 }
 
+// SubsidyRampParams defines an optional interface that the parameters provided
+// to NewSubsidyCache may also implement in order to enable a slow-start subsidy
+// ramp.  The ramp is disabled when the parameters do not implement it.
+type SubsidyRampParams interface {
+	// SubsidyRampBlockCount returns the number of blocks after block height 1
+	// over which the max potential subsidy linearly ramps up to the full
+	// subsidy.  A value of zero disables the ramp.
+	SubsidyRampBlockCount() int64
+
+	// SubsidyRampStartProportion returns the percentage, from 0 to 100, of the
+	// full subsidy paid by the first block of the subsidy ramp.  See the
+	// documentation for CalcBlockSubsidy for more details on how the
+	// parameter is used.
+	SubsidyRampStartProportion() uint16
+}
+
 // SubsidyCache provides efficient access to consensus-critical subsidy
 // calculations for blocks and votes, including the max potential subsidy for
 // given block heights, the proportional proof-of-work subsidy, the proportional
@@ -111,8 +128,13 @@ type SubsidyCache struct {
 	// be consider valid by consensus.
 	//
 	// totalProportions is the sum of the PoW, PoS, and Treasury proportions.
-	minVotesRequired uint16
-	totalProportions uint16
+	//
+	// rampBlocks and rampStartProportion are the parameters of the slow-start
+	// subsidy ramp.  They are zero when the ramp is disabled.
+	minVotesRequired    uint16
+	totalProportions    uint16
+	rampBlocks          int64
+	rampStartProportion uint16
 }
 
 // NewSubsidyCache creates and initializes a new subsidy cache instance.  See
@@ -125,7 +147,7 @@ func NewSubsidyCache(params SubsidyParams) *SubsidyCache {
 	cache := make(map[uint64]int64, prealloc)
 	cache[0] = baseSubsidy
 
-	return &SubsidyCache{
+	c := &SubsidyCache{
 		cache:            cache,
 		cachedIntervals:  make([]uint64, 1, prealloc),
 		params:           params,
@@ -134,6 +156,11 @@ func NewSubsidyCache(params SubsidyParams) *SubsidyCache {
 			params.StakeSubsidyProportion() +
 			params.TreasurySubsidyProportion(),
 	}
+	if rampParams, ok := params.(SubsidyRampParams); ok {
+		c.rampBlocks = rampParams.SubsidyRampBlockCount()
+		c.rampStartProportion = rampParams.SubsidyRampStartProportion()
+	}
+	return c
 }
 
 // uint64s implements sort.Interface for *[]uint64.
@@ -143,6 +170,38 @@ func (s *uint64s) Len() int           { return len(*s) }
 func (s *uint64s) Less(i, j int) bool { return (*s)[i] < (*s)[j] }
 func (s *uint64s) Swap(i, j int)      { (*s)[i], (*s)[j] = (*s)[j], (*s)[i] }
 
+// calcRampedSubsidy returns the provided full subsidy for a block at the given
+// height reduced according to the linear slow-start subsidy ramp defined by the
+// provided number of ramp blocks and starting proportion.
+//
+// The ramp covers the blocks at heights 2 through rampBlocks+1, inclusive, and
+// the subsidy for those blocks is:
+//
+//	full * (start*rampBlocks + (100-start)*(height-2)) / (100*rampBlocks)
+//
+// Blocks outside of the ramp receive the full subsidy.
+func calcRampedSubsidy(fullSubsidy, height, rampBlocks int64, startProportion uint16) int64 {
+	rampIdx := height - 2
+	if rampBlocks <= 0 || rampIdx < 0 || rampIdx >= rampBlocks {
+		return fullSubsidy
+	}
+
+	// Treat proportions over 100% as no reduction at all.
+	start := int64(startProportion)
+	if start >= 100 {
+		return fullSubsidy
+	}
+
+	// Perform the calculation with 128-bit intermediate precision to avoid
+	// overflow for large subsidies and ramps.  The quotient can't overflow
+	// since the numerator is never larger than the denominator.
+	numerator := uint64(start*rampBlocks + (100-start)*rampIdx)
+	denominator := uint64(100 * rampBlocks)
+	hi, lo := bits.Mul64(uint64(fullSubsidy), numerator)
+	subsidy, _ := bits.Div64(hi, lo, denominator)
+	return int64(subsidy)
+}
+
 // CalcBlockSubsidy returns the max potential subsidy for a block at the
 // provided height.  This value is reduced over time based on the height and
 // then split proportionally between PoW, PoS, and the Treasury.
@@ -155,8 +214,44 @@ func (s *uint64s) Swap(i, j int)      { (*s)[i], (*s)[j] = (*s)[j], (*s)[i] }
 //	  subsidy /= SubsidyReductionDivisor()
 //	}
 //
+// Additionally, when the parameters implement SubsidyRampParams with a nonzero
+// SubsidyRampBlockCount, the subsidy of the blocks after block height 1
+// linearly ramps up from SubsidyRampStartProportion percent of the above value
+// to the full value over that many blocks in order to discourage instamining.
+//
 // This function is safe for concurrent access.
 func (c *SubsidyCache) CalcBlockSubsidy(height int64) int64 {
+	subsidy := c.calcReducedBlockSubsidy(height)
+	return calcRampedSubsidy(subsidy, height, c.rampBlocks,
+		c.rampStartProportion)
+}
+
+// CalcSubsidyRampShortfall returns the total amount by which the max potential
+// subsidy of all blocks up to and including the provided height is reduced
+// from the full subsidy due to the slow-start subsidy ramp.  It is zero when
+// the ramp is disabled.
+//
+// This function is safe for concurrent access.
+func (c *SubsidyCache) CalcSubsidyRampShortfall(height int64) int64 {
+	lastRampHeight := c.rampBlocks + 1
+	if height < lastRampHeight {
+		lastRampHeight = height
+	}
+
+	var shortfall int64
+	for rampHeight := int64(2); rampHeight <= lastRampHeight; rampHeight++ {
+		shortfall += c.calcReducedBlockSubsidy(rampHeight) -
+			c.CalcBlockSubsidy(rampHeight)
+	}
+	return shortfall
+}
+
+// calcReducedBlockSubsidy returns the max potential subsidy for a block at the
+// provided height prior to applying the slow-start subsidy ramp.  See the
+// documentation for CalcBlockSubsidy for more details.
+//
+// This function is safe for concurrent access.
+func (c *SubsidyCache) calcReducedBlockSubsidy(height int64) int64 {
 	// Negative block heights are invalid and produce no subsidy.
 	// Block 0 is the genesis block and produces no subsidy.
 	// Block 1 subsidy is special as it is used for initial token distribution.
@@ -33,11 +33,16 @@ type mockSubsidyParams struct {
 	treasuryProportion    uint16
 	stakeValidationHeight int64
 	votesPerBlock         uint16
+	rampBlocks            int64
+	rampProportion        uint16
 }
 
 // Ensure the mock subsidy params satisfy the SubsidyParams interface.
 var _ SubsidyParams = (*mockSubsidyParams)(nil)
 
+// Ensure the mock subsidy params satisfy the SubsidyRampParams interface.
+var _ SubsidyRampParams = (*mockSubsidyParams)(nil)
+
 // BlockOneSubsidy returns the value associated with the mock params for the
 // total subsidy of block height 1 for the network.
 //
@@ -80,6 +85,23 @@ func (p *mockSubsidyParams) SubsidyReductionIntervalBlocks() int64 {
 	return p.reductionInterval
 }
 
+// SubsidyRampBlockCount returns the value associated with the mock params for
+// the number of blocks over which the subsidy ramps up to the full subsidy.
+//
+// This is part of the SubsidyRampParams interface.
+func (p *mockSubsidyParams) SubsidyRampBlockCount() int64 {
+	return p.rampBlocks
+}
+
+// SubsidyRampStartProportion returns the value associated with the mock params
+// for the percentage of the full subsidy paid by the first block of the subsidy
+// ramp.
+//
+// This is part of the SubsidyRampParams interface.
+func (p *mockSubsidyParams) SubsidyRampStartProportion() uint16 {
+	return p.rampProportion
+}
+
 // WorkSubsidyProportion returns the value associated with the mock params for
 // the comparative proportion of the subsidy generated for creating a block
 // (PoW).
@@ -1538,3 +1560,93 @@ func TestCalcBlockSubsidySparseCaching(t *testing.T) {
 		}
 	}
 }
+
+// TestCalcBlockSubsidyRamp ensures the max potential block subsidy increases
+// linearly over the slow-start subsidy ramp and matches the full subsidy once
+// the ramp is complete.
+func TestCalcBlockSubsidyRamp(t *testing.T) {
+	// Mock params with a ramp over 10 blocks that starts at 10% of the full
+	// subsidy.
+	params := mockMainNetParams()
+	params.rampBlocks = 10
+	params.rampProportion = 10
+	fullSubsidy := params.baseSubsidy
+	cache := NewSubsidyCache(params)
+
+	// Ensure block one is not impacted by the ramp.
+	if got := cache.CalcBlockSubsidy(1); got != params.blockOne {
+		t.Fatalf("unexpected block one subsidy -- got %d, want %d", got,
+			params.blockOne)
+	}
+
+	// Ensure the first block of the ramp pays the starting proportion.
+	const firstRampHeight = 2
+	rampStart := fullSubsidy * int64(params.rampProportion) / 100
+	if got := cache.CalcBlockSubsidy(firstRampHeight); got != rampStart {
+		t.Fatalf("unexpected first ramp block subsidy -- got %d, want %d",
+			got, rampStart)
+	}
+
+	// Ensure the subsidy increases by the same amount, within rounding, for
+	// every block in the ramp through the first block after it.
+	wantStep := (fullSubsidy - rampStart) / params.rampBlocks
+	prevSubsidy := rampStart
+	for i := int64(1); i <= params.rampBlocks; i++ {
+		height := firstRampHeight + i
+		subsidy := cache.CalcBlockSubsidy(height)
+		step := subsidy - prevSubsidy
+		if diff := step - wantStep; diff < -1 || diff > 1 {
+			t.Fatalf("unexpected subsidy increase at height %d -- got %d, "+
+				"want %d", height, step, wantStep)
+		}
+		prevSubsidy = subsidy
+	}
+
+	// Ensure the blocks after the ramp receive the full base subsidy.
+	for height := firstRampHeight + params.rampBlocks; height < 20; height++ {
+		if got := cache.CalcBlockSubsidy(height); got != fullSubsidy {
+			t.Fatalf("unexpected subsidy at height %d after ramp -- got %d, "+
+				"want %d", height, got, fullSubsidy)
+		}
+	}
+}
+
+// TestCalcSubsidyRampShortfall ensures the total subsidy withheld by the
+// slow-start subsidy ramp matches the sum of the reductions applied to the
+// individual blocks and that the ramp is disabled for parameters that do not
+// implement the SubsidyRampParams interface.
+func TestCalcSubsidyRampShortfall(t *testing.T) {
+	// Mock params with a ramp over 10 blocks that starts at 10% of the full
+	// subsidy.
+	params := mockMainNetParams()
+	params.rampBlocks = 10
+	params.rampProportion = 10
+	cache := NewSubsidyCache(params)
+
+	// Ensure the shortfall is the running total of the reduction of every
+	// block through a few blocks after the ramp completes.
+	var wantShortfall int64
+	for height := int64(0); height < params.rampBlocks+5; height++ {
+		if height > 1 {
+			wantShortfall += params.baseSubsidy - cache.CalcBlockSubsidy(height)
+		}
+		got := cache.CalcSubsidyRampShortfall(height)
+		if got != wantShortfall {
+			t.Fatalf("unexpected shortfall at height %d -- got %d, want %d",
+				height, got, wantShortfall)
+		}
+	}
+
+	// Ensure the ramp is disabled when the params only implement the required
+	// SubsidyParams interface.
+	noRampCache := NewSubsidyCache(struct{ SubsidyParams }{params})
+	const rampHeight = 2
+	if got := noRampCache.CalcBlockSubsidy(rampHeight); got != params.baseSubsidy {
+		t.Fatalf("unexpected subsidy without ramp params -- got %d, want %d",
+			got, params.baseSubsidy)
+	}
+	if got := noRampCache.CalcSubsidyRampShortfall(100); got != 0 {
+		t.Fatalf("unexpected shortfall without ramp params -- got %d, want 0",
+			got)
+	}
+}
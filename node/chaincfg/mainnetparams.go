@@ -104,6 +104,9 @@ func MainNetParams() *Params {
 		MaxTxSize:            393216,
 		TargetTimePerBlock:   time.Minute * 5,
 
+		// Blocks may not have timestamps before their parent.
+		MaxBlockTimeRegression: 0,
+
 		// Version 1 difficulty algorithm (EMA + BLAKE256) parameters.
 		WorkDiffAlpha:            1,
 		WorkDiffWindowSize:       144,
@@ -113,8 +116,19 @@ func MainNetParams() *Params {
 
 		// Version 2 difficulty algorithm (ASERT + BLAKE3) parameters.
 		WorkDiffV2Blake3StartBits: 0x1b00a5a6,
+		WorkDiffV2KawPowStartBits: mainPowLimitBits,
 		WorkDiffV2HalfLifeSecs:    43200, // 144 * TimePerBlock (12 hours)
 
+		// KawPoW proof of work parameters.
+		KawPow: KawPowParams{
+			EpochLength:        7500,
+			DatasetInitBytes:   2 * 1024 * 1024 * 1024, // 2 GiB
+			DatasetGrowthBytes: 8 * 1024 * 1024,        // 8 MiB
+			CacheInitBytes:     16 * 1024 * 1024,       // 16 MiB
+			CacheGrowthBytes:   128 * 1024,             // 128 KiB
+			CacheRounds:        3,
+		},
+
 		// Subsidy parameters.
 		BaseSubsidy:              3119582664, // 21m
 		MulSubsidy:               100,
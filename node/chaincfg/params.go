@@ -204,6 +204,41 @@ type ConsensusDeployment struct {
 	ExpireTime uint64
 }
 
+// KawPowParams defines the tunable parameters of the KawPoW proof of work
+// algorithm for a network.
+type KawPowParams struct {
+	// EpochLength is the number of blocks in each KawPoW epoch.  The seed
+	// hash, and therefore the cache and dataset (DAG), changes at every epoch
+	// boundary.
+	EpochLength int64
+
+	// DatasetInitBytes is the target size of the dataset in bytes for the
+	// first epoch.  It must be a multiple of 128.  The actual size of the
+	// dataset for each epoch is the largest prime number of 128-byte items
+	// below its target size.
+	DatasetInitBytes uint64
+
+	// DatasetGrowthBytes is the number of bytes the target size of the
+	// dataset grows by for each epoch after the first one.  It must be a
+	// multiple of 128.
+	DatasetGrowthBytes uint64
+
+	// CacheInitBytes is the target size of the verification cache in bytes
+	// for the first epoch.  It must be a multiple of 64.  The actual size of
+	// the cache for each epoch is the largest prime number of 64-byte items
+	// below its target size.
+	CacheInitBytes uint64
+
+	// CacheGrowthBytes is the number of bytes the target size of the
+	// verification cache grows by for each epoch after the first one.  It
+	// must be a multiple of 64.
+	CacheGrowthBytes uint64
+
+	// CacheRounds is the number of rounds used to generate the verification
+	// cache.
+	CacheRounds int
+}
+
 // TokenPayout is a payout for block 1 which specifies a required script
 // version, script, and amount to pay in a transaction output.
 type TokenPayout struct {
@@ -254,6 +289,12 @@ type Params struct {
 	// GenesisHash is the starting block hash.
 	GenesisHash chainhash.Hash
 
+	// GenesisPowRequired specifies whether or not the genesis block must
+	// satisfy the proof of work target given by its difficulty bits.  It is
+	// not set for networks with genesis blocks that are valid by definition
+	// and were never mined.
+	GenesisPowRequired bool
+
 	// PowLimit defines the highest allowed proof of work value for a block
 	// as a uint256.
 	PowLimit *big.Int
@@ -278,6 +319,16 @@ type Params struct {
 	// Deprecated: This will be removed in the next major version bump.
 	MinDiffReductionTime time.Duration
 
+	// MinTestNetTarget defines the lowest allowed proof of work target, which
+	// equates to the highest allowed difficulty, for a block on test networks.
+	// It prevents runaway difficulty on test networks due to ASICs and GPUs
+	// since it is not reasonable to require high-powered hardware to keep a
+	// test network running smoothly.
+	//
+	// A nil value means the difficulty is not capped and it must not be set
+	// on a main network.
+	MinTestNetTarget *big.Int
+
 	// GenerateSupported specifies whether or not CPU mining is allowed.
 	GenerateSupported bool
 
@@ -297,6 +348,16 @@ type Params struct {
 	// block.
 	TargetTimePerBlock time.Duration
 
+	// MaxBlockTimeRegression is the maximum amount of time the timestamp of a
+	// block is permitted to be before the timestamp of its parent.  It is
+	// enforced independently of the median time of the previous blocks in
+	// order to limit the ability to manipulate the ASERT difficulty by
+	// marching timestamps backwards within the median time window.
+	//
+	// A value of zero means the timestamp of a block may not be before the
+	// timestamp of its parent.
+	MaxBlockTimeRegression time.Duration
+
 	// -------------------------------------------------------------------------
 	// Version 1 difficulty algorithm (EMA + BLAKE256) parameters.
 	// -------------------------------------------------------------------------
@@ -341,8 +402,19 @@ type Params struct {
 
 	// WorkDiffV2Blake3StartBits is the starting difficulty bits to use for
 	// proof of work under BLAKE3.
+	//
+	// Deprecated: The proof of work hash introduced along with the version 2
+	// difficulty algorithm is KawPoW, so the difficulty is reset to
+	// WorkDiffV2KawPowStartBits instead.  This will be removed in the next
+	// major version bump.
 	WorkDiffV2Blake3StartBits uint32
 
+	// WorkDiffV2KawPowStartBits is the starting difficulty bits to use for
+	// proof of work under KawPoW.  The difficulty is reset to this value upon
+	// activation since the work performed under KawPoW is not comparable to
+	// the work performed under the original proof of work hash function.
+	WorkDiffV2KawPowStartBits uint32
+
 	// WorkDiffV2HalfLife is the number of seconds to use for the relaxation
 	// time when calculating how difficult it is to solve a block.  The
 	// algorithm sets the difficulty exponentially such that it is halved or
@@ -350,6 +422,14 @@ type Params struct {
 	// or ahead of the ideal schedule.
 	WorkDiffV2HalfLifeSecs int64
 
+	// -------------------------------------------------------------------------
+	// KawPoW proof of work parameters.
+	// -------------------------------------------------------------------------
+
+	// KawPow defines the tunable parameters of the KawPoW proof of work
+	// algorithm for the network.
+	KawPow KawPowParams
+
 	// Subsidy parameters.
 	//
 	// Subsidy calculation for exponential reductions:
@@ -373,6 +453,15 @@ type Params struct {
 	// SubsidyReductionInterval is the reduction interval in blocks.
 	SubsidyReductionInterval int64
 
+	// SubsidyRampBlocks is the number of blocks after block height 1 over
+	// which the subsidy linearly ramps up to the full subsidy in order to
+	// discourage instamining.  A value of zero disables the ramp.
+	SubsidyRampBlocks int64
+
+	// SubsidyRampProportion is the percentage, from 0 to 100, of the full
+	// subsidy paid by the first block of the subsidy ramp.
+	SubsidyRampProportion uint16
+
 	// WorkRewardProportion is the comparative amount of the subsidy given for
 	// creating a block using the proportions prior to the modified values
 	// defined in DCP0010.
@@ -687,6 +776,18 @@ func (p *Params) SubsidyReductionIntervalBlocks() int64 {
 	return p.SubsidyReductionInterval
 }
 
+// SubsidyRampBlockCount returns the number of blocks after block height 1 over
+// which the subsidy linearly ramps up to the full subsidy.
+func (p *Params) SubsidyRampBlockCount() int64 {
+	return p.SubsidyRampBlocks
+}
+
+// SubsidyRampStartProportion returns the percentage of the full subsidy paid by
+// the first block of the subsidy ramp.
+func (p *Params) SubsidyRampStartProportion() uint16 {
+	return p.SubsidyRampProportion
+}
+
 // WorkSubsidyProportion returns the comparative proportion of the subsidy
 // generated for creating a block (PoW) using the proportions prior to the
 // modified values defined in DCP0010.
@@ -104,6 +104,9 @@ func RegNetParams() *Params {
 		MaxTxSize:            1000000,
 		TargetTimePerBlock:   time.Second,
 
+		// Blocks may not have timestamps before their parent.
+		MaxBlockTimeRegression: 0,
+
 		// Version 1 difficulty algorithm (EMA + BLAKE256) parameters.
 		WorkDiffAlpha:            1,
 		WorkDiffWindowSize:       8,
@@ -113,8 +116,19 @@ func RegNetParams() *Params {
 
 		// Version 2 difficulty algorithm (ASERT + BLAKE3) parameters.
 		WorkDiffV2Blake3StartBits: regNetPowLimitBits,
+		WorkDiffV2KawPowStartBits: regNetPowLimitBits,
 		WorkDiffV2HalfLifeSecs:    6, // 6 * TimePerBlock
 
+		// KawPoW proof of work parameters.
+		KawPow: KawPowParams{
+			EpochLength:        7500,
+			DatasetInitBytes:   2 * 1024 * 1024 * 1024, // 2 GiB
+			DatasetGrowthBytes: 8 * 1024 * 1024,        // 8 MiB
+			CacheInitBytes:     16 * 1024 * 1024,       // 16 MiB
+			CacheGrowthBytes:   128 * 1024,             // 128 KiB
+			CacheRounds:        3,
+		},
+
 		// Subsidy parameters.
 		BaseSubsidy:              50000000000,
 		MulSubsidy:               100,
@@ -97,11 +97,15 @@ func TestNet3Params() *Params {
 		PowLimitBits:         testNetPowLimitBits,
 		ReduceMinDifficulty:  true,
 		MinDiffReductionTime: time.Minute * 10, // ~99.3% chance to be mined before reduction
+		MinTestNetTarget:     new(big.Int).Rsh(testNetPowLimit, 6),
 		GenerateSupported:    true,
 		MaximumBlockSizes:    []int{1310720},
 		MaxTxSize:            1000000,
 		TargetTimePerBlock:   time.Minute * 2,
 
+		// Blocks may not have timestamps before their parent.
+		MaxBlockTimeRegression: 0,
+
 		// Version 1 difficulty algorithm (EMA + BLAKE256) parameters.
 		WorkDiffAlpha:            1,
 		WorkDiffWindowSize:       144,
@@ -111,8 +115,19 @@ func TestNet3Params() *Params {
 
 		// Version 2 difficulty algorithm (ASERT + BLAKE3) parameters.
 		WorkDiffV2Blake3StartBits: testNetPowLimitBits,
+		WorkDiffV2KawPowStartBits: testNetPowLimitBits,
 		WorkDiffV2HalfLifeSecs:    720, // 6 * TimePerBlock (12 minutes)
 
+		// KawPoW proof of work parameters.
+		KawPow: KawPowParams{
+			EpochLength:        7500,
+			DatasetInitBytes:   2 * 1024 * 1024 * 1024, // 2 GiB
+			DatasetGrowthBytes: 8 * 1024 * 1024,        // 8 MiB
+			CacheInitBytes:     16 * 1024 * 1024,       // 16 MiB
+			CacheGrowthBytes:   128 * 1024,             // 128 KiB
+			CacheRounds:        3,
+		},
+
 		// Subsidy parameters.
 		BaseSubsidy:              2500000000, // 25 Coin
 		MulSubsidy:               100,
@@ -0,0 +1,187 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+)
+
+const (
+	// minCoinbaseScriptLen is the minimum length a coinbase signature script
+	// can be.
+	minCoinbaseScriptLen = 2
+
+	// maxCoinbaseScriptLen is the maximum length a coinbase signature script
+	// can be.
+	maxCoinbaseScriptLen = 100
+)
+
+var (
+	errNoGenesisBlock        = errors.New("genesis block is not defined")
+	errGenesisPrevBlock      = errors.New("genesis block must not have a previous block")
+	errGenesisNumTxns        = errors.New("genesis block must only contain a coinbase transaction")
+	errGenesisCoinbase       = errors.New("malformed genesis coinbase")
+	errGenesisMerkleRoot     = errors.New("genesis merkle root mismatch")
+	errGenesisStakeRoot      = errors.New("genesis stake root mismatch")
+	errGenesisHash           = errors.New("genesis hash mismatch")
+	errGenesisProofOfWork    = errors.New("genesis block does not satisfy proof of work")
+	errGenesisDifficultyBits = errors.New("genesis block has invalid difficulty bits")
+)
+
+// hashToBig converts a chainhash.Hash into a big.Int that can be used to
+// perform math comparisons.
+func hashToBig(hash *chainhash.Hash) *big.Int {
+	// A Hash is in little-endian, but the big package wants the bytes in
+	// big-endian, so reverse them.
+	buf := *hash
+	blen := len(buf)
+	for i := 0; i < blen/2; i++ {
+		buf[i], buf[blen-1-i] = buf[blen-1-i], buf[i]
+	}
+
+	return new(big.Int).SetBytes(buf[:])
+}
+
+// compactToBig converts a compact representation of a whole number N to an
+// unsigned 32-bit number.  See CompactToBig in the standalone blockchain
+// package for details.
+func compactToBig(compact uint32) *big.Int {
+	// Extract the mantissa, sign bit, and exponent.
+	mantissa := compact & 0x007fffff
+	isNegative := compact&0x00800000 != 0
+	exponent := uint(compact >> 24)
+
+	// N = mantissa * 256^(exponent-3)
+	var bn *big.Int
+	if exponent <= 3 {
+		mantissa >>= 8 * (3 - exponent)
+		bn = big.NewInt(int64(mantissa))
+	} else {
+		bn = big.NewInt(int64(mantissa))
+		bn.Lsh(bn, 8*(exponent-3))
+	}
+
+	// Make it negative if the sign bit is set.
+	if isNegative {
+		bn = bn.Neg(bn)
+	}
+
+	return bn
+}
+
+// validateGenesisCoinbase ensures the provided genesis coinbase transaction is
+// well formed.  That is to say it has a single fully null input with a
+// signature script of a valid length and at least one output that does not
+// pay a negative amount.
+func validateGenesisCoinbase(tx *wire.MsgTx) error {
+	if len(tx.TxIn) != 1 {
+		return fmt.Errorf("%w: coinbase has %d inputs instead of 1",
+			errGenesisCoinbase, len(tx.TxIn))
+	}
+	txIn := tx.TxIn[0]
+	prevOut := &txIn.PreviousOutPoint
+	if prevOut.Hash != (chainhash.Hash{}) ||
+		prevOut.Index != wire.MaxPrevOutIndex ||
+		prevOut.Tree != wire.TxTreeRegular {
+
+		return fmt.Errorf("%w: coinbase input references non-null outpoint "+
+			"%v", errGenesisCoinbase, prevOut)
+	}
+	scriptLen := len(txIn.SignatureScript)
+	if scriptLen < minCoinbaseScriptLen || scriptLen > maxCoinbaseScriptLen {
+		return fmt.Errorf("%w: coinbase signature script length of %d is "+
+			"out of range (min: %d, max: %d)", errGenesisCoinbase, scriptLen,
+			minCoinbaseScriptLen, maxCoinbaseScriptLen)
+	}
+	if len(tx.TxOut) == 0 {
+		return fmt.Errorf("%w: coinbase does not have any outputs",
+			errGenesisCoinbase)
+	}
+	for i, txOut := range tx.TxOut {
+		if txOut.Value < 0 {
+			return fmt.Errorf("%w: coinbase output %d pays negative amount "+
+				"%d", errGenesisCoinbase, i, txOut.Value)
+		}
+	}
+	return nil
+}
+
+// ValidateGenesis ensures the genesis block defined by the parameters is
+// internally consistent.  In particular, it ensures the coinbase is well
+// formed, the merkle root commits to the coinbase, the genesis hash matches
+// the hash of the block, and, when the network requires it, the block
+// satisfies the proof of work target given by its difficulty bits.
+//
+// This is intended to catch misconfigured parameters at startup as opposed to
+// when the genesis block is first used for validation.
+func (p *Params) ValidateGenesis() error {
+	block := p.GenesisBlock
+	if block == nil {
+		return errNoGenesisBlock
+	}
+	header := &block.Header
+	if header.PrevBlock != (chainhash.Hash{}) {
+		return fmt.Errorf("%w (prev block %v)", errGenesisPrevBlock,
+			header.PrevBlock)
+	}
+
+	// The genesis block must only contain a well-formed coinbase.
+	if len(block.Transactions) != 1 || len(block.STransactions) != 0 {
+		return fmt.Errorf("%w (regular txns: %d, stake txns: %d)",
+			errGenesisNumTxns, len(block.Transactions),
+			len(block.STransactions))
+	}
+	coinbase := block.Transactions[0]
+	if err := validateGenesisCoinbase(coinbase); err != nil {
+		return err
+	}
+
+	// The merkle root of a block that only contains a coinbase is the full
+	// hash of the coinbase.
+	//
+	// NOTE: The test network (version 3) incorrectly committed to the hash of
+	// the coinbase without the witness data.  Correcting it would change the
+	// genesis hash, so it is allowed as a special case.
+	merkleRoot := coinbase.TxHashFull()
+	if p.Net == wire.TestNet3 && header.MerkleRoot != merkleRoot {
+		merkleRoot = coinbase.TxHash()
+	}
+	if header.MerkleRoot != merkleRoot {
+		return fmt.Errorf("%w: header commits to %v instead of %v",
+			errGenesisMerkleRoot, header.MerkleRoot, merkleRoot)
+	}
+	if header.StakeRoot != (chainhash.Hash{}) {
+		return fmt.Errorf("%w: header commits to %v instead of %v",
+			errGenesisStakeRoot, header.StakeRoot, chainhash.Hash{})
+	}
+
+	// The genesis hash must match the hash of the block.
+	hash := block.BlockHash()
+	if p.GenesisHash != hash {
+		return fmt.Errorf("%w: parameters specify %v instead of %v",
+			errGenesisHash, p.GenesisHash, hash)
+	}
+
+	// Ensure the block satisfies the proof of work target given by its bits
+	// for networks that require it.
+	if !p.GenesisPowRequired {
+		return nil
+	}
+	target := compactToBig(header.Bits)
+	if target.Sign() <= 0 || (p.PowLimit != nil && target.Cmp(p.PowLimit) > 0) {
+		return fmt.Errorf("%w: target difficulty of %064x is not in the "+
+			"range [1, %064x]", errGenesisDifficultyBits, target, p.PowLimit)
+	}
+	if hashNum := hashToBig(&hash); hashNum.Cmp(target) > 0 {
+		return fmt.Errorf("%w: block hash of %064x is higher than expected "+
+			"max of %064x", errGenesisProofOfWork, hashNum, target)
+	}
+	return nil
+}
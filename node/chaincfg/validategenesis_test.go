@@ -0,0 +1,142 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+)
+
+// TestValidateGenesis ensures the genesis blocks of all networks are valid and
+// that misconfigured genesis blocks are rejected with the expected errors.
+func TestValidateGenesis(t *testing.T) {
+	t.Parallel()
+
+	// Ensure the genesis blocks of all networks are valid.
+	vigilTestParams := VigilTestNetParams
+	for _, params := range []*Params{MainNetParams(), TestNet3Params(),
+		SimNetParams(), RegNetParams(), &vigilTestParams} {
+
+		if err := params.ValidateGenesis(); err != nil {
+			t.Errorf("%s: unexpected genesis validation error: %v",
+				params.Name, err)
+		}
+	}
+
+	tests := []struct {
+		name    string        // test description
+		munge   func(*Params) // function to corrupt the params
+		err     error         // expected error
+		wantMsg string        // expected error message
+	}{{
+		name: "merkle root does not commit to coinbase",
+		munge: func(p *Params) {
+			p.GenesisBlock.Header.MerkleRoot = chainhash.Hash{0x01}
+			p.GenesisHash = p.GenesisBlock.BlockHash()
+		},
+		err: errGenesisMerkleRoot,
+		wantMsg: "genesis merkle root mismatch: header commits to " +
+			"0000000000000000000000000000000000000000000000000000000000000001 " +
+			"instead of " +
+			"66aa7491b9adce110585ccab7e3fb5fe280de174530cca10eba2c6c3df01c10d",
+	}, {
+		name: "genesis hash does not match block",
+		munge: func(p *Params) {
+			p.GenesisHash = chainhash.Hash{0x01}
+		},
+		err: errGenesisHash,
+		wantMsg: "genesis hash mismatch: parameters specify " +
+			"0000000000000000000000000000000000000000000000000000000000000001 " +
+			"instead of " +
+			"5405eb50fa33872507f7f580c64bd2215a2cc7159d99a0861bae29e835188122",
+	}, {
+		name: "coinbase spends regular outpoint",
+		munge: func(p *Params) {
+			coinbase := p.GenesisBlock.Transactions[0]
+			coinbase.TxIn[0].PreviousOutPoint.Index = 0
+			p.GenesisBlock.Header.MerkleRoot = coinbase.TxHashFull()
+			p.GenesisHash = p.GenesisBlock.BlockHash()
+		},
+		err: errGenesisCoinbase,
+		wantMsg: "malformed genesis coinbase: coinbase input references " +
+			"non-null outpoint " +
+			"0000000000000000000000000000000000000000000000000000000000000000:0",
+	}, {
+		name: "coinbase without outputs",
+		munge: func(p *Params) {
+			coinbase := p.GenesisBlock.Transactions[0]
+			coinbase.TxOut = nil
+			p.GenesisBlock.Header.MerkleRoot = coinbase.TxHashFull()
+			p.GenesisHash = p.GenesisBlock.BlockHash()
+		},
+		err:     errGenesisCoinbase,
+		wantMsg: "malformed genesis coinbase: coinbase does not have any outputs",
+	}, {
+		name: "extra regular transaction",
+		munge: func(p *Params) {
+			block := p.GenesisBlock
+			block.Transactions = append(block.Transactions, wire.NewMsgTx())
+			p.GenesisHash = block.BlockHash()
+		},
+		err: errGenesisNumTxns,
+		wantMsg: "genesis block must only contain a coinbase transaction " +
+			"(regular txns: 2, stake txns: 0)",
+	}, {
+		name: "unmined genesis with required proof of work",
+		munge: func(p *Params) {
+			p.GenesisPowRequired = true
+		},
+		err: errGenesisProofOfWork,
+		wantMsg: "genesis block does not satisfy proof of work: block hash " +
+			"of 5405eb50fa33872507f7f580c64bd2215a2cc7159d99a0861bae29e835188122 " +
+			"is higher than expected max of " +
+			"000000000001ffff000000000000000000000000000000000000000000000000",
+	}, {
+		name: "required proof of work with bits above the limit",
+		munge: func(p *Params) {
+			p.GenesisPowRequired = true
+			p.GenesisBlock.Header.Bits = 0x2100ffff
+			p.GenesisHash = p.GenesisBlock.BlockHash()
+		},
+		err: errGenesisDifficultyBits,
+	}}
+
+	for _, test := range tests {
+		params := MainNetParams()
+		test.munge(params)
+		err := params.ValidateGenesis()
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: unexpected error -- got %v, want %v", test.name, err,
+				test.err)
+			continue
+		}
+		if test.wantMsg != "" && err.Error() != test.wantMsg {
+			t.Errorf("%q: unexpected error message -- got %q, want %q",
+				test.name, err.Error(), test.wantMsg)
+		}
+	}
+
+	// Ensure a genesis block that satisfies its required proof of work is
+	// accepted.  The regression test network genesis block is solved here
+	// since its proof of work limit is low enough to do so quickly.
+	params := RegNetParams()
+	params.GenesisPowRequired = true
+	header := &params.GenesisBlock.Header
+	target := compactToBig(header.Bits)
+	for {
+		hash := header.BlockHash()
+		if hashToBig(&hash).Cmp(target) <= 0 {
+			params.GenesisHash = hash
+			break
+		}
+		header.Nonce++
+	}
+	if err := params.ValidateGenesis(); err != nil {
+		t.Errorf("unexpected error for mined genesis block: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// errInvalidParams is returned when the parameters for a network violate one
+// or more of the invariants the consensus code relies on.
+var errInvalidParams = errors.New("invalid network parameters")
+
+// maxWorkDiffWeightShift is the maximum number of bits the exponential weights
+// of the proof of work difficulty windows may be shifted by.  It ensures the sum
+// of the weights of all windows fits in an int64.
+const maxWorkDiffWeightShift = 62
+
+// Validate ensures the parameters do not violate the invariants the consensus
+// code relies on.  In particular, it ensures:
+//
+//   - The proof of work difficulty windows are positive
+//   - The proof of work difficulty window weights do not overflow
+//   - The target timespan is the target time per block multiplied by the
+//     proof of work difficulty window size
+//   - The retarget adjustment factor is greater than one
+//   - The test network maximum difficulty, when set, is within the proof of
+//     work limit
+//   - The subsidy reduction factors and interval are positive
+//   - Votes are not required until after the coinbase maturity
+//   - The parameters used by the stake difficulty calculations are set as
+//     described by ValidateStakeParams
+//
+// The returned error lists every violation as opposed to only the first one so
+// all of them can be addressed at once.
+//
+// This is intended to catch misconfigured parameters at startup as opposed to
+// when they are first used by the consensus code.
+func (p *Params) Validate() error {
+	var violations []string
+	addViolation := func(format string, args ...interface{}) {
+		violations = append(violations, fmt.Sprintf(format, args...))
+	}
+
+	// Proof of work difficulty parameters.
+	if p.TargetTimePerBlock <= 0 {
+		addViolation("TargetTimePerBlock must be positive instead of %v",
+			p.TargetTimePerBlock)
+	}
+	if p.WorkDiffWindowSize <= 0 {
+		addViolation("WorkDiffWindowSize must be positive instead of %d",
+			p.WorkDiffWindowSize)
+	}
+	if p.WorkDiffWindows <= 0 {
+		addViolation("WorkDiffWindows must be positive instead of %d",
+			p.WorkDiffWindows)
+	}
+	if p.WorkDiffAlpha < 0 || (p.WorkDiffWindows > 0 &&
+		p.WorkDiffAlpha > maxWorkDiffWeightShift/p.WorkDiffWindows) {
+
+		addViolation("WorkDiffAlpha must not be negative or exceed %d / "+
+			"WorkDiffWindows instead of %d", maxWorkDiffWeightShift,
+			p.WorkDiffAlpha)
+	}
+	wantTimespan := p.TargetTimePerBlock * time.Duration(p.WorkDiffWindowSize)
+	if p.TargetTimespan != wantTimespan {
+		addViolation("TargetTimespan must be TargetTimePerBlock * "+
+			"WorkDiffWindowSize (%v) instead of %v", wantTimespan,
+			p.TargetTimespan)
+	}
+	if p.RetargetAdjustmentFactor <= 1 {
+		addViolation("RetargetAdjustmentFactor must be greater than 1 "+
+			"instead of %d", p.RetargetAdjustmentFactor)
+	}
+	if p.MinTestNetTarget != nil && (p.MinTestNetTarget.Sign() <= 0 ||
+		(p.PowLimit != nil && p.MinTestNetTarget.Cmp(p.PowLimit) > 0)) {
+
+		addViolation("MinTestNetTarget must be positive and not exceed "+
+			"PowLimit instead of %064x", p.MinTestNetTarget)
+	}
+
+	// Subsidy parameters.
+	if p.MulSubsidy <= 0 {
+		addViolation("MulSubsidy must be positive instead of %d",
+			p.MulSubsidy)
+	}
+	if p.DivSubsidy <= 0 {
+		addViolation("DivSubsidy must be positive instead of %d",
+			p.DivSubsidy)
+	}
+	if p.SubsidyReductionInterval <= 0 {
+		addViolation("SubsidyReductionInterval must be positive instead of "+
+			"%d", p.SubsidyReductionInterval)
+	}
+
+	// Stake parameters.
+	if p.StakeValidationHeight <= int64(p.CoinbaseMaturity) {
+		addViolation("StakeValidationHeight must be greater than "+
+			"CoinbaseMaturity (%d) instead of %d", p.CoinbaseMaturity,
+			p.StakeValidationHeight)
+	}
+	violations = append(violations, p.stakeParamViolations()...)
+
+	if len(violations) != 0 {
+		return fmt.Errorf("%w: %s", errInvalidParams,
+			strings.Join(violations, "; "))
+	}
+	return nil
+}
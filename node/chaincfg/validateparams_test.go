@@ -0,0 +1,140 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestValidate ensures the parameters of all networks are valid and that
+// parameters which violate the invariants are rejected with an error that lists
+// every violation.
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	// Ensure the parameters of all networks are valid.
+	vigilParams := VigilMainNetParams
+	vigilTestParams := VigilTestNetParams
+	for _, params := range []*Params{MainNetParams(), TestNet3Params(),
+		SimNetParams(), RegNetParams(), &vigilParams, &vigilTestParams} {
+
+		if err := params.Validate(); err != nil {
+			t.Errorf("%s: unexpected params validation error: %v",
+				params.Name, err)
+		}
+	}
+
+	tests := []struct {
+		name  string        // test description
+		munge func(*Params) // function to corrupt the params
+		want  []string      // expected violated parameters
+	}{{
+		name:  "zero work diff window size",
+		munge: func(p *Params) { p.WorkDiffWindowSize = 0 },
+		want:  []string{"WorkDiffWindowSize", "TargetTimespan"},
+	}, {
+		name:  "zero work diff windows",
+		munge: func(p *Params) { p.WorkDiffWindows = 0 },
+		want:  []string{"WorkDiffWindows"},
+	}, {
+		name:  "zero target time per block",
+		munge: func(p *Params) { p.TargetTimePerBlock = 0 },
+		want:  []string{"TargetTimePerBlock", "TargetTimespan"},
+	}, {
+		name:  "negative work diff alpha",
+		munge: func(p *Params) { p.WorkDiffAlpha = -1 },
+		want:  []string{"WorkDiffAlpha"},
+	}, {
+		name: "work diff window weights overflow",
+		munge: func(p *Params) {
+			p.WorkDiffAlpha = maxWorkDiffWeightShift/p.WorkDiffWindows + 1
+		},
+		want: []string{"WorkDiffAlpha"},
+	}, {
+		name: "target timespan does not match window",
+		munge: func(p *Params) {
+			p.TargetTimespan = p.TargetTimePerBlock *
+				time.Duration(p.WorkDiffWindowSize*p.WorkDiffWindows)
+		},
+		want: []string{"TargetTimespan"},
+	}, {
+		name:  "retarget adjustment factor of one",
+		munge: func(p *Params) { p.RetargetAdjustmentFactor = 1 },
+		want:  []string{"RetargetAdjustmentFactor"},
+	}, {
+		name: "zero min testnet target",
+		munge: func(p *Params) {
+			p.MinTestNetTarget = new(big.Int)
+		},
+		want: []string{"MinTestNetTarget"},
+	}, {
+		name: "min testnet target above pow limit",
+		munge: func(p *Params) {
+			p.MinTestNetTarget = new(big.Int).Add(p.PowLimit, bigOne)
+		},
+		want: []string{"MinTestNetTarget"},
+	}, {
+		name:  "zero subsidy multiplier",
+		munge: func(p *Params) { p.MulSubsidy = 0 },
+		want:  []string{"MulSubsidy"},
+	}, {
+		name:  "zero subsidy divisor",
+		munge: func(p *Params) { p.DivSubsidy = 0 },
+		want:  []string{"DivSubsidy"},
+	}, {
+		name:  "zero subsidy reduction interval",
+		munge: func(p *Params) { p.SubsidyReductionInterval = 0 },
+		want:  []string{"SubsidyReductionInterval"},
+	}, {
+		name: "stake validation height at coinbase maturity",
+		munge: func(p *Params) {
+			p.StakeValidationHeight = int64(p.CoinbaseMaturity)
+		},
+		want: []string{"StakeValidationHeight"},
+	}, {
+		name:  "zero ticket pool size",
+		munge: func(p *Params) { p.TicketPoolSize = 0 },
+		want:  []string{"TicketPoolSize"},
+	}, {
+		name: "multiple violations",
+		munge: func(p *Params) {
+			p.RetargetAdjustmentFactor = 0
+			p.DivSubsidy = 0
+			p.StakeDiffWindowSize = 0
+		},
+		want: []string{"RetargetAdjustmentFactor", "DivSubsidy",
+			"StakeDiffWindowSize"},
+	}}
+
+	for _, test := range tests {
+		params := VigilMainNetParams
+		test.munge(&params)
+		err := params.Validate()
+		if !errors.Is(err, errInvalidParams) {
+			t.Errorf("%q: unexpected error -- got %v, want %v", test.name,
+				err, errInvalidParams)
+			continue
+		}
+
+		// Ensure the error lists every violated parameter and nothing else.
+		violations := strings.Split(strings.TrimPrefix(err.Error(),
+			errInvalidParams.Error()+": "), "; ")
+		if len(violations) != len(test.want) {
+			t.Errorf("%q: unexpected number of violations -- got %d (%v), "+
+				"want %d", test.name, len(violations), err, len(test.want))
+			continue
+		}
+		for i, param := range test.want {
+			if !strings.HasPrefix(violations[i], param+" ") {
+				t.Errorf("%q: unexpected violation %d -- got %q, want %s",
+					test.name, i, violations[i], param)
+			}
+		}
+	}
+}
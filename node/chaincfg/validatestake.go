@@ -0,0 +1,57 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errInvalidStakeParam is returned when a parameter that is used by the stake
+// difficulty calculations is not set to a value they can make use of.
+var errInvalidStakeParam = errors.New("invalid stake parameter")
+
+// stakeParamViolations returns a description of every parameter used by the
+// stake difficulty calculations that is not set to a positive value.
+func (p *Params) stakeParamViolations() []string {
+	positive := []struct {
+		name  string
+		value int64
+	}{
+		{"StakeDiffWindowSize", p.StakeDiffWindowSize},
+		{"StakeDiffWindows", p.StakeDiffWindows},
+		{"StakeDiffAlpha", p.StakeDiffAlpha},
+		{"TicketsPerBlock", int64(p.TicketsPerBlock)},
+		{"TicketPoolSize", int64(p.TicketPoolSize)},
+		{"TicketPoolSizeWeight", int64(p.TicketPoolSizeWeight)},
+		{"TicketMaturity", int64(p.TicketMaturity)},
+		{"MaxFreshStakePerBlock", int64(p.MaxFreshStakePerBlock)},
+		{"MinimumStakeDiff", p.MinimumStakeDiff},
+		{"StakeValidationHeight", p.StakeValidationHeight},
+	}
+	var violations []string
+	for _, param := range positive {
+		if param.value <= 0 {
+			violations = append(violations, fmt.Sprintf("%s must be "+
+				"positive instead of %d", param.name, param.value))
+		}
+	}
+	return violations
+}
+
+// ValidateStakeParams ensures the parameters that are used by the stake
+// difficulty calculations are set.  In particular, it ensures the parameters
+// that define the ticket pool, the ticket price windows, and the minimum
+// ticket price are all positive since the calculations would otherwise divide
+// by zero or produce nonsensical ticket prices.
+//
+// This is intended to catch misconfigured parameters at startup as opposed to
+// when the stake difficulty is first calculated.
+func (p *Params) ValidateStakeParams() error {
+	if violations := p.stakeParamViolations(); len(violations) != 0 {
+		return fmt.Errorf("%w: %s", errInvalidStakeParam, violations[0])
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestValidateStakeParams ensures the stake parameters of all networks are
+// valid and that every parameter used by the stake difficulty calculations is
+// rejected when it is not set.
+func TestValidateStakeParams(t *testing.T) {
+	t.Parallel()
+
+	// Ensure the stake parameters of all networks are valid.
+	vigilParams := VigilMainNetParams
+	vigilTestParams := VigilTestNetParams
+	for _, params := range []*Params{MainNetParams(), TestNet3Params(),
+		SimNetParams(), RegNetParams(), &vigilParams, &vigilTestParams} {
+
+		if err := params.ValidateStakeParams(); err != nil {
+			t.Errorf("%s: unexpected stake params validation error: %v",
+				params.Name, err)
+		}
+	}
+
+	tests := []struct {
+		name  string        // test description
+		munge func(*Params) // function to corrupt the params
+	}{{
+		name:  "zero stake diff window size",
+		munge: func(p *Params) { p.StakeDiffWindowSize = 0 },
+	}, {
+		name:  "negative stake diff window size",
+		munge: func(p *Params) { p.StakeDiffWindowSize = -1 },
+	}, {
+		name:  "zero stake diff windows",
+		munge: func(p *Params) { p.StakeDiffWindows = 0 },
+	}, {
+		name:  "zero stake diff alpha",
+		munge: func(p *Params) { p.StakeDiffAlpha = 0 },
+	}, {
+		name:  "zero tickets per block",
+		munge: func(p *Params) { p.TicketsPerBlock = 0 },
+	}, {
+		name:  "zero ticket pool size",
+		munge: func(p *Params) { p.TicketPoolSize = 0 },
+	}, {
+		name:  "zero ticket pool size weight",
+		munge: func(p *Params) { p.TicketPoolSizeWeight = 0 },
+	}, {
+		name:  "zero ticket maturity",
+		munge: func(p *Params) { p.TicketMaturity = 0 },
+	}, {
+		name:  "zero max fresh stake per block",
+		munge: func(p *Params) { p.MaxFreshStakePerBlock = 0 },
+	}, {
+		name:  "zero minimum stake diff",
+		munge: func(p *Params) { p.MinimumStakeDiff = 0 },
+	}, {
+		name:  "zero stake validation height",
+		munge: func(p *Params) { p.StakeValidationHeight = 0 },
+	}}
+
+	for _, test := range tests {
+		params := VigilMainNetParams
+		test.munge(&params)
+		err := params.ValidateStakeParams()
+		if !errors.Is(err, errInvalidStakeParam) {
+			t.Errorf("%q: unexpected error -- got %v, want %v", test.name,
+				err, errInvalidStakeParam)
+		}
+	}
+}
@@ -30,6 +30,43 @@ var VigilMainNetParams = Params{
 	TargetTimespan:           150 * 144 * time.Second, // 6 hours
 	RetargetAdjustmentFactor: 4,
 
+	// Subsidy parameters.
+	//
+	// The target time per block is half that of the Decred main network, so
+	// the base subsidy is halved and the reduction interval is doubled in
+	// order to retain the same emission rate and reduction schedule over
+	// time.
+	BaseSubsidy:              1559791332, // 21m
+	MulSubsidy:               100,
+	DivSubsidy:               101,
+	SubsidyReductionInterval: 12288,
+	WorkRewardProportion:     6,
+	WorkRewardProportionV2:   1,
+	StakeRewardProportion:    3,
+	StakeRewardProportionV2:  8,
+	BlockTaxProportion:       1,
+	BlockOneLedger:           tokenPayouts_MainNetParams(),
+
+	// Vigil PoS parameters
+	//
+	// The target time per block is half that of the Decred main network, so
+	// the parameters that are measured in blocks in order to target an
+	// amount of time are doubled.
+	MinimumStakeDiff:      2 * 1e8, // 2 Coin
+	TicketPoolSize:        8192,
+	TicketsPerBlock:       5,
+	TicketMaturity:        256,
+	TicketExpiry:          40960, // 5*TicketPoolSize
+	SStxChangeMaturity:    1,
+	TicketPoolSizeWeight:  4,
+	StakeDiffAlpha:        1,   // Minimal
+	StakeDiffWindowSize:   288, // ~12 hours
+	StakeDiffWindows:      20,
+	StakeVersionInterval:  288 * 2 * 7, // ~1 week
+	MaxFreshStakePerBlock: 20,          // 4*TicketsPerBlock
+	StakeEnabledHeight:    256 + 256,   // CoinbaseMaturity + TicketMaturity
+	StakeValidationHeight: 8192,        // ~14 days
+
 	// Add other required parameters with default values
 	AcceptNonStdTxs:         false,
 	CoinbaseMaturity:        256,
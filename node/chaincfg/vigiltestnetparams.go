@@ -0,0 +1,147 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package chaincfg
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+)
+
+var (
+	// VigilTestPowLimit is the highest proof of work value a Vigil block can
+	// have for the test network.  It is the value 2^232 - 1.
+	VigilTestPowLimit = new(big.Int).Sub(new(big.Int).Lsh(bigOne, 232), bigOne)
+
+	// VigilTestNetGenesisBlock defines the genesis block of the block chain
+	// which serves as the public transaction ledger for the Vigil test network.
+	VigilTestNetGenesisBlock = newVigilTestNetGenesisBlock()
+)
+
+// vigilTestPowLimitBits is the Vigil test network proof of work limit in its
+// compact representation.
+//
+// Note that due to the limited precision of the compact representation, this
+// is not exactly equal to the pow limit.  It is the value:
+//
+// 0x000000ffff000000000000000000000000000000000000000000000000000000
+const vigilTestPowLimitBits = 0x1e00ffff
+
+// newVigilTestNetGenesisBlock returns the genesis block for the Vigil test
+// network with the merkle root committing to its coinbase.
+func newVigilTestNetGenesisBlock() wire.MsgBlock {
+	genesisBlock := wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:   1,
+			PrevBlock: chainhash.Hash{},
+			// MerkleRoot: Calculated below.
+			Timestamp: time.Unix(1759276800, 0), // 2025-10-01 00:00:00 UTC
+			Bits:      vigilTestPowLimitBits,    // Difficulty 1
+			SBits:     20000000,
+		},
+		Transactions: []*wire.MsgTx{{
+			SerType: wire.TxSerializeFull,
+			Version: 1,
+			TxIn: []*wire.TxIn{{
+				// Fully null.
+				PreviousOutPoint: wire.OutPoint{
+					Hash:  chainhash.Hash{},
+					Index: 0xffffffff,
+					Tree:  0,
+				},
+				SignatureScript: []byte("Vigil Testnet Genesis Block"),
+				Sequence:        0xffffffff,
+				BlockHeight:     wire.NullBlockHeight,
+				BlockIndex:      wire.NullBlockIndex,
+				ValueIn:         wire.NullValueIn,
+			}},
+			TxOut: []*wire.TxOut{{
+				Version:  0x0000,
+				Value:    0x00000000,
+				PkScript: hexDecode("6a"), // OP_RETURN
+			}},
+			LockTime: 0,
+			Expiry:   0,
+		}},
+	}
+	genesisBlock.Header.MerkleRoot = genesisBlock.Transactions[0].TxHashFull()
+	return genesisBlock
+}
+
+// VigilTestNetParams defines the network parameters for the Vigil test
+// network.
+//
+// Unlike the main network, the required difficulty is reduced to the minimum
+// once too much time has elapsed without mining a block so the network can
+// keep running with little hash power.
+var VigilTestNetParams = Params{
+	Name:        "testnet",
+	Net:         0xd9b40002, // Unique network ID for the Vigil test network
+	DefaultPort: "19250",
+	DNSSeeds: []DNSSeed{
+		{Host: "testnet-seed.vigil.network", HasFiltering: true},
+	},
+
+	// Chain parameters
+	GenesisBlock:             &VigilTestNetGenesisBlock,
+	GenesisHash:              VigilTestNetGenesisBlock.BlockHash(),
+	PowLimit:                 VigilTestPowLimit,
+	PowLimitBits:             vigilTestPowLimitBits,
+	ReduceMinDifficulty:      true,
+	MinDiffReductionTime:     150 * 5 * time.Second, // 5*TargetTimePerBlock
+	MinTestNetTarget:         new(big.Int).Rsh(VigilTestPowLimit, 6),
+	GenerateSupported:        true,
+	MaximumBlockSizes:        []int{1310720},
+	MaxTxSize:                1000000,
+	TargetTimePerBlock:       150 * time.Second, // 2.5 minutes
+	WorkDiffAlpha:            1,
+	WorkDiffWindowSize:       144,
+	WorkDiffWindows:          20,
+	TargetTimespan:           150 * 144 * time.Second, // 6 hours
+	RetargetAdjustmentFactor: 4,
+
+	// KawPoW proof of work parameters.
+	KawPow: KawPowParams{
+		EpochLength:        7500,
+		DatasetInitBytes:   2 * 1024 * 1024 * 1024, // 2 GiB
+		DatasetGrowthBytes: 8 * 1024 * 1024,        // 8 MiB
+		CacheInitBytes:     16 * 1024 * 1024,       // 16 MiB
+		CacheGrowthBytes:   128 * 1024,             // 128 KiB
+		CacheRounds:        3,
+	},
+
+	// Subsidy parameters.
+	BaseSubsidy:              1559791332,
+	MulSubsidy:               100,
+	DivSubsidy:               101,
+	SubsidyReductionInterval: 4096,
+	WorkRewardProportion:     6,
+	WorkRewardProportionV2:   1,
+	StakeRewardProportion:    3,
+	StakeRewardProportionV2:  8,
+	BlockTaxProportion:       1,
+	BlockOneLedger:           tokenPayouts_TestNet3Params(),
+
+	// Vigil PoS parameters
+	MinimumStakeDiff:      20000000, // 0.2 Coin
+	TicketPoolSize:        1024,
+	TicketsPerBlock:       5,
+	TicketMaturity:        16,
+	TicketExpiry:          6144, // 6*TicketPoolSize
+	CoinbaseMaturity:      16,
+	SStxChangeMaturity:    1,
+	TicketPoolSizeWeight:  4,
+	StakeDiffAlpha:        1,   // Minimal
+	StakeDiffWindowSize:   288, // ~12 hours
+	StakeDiffWindows:      20,
+	StakeVersionInterval:  288 * 2 * 7, // ~1 week
+	MaxFreshStakePerBlock: 20,          // 4*TicketsPerBlock
+	StakeEnabledHeight:    16 + 16,     // CoinbaseMaturity + TicketMaturity
+	StakeValidationHeight: 768,         // Arbitrary
+
+	AcceptNonStdTxs: true,
+}
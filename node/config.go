@@ -28,6 +28,7 @@ import (
 	_ "github.com/decred/dcrd/database/v3/ffldb"
 	"github.com/decred/dcrd/dcrutil/v4"
 	"github.com/decred/dcrd/internal/mempool"
+	"github.com/decred/dcrd/internal/mining"
 	"github.com/decred/dcrd/internal/version"
 	"github.com/decred/dcrd/rpc/jsonrpc/types/v4"
 	"github.com/decred/dcrd/sampleconfig"
@@ -42,6 +43,7 @@ const (
 	defaultConfigFilename   = "dcrd.conf"
 	defaultDataDirname      = "data"
 	defaultLogDirname       = "logs"
+	defaultDAGDirname       = "dag"
 	defaultLogFilename      = "dcrd.log"
 	defaultLogSize          = "10M"
 	defaultDbType           = "ffldb"
@@ -57,6 +59,9 @@ const (
 	defaultMaxRPCWebsockets     = 25
 	defaultMaxRPCConcurrentReqs = 20
 
+	// Defaults for chain related options.
+	defaultMaxEpochLookahead = 2
+
 	// Defaults for P2P network options.
 	defaultMaxSameIP       = 5
 	defaultMaxPeers        = 125
@@ -138,6 +143,8 @@ type config struct {
 	RPCClientCAs         string   `long:"clientcafile" description:"File containing Certificate Authorities to verify TLS client certificates; requires authtype=clientcert"`
 	RPCLimitUser         string   `long:"rpclimituser" description:"Username for limited RPC connections"`
 	RPCLimitPass         string   `long:"rpclimitpass" default-mask:"-" description:"Password for limited RPC connections"`
+	RPCMiningUser        string   `long:"rpcmininguser" description:"Username for mining-only RPC connections"`
+	RPCMiningPass        string   `long:"rpcminingpass" default-mask:"-" description:"Password for mining-only RPC connections"`
 	RPCCert              string   `long:"rpccert" description:"File containing the certificate file"`
 	RPCKey               string   `long:"rpckey" description:"File containing the certificate key"`
 	TLSCurve             string   `long:"tlscurve" description:"Curve to use when generating TLS keypairs"`
@@ -181,9 +188,14 @@ type config struct {
 	Whitelists     []string      `long:"whitelist" description:"Add an IP network or IP that will not be banned (eg. 192.168.1.0/24 or ::1)"`
 
 	// Chain related options.
-	AllowOldForks  bool   `long:"allowoldforks" description:"Process forks deep in history.  Don't do this unless you know what you're doing"`
-	DumpBlockchain string `long:"dumpblockchain" description:"Write blockchain as a flat file of blocks for use with addblock, to the specified filename"`
-	AssumeValid    string `long:"assumevalid" description:"Hash of an assumed valid block.  Defaults to the hard-coded assumed valid block that is updated periodically with new releases.  Don't use a different hash unless you understand the implications.  Set to 0 to disable"`
+	AllowOldForks     bool   `long:"allowoldforks" description:"Process forks deep in history.  Don't do this unless you know what you're doing"`
+	DumpBlockchain    string `long:"dumpblockchain" description:"Write blockchain as a flat file of blocks for use with addblock, to the specified filename"`
+	AssumeValid       string `long:"assumevalid" description:"Hash of an assumed valid block.  Defaults to the hard-coded assumed valid block that is updated periodically with new releases.  Don't use a different hash unless you understand the implications.  Set to 0 to disable"`
+	FullVerifyDAG     bool   `long:"fullverifydag" description:"Verify the proof of work of blocks against the full KawPoW DAG for their epoch instead of the much cheaper light verification cache"`
+	MaxEpochLookahead uint32 `long:"maxepochlookahead" description:"Maximum number of KawPoW epochs beyond the epoch of the best known header that received block headers may claim.  Headers claiming later epochs are rejected before any proof of work verification to avoid generating caches and DAGs for far future epochs"`
+	TrimBlockIndex    bool   `long:"trimblockindex" description:"Reduce memory usage by periodically removing old side chain blocks that are below the fork rejection checkpoint and too deep to be reorganized to from the in-memory block index"`
+	DAGDir            string `long:"dagdir" description:"Directory to store the cached KawPoW DAG files (default: dag directory within the network data directory)"`
+	MaxDAGDiskBytes   uint64 `long:"maxdagdiskbytes" description:"Maximum number of bytes the cached KawPoW DAG files may use on disk.  The DAG files for the oldest epochs are pruned as needed to stay under the limit.  Set to 0 for no limit"`
 
 	// Relay and mempool policy.
 	MinRelayTxFee    float64 `long:"minrelaytxfee" description:"The minimum transaction fee in DCR/kB to be considered a non-zero fee"`
@@ -202,6 +214,7 @@ type config struct {
 	BlockMaxSize        uint32   `long:"blockmaxsize" description:"Maximum block size in bytes to be used when creating a block"`
 	BlockPrioritySize   uint32   `long:"blockprioritysize" description:"DEPRECATED: This behavior is no longer available and this option will be removed in a future version of the software"`
 	MiningTimeOffset    int      `long:"miningtimeoffset" description:"Offset the mining timestamp of a block by this many seconds (positive values are in the past)"`
+	CoinbaseExtra       string   `long:"coinbaseextra" description:"Extra data, such as a pool identifier, to include in the coinbase of generated blocks (max 92 bytes)"`
 	NonAggressive       bool     `long:"nonaggressive" description:"Disable mining off of the parent block of the blockchain if there aren't enough voters"`
 	NoMiningStateSync   bool     `long:"nominingstatesync" description:"Disable synchronizing the mining state with other nodes"`
 	AllowUnsyncedMining bool     `long:"allowunsyncedmining" description:"Allow block templates to be generated even when the chain is not considered synced on networks other than the main network.  This is automatically enabled when the simnet option is set.  Don't do this unless you know what you're doing"`
@@ -616,6 +629,9 @@ func loadConfig(appName string) (*config, []string, error) {
 		RPCMaxWebsockets:     defaultMaxRPCWebsockets,
 		RPCMaxConcurrentReqs: defaultMaxRPCConcurrentReqs,
 
+		// Chain related options.
+		MaxEpochLookahead: defaultMaxEpochLookahead,
+
 		// P2P network options.
 		MaxSameIP:       defaultMaxSameIP,
 		MaxPeers:        defaultMaxPeers,
@@ -813,6 +829,14 @@ func loadConfig(appName string) (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Ensure the parameters for the active network are sane so misconfigured
+	// networks are detected prior to starting any subsystems.
+	if err := cfg.params.Validate(); err != nil {
+		str := "%s: invalid parameters for network %s: %v"
+		err := fmt.Errorf(str, funcName, cfg.params.Name, err)
+		return nil, nil, err
+	}
+
 	// Warn on use of deprecated option to modify the rate limit of low-fee/free
 	// transaction rate limiting.
 	if cfg.FreeTxRelayLimit != 0 {
@@ -861,6 +885,14 @@ func loadConfig(appName string) (*config, []string, error) {
 	oldTestNets = append(oldTestNets, filepath.Join(cfg.DataDir, "testnet"))
 	oldTestNets = append(oldTestNets, filepath.Join(cfg.DataDir, "testnet2"))
 	cfg.DataDir = filepath.Join(cfg.DataDir, cfg.params.Name)
+
+	// Default to storing the DAG files in the network data directory when a
+	// DAG directory is not specified.
+	if cfg.DAGDir == "" {
+		cfg.DAGDir = filepath.Join(cfg.DataDir, defaultDAGDirname)
+	} else {
+		cfg.DAGDir = cleanAndExpandPath(cfg.DAGDir)
+	}
 	logRotator = nil
 	if !cfg.NoFileLogging {
 		// Append the network type to the log directory so it is "namespaced"
@@ -1038,11 +1070,31 @@ func loadConfig(appName string) (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Check to make sure mining and admin users don't have the same username
+	// or password.
+	if cfg.RPCMiningUser != "" && (cfg.RPCMiningUser == cfg.RPCUser ||
+		cfg.RPCMiningUser == cfg.RPCLimitUser) {
+
+		str := "%s: --rpcmininguser must not specify the same username " +
+			"as --rpcuser or --rpclimituser"
+		err := fmt.Errorf(str, funcName)
+		return nil, nil, err
+	}
+	if cfg.RPCMiningPass != "" && (cfg.RPCMiningPass == cfg.RPCPass ||
+		cfg.RPCMiningPass == cfg.RPCLimitPass) {
+
+		str := "%s: --rpcminingpass must not specify the same password " +
+			"as --rpcpass or --rpclimitpass"
+		err := fmt.Errorf(str, funcName)
+		return nil, nil, err
+	}
+
 	// The RPC server is disabled if no username or password is provided
 	// under basic user/pass authentication.
 	if cfg.RPCAuthType == authTypeBasic &&
 		(cfg.RPCUser == "" || cfg.RPCPass == "") &&
-		(cfg.RPCLimitUser == "" || cfg.RPCLimitPass == "") {
+		(cfg.RPCLimitUser == "" || cfg.RPCLimitPass == "") &&
+		(cfg.RPCMiningUser == "" || cfg.RPCMiningPass == "") {
 		cfg.DisableRPC = true
 	}
 
@@ -1051,7 +1103,8 @@ func loadConfig(appName string) (*config, []string, error) {
 	if cfg.RPCAuthType == authTypeClientCert {
 		switch {
 		case cfg.RPCUser != "", cfg.RPCPass != "",
-			cfg.RPCLimitUser != "", cfg.RPCLimitPass != "":
+			cfg.RPCLimitUser != "", cfg.RPCLimitPass != "",
+			cfg.RPCMiningUser != "", cfg.RPCMiningPass != "":
 			str := "%s: RPC usernames and passwords are not allowed " +
 				"with --authtype=clientcert"
 			err := fmt.Errorf(str, funcName)
@@ -1116,6 +1169,16 @@ func loadConfig(appName string) (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Ensure the extra coinbase data fits in the space available in the
+	// coinbase signature script.
+	if len(cfg.CoinbaseExtra) > mining.MaxCoinbaseExtraLen {
+		str := "%s: the coinbaseextra option may not be more than %d " +
+			"bytes -- parsed %d bytes"
+		err := fmt.Errorf(str, funcName, mining.MaxCoinbaseExtraLen,
+			len(cfg.CoinbaseExtra))
+		return nil, nil, err
+	}
+
 	// Limit the max orphan count to a sane value.
 	if cfg.MaxOrphanTxs < 0 {
 		str := "%s: the maxorphantx option may not be less than 0 " +
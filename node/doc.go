@@ -66,6 +66,8 @@ Application Options:
 	                             authtype=clientcert
 	    --rpclimituser=          Username for limited RPC connections
 	    --rpclimitpass=          Password for limited RPC connections
+	    --rpcmininguser=         Username for mining-only RPC connections
+	    --rpcminingpass=         Password for mining-only RPC connections
 	    --rpccert=               File containing the certificate file
 	    --rpckey=                File containing the certificate key
 	    --tlscurve=              Curve to use when generating the TLS keypair
@@ -137,6 +139,26 @@ Application Options:
 	                             periodically with new releases. Don't use a
 	                             different hash unless you understand the
 	                             implications. Set to 0 to disable
+	    --fullverifydag          Verify the proof of work of blocks against the
+	                             full KawPoW DAG for their epoch instead of the
+	                             much cheaper light verification cache
+	    --maxepochlookahead=     Maximum number of KawPoW epochs beyond the epoch
+	                             of the best known header that received block
+	                             headers may claim.  Headers claiming later
+	                             epochs are rejected before any proof of work
+	                             verification to avoid generating caches and
+	                             DAGs for far future epochs (default: 2)
+	    --trimblockindex        Reduce memory usage by periodically removing old
+	                             side chain blocks that are below the fork
+	                             rejection checkpoint and too deep to be
+	                             reorganized to from the in-memory block index
+	    --dagdir=                Directory to store the cached KawPoW DAG files
+	                             (default: dag directory within the network data
+	                             directory)
+	    --maxdagdiskbytes=       Maximum number of bytes the cached KawPoW DAG
+	                             files may use on disk.  The DAG files for the
+	                             oldest epochs are pruned as needed to stay under
+	                             the limit.  Set to 0 for no limit
 	    --minrelaytxfee=         The minimum transaction fee in DCR/kB to be
 	                             considered a non-zero fee (default: 0.0001)
 	    --limitfreerelay=        DEPRECATED: This behavior is no longer available
@@ -170,6 +192,9 @@ Application Options:
 	                             version of the software
 	    --miningtimeoffset=      Offset the mining timestamp of a block by this
 	                             many seconds (positive values are in the past)
+	    --coinbaseextra=         Extra data, such as a pool identifier, to
+	                             include in the coinbase of generated blocks
+	                             (max 92 bytes)
 	    --nonaggressive          Disable mining off of the parent block of the
 	                             blockchain if there aren't enough voters
 	    --nominingstatesync      Disable synchronizing the mining state with
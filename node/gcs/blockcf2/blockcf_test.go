@@ -0,0 +1,107 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockcf2
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+)
+
+// mockPrevScripter implements the PrevScripter interface for tests with a
+// map of previous outputs to their scripts.
+type mockPrevScripter map[wire.OutPoint][]byte
+
+// PrevScript returns the script of the provided previous output when it
+// exists.  The script version is always 0.
+//
+// This is part of the PrevScripter interface.
+func (m mockPrevScripter) PrevScript(prevOut *wire.OutPoint) (uint16, []byte, bool) {
+	script, ok := m[*prevOut]
+	return 0, script, ok
+}
+
+// TestRegularFilterMatches ensures a regular filter built from a block matches
+// all of the output scripts in the block as well as the previous output scripts
+// it spends and rejects unrelated scripts with a low false positive rate.
+func TestRegularFilterMatches(t *testing.T) {
+	rng := rand.New(rand.NewSource(20250101))
+	randScript := func() []byte {
+		script := make([]byte, 25)
+		rng.Read(script)
+		return script
+	}
+
+	// Create a block with a coinbase and a transaction that spends a previous
+	// output where every output pays to a distinct script.
+	var block wire.MsgBlock
+	block.Header.MerkleRoot = chainhash.HashH([]byte("merkle root"))
+	var wantScripts [][]byte
+	coinbase := wire.NewMsgTx()
+	coinbase.AddTxIn(&wire.TxIn{})
+	for i := 0; i < 3; i++ {
+		script := randScript()
+		coinbase.AddTxOut(wire.NewTxOut(1, script))
+		wantScripts = append(wantScripts, script)
+	}
+	block.AddTransaction(coinbase)
+
+	prevOut := wire.OutPoint{Hash: chainhash.HashH([]byte("prev tx"))}
+	prevScript := randScript()
+	prevScripts := mockPrevScripter{prevOut: prevScript}
+	wantScripts = append(wantScripts, prevScript)
+	spendTx := wire.NewMsgTx()
+	spendTx.AddTxIn(wire.NewTxIn(&prevOut, 1, nil))
+	for i := 0; i < 2; i++ {
+		script := randScript()
+		spendTx.AddTxOut(wire.NewTxOut(1, script))
+		wantScripts = append(wantScripts, script)
+	}
+	block.AddTransaction(spendTx)
+
+	filter, err := Regular(&block, prevScripts)
+	if err != nil {
+		t.Fatalf("unexpected error building filter: %v", err)
+	}
+	if filter.N() != uint32(len(wantScripts)) {
+		t.Fatalf("unexpected number of filter entries -- got %d, want %d",
+			filter.N(), len(wantScripts))
+	}
+
+	// Ensure the filter matches all of the scripts in the block.
+	key := Key(&block.Header.MerkleRoot)
+	for i, script := range wantScripts {
+		if !filter.Match(key, script) {
+			t.Fatalf("filter does not match script #%d %x", i, script)
+		}
+	}
+	if !filter.MatchAny(key, wantScripts) {
+		t.Fatal("filter does not match any of the scripts in the block")
+	}
+
+	// Ensure unrelated scripts are rejected with a low false positive rate.
+	const numUnrelated = 10000
+	const maxFalsePositiveRate = 0.001
+	var falsePositives int
+	for i := 0; i < numUnrelated; i++ {
+		if filter.Match(key, randScript()) {
+			falsePositives++
+		}
+	}
+	fpRate := float64(falsePositives) / numUnrelated
+	if fpRate > maxFalsePositiveRate {
+		t.Fatalf("false positive rate of %v exceeds max of %v", fpRate,
+			maxFalsePositiveRate)
+	}
+
+	// Ensure building the filter fails when a previous output script is not
+	// available.
+	_, err = Regular(&block, mockPrevScripter{})
+	if _, ok := err.(PrevScriptError); !ok {
+		t.Fatalf("did not receive expected PrevScriptError -- got %v", err)
+	}
+}
@@ -8,8 +8,6 @@ require (
 	github.com/decred/dcrd/addrmgr/v3 v3.0.0-20250614073006-47d690d84e5b
 	github.com/decred/dcrd/bech32 v1.1.4
 	github.com/decred/dcrd/blockchain/stake/v5 v5.0.1
-	github.com/decred/dcrd/blockchain/standalone v1.1.1
-	github.com/decred/dcrd/blockchain/standalone/v2 v2.2.1
 	github.com/decred/dcrd/blockchain/v5 v5.0.1
 	github.com/decred/dcrd/certgen v1.2.0
 	github.com/decred/dcrd/chaincfg/chainhash v1.0.4
@@ -26,7 +24,6 @@ require (
 	github.com/decred/dcrd/dcrjson/v4 v4.1.0
 	github.com/decred/dcrd/dcrutil/v4 v4.0.2
 	github.com/decred/dcrd/gcs/v4 v4.1.0
-	github.com/decred/dcrd/internal/kawpow v0.0.0-00010101000000-000000000000
 	github.com/decred/dcrd/math/uint256 v1.0.2
 	github.com/decred/dcrd/mixing v0.5.0
 	github.com/decred/dcrd/peer/v3 v3.1.3
@@ -47,6 +44,8 @@ require (
 	golang.org/x/net v0.41.0
 	golang.org/x/sys v0.33.0
 	golang.org/x/term v0.32.0
+	vigil.network/node/blockchain/standalone v0.0.0
+	vigil.network/node/blockchain/standalone/kawpow v0.0.0
 )
 
 require (
@@ -63,6 +62,12 @@ require (
 	golang.org/x/sync v0.15.0 // indirect
 	golang.org/x/text v0.26.0 // indirect
 	lukechampine.com/blake3 v1.3.0 // indirect
+	vigil.network/node/chaincfg/chainhash v0.0.0 // indirect
 )
 
-replace github.com/decred/dcrd/internal/kawpow => ./blockchain/standalone/kawpow
+replace (
+	github.com/decred/dcrd/wire => ./wire
+	vigil.network/node/blockchain/standalone => ./blockchain/standalone
+	vigil.network/node/blockchain/standalone/kawpow => ./blockchain/standalone/kawpow
+	vigil.network/node/chaincfg/chainhash => ./chaincfg/chainhash
+)
@@ -6,6 +6,12 @@ package blockchain
 
 import (
 	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/wire"
+	"vigil.network/node/blockchain/standalone"
+	"vigil.network/node/blockchain/standalone/kawpow"
 )
 
 // BenchmarkAncestor benchmarks ancestor traversal for various numbers of nodes.
@@ -30,3 +36,108 @@ func BenchmarkAncestor(b *testing.B) {
 		branchTip(branch2Nodes).Ancestor(0)
 	}
 }
+
+// BenchmarkCalcNextRequiredDifficulty benchmarks calculating the required
+// difficulty for the block after a chain tip both when the active proof of
+// work algorithm is cached in the tip block node and when it must be
+// determined from the threshold state each time.
+func BenchmarkCalcNextRequiredDifficulty(b *testing.B) {
+	// Construct a synthetic block chain that spans several rule change
+	// activation intervals past stake validation height so that determining
+	// the state of the agenda involves traversing multiple intervals.
+	params := chaincfg.MainNetParams()
+	chain := newFakeChain(params)
+	node := chain.bestChain.Tip()
+	blockTime := time.Unix(node.timestamp, 0)
+	numNodes := params.StakeValidationHeight +
+		4*int64(params.RuleChangeActivationInterval)
+	for i := int64(0); i < numNodes; i++ {
+		blockTime = blockTime.Add(params.TargetTimePerBlock)
+		node = newFakeNode(node, 1, 1, params.PowLimitBits, blockTime)
+		chain.index.AddNode(node)
+	}
+	chain.bestChain.SetTip(node)
+	tip := chain.bestChain.Tip()
+	nextBlockTime := blockTime.Add(params.TargetTimePerBlock)
+
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, err := chain.calcNextRequiredDifficulty(tip, nextBlockTime)
+			if err != nil {
+				b.Fatalf("unexpected error: %v", err)
+			}
+		}
+	})
+
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			tip.nextPowAlgo = powAlgoUnknown
+			_, err := chain.calcNextRequiredDifficulty(tip, nextBlockTime)
+			if err != nil {
+				b.Fatalf("unexpected error: %v", err)
+			}
+		}
+	})
+}
+
+// BenchmarkCheckBlockProofOfWork benchmarks the proof of work check performed
+// while processing blocks both when the proof of work must be verified and when
+// it is skipped due to the block having recently passed proof of work
+// verification.
+func BenchmarkCheckBlockProofOfWork(b *testing.B) {
+	params := chaincfg.RegNetParams()
+	chain := &BlockChain{
+		chainParams: params,
+		kawPow:      kawpow.NewLightWithParams(kawPowParams(params)),
+	}
+
+	// Solve a header with an easy target difficulty so the proof of work
+	// check succeeds when it is performed.
+	header := wire.BlockHeader{
+		Version:   1,
+		PrevBlock: params.GenesisHash,
+		Bits:      params.PowLimitBits,
+		Height:    1,
+		Timestamp: params.GenesisBlock.Header.Timestamp.Add(time.Minute),
+	}
+	target := standalone.CompactToBig(header.Bits)
+	nonce, mixDigest, found := chain.kawPow.Search(header.BytesNoNonce(),
+		target, 0, nil)
+	if !found {
+		b.Fatal("failed to find a nonce for an easy target difficulty")
+	}
+	header.Nonce = nonce
+	header.MixDigest = mixDigest
+	blockHash := header.BlockHash()
+
+	recentPowChecks := newRecentPowChecksCache()
+	recentPowChecks.Put(blockHash)
+
+	benches := []struct {
+		name   string
+		cached bool
+	}{
+		{name: "verified", cached: false},
+		{name: "cached", cached: true},
+	}
+	for _, bench := range benches {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				flags := BFNone
+				if bench.cached && recentPowChecks.Contains(blockHash) {
+					flags |= BFNoPoWCheck
+				}
+				err := chain.checkBlockProofOfWork(&header, flags)
+				if err != nil {
+					b.Fatalf("unexpected error: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"sort"
 	"sync"
 	"time"
+	"unsafe"
 
 	"github.com/decred/dcrd/blockchain/stake/v5"
 	"github.com/decred/dcrd/chaincfg/chainhash"
@@ -56,6 +57,16 @@ const (
 	// target block time for the main network such that there is approximately
 	// one hour of chain tips cached.
 	cachedTipsPruneDepth = 12
+
+	// sideChainTrimInterval is the amount of time to wait in between trimming
+	// old side chain nodes from the block index.
+	sideChainTrimInterval = time.Minute * 5
+
+	// approxNodeBaseSize is the approximate number of bytes used by a block
+	// node in the block index excluding any dynamically-allocated data.  It
+	// includes an estimate of the overhead of the index map entry that refers
+	// to the node.
+	approxNodeBaseSize = uint64(unsafe.Sizeof(blockNode{})) + 16
 )
 
 // HaveData returns whether the full block data is stored in the database.  This
@@ -149,6 +160,7 @@ type blockNode struct {
 	stakeRoot    chainhash.Hash
 	blockSize    uint32
 	nonce        uint64 // Changed from uint32 to uint64 for KawPoW
+	mixDigest    [32]byte
 	extraData    [32]byte
 	stakeVersion uint32
 
@@ -189,6 +201,13 @@ type blockNode struct {
 	//
 	// It is protected by the block index mutex.
 	receivedOrderID uint32
+
+	// nextPowAlgo caches the proof of work algorithm that is active for the
+	// block AFTER this node once it has been determined so that repeated
+	// lookups do not need to consult the threshold state.
+	//
+	// It is protected by the chain lock and is only stored in memory.
+	nextPowAlgo powAlgorithm
 }
 
 // clearLowestOneBit clears the lowest set bit in the passed value.
@@ -248,7 +267,8 @@ func initBlockNode(node *blockNode, blockHeader *wire.BlockHeader, parent *block
 		stakeRoot:    blockHeader.StakeRoot,
 		revocations:  blockHeader.Revocations,
 		blockSize:    blockHeader.Size,
-		nonce:        uint64(blockHeader.Nonce), // Convert from uint32 to uint64
+		nonce:        blockHeader.Nonce,
+		mixDigest:    blockHeader.MixDigest,
 		extraData:    blockHeader.ExtraData,
 		stakeVersion: blockHeader.StakeVersion,
 		status:       statusNone,
@@ -274,7 +294,10 @@ func newBlockNode(blockHeader *wire.BlockHeader, parent *blockNode) *blockNode {
 // This function is safe for concurrent access.
 func (node *blockNode) Header() wire.BlockHeader {
 	// No lock is needed because all accessed fields are immutable.
-	prevHash := node.parent.hash
+	prevHash := zeroHash
+	if node.parent != nil {
+		prevHash = node.parent.hash
+	}
 	return wire.BlockHeader{
 		Version:      node.blockVersion,
 		PrevBlock:    prevHash,
@@ -291,7 +314,8 @@ func (node *blockNode) Header() wire.BlockHeader {
 		Height:       uint32(node.height),
 		Size:         node.blockSize,
 		Timestamp:    time.Unix(node.timestamp, 0),
-		Nonce:        uint32(node.nonce), // Convert back to uint32 for header
+		Nonce:        node.nonce,
+		MixDigest:    node.mixDigest,
 		ExtraData:    node.extraData,
 		StakeVersion: node.stakeVersion,
 	}
@@ -559,6 +583,10 @@ type blockIndex struct {
 	// bestInvalid tracks the highest work block node that was found to be
 	// invalid.
 	//
+	// lastValidateFailed is the time a block was most recently marked as
+	// having failed validation.  It is the zero time when no blocks have
+	// failed validation since the index was created.
+	//
 	// bestChainCandidates tracks a set of block nodes in the block index that
 	// are potential candidates to become the best chain.
 	//
@@ -575,6 +603,7 @@ type blockIndex struct {
 	// header first.
 	bestHeader          *blockNode
 	bestInvalid         *blockNode
+	lastValidateFailed  time.Time
 	bestChainCandidates map[*blockNode]struct{}
 	unlinkedChildrenOf  map[*blockNode][]*blockNode
 	nextReceivedOrderID uint32
@@ -593,6 +622,10 @@ type blockIndex struct {
 	cachedTips           map[chainhash.Hash]*blockNode
 	cachedTipsStart      int64
 	cachedTipsLastPruned time.Time
+
+	// sideChainsLastTrimmed is the last time old side chain nodes were trimmed
+	// from the index.  It is protected by the embedded mutex.
+	sideChainsLastTrimmed time.Time
 }
 
 // newBlockIndex returns a new empty instance of a block index.  The index will
@@ -1052,6 +1085,158 @@ func (bi *blockIndex) MaybePruneCachedTips(bestNode *blockNode) {
 	bi.Unlock()
 }
 
+// approxSize returns the approximate number of bytes of memory used by the
+// block node including its dynamically-allocated ticket and vote data.  Note
+// that it does not include the associated stake node, if any, since those are
+// pruned separately.
+func (node *blockNode) approxSize() uint64 {
+	const hashSize = uint64(unsafe.Sizeof(chainhash.Hash{}))
+	const voteSize = uint64(unsafe.Sizeof(stake.VoteVersionTuple{}))
+	numHashes := uint64(len(node.newTickets) + len(node.ticketsVoted) +
+		len(node.ticketsRevoked))
+	return approxNodeBaseSize + numHashes*hashSize +
+		uint64(len(node.votes))*voteSize
+}
+
+// BlockIndexStats houses statistics about the approximate memory usage of the
+// block index.
+type BlockIndexStats struct {
+	// Nodes is the total number of block nodes in the index.
+	Nodes uint64
+
+	// EstimatedBytes is the approximate number of bytes of memory used by the
+	// block nodes in the index.
+	EstimatedBytes uint64
+}
+
+// Stats returns statistics about the approximate memory usage of the block
+// index.
+//
+// This function is safe for concurrent access.
+func (bi *blockIndex) Stats() BlockIndexStats {
+	var stats BlockIndexStats
+	bi.RLock()
+	for _, node := range bi.index {
+		// Nil entries signal the node is in the collisions map instead.
+		if node == nil {
+			continue
+		}
+		stats.Nodes++
+		stats.EstimatedBytes += node.approxSize()
+	}
+	for _, node := range bi.collisions {
+		stats.Nodes++
+		stats.EstimatedBytes += node.approxSize()
+	}
+	bi.RUnlock()
+	return stats
+}
+
+// removeNode removes the provided node from the block index along with any
+// other references the index holds to it.  It does not update the chain tips
+// of the parent, so it is up to the caller to handle that as needed.
+//
+// This function MUST be called with the block index lock held (for writes).
+func (bi *blockIndex) removeNode(node *blockNode) {
+	blockKey := shortBlockKey(&node.hash)
+	if bi.index[blockKey] == node {
+		delete(bi.index, blockKey)
+	} else if bi.collisions[node.hash] == node {
+		delete(bi.collisions, node.hash)
+	}
+	bi.removeChainTip(node)
+	delete(bi.modified, node)
+	delete(bi.bestChainCandidates, node)
+	delete(bi.unlinkedChildrenOf, node)
+}
+
+// trimSideChains removes all block nodes that are not part of the main chain
+// ending with the provided tip from the index when they are on a side chain
+// with a tip below the provided height.  Nodes that are shared with other side
+// chains that are not eligible for trimming as well as the best known header
+// and best known invalid block are retained.  It returns the number of nodes
+// that were removed.
+//
+// Note that the removed nodes are only removed from memory and will be loaded
+// again from the database the next time the block index is loaded.
+//
+// This function MUST be called with the block index lock held (for writes).
+func (bi *blockIndex) trimSideChains(mainTip *blockNode, belowHeight int64) uint64 {
+	isMainChain := func(node *blockNode) bool {
+		return node.height <= mainTip.height &&
+			mainTip.Ancestor(node.height) == node
+	}
+
+	// Determine the number of side chain children for every node on a side
+	// chain so that nodes shared by multiple side chains are only removed once
+	// none of the side chains that build on them remain.  Also collect the tips
+	// that are eligible for trimming.
+	numChildren := make(map[*blockNode]int)
+	var eligibleTips []*blockNode
+	bi.forEachChainTip(func(tip *blockNode) error {
+		if isMainChain(tip) {
+			return nil
+		}
+		if tip.height < belowHeight {
+			eligibleTips = append(eligibleTips, tip)
+		}
+		for n := tip; n.parent != nil && !isMainChain(n.parent); n = n.parent {
+			count, ok := numChildren[n.parent]
+			numChildren[n.parent] = count + 1
+			if ok {
+				break
+			}
+		}
+		return nil
+	})
+
+	// Remove the nodes of each eligible side chain starting from its tip until
+	// reaching the main chain, a node that other side chains still build on,
+	// or a node that must be retained.
+	var numRemoved uint64
+	for _, tip := range eligibleTips {
+		n := tip
+		for n != nil && !isMainChain(n) && numChildren[n] == 0 {
+			if n == bi.bestHeader || n == bi.bestInvalid {
+				// The node is now a tip since all of its children were
+				// removed.
+				if n != tip {
+					bi.addChainTip(n)
+				}
+				break
+			}
+
+			bi.removeNode(n)
+			delete(numChildren, n)
+			numRemoved++
+			n = n.parent
+			if n != nil {
+				numChildren[n]--
+			}
+		}
+	}
+
+	return numRemoved
+}
+
+// MaybeTrimSideChains periodically removes block nodes that are on side chains
+// with a tip below the provided height from the index.  See trimSideChains
+// for more details.
+//
+// This function is safe for concurrent access.
+func (bi *blockIndex) MaybeTrimSideChains(mainTip *blockNode, belowHeight int64) {
+	bi.Lock()
+	if time.Since(bi.sideChainsLastTrimmed) >= sideChainTrimInterval {
+		numRemoved := bi.trimSideChains(mainTip, belowHeight)
+		if numRemoved > 0 {
+			log.Debugf("Trimmed %d side chain nodes below height %d from the "+
+				"block index", numRemoved, belowHeight)
+		}
+		bi.sideChainsLastTrimmed = time.Now()
+	}
+	bi.Unlock()
+}
+
 // removeBestChainCandidate removes the passed block node from the potential
 // candidates for becoming the tip of the best chain.
 //
@@ -1105,6 +1290,18 @@ func (bi *blockIndex) BestHeader() *blockNode {
 	return bestHeader
 }
 
+// LastValidateFailedTime returns the time a block was most recently marked as
+// having failed validation.  The zero time is returned when no blocks have
+// failed validation since the index was created.
+//
+// This function is safe for concurrent access.
+func (bi *blockIndex) LastValidateFailedTime() time.Time {
+	bi.RLock()
+	lastValidateFailed := bi.lastValidateFailed
+	bi.RUnlock()
+	return lastValidateFailed
+}
+
 // MarkBlockFailedValidation marks the passed node as having failed validation
 // and then marks all of its descendants (if any) as having a failed ancestor.
 //
@@ -1115,6 +1312,7 @@ func (bi *blockIndex) MarkBlockFailedValidation(node *blockNode) {
 	bi.unsetStatusFlags(node, statusValidated)
 	bi.removeBestChainCandidate(node)
 	bi.maybeUpdateBestInvalid(node)
+	bi.lastValidateFailed = time.Now()
 	delete(bi.unlinkedChildrenOf, node)
 
 	// Mark all descendants of the failed block as having a failed ancestor.
@@ -17,7 +17,9 @@ import (
 )
 
 // TestBlockNodeHeader ensures that block nodes reconstruct the correct header
-// and fetching the header from the chain reconstructs it from memory.
+// and fetching the header from the chain reconstructs it from memory.  The
+// nonce intentionally does not fit in 32 bits to ensure the full KawPoW nonce
+// and mix digest survive the round trip.
 func TestBlockNodeHeader(t *testing.T) {
 	// Create a fake chain and block header with all fields set to nondefault
 	// values.
@@ -40,7 +42,8 @@ func TestBlockNodeHeader(t *testing.T) {
 		Height:       1,
 		Size:         393216,
 		Timestamp:    time.Unix(1454954400, 0),
-		Nonce:        7,
+		Nonce:        0x0102030405060708,
+		MixDigest:    [32]byte{0xcc},
 		ExtraData:    [32]byte{0xbb},
 		StakeVersion: 5,
 	}
@@ -68,9 +71,54 @@ func TestBlockNodeHeader(t *testing.T) {
 	}
 }
 
-// TestCalcPastMedianTime ensures the CalcPastMedianTie function works as
-// intended including when there are less than the typical number of blocks
-// which happens near the beginning of the chain.
+// TestNewBlockNodeFields ensures block nodes created from a header expose the
+// exact values from the header that the difficulty and stake difficulty
+// calculations read directly from the node.
+func TestNewBlockNodeFields(t *testing.T) {
+	params := chaincfg.RegNetParams()
+	bc := newFakeChain(params)
+	tip := bc.bestChain.Tip()
+	header := wire.BlockHeader{
+		Version:     1,
+		PrevBlock:   tip.hash,
+		Voters:      3,
+		FreshStake:  7,
+		Revocations: 2,
+		PoolSize:    40960,
+		Bits:        0x1b01ffff,
+		SBits:       987654321,
+		Height:      uint32(tip.height + 1),
+		Timestamp:   time.Unix(1454954400, 0),
+	}
+	node := newBlockNode(&header, tip)
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"bits", node.bits, header.Bits},
+		{"sbits", node.sbits, header.SBits},
+		{"pool size", node.poolSize, header.PoolSize},
+		{"fresh stake", node.freshStake, header.FreshStake},
+		{"voters", node.voters, header.Voters},
+		{"revocations", node.revocations, header.Revocations},
+		{"height", node.height, int64(header.Height)},
+		{"timestamp", node.timestamp, header.Timestamp.Unix()},
+		{"parent", node.parent, tip},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s: mismatched value -- got %v, want %v", test.name,
+				test.got, test.want)
+		}
+	}
+}
+
+// TestCalcPastMedianTime ensures the CalcPastMedianTime function and the
+// exported MedianTimeByHash function that makes use of it work as intended
+// including when there are less than the typical number of blocks which happens
+// near the beginning of the chain.
 func TestCalcPastMedianTime(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -166,6 +214,18 @@ func TestCalcPastMedianTime(t *testing.T) {
 				test.name, gotTime, wantTime)
 			continue
 		}
+
+		// Ensure the median time looked up by the block hash is the same.
+		gotTime, err := bc.MedianTimeByHash(&node.hash)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if !gotTime.Equal(wantTime) {
+			t.Errorf("%s: mismatched timestamps by hash -- got: %v, want: %v",
+				test.name, gotTime, wantTime)
+			continue
+		}
 	}
 }
 
@@ -758,3 +818,140 @@ func TestShortBlockKeyCollisions(t *testing.T) {
 	assertLookupResult(fullCollisions[0], fullCollisions[1])
 	assertLookupResult(fullCollisions[1], fullCollisions[1])
 }
+
+// TestTrimBlockIndex ensures trimming the block index removes side chain nodes
+// that are eligible for trimming while retaining the main chain nodes as well as
+// any side chain nodes that are either too recent or still needed by other
+// side chains.  It also ensures the reported block index stats reflect the
+// removals.
+func TestTrimBlockIndex(t *testing.T) {
+	params := chaincfg.RegNetParams()
+	bc := newFakeChain(params)
+	genesis := bc.bestChain.Genesis()
+
+	// Construct a synthetic chain consisting of the following structure where
+	// the main chain is long enough for the checkpoint at height 10 to be more
+	// than the max reorg depth behind the tip.
+	//
+	// 0 -> 1 -> ... -> 5  -> 6  -> ... -> 4042 -> ... -> 4052
+	//                   |\-> 6a -> 7a -> 8a           \-> 4043e -> 4044e
+	//                   |      \-> 7b -> 8b
+	//                   \-> 6c -> 7c -> ... -> 16c
+	//                           \-> 7d
+	mainChain := chainedFakeNodes(genesis, MaxReorgDepth+20)
+	branchA := chainedFakeNodes(mainChain[4], 3)
+	branchB := chainedFakeNodes(branchA[0], 2)
+	branchC := chainedFakeNodes(mainChain[4], 11)
+	branchD := chainedFakeNodes(branchC[0], 1)
+	branchE := chainedFakeNodes(mainChain[len(mainChain)-11], 2)
+	for _, branch := range [][]*blockNode{mainChain, branchA, branchB, branchC,
+		branchD, branchE} {
+
+		for _, node := range branch {
+			bc.index.AddNode(node)
+		}
+	}
+	tip := branchTip(mainChain)
+	bc.bestChain.SetTip(tip)
+
+	// Ensure nothing is eligible for trimming when the old fork rejection
+	// checkpoint is not known.
+	if height := bc.blockIndexTrimHeight(tip); height != 0 {
+		t.Fatalf("unexpected trim height without checkpoint -- got %d, want 0",
+			height)
+	}
+
+	// Ensure the trim height is the checkpoint when it is older than the max
+	// reorg depth.
+	bc.rejectForksCheckpoint = mainChain[9]
+	if height := bc.blockIndexTrimHeight(tip); height != 10 {
+		t.Fatalf("unexpected trim height -- got %d, want 10", height)
+	}
+
+	// Trim the index and ensure the stats reflect the removed nodes.
+	removed := append(append(append([]*blockNode(nil), branchA...), branchB...),
+		branchD...)
+	statsBefore := bc.BlockIndexStats()
+	bc.maybeTrimBlockIndex(tip)
+	statsAfter := bc.BlockIndexStats()
+	if statsBefore.Nodes-statsAfter.Nodes != uint64(len(removed)) {
+		t.Fatalf("unexpected number of trimmed nodes -- got %d, want %d",
+			statsBefore.Nodes-statsAfter.Nodes, len(removed))
+	}
+	var removedBytes uint64
+	for _, node := range removed {
+		removedBytes += node.approxSize()
+	}
+	if statsBefore.EstimatedBytes-statsAfter.EstimatedBytes != removedBytes {
+		t.Fatalf("unexpected number of trimmed bytes -- got %d, want %d",
+			statsBefore.EstimatedBytes-statsAfter.EstimatedBytes, removedBytes)
+	}
+
+	// Ensure the eligible side chain nodes were removed.
+	for _, node := range removed {
+		if bc.index.LookupNode(&node.hash) != nil {
+			t.Fatalf("side chain node %s (height %d) was not trimmed",
+				node.hash, node.height)
+		}
+	}
+
+	// Ensure all main chain nodes and all side chain nodes that are not
+	// eligible for trimming were retained.
+	for _, branch := range [][]*blockNode{mainChain, branchC, branchE} {
+		for _, node := range branch {
+			if bc.index.LookupNode(&node.hash) == nil {
+				t.Fatalf("node %s (height %d) was unexpectedly trimmed",
+					node.hash, node.height)
+			}
+		}
+	}
+
+	// Ensure the tips of the trimmed side chains are no longer chain tips.
+	expectedTips := map[*blockNode]struct{}{
+		tip:                {},
+		branchTip(branchC): {},
+		branchTip(branchE): {},
+	}
+	bc.index.RLock()
+	var numTips int
+	bc.index.forEachChainTip(func(chainTip *blockNode) error {
+		numTips++
+		if _, ok := expectedTips[chainTip]; !ok {
+			t.Errorf("unexpected chain tip %s (height %d)", chainTip.hash,
+				chainTip.height)
+		}
+		return nil
+	})
+	bc.index.RUnlock()
+	if numTips != len(expectedTips) {
+		t.Fatalf("unexpected number of chain tips -- got %d, want %d", numTips,
+			len(expectedTips))
+	}
+}
+
+// TestLastValidationFailureTime ensures the time a block was most recently
+// marked as having failed validation is tracked as expected.
+func TestLastValidationFailureTime(t *testing.T) {
+	params := chaincfg.RegNetParams()
+	bc := newFakeChain(params)
+	branch := chainedFakeNodes(bc.bestChain.Genesis(), 3)
+	for _, node := range branch {
+		bc.index.AddNode(node)
+	}
+	bc.bestChain.SetTip(branchTip(branch))
+
+	// Ensure the zero time is reported when no blocks have failed validation.
+	if failedTime := bc.LastValidationFailureTime(); !failedTime.IsZero() {
+		t.Fatalf("unexpected validation failure time -- got %v, want zero",
+			failedTime)
+	}
+
+	// Ensure marking a block as having failed validation updates the time.
+	before := time.Now()
+	bc.index.MarkBlockFailedValidation(branch[2])
+	failedTime := bc.LastValidationFailureTime()
+	if failedTime.Before(before) || failedTime.After(time.Now()) {
+		t.Fatalf("unexpected validation failure time -- got %v, want "+
+			"between %v and now", failedTime, before)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"math/bits"
 	"strings"
@@ -19,7 +20,6 @@ import (
 	"time"
 
 	"github.com/decred/dcrd/blockchain/stake/v5"
-	"github.com/decred/dcrd/blockchain/standalone/v2"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/dcrd/container/lru"
@@ -31,6 +31,8 @@ import (
 	"github.com/decred/dcrd/math/uint256"
 	"github.com/decred/dcrd/txscript/v4"
 	"github.com/decred/dcrd/wire"
+	"vigil.network/node/blockchain/standalone"
+	"vigil.network/node/blockchain/standalone/kawpow"
 )
 
 const (
@@ -50,6 +52,28 @@ const (
 	// contextCheckCacheSize is the number of recent successful contextual block
 	// check results to keep in memory.
 	contextCheckCacheSize = 25
+
+	// powCheckCacheSize is the number of recent block hashes that passed proof
+	// of work verification to keep in memory.  It is set large enough to cover
+	// the typical window between a header being accepted during the initial
+	// headers sync and its associated block data arriving.
+	powCheckCacheSize = 2048
+
+	// invalidBlockCacheSize is the number of recent blocks that are known to
+	// be invalid, along with the reason, to keep in memory.
+	invalidBlockCacheSize = 256
+
+	// MaxReorgDepth is the number of blocks before the current best chain tip
+	// for which side chain nodes are always retained in the block index when
+	// trimming is enabled so that reorganizations up to that depth remain
+	// possible without needing to reload nodes from the database.  This value
+	// is set based on the target block time for the main network such that
+	// there is approximately two weeks worth of blocks.
+	MaxReorgDepth = 4032
+
+	// defaultNetworkHashPSBlocks is the number of blocks used to estimate the
+	// network hashes per second when the caller does not specify it.
+	defaultNetworkHashPSBlocks = 120
 )
 
 // panicf is a convenience function that formats according to the given format
@@ -178,6 +202,8 @@ type BlockChain struct {
 	indexSubscriber          *indexers.IndexSubscriber
 	interrupt                <-chan struct{}
 	utxoCache                UtxoCacher
+	maxKawPowEpochLookahead  uint32
+	trimBlockIndex           bool
 
 	// subsidyCache is the cache that provides quick lookup of subsidy
 	// values.
@@ -218,8 +244,39 @@ type BlockChain struct {
 	// recentContextChecks tracks recent blocks that have successfully passed
 	// all contextual checks and is primarily used as an optimization to avoid
 	// running the checks again when possible.
+	//
+	// recentPowChecks tracks recent blocks whose headers have successfully
+	// passed proof of work verification and is used to avoid recalculating
+	// the comparatively expensive proof of work hash when the block data for
+	// an already verified header is processed.  The cache is keyed by the
+	// block hash, which commits to every field of the header, including the
+	// nonce and mix digest, so an entry can never vouch for a different
+	// header.
+	//
+	// recentInvalidBlocks tracks recent blocks that failed validation along
+	// with the associated rule error and is used to reject repeated
+	// submissions of the same invalid block without validating it again.  It
+	// is intentionally only kept in memory so that blocks are reconsidered
+	// after a restart, such as when upgrading to a version with different
+	// rules.
 	recentBlocks        *lru.Map[chainhash.Hash, *dcrutil.Block]
 	recentContextChecks *lru.Set[chainhash.Hash]
+	recentPowChecks     *lru.Set[chainhash.Hash]
+	recentInvalidBlocks *lru.Map[chainhash.Hash, error]
+
+	// kawPow is the light KawPoW hasher used to verify proof of work.  It is
+	// constructed from the KawPoW parameters of the network.
+	//
+	// kawPowFull is the full KawPoW hasher used to verify the proof of work of
+	// blocks instead when full DAG verification is enabled.  It is constructed
+	// from the same parameters and is nil when full DAG verification is not
+	// enabled.
+	kawPow     *kawpow.KawPow
+	kawPowFull *kawpow.KawPow
+
+	// dagManager manages the KawPoW DAG files cached on disk.  The full
+	// hasher persists the DAG for each epoch through it.
+	dagManager *kawpow.DAGManager
 
 	// These fields house a cached view that represents a block that votes
 	// against its parent and therefore contains all changes as a result
@@ -434,6 +491,66 @@ func (b *BlockChain) ChainWork(hash *chainhash.Hash) (uint256.Uint256, error) {
 	return node.workSum, nil
 }
 
+// NetworkHashPS returns the estimated network hashes per second based on the
+// work performed over the provided number of blocks in the main chain ending
+// at the provided height.  The estimate is the total work of every block in the
+// window divided by the time elapsed between the earliest and latest block
+// timestamps in the window.
+//
+// A negative number of blocks uses a default number of blocks and a negative
+// height uses the current best chain height.  The window is limited to start at
+// the genesis block, which only serves as the starting point for the elapsed
+// time since it does not represent any work towards the estimate.  An estimate
+// of zero is returned when there is no elapsed time within the window, such as
+// when the window only consists of the genesis block.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) NetworkHashPS(blocks int, height int64) (int64, error) {
+	endNode := b.bestChain.Tip()
+	if height >= 0 {
+		endNode = b.bestChain.NodeByHeight(height)
+		if endNode == nil {
+			return 0, errNotInMainChainByHeight(height)
+		}
+	}
+
+	numBlocks := int64(defaultNetworkHashPSBlocks)
+	if blocks >= 0 {
+		numBlocks = int64(blocks)
+	}
+	startHeight := endNode.height - numBlocks
+	if startHeight < 0 {
+		startHeight = 0
+	}
+	startNode := endNode.Ancestor(startHeight)
+
+	// Find the min and max block timestamps in the window since timestamps
+	// are not required to be monotonically increasing.
+	minTimestamp, maxTimestamp := startNode.timestamp, startNode.timestamp
+	for node := endNode; node != startNode; node = node.parent {
+		if node.timestamp < minTimestamp {
+			minTimestamp = node.timestamp
+		}
+		if node.timestamp > maxTimestamp {
+			maxTimestamp = node.timestamp
+		}
+	}
+	timeDiff := maxTimestamp - minTimestamp
+	if timeDiff == 0 {
+		return 0, nil
+	}
+
+	// The total work performed by the blocks after the start of the window is
+	// the difference between the cumulative work of the end and start blocks.
+	var hashesPerSec uint256.Uint256
+	hashesPerSec.Sub2(&endNode.workSum, &startNode.workSum)
+	hashesPerSec.DivUint64(uint64(timeDiff))
+	if !hashesPerSec.IsUint64() || hashesPerSec.Uint64() > math.MaxInt64 {
+		return math.MaxInt64, nil
+	}
+	return int64(hashesPerSec.Uint64()), nil
+}
+
 // TipGeneration returns the entire generation of blocks stemming from the
 // parent of the current tip.
 //
@@ -744,6 +861,9 @@ func (b *BlockChain) connectBlock(node *blockNode, block, parent *dcrutil.Block,
 	// This node is now the end of the best chain.
 	b.bestChain.SetTip(node)
 	b.index.MaybePruneCachedTips(node)
+	if b.trimBlockIndex {
+		b.maybeTrimBlockIndex(node)
+	}
 
 	// Update the state for the best block.  Notice how this replaces the
 	// entire struct instead of updating the existing one.  This effectively
@@ -767,6 +887,10 @@ func (b *BlockChain) connectBlock(node *blockNode, block, parent *dcrutil.Block,
 		log.Debugf("New target %08x (%064x)", node.bits, newDiff)
 	}
 
+	// Ensure the KawPoW verification caches needed for the next blocks are
+	// resident.
+	b.prepareKawPowCaches(node.height)
+
 	// Notify the caller that the block was connected to the main chain.
 	// The caller would typically want to react with actions such as
 	// updating wallets.
@@ -1216,7 +1340,7 @@ func (b *BlockChain) reorganizeChainInternal(target *blockNode) error {
 		} else {
 			// The block must pass all of the validation rules which depend on
 			// having the full block data for all of its ancestors available.
-			if err := b.checkBlockContext(block.MsgBlock(), n.parent); err != nil {
+			if err := b.checkBlockContext(block, n.parent, BFNone); err != nil {
 				var rerr RuleError
 				if errors.As(err, &rerr) {
 					b.index.MarkBlockFailedValidation(n)
@@ -1607,6 +1731,15 @@ func (b *BlockChain) IsCurrent() bool {
 	return isCurrent
 }
 
+// LastValidationFailureTime returns the time a block was most recently found
+// to have failed validation.  The zero time is returned when no blocks have
+// failed validation since the chain instance was created.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) LastValidationFailureTime() time.Time {
+	return b.index.LastValidateFailedTime()
+}
+
 // BestSnapshot returns information about the current best chain block and
 // related state as of the current point in time.  The returned instance must be
 // treated as immutable since it is shared by all callers.
@@ -1619,6 +1752,14 @@ func (b *BlockChain) BestSnapshot() *BestState {
 	return snapshot
 }
 
+// TotalTransactions returns the total number of transactions, including both
+// the regular and stake transactions, in all blocks of the current best chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) TotalTransactions() uint64 {
+	return b.BestSnapshot().TotalTxns
+}
+
 // maxBlockSize returns the maximum permitted block size for the block
 // AFTER the given node.
 //
@@ -1679,6 +1820,60 @@ func (b *BlockChain) HeaderByHash(hash *chainhash.Hash) (wire.BlockHeader, error
 	return node.Header(), nil
 }
 
+// TipHeader returns the full header of the block at the tip of the current
+// best chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) TipHeader() *wire.BlockHeader {
+	b.chainLock.RLock()
+	header := b.bestChain.Tip().Header()
+	b.chainLock.RUnlock()
+	return &header
+}
+
+// BlockIndexStats returns statistics about the number of nodes in the block
+// index and their approximate memory usage.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) BlockIndexStats() BlockIndexStats {
+	return b.index.Stats()
+}
+
+// blockIndexTrimHeight returns the height below which side chains are eligible
+// to be trimmed from the block index given the provided best chain tip.  Side
+// chains must be both below the old fork rejection checkpoint and more than
+// MaxReorgDepth blocks before the tip.  A height of zero, which means no side
+// chains are eligible, is returned when the checkpoint is not known.
+//
+// This function MUST be called with the chain lock held (for reads).
+func (b *BlockChain) blockIndexTrimHeight(tip *blockNode) int64 {
+	checkpoint := b.rejectForksCheckpoint
+	if checkpoint == nil {
+		return 0
+	}
+	trimHeight := tip.height - MaxReorgDepth
+	if checkpoint.height < trimHeight {
+		trimHeight = checkpoint.height
+	}
+	if trimHeight < 0 {
+		trimHeight = 0
+	}
+	return trimHeight
+}
+
+// maybeTrimBlockIndex periodically removes side chain nodes that are no longer
+// needed from the block index using the provided best chain tip as a reference
+// point.  See blockIndexTrimHeight for the details of which nodes are eligible.
+//
+// This function MUST be called with the chain lock held (for writes).
+func (b *BlockChain) maybeTrimBlockIndex(tip *blockNode) {
+	trimHeight := b.blockIndexTrimHeight(tip)
+	if trimHeight == 0 {
+		return
+	}
+	b.index.MaybeTrimSideChains(tip, trimHeight)
+}
+
 // HeaderByHeight returns the block header at the given height in the main
 // chain.
 //
@@ -2355,6 +2550,45 @@ type Config struct {
 	//
 	// This field is required.
 	UtxoCache UtxoCacher
+
+	// FullVerifyDAG specifies whether the proof of work of blocks is verified
+	// against the full KawPoW DAG for the epoch of each block.  When it is not
+	// set, the light verification cache is used instead which avoids needing
+	// to generate the full DAG for every historical epoch during sync.  The
+	// full DAG is typically only needed for mining.
+	FullVerifyDAG bool
+
+	// DAGDir specifies the directory the KawPoW DAG files for each epoch are
+	// cached in.  The full DAGs generated when full DAG verification is
+	// enabled are persisted to it so they do not need to be generated again,
+	// such as after a restart.
+	DAGDir string
+
+	// MaxDAGDiskBytes specifies the maximum number of bytes the DAG files
+	// cached in the DAG directory are allowed to consume on disk.  The DAG
+	// files for the oldest epochs are pruned as needed to stay under it.  A
+	// value of zero means no limit.
+	MaxDAGDiskBytes uint64
+
+	// MaxKawPowEpochLookahead specifies the maximum number of KawPoW epochs
+	// beyond the epoch of the highest header that could be received in a
+	// single headers message building on the best known header that block
+	// headers are allowed to claim.  Headers that claim heights in later
+	// epochs are rejected before any proof of work verification is performed
+	// so they can't be used to force the generation of caches or DAGs for far
+	// future epochs.
+	MaxKawPowEpochLookahead uint32
+
+	// TrimBlockIndex specifies whether block nodes on side chains that are
+	// both below the old fork rejection checkpoint and more than MaxReorgDepth
+	// blocks before the best chain tip are periodically removed from memory in
+	// order to reduce the memory footprint of the block index.
+	TrimBlockIndex bool
+
+	// skipGenesisValidation skips validation of the genesis block defined by
+	// the chain parameters.  It is only set by tests that rely on legacy data
+	// created with a genesis block that does not commit to its coinbase.
+	skipGenesisValidation bool
 }
 
 // newRecentBlocksCache returns a new LRU map for more efficient access to
@@ -2369,6 +2603,18 @@ func newRecentContextChecksCache() *lru.Set[chainhash.Hash] {
 	return lru.NewSet[chainhash.Hash](contextCheckCacheSize)
 }
 
+// newRecentPowChecksCache returns a new LRU cache for tracking recent blocks
+// that have successfully passed proof of work verification.
+func newRecentPowChecksCache() *lru.Set[chainhash.Hash] {
+	return lru.NewSet[chainhash.Hash](powCheckCacheSize)
+}
+
+// newRecentInvalidBlocksCache returns a new LRU map for tracking recent blocks
+// that are known to be invalid along with the reason.
+func newRecentInvalidBlocksCache() *lru.Map[chainhash.Hash, error] {
+	return lru.NewMap[chainhash.Hash, error](invalidBlockCacheSize)
+}
+
 // New returns a BlockChain instance using the provided configuration details.
 func New(ctx context.Context, config *Config) (*BlockChain, error) {
 	// Enforce required config fields.
@@ -2382,14 +2628,37 @@ func New(ctx context.Context, config *Config) (*BlockChain, error) {
 		return nil, AssertError("blockchain.New chain parameters nil")
 	}
 
+	// Ensure the genesis block defined by the provided params is consistent
+	// so misconfigurations are detected before it is ever used.
+	params := config.ChainParams
+	if !config.skipGenesisValidation {
+		if err := params.ValidateGenesis(); err != nil {
+			return nil, fmt.Errorf("invalid genesis block for network %s: %w",
+				params.Name, err)
+		}
+	}
+
+	// Ensure the parameters do not violate any of the invariants the consensus
+	// code relies on, such as the parameters used by the stake difficulty
+	// calculations being set since they would otherwise divide by zero.
+	if err := params.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid parameters for network %s: %w",
+			params.Name, err)
+	}
+
 	// Generate a deployment ID map from the provided params while validating
 	// they conform to the required semantics.
-	params := config.ChainParams
 	deploymentData, err := extractDeployments(params)
 	if err != nil {
 		return nil, err
 	}
 
+	// Ensure the KawPoW parameters have not been misconfigured in a way that
+	// would defeat the ASIC resistance of the proof of work.
+	if err := checkKawPowASICResistance(params); err != nil {
+		return nil, err
+	}
+
 	// Convert the minimum known work to a uint256 when it exists.  Ideally, the
 	// chain params should be updated to use the new type, but that will be a
 	// major version bump, so a one-time conversion is a good tradeoff in the
@@ -2399,17 +2668,10 @@ func New(ctx context.Context, config *Config) (*BlockChain, error) {
 		minKnownWork = new(uint256.Uint256).SetBig(params.MinKnownChainWork)
 	}
 
-	// Impose a maximum difficulty target on the test network to prevent runaway
-	// difficulty on testnet by ASICs and GPUs since it's not reasonable to
-	// require high-powered hardware to keep the test network running smoothly.
-	var minTestNetTarget *big.Int
-	var minTestNetDiffBits uint32
-	if params.Net == wire.TestNet3 {
-		// This equates to a maximum difficulty of 2^6 = 64.
-		const maxTestDiffShift = 6
-		minTestNetTarget = new(big.Int).Rsh(params.PowLimit, maxTestDiffShift)
-		minTestNetDiffBits = standalone.BigToCompact(minTestNetTarget)
-	}
+	// Impose a maximum difficulty target on test networks to prevent runaway
+	// difficulty on them by ASICs and GPUs since it's not reasonable to
+	// require high-powered hardware to keep a test network running smoothly.
+	minTestNetTarget, minTestNetDiffBits := testNetDifficultyCap(params)
 
 	// Either use the subsidy cache provided by the caller or create a new
 	// one when one was not provided.
@@ -2451,15 +2713,34 @@ func New(ctx context.Context, config *Config) (*BlockChain, error) {
 		bestChain:                     newChainView(nil),
 		recentBlocks:                  newRecentBlocksCache(),
 		recentContextChecks:           newRecentContextChecksCache(),
+		recentPowChecks:               newRecentPowChecksCache(),
+		recentInvalidBlocks:           newRecentInvalidBlocksCache(),
+		kawPow:                        kawpow.NewLightWithParams(kawPowParams(params)),
 		isVoterMajorityVersionCache:   make(map[[stakeMajorityCacheKeySize]byte]bool),
 		isStakeMajorityVersionCache:   make(map[[stakeMajorityCacheKeySize]byte]bool),
 		calcPriorStakeVersionCache:    make(map[[chainhash.HashSize]byte]uint32),
 		calcVoterVersionIntervalCache: make(map[[chainhash.HashSize]byte]uint32),
 		calcStakeVersionCache:         make(map[[chainhash.HashSize]byte]uint32),
 		utxoCache:                     config.UtxoCache,
+		maxKawPowEpochLookahead:       config.MaxKawPowEpochLookahead,
+		trimBlockIndex:                config.TrimBlockIndex,
 	}
 	b.pruner = newChainPruner(&b)
 
+	// Create the full KawPoW hasher when full DAG verification is enabled.
+	// Only a single long-lived instance is used so the DAG for each epoch is
+	// only generated once as opposed to for every block that is verified.
+	// The DAGs it generates are persisted to the DAG files managed by the
+	// DAG manager so they are also not generated again after a restart.
+	b.dagManager = kawpow.NewDAGManager(config.DAGDir, config.MaxDAGDiskBytes,
+		kawPowParams(params))
+	if config.FullVerifyDAG {
+		b.kawPowFull = kawpow.NewWithParams(kawPowParams(params))
+		if config.DAGDir != "" {
+			b.kawPowFull.SetDAGManager(b.dagManager)
+		}
+	}
+
 	// Initialize the chain state from the passed database.  When the db
 	// does not yet contain any chain state, both it and the chain state
 	// will be initialized to contain only the genesis block.
@@ -2526,5 +2807,9 @@ func New(ctx context.Context, config *Config) (*BlockChain, error) {
 		"%v, progress %0.2f%%", tip.height, tip.hash,
 		b.stateSnapshot.TotalTxns, tip.workSum, b.VerifyProgress())
 
+	// Prepare the KawPoW verification caches needed to verify the blocks that
+	// follow the current tip.
+	b.prepareKawPowCaches(tip.height)
+
 	return &b, nil
 }
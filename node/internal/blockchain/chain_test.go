@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -24,6 +25,7 @@ import (
 	"github.com/decred/dcrd/dcrutil/v4"
 	"github.com/decred/dcrd/txscript/v4/stdaddr"
 	"github.com/decred/dcrd/wire"
+	"vigil.network/node/blockchain/standalone"
 )
 
 const (
@@ -305,8 +307,9 @@ func TestBlockchainFunctions(t *testing.T) {
 	}}
 	params.GenesisHash = params.GenesisBlock.BlockHash()
 
-	// Create a new database and chain instance to run tests against.
-	chain, err := chainSetup(t, params)
+	// Create a new database and chain instance to run tests against.  Note
+	// that the legacy genesis block does not commit to its coinbase.
+	chain, err := legacyChainSetup(t, params)
 	if err != nil {
 		t.Errorf("Failed to setup chain instance: %v", err)
 		return
@@ -1031,3 +1034,378 @@ func TestLocateInventory(t *testing.T) {
 		}
 	}
 }
+
+// TestBlocksAtHeight ensures that the hashes of all blocks known to the block
+// index at a given height are returned, including those in side chains.
+func TestBlocksAtHeight(t *testing.T) {
+	// Construct a synthetic block chain with a block index consisting of
+	// the following structure.
+	// 	genesis -> 1 -> 2 -> ... -> 15 -> 16  -> 17  -> 18
+	// 	                              \-> 16a -> 17a
+	tip := branchTip
+	chain := newFakeChain(chaincfg.MainNetParams())
+	branch0Nodes := chainedFakeNodes(chain.bestChain.Genesis(), 18)
+	branch1Nodes := chainedFakeNodes(branch0Nodes[14], 2)
+	for _, node := range branch0Nodes {
+		chain.index.AddNode(node)
+	}
+	for _, node := range branch1Nodes {
+		chain.index.AddNode(node)
+	}
+	chain.bestChain.SetTip(tip(branch0Nodes))
+
+	tests := []struct {
+		name   string
+		height int64
+		want   []chainhash.Hash
+	}{{
+		name:   "main chain only before fork",
+		height: 10,
+		want:   nodeHashes(branch0Nodes, 9),
+	}, {
+		name:   "both blocks at fork height",
+		height: 16,
+		want: []chainhash.Hash{branch0Nodes[15].hash,
+			branch1Nodes[0].hash},
+	}, {
+		name:   "both blocks at side chain tip height",
+		height: 17,
+		want: []chainhash.Hash{branch0Nodes[16].hash,
+			branch1Nodes[1].hash},
+	}, {
+		name:   "main chain only after side chain tip",
+		height: 18,
+		want:   nodeHashes(branch0Nodes, 17),
+	}, {
+		name:   "no blocks past tip",
+		height: 19,
+		want:   nil,
+	}}
+
+	for _, test := range tests {
+		hashes := chain.BlocksAtHeight(test.height)
+		if len(hashes) != len(test.want) {
+			t.Errorf("%q: unexpected number of hashes -- got %d, want %d",
+				test.name, len(hashes), len(test.want))
+			continue
+		}
+
+		got := make(map[chainhash.Hash]struct{}, len(hashes))
+		for _, hash := range hashes {
+			got[*hash] = struct{}{}
+		}
+		for _, hash := range test.want {
+			if _, ok := got[hash]; !ok {
+				t.Errorf("%q: missing expected hash %s", test.name, hash)
+			}
+		}
+	}
+}
+
+// TestTipHeader ensures the full header of the current best chain tip is
+// returned, including the 64-bit nonce and mix digest used by KawPoW.
+func TestTipHeader(t *testing.T) {
+	// Ensure the tip header of a chain that only consists of the genesis
+	// block matches the genesis block header.
+	params := chaincfg.MainNetParams()
+	chain := newFakeChain(params)
+	genesisHeader := chain.TipHeader()
+	if !genesisHeader.Equals(&params.GenesisBlock.Header) {
+		t.Fatalf("mismatched genesis tip header fields: %v",
+			genesisHeader.DiffFields(&params.GenesisBlock.Header))
+	}
+
+	// Construct a synthetic block chain consisting of the following
+	// structure.
+	// 	genesis -> 1 -> 2 -> ... -> 10 -> 11
+	chain = newFakeChain(params)
+	nodes := chainedFakeNodes(chain.bestChain.Genesis(), 10)
+	for _, node := range nodes {
+		chain.index.AddNode(node)
+	}
+
+	// Create a tip block with a nonce that does not fit in 32 bits and a
+	// non-zero mix digest.
+	prevNode := branchTip(nodes)
+	tipHeader := prevNode.Header()
+	tipHeader.PrevBlock = prevNode.hash
+	tipHeader.Height++
+	tipHeader.Nonce = 0x0102030405060708
+	tipHeader.MixDigest = [32]byte{0x01, 0x02, 0x03}
+	tipNode := newBlockNode(&tipHeader, prevNode)
+	chain.index.AddNode(tipNode)
+	chain.bestChain.SetTip(tipNode)
+	chain.stateSnapshot = newBestState(tipNode, 0, 0, 0, time.Unix(0, 0), 0,
+		0, 0, nil, nil, nil, [6]byte{})
+
+	// Ensure the tip header matches the best snapshot and the header the tip
+	// was created from.
+	gotHeader := chain.TipHeader()
+	gotHash, wantHash := gotHeader.BlockHash(), chain.BestSnapshot().Hash
+	if gotHash != wantHash {
+		t.Fatalf("mismatched tip header hash -- got %v, want %v", gotHash,
+			wantHash)
+	}
+	if !gotHeader.Equals(&tipHeader) {
+		t.Fatalf("mismatched tip header fields: %v",
+			gotHeader.DiffFields(&tipHeader))
+	}
+}
+
+// TestTotalTransactions ensures the total number of transactions in the chain
+// is updated as expected when blocks are connected and disconnected.
+func TestTotalTransactions(t *testing.T) {
+	// Create a test harness initialized with the genesis block as the tip and
+	// generate enough blocks to reach stake validation height.
+	params := chaincfg.RegNetParams()
+	g := newChaingenHarness(t, params)
+	g.AdvanceToStakeValidationHeight()
+
+	// assertTotalTxns ensures the total number of transactions reported by the
+	// chain matches the provided value.
+	assertTotalTxns := func(want uint64) {
+		t.Helper()
+		if got := g.chain.TotalTransactions(); got != want {
+			t.Fatalf("mismatched total txns -- got %d, want %d", got, want)
+		}
+		if got := g.chain.BestSnapshot().TotalTxns; got != want {
+			t.Fatalf("mismatched best state total txns -- got %d, want %d",
+				got, want)
+		}
+	}
+
+	// numTxns returns the total number of regular and stake transactions in
+	// the provided block.
+	numTxns := func(b *wire.MsgBlock) uint64 {
+		return uint64(len(b.Transactions) + len(b.STransactions))
+	}
+
+	// Connect several blocks with differing numbers of transactions and ensure
+	// the total is increased by the number of transactions in each block.
+	//
+	//   ... -> bsv# -> b0 -> b1 -> b2
+	svhTipName := g.TipName()
+	baseTotal := g.chain.TotalTransactions()
+	wantTotal := baseTotal
+	var blockTxns []uint64
+	for i := 0; i < 3; i++ {
+		outs := g.OldestCoinbaseOuts()
+		blockName := fmt.Sprintf("b%d", i)
+		b := g.NextBlock(blockName, &outs[0], outs[1:i+1])
+		g.AcceptTipBlock()
+		blockTxns = append(blockTxns, numTxns(b))
+		wantTotal += numTxns(b)
+		assertTotalTxns(wantTotal)
+	}
+
+	// Disconnect the final block and ensure the total is decreased by the
+	// number of transactions in it.
+	g.InvalidateBlockAndExpectTip("b2", nil, "b1")
+	wantTotal -= blockTxns[2]
+	assertTotalTxns(wantTotal)
+
+	// Disconnect the remaining blocks and ensure the total returns to the
+	// original value.
+	g.InvalidateBlockAndExpectTip("b0", nil, svhTipName)
+	assertTotalTxns(baseTotal)
+}
+
+// TestMainChainHasBlock ensures querying whether or not a block is part of the
+// main chain works as expected for main chain blocks, side chain blocks, and
+// unknown blocks.
+func TestMainChainHasBlock(t *testing.T) {
+	// Construct a synthetic block chain with a block index consisting of
+	// the following structure.
+	// 	genesis -> 1 -> 2 -> ... -> 15 -> 16  -> 17  -> 18
+	// 	                              \-> 16a -> 17a
+	tip := branchTip
+	chain := newFakeChain(chaincfg.MainNetParams())
+	branch0Nodes := chainedFakeNodes(chain.bestChain.Genesis(), 18)
+	branch1Nodes := chainedFakeNodes(branch0Nodes[14], 2)
+	for _, node := range branch0Nodes {
+		chain.index.AddNode(node)
+	}
+	for _, node := range branch1Nodes {
+		chain.index.AddNode(node)
+	}
+	chain.bestChain.SetTip(tip(branch0Nodes))
+
+	unknownHash := chainhash.HashH([]byte("unknown block"))
+	tests := []struct {
+		name string
+		hash *chainhash.Hash
+		want bool
+	}{{
+		name: "genesis block",
+		hash: &chain.bestChain.Genesis().hash,
+		want: true,
+	}, {
+		name: "main chain block before fork",
+		hash: &branch0Nodes[9].hash,
+		want: true,
+	}, {
+		name: "main chain block at fork height",
+		hash: &branch0Nodes[15].hash,
+		want: true,
+	}, {
+		name: "main chain tip",
+		hash: &tip(branch0Nodes).hash,
+		want: true,
+	}, {
+		name: "side chain block at fork height",
+		hash: &branch1Nodes[0].hash,
+		want: false,
+	}, {
+		name: "side chain tip",
+		hash: &tip(branch1Nodes).hash,
+		want: false,
+	}, {
+		name: "unknown block",
+		hash: &unknownHash,
+		want: false,
+	}}
+
+	for _, test := range tests {
+		got := chain.MainChainHasBlock(test.hash)
+		if got != test.want {
+			t.Errorf("%q: unexpected result -- got %v, want %v", test.name,
+				got, test.want)
+		}
+	}
+}
+
+// TestNetworkHashPS ensures the estimated network hashes per second are
+// calculated correctly for windows of various sizes, including those that
+// would otherwise span past the genesis block and those with timestamps that
+// are not monotonically increasing.
+func TestNetworkHashPS(t *testing.T) {
+	// Construct a synthetic block chain consisting of 10 blocks after the
+	// genesis block with a block every 300 seconds except for the final block
+	// which has a timestamp 1000 seconds prior to its parent.
+	chain := newFakeChain(chaincfg.MainNetParams())
+	genesis := chain.bestChain.Genesis()
+	node := genesis
+	for i := int64(1); i <= 10; i++ {
+		blockTime := time.Unix(genesis.timestamp+i*300, 0)
+		if i == 10 {
+			blockTime = time.Unix(node.timestamp-1000, 0)
+		}
+		node = newFakeNode(node, 1, 1, genesis.bits, blockTime)
+		chain.index.AddNode(node)
+		chain.bestChain.SetTip(node)
+	}
+
+	// hashesPerSec returns the expected hashes per second for the given number
+	// of blocks at the genesis block difficulty over the given number of
+	// seconds.
+	workPerBlock := standalone.CalcWork(genesis.bits)
+	hashesPerSec := func(numBlocks, seconds int64) int64 {
+		totalWork := new(big.Int).Mul(workPerBlock, big.NewInt(numBlocks))
+		return totalWork.Div(totalWork, big.NewInt(seconds)).Int64()
+	}
+
+	tests := []struct {
+		name    string // test description
+		blocks  int    // number of blocks in the window
+		height  int64  // height at the end of the window
+		want    int64  // expected hashes per second
+		wantErr bool   // whether or not an error is expected
+	}{{
+		name:   "defaults",
+		blocks: -1,
+		height: -1,
+		want:   hashesPerSec(10, 9*300),
+	}, {
+		name:   "window prior to the best chain tip",
+		blocks: 4,
+		height: 6,
+		want:   hashesPerSec(4, 4*300),
+	}, {
+		name:   "window spanning past the genesis block",
+		blocks: 5,
+		height: 2,
+		want:   hashesPerSec(2, 2*300),
+	}, {
+		name:   "window with decreasing timestamp",
+		blocks: 2,
+		height: 10,
+		want:   hashesPerSec(2, 1000),
+	}, {
+		name:   "window ending at the genesis block",
+		blocks: 5,
+		height: 0,
+		want:   0,
+	}, {
+		name:   "empty window",
+		blocks: 0,
+		height: 5,
+		want:   0,
+	}, {
+		name:    "height beyond the best chain tip",
+		blocks:  5,
+		height:  11,
+		wantErr: true,
+	}}
+
+	for _, test := range tests {
+		got, err := chain.NetworkHashPS(test.blocks, test.height)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%q: unexpected error -- got %v, want error %v",
+				test.name, err, test.wantErr)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%q: unexpected hashes per second -- got %d, want %d",
+				test.name, got, test.want)
+		}
+	}
+}
+
+// TestCoinbaseMaturity ensures the coinbase maturity height and output
+// maturity are calculated correctly around the maturity boundary.
+func TestCoinbaseMaturity(t *testing.T) {
+	params := chaincfg.RegNetParams()
+	chain := newFakeChain(params)
+	maturity := int64(params.CoinbaseMaturity)
+
+	const minedHeight = 100
+	wantHeight := minedHeight + maturity
+	if got := chain.CoinbaseMaturityHeight(minedHeight); got != wantHeight {
+		t.Fatalf("unexpected maturity height -- got %d, want %d", got,
+			wantHeight)
+	}
+
+	tests := []struct {
+		name      string
+		tipHeight int64
+		want      bool
+	}{{
+		name:      "tip at mined height",
+		tipHeight: minedHeight,
+		want:      false,
+	}, {
+		name:      "next block two before maturity height",
+		tipHeight: wantHeight - 2,
+		want:      false,
+	}, {
+		name:      "next block at maturity height",
+		tipHeight: wantHeight - 1,
+		want:      true,
+	}, {
+		name:      "tip at maturity height",
+		tipHeight: wantHeight,
+		want:      true,
+	}, {
+		name:      "tip well after maturity height",
+		tipHeight: wantHeight + 1000,
+		want:      true,
+	}}
+
+	for _, test := range tests {
+		got := chain.IsOutputMature(minedHeight, test.tipHeight)
+		if got != test.want {
+			t.Errorf("%q: unexpected result -- got %v, want %v", test.name,
+				got, test.want)
+		}
+	}
+}
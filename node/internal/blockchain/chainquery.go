@@ -136,6 +136,37 @@ func (b *BlockChain) ChainTips() []ChainTipInfo {
 	return results
 }
 
+// BlocksAtHeight returns the hashes of all blocks known to the block index at
+// the provided height.  This includes the block in the main chain as well as
+// any blocks in side chains.  The hashes are sorted in ascending order.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) BlocksAtHeight(height int64) []*chainhash.Hash {
+	// Gather all nodes at the height.  Note that entries in the index are nil
+	// when there is a short key collision, in which case the nodes are stored
+	// in the collisions map instead.
+	var nodes []*blockNode
+	b.index.RLock()
+	for _, node := range b.index.index {
+		if node != nil && node.height == height {
+			nodes = append(nodes, node)
+		}
+	}
+	for _, node := range b.index.collisions {
+		if node.height == height {
+			nodes = append(nodes, node)
+		}
+	}
+	b.index.RUnlock()
+
+	sort.Sort(nodeHeightSorter(nodes))
+	hashes := make([]*chainhash.Hash, 0, len(nodes))
+	for _, node := range nodes {
+		hashes = append(hashes, &node.hash)
+	}
+	return hashes
+}
+
 // BestHeader returns the header with the most cumulative work that is NOT
 // known to be invalid.
 //
@@ -269,3 +300,22 @@ func (b *BlockChain) IsKnownInvalidBlock(hash *chainhash.Hash) bool {
 
 	return b.index.NodeStatus(node).KnownInvalid()
 }
+
+// CoinbaseMaturityHeight returns the first block height at which the outputs
+// of a coinbase transaction mined in a block at the provided height may be
+// spent.  In other words, a transaction that spends the outputs is only valid
+// when it is included in a block at or after the returned height.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) CoinbaseMaturityHeight(minedHeight int64) int64 {
+	return minedHeight + int64(b.chainParams.CoinbaseMaturity)
+}
+
+// IsOutputMature returns whether or not the outputs of a coinbase transaction
+// mined in a block at the provided height may be spent by a transaction in the
+// block that follows a chain tip at the provided height.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) IsOutputMature(minedHeight, tipHeight int64) bool {
+	return tipHeight+1 >= b.CoinbaseMaturityHeight(minedHeight)
+}
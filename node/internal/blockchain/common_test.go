@@ -32,6 +32,7 @@ import (
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/filter"
 	"github.com/syndtr/goleveldb/leveldb/opt"
+	"vigil.network/node/blockchain/standalone/kawpow"
 )
 
 const (
@@ -116,6 +117,20 @@ func createTestUtxoDatabase(t testing.TB) (*leveldb.DB, func(), error) {
 // chainSetup is used to create a new db and chain instance with the genesis
 // block already inserted.
 func chainSetup(t testing.TB, params *chaincfg.Params) (*BlockChain, error) {
+	return setupTestChain(t, params, false)
+}
+
+// legacyChainSetup is identical to chainSetup except it skips validation of
+// the genesis block defined by the provided parameters.  It is used by tests
+// that rely on legacy data created with a genesis block that does not commit to
+// its coinbase.
+func legacyChainSetup(t testing.TB, params *chaincfg.Params) (*BlockChain, error) {
+	return setupTestChain(t, params, true)
+}
+
+// setupTestChain creates a new db and chain instance with the genesis block
+// already inserted while optionally skipping validation of the genesis block.
+func setupTestChain(t testing.TB, params *chaincfg.Params, skipGenesisValidation bool) (*BlockChain, error) {
 	if !isSupportedDbType(testDbType) {
 		return nil, fmt.Errorf("unsupported db type %v", testDbType)
 	}
@@ -163,6 +178,7 @@ func chainSetup(t testing.TB, params *chaincfg.Params) (*BlockChain, error) {
 				},
 				MaxSize: 100 * 1024 * 1024, // 100 MiB
 			}),
+			skipGenesisValidation: skipGenesisValidation,
 		})
 
 	if err != nil {
@@ -193,12 +209,16 @@ func newFakeChain(params *chaincfg.Params) *BlockChain {
 		panic(err)
 	}
 
+	minTestNetTarget, minTestNetDiffBits := testNetDifficultyCap(params)
 	return &BlockChain{
 		deploymentData:                deploymentData,
 		chainParams:                   params,
+		minTestNetTarget:              minTestNetTarget,
+		minTestNetDiffBits:            minTestNetDiffBits,
 		index:                         index,
 		bestChain:                     newChainView(node),
 		recentBlocks:                  newRecentBlocksCache(),
+		kawPow:                        kawpow.NewLightWithParams(kawPowParams(params)),
 		isVoterMajorityVersionCache:   make(map[[stakeMajorityCacheKeySize]byte]bool),
 		isStakeMajorityVersionCache:   make(map[[stakeMajorityCacheKeySize]byte]bool),
 		calcPriorStakeVersionCache:    make(map[[chainhash.HashSize]byte]uint32),
@@ -224,7 +244,7 @@ func newFakeNode(parent *blockNode, blockVersion int32, stakeVersion uint32, bit
 		Bits:         bits,
 		Height:       height,
 		Timestamp:    timestamp,
-		Nonce:        rand.Uint32(),
+		Nonce:        rand.Uint64(),
 		StakeVersion: stakeVersion,
 	}
 	node := newBlockNode(header, parent)
@@ -8,11 +8,6 @@ var zeroHash = chainhash.Hash{}
 // medianTimeBlocks is the number of blocks to use for median time calculations
 const medianTimeBlocks = 11
 
-// Error codes
-const (
-	ErrInvalidPoW = "invalid proof of work"
-)
-
 // AgendaFlags tracks voting agenda activation status
 type AgendaFlags struct {
 	// Add necessary fields
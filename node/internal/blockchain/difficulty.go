@@ -8,12 +8,13 @@ package blockchain
 import (
 	"fmt"
 	"math/big"
+	"strconv"
 	"time"
 
-	"github.com/decred/dcrd/blockchain/standalone/v2"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/dcrd/wire"
+	"vigil.network/node/blockchain/standalone"
 )
 
 var (
@@ -22,8 +23,71 @@ var (
 	bigZero = big.NewInt(0)
 )
 
+const (
+	// testNet3MaxDiffActivationHeight is the height that enforcement of the
+	// maximum difficulty rules starts on version 3 of the test network.
+	testNet3MaxDiffActivationHeight = 962928
+
+	// maxWorkDiffWeightShift is the maximum number of bits the exponential
+	// weights of the windows used in the blake256 difficulty calculation may be
+	// shifted by so that the sum of the weights of all windows fits in an
+	// int64.
+	maxWorkDiffWeightShift = 62
+)
+
+// testNetDifficultyCap returns the minimum target, which equates to the
+// maximum difficulty, along with its compact representation that is imposed on
+// blocks by the provided network parameters.  A nil target is returned when the
+// parameters do not impose a maximum difficulty.
+//
+// The target is limited to the proof of work limit so that a misconfigured
+// value can never result in requiring a difficulty lower than the minimum.
+func testNetDifficultyCap(params *chaincfg.Params) (*big.Int, uint32) {
+	if params.MinTestNetTarget == nil {
+		return nil, 0
+	}
+
+	minTarget := new(big.Int).Set(params.MinTestNetTarget)
+	if minTarget.Cmp(params.PowLimit) > 0 {
+		minTarget.Set(params.PowLimit)
+	}
+	return minTarget, standalone.BigToCompact(minTarget)
+}
+
+// GetDifficultyRatio returns the proof-of-work difficulty as a multiple of the
+// minimum difficulty using the passed bits field from the header of a block.
+//
+// The compact representation is able to encode targets that are zero or
+// negative, neither of which is a valid difficulty, so a ratio of zero is
+// returned for them.
+func GetDifficultyRatio(bits uint32, params *chaincfg.Params) float64 {
+	// The minimum difficulty is the max possible proof-of-work limit bits
+	// converted back to a number.  Note this is not the same as the proof
+	// of work limit directly because the block difficulty is encoded in a
+	// block with the compact form which loses precision.
+	max := standalone.CompactToBig(params.PowLimitBits)
+	target := standalone.CompactToBig(bits)
+	if target.Sign() <= 0 {
+		return 0
+	}
+
+	difficulty := new(big.Rat).SetFrac(max, target)
+	outString := difficulty.FloatString(8)
+	diff, err := strconv.ParseFloat(outString, 64)
+	if err != nil {
+		log.Errorf("Cannot get difficulty: %v", err)
+		return 0
+	}
+	return diff
+}
+
 // findPrevTestNetDifficulty returns the difficulty of the previous block which
 // did not have the special testnet minimum difficulty rule applied.
+//
+// Note that the search stops at the most recent boundary of a full set of
+// difficulty windows since the difficulty of a block at such a boundary is
+// treated as the result of the normal retarget calculation, even when that
+// calculation results in the minimum difficulty.
 func (b *BlockChain) findPrevTestNetDifficulty(startNode *blockNode) uint32 {
 	// Search backwards through the chain for the last block without
 	// the special rule applied.
@@ -61,25 +125,25 @@ func (b *BlockChain) calcNextBlake256Diff(prevNode *blockNode, newBlockTime time
 		// For networks that support it, allow special reduction of the required
 		// difficulty once too much time has elapsed without mining a block.
 		//
-		// Note that this behavior is deprecated and thus is only supported on
-		// testnet v3 prior to the max diff activation height.  It will be
-		// removed in future version of testnet.
-		// if params.ReduceMinDifficulty && (!b.isTestNet3() || nextHeight <
-		// 	testNet3MaxDiffActivationHeight) {
-
-		// 	// Return minimum difficulty when more than the desired
-		// 	// amount of time has elapsed without mining a block.
-		// 	reductionTime := int64(params.MinDiffReductionTime / time.Second)
-		// 	allowMinTime := prevNode.timestamp + reductionTime
-		// 	if newBlockTime.Unix() > allowMinTime {
-		// 		return params.PowLimitBits
-		// 	}
-
-		// 	// The block was mined within the desired timeframe, so
-		// 	// return the difficulty for the last block which did
-		// 	// not have the special minimum difficulty rule applied.
-		// 	return b.findPrevTestNetDifficulty(prevNode)
-		// }
+		// Note that this behavior is deprecated on version 3 of the test
+		// network and thus is only supported there prior to the max diff
+		// activation height.
+		if params.ReduceMinDifficulty && (!b.isTestNet3() || nextHeight <
+			testNet3MaxDiffActivationHeight) {
+
+			// Return minimum difficulty when more than the desired
+			// amount of time has elapsed without mining a block.
+			reductionTime := int64(params.MinDiffReductionTime / time.Second)
+			allowMinTime := prevNode.timestamp + reductionTime
+			if newBlockTime.Unix() > allowMinTime {
+				return params.PowLimitBits
+			}
+
+			// The block was mined within the desired timeframe, so
+			// return the difficulty for the last block which did
+			// not have the special minimum difficulty rule applied.
+			return b.findPrevTestNetDifficulty(prevNode)
+		}
 
 		return oldDiff
 	}
@@ -91,17 +155,24 @@ func (b *BlockChain) calcNextBlake256Diff(prevNode *blockNode, newBlockTime time
 	nextDiffBigMax := standalone.CompactToBig(prevNode.bits)
 	nextDiffBigMax.Mul(nextDiffBigMax, RAFBig)
 
+	// Limit alpha such that the exponential weights of the windows can't be
+	// shifted beyond the point their sum fits in an int64.  This should never
+	// happen with valid parameters, but it prevents the sum from silently
+	// wrapping and producing a nonsensical difficulty in case it does.
 	alpha := params.WorkDiffAlpha
+	if maxAlpha := maxWorkDiffWeightShift / params.WorkDiffWindows; alpha > maxAlpha {
+		alpha = maxAlpha
+	}
 
 	// Number of nodes to traverse while calculating difficulty.
 	nodesToTraverse := (params.WorkDiffWindowSize * params.WorkDiffWindows)
 
-	// Initialize bigInt slice for the percentage changes for each window period
-	// above or below the target.
-	windowChanges := make([]*big.Int, params.WorkDiffWindows)
+	// Initialize fixed point slice for the percentage changes for each window
+	// period above or below the target.
+	windowChanges := make([]*fixed64_32, params.WorkDiffWindows)
 
 	// Regress through all of the previous blocks and store the percent changes
-	// per window period; use bigInts to emulate 64.32 bit fixed point.
+	// per window period using 64.32 bit fixed point.
 	var olderTime, windowPeriod int64
 	var weights uint64
 	oldNode := prevNode
@@ -119,16 +190,13 @@ func (b *BlockChain) calcNextBlake256Diff(prevNode *blockNode, newBlockTime time
 				timeDifference = int64(params.TargetTimespan / time.Second)
 			}
 
-			timeDifBig := big.NewInt(timeDifference)
-			timeDifBig.Lsh(timeDifBig, 32) // Add padding
 			targetTemp := big.NewInt(int64(params.TargetTimespan / time.Second))
+			windowAdjusted := new(fixed64_32).FromInt(timeDifference).
+				MulDiv(bigOne, targetTemp)
 
-			windowAdjusted := targetTemp.Div(timeDifBig, targetTemp)
-
-			// Weight it exponentially. Be aware that this could at some point
-			// overflow if alpha or the number of blocks used is really large.
-			windowAdjusted = windowAdjusted.Lsh(windowAdjusted,
-				uint((params.WorkDiffWindows-windowPeriod)*alpha))
+			// Weight it exponentially.
+			windowAdjusted.Lsh(uint((params.WorkDiffWindows - windowPeriod) *
+				alpha))
 
 			// Sum up all the different weights incrementally.
 			weights += 1 << uint64((params.WorkDiffWindows-windowPeriod)*alpha)
@@ -153,20 +221,17 @@ func (b *BlockChain) calcNextBlake256Diff(prevNode *blockNode, newBlockTime time
 	}
 
 	// Sum up the weighted window periods.
-	weightedSum := big.NewInt(0)
+	weightedSum := new(fixed64_32)
 	for i := int64(0); i < params.WorkDiffWindows; i++ {
-		weightedSum.Add(weightedSum, windowChanges[i])
+		weightedSum.Add(windowChanges[i])
 	}
 
-	// Divide by the sum of all weights.
+	// Divide by the sum of all weights and then multiply by the old diff
+	// before converting back to an integer.  Note that the division must
+	// happen first since it affects the rounding of the result.
 	weightsBig := big.NewInt(int64(weights))
-	weightedSumDiv := weightedSum.Div(weightedSum, weightsBig)
-
-	// Multiply by the old diff.
-	nextDiffBig := weightedSumDiv.Mul(weightedSumDiv, oldDiffBig)
-
-	// Right shift to restore the original padding (restore non-fixed point).
-	nextDiffBig = nextDiffBig.Rsh(nextDiffBig, 32)
+	nextDiffBig := weightedSum.MulDiv(bigOne, weightsBig).
+		MulDiv(oldDiffBig, bigOne).ToInt()
 
 	// Check to see if we're over the limits for the maximum allowable retarget;
 	// if we are, return the maximum or minimum except in the case that oldDiff
@@ -200,11 +265,11 @@ func (b *BlockChain) calcNextBlake256Diff(prevNode *blockNode, newBlockTime time
 	//
 	// This rule is only active on the version 3 test network once the max diff
 	// activation height has been reached.
-	// if b.minTestNetTarget != nil && nextDiffBig.Cmp(b.minTestNetTarget) < 0 &&
-	// 		(!b.isTestNet3() || nextHeight >= testNet3MaxDiffActivationHeight) {
+	if b.minTestNetTarget != nil && nextDiffBig.Cmp(b.minTestNetTarget) < 0 &&
+		(!b.isTestNet3() || nextHeight >= testNet3MaxDiffActivationHeight) {
 
-	// 		nextDiffBig = b.minTestNetTarget
-	// }
+		nextDiffBig = b.minTestNetTarget
+	}
 
 	// Convert the difficulty to the compact representation and return it.
 	nextDiffBits := standalone.BigToCompact(nextDiffBig)
@@ -235,10 +300,12 @@ func (b *BlockChain) calcNextBlake3DiffFromAnchor(prevNode *blockNode, blake3Anc
 	//
 	// Note that the difficulty of the anchor block is NOT used for the initial
 	// difficulty because the difficulty must be reset due to the change to
-	// blake3 for proof of work.  The initial difficulty comes from the chain
-	// parameters instead.
+	// KawPoW for proof of work since the work performed under it is not
+	// comparable to the work performed under blake256.  The initial difficulty
+	// comes from the KawPoW starting difficulty in the chain parameters
+	// instead.
 	params := b.chainParams
-	nextDiff := standalone.CalcASERTDiff(params.WorkDiffV2Blake3StartBits,
+	nextDiff := standalone.CalcASERTDiff(params.WorkDiffV2KawPowStartBits,
 		params.PowLimit, int64(params.TargetTimePerBlock.Seconds()), timeDelta,
 		heightDelta, params.WorkDiffV2HalfLifeSecs)
 
@@ -340,7 +407,7 @@ func (b *BlockChain) calcNextBlake3Diff(prevNode *blockNode) uint32 {
 	if b.isBlake3PowAgendaForcedActive() {
 		// Use the initial starting difficulty for the first block.
 		if prevNode.height == 0 {
-			return b.chainParams.WorkDiffV2Blake3StartBits
+			return b.chainParams.WorkDiffV2KawPowStartBits
 		}
 
 		// Treat the first block as the anchor for all descendants of it.
@@ -393,6 +460,142 @@ func (b *BlockChain) CalcNextRequiredDifficulty(hash *chainhash.Hash, timestamp
 	return difficulty, err
 }
 
+// DiffWindowStats houses statistics about the progress of the current proof of
+// work difficulty retarget window.
+type DiffWindowStats struct {
+	// WindowSize is the number of blocks in a difficulty retarget window.
+	WindowSize int64
+
+	// BlocksIntoWindow is the number of blocks that have been mined since the
+	// start of the current window.
+	BlocksIntoWindow int64
+
+	// ElapsedTime is the time between the block that started the current window
+	// and the current best chain tip.
+	ElapsedTime time.Duration
+
+	// ExpectedTime is the time the blocks mined since the start of the current
+	// window were expected to take based on the target time per block.
+	ExpectedTime time.Duration
+}
+
+// Direction returns the direction the proof of work difficulty is trending for
+// the current window based on the elapsed time compared to the expected time.
+// Blocks being mined faster than expected implies an "increase", blocks being
+// mined slower than expected implies a "decrease", and otherwise the result is
+// "unchanged".
+func (s *DiffWindowStats) Direction() string {
+	switch {
+	case s.BlocksIntoWindow == 0 || s.ElapsedTime == s.ExpectedTime:
+		return "unchanged"
+	case s.ElapsedTime < s.ExpectedTime:
+		return "increase"
+	}
+	return "decrease"
+}
+
+// calcDiffWindowStats returns statistics about the progress of the difficulty
+// retarget window that contains the passed node.
+//
+// This function is safe for concurrent access.
+func calcDiffWindowStats(node *blockNode, params *chaincfg.Params) DiffWindowStats {
+	windowSize := params.WorkDiffWindowSize
+	windowStart := node.Ancestor(node.height - node.height%windowSize)
+	blocksIntoWindow := node.height - windowStart.height
+	elapsed := time.Duration(node.timestamp-windowStart.timestamp) * time.Second
+	return DiffWindowStats{
+		WindowSize:       windowSize,
+		BlocksIntoWindow: blocksIntoWindow,
+		ElapsedTime:      elapsed,
+		ExpectedTime:     time.Duration(blocksIntoWindow) * params.TargetTimePerBlock,
+	}
+}
+
+// DiffWindowStats returns statistics about the progress of the difficulty
+// retarget window that contains the current best chain tip.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) DiffWindowStats() *DiffWindowStats {
+	stats := calcDiffWindowStats(b.bestChain.Tip(), b.chainParams)
+	return &stats
+}
+
+// SimulatedDiff houses the required proof of work difficulty for a block in a
+// hypothetical sequence of blocks.
+type SimulatedDiff struct {
+	// Height is the height of the hypothetical block.
+	Height int64
+
+	// Timestamp is the timestamp of the hypothetical block as a unix time.
+	Timestamp int64
+
+	// Bits is the required difficulty of the hypothetical block in compact
+	// form.
+	Bits uint32
+}
+
+// SimulateDifficulty returns the required proof of work difficulty for each
+// block in a hypothetical sequence of blocks that extends the current best
+// chain tip.  The passed intervals specify the number of seconds between each
+// hypothetical block and its parent.
+//
+// The difficulty algorithm that is active for the block after the current best
+// chain tip is used for the entire sequence.  None of the hypothetical blocks
+// are added to the block index.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) SimulateDifficulty(intervals []int64) ([]SimulatedDiff, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	// Determine the active difficulty algorithm along with the blake3 anchor
+	// block when it applies up front since the deployment state can't be
+	// determined for the hypothetical blocks.
+	tip := b.bestChain.Tip()
+	isBlake3Active, err := b.isBlake3PowAgendaActive(tip)
+	if err != nil {
+		return nil, err
+	}
+	var blake3Anchor *blockNode
+	if isBlake3Active && !b.isBlake3PowAgendaForcedActive() {
+		blake3Anchor = b.blake3WorkDiffAnchor(tip)
+	}
+
+	results := make([]SimulatedDiff, 0, len(intervals))
+	node := tip
+	for _, interval := range intervals {
+		blockTime := time.Unix(node.timestamp+interval, 0)
+		var bits uint32
+		switch {
+		case blake3Anchor != nil:
+			bits = b.calcNextBlake3DiffFromAnchor(node, blake3Anchor)
+		case isBlake3Active:
+			bits = b.calcNextBlake3Diff(node)
+		default:
+			bits = b.calcNextBlake256Diff(node, blockTime)
+		}
+
+		// Extend the hypothetical chain with a block that has the simulated
+		// difficulty and timestamp.
+		header := wire.BlockHeader{
+			PrevBlock: node.hash,
+			Bits:      bits,
+			SBits:     node.sbits,
+			PoolSize:  node.poolSize,
+			Height:    uint32(node.height + 1),
+			Timestamp: blockTime,
+		}
+		node = newBlockNode(&header, node)
+		results = append(results, SimulatedDiff{
+			Height:    node.height,
+			Timestamp: node.timestamp,
+			Bits:      bits,
+		})
+	}
+
+	return results, nil
+}
+
 // mergeDifficulty takes an original stake difficulty and two new, scaled
 // stake difficulties, merges the new difficulties, and outputs a new
 // merged stake difficulty.
@@ -464,12 +667,12 @@ func (b *BlockChain) calcNextRequiredStakeDifficultyV1(curNode *blockNode) int64
 	targetForTicketPool := int64(b.chainParams.TicketsPerBlock) *
 		int64(b.chainParams.TicketPoolSize)
 
-	// Initialize bigInt slice for the percentage changes for each window period
-	// above or below the target.
-	windowChanges := make([]*big.Int, b.chainParams.StakeDiffWindows)
+	// Initialize fixed point slice for the percentage changes for each window
+	// period above or below the target.
+	windowChanges := make([]*fixed64_32, b.chainParams.StakeDiffWindows)
 
 	// Regress through all of the previous blocks and store the percent changes
-	// per window period; use bigInts to emulate 64.32 bit fixed point.
+	// per window period using 64.32 bit fixed point.
 	oldNode := curNode
 	windowPeriod := int64(0)
 	weights := uint64(0)
@@ -488,16 +691,15 @@ func (b *BlockChain) calcNextRequiredStakeDifficultyV1(curNode *blockNode) int64
 				poolSizeSkew = 1
 			}
 
-			curPoolSizeTemp := big.NewInt(poolSizeSkew)
-			curPoolSizeTemp.Lsh(curPoolSizeTemp, 32) // Add padding
 			targetTemp := big.NewInt(targetForTicketPool)
+			windowAdjusted := new(fixed64_32).FromInt(poolSizeSkew).
+				MulDiv(bigOne, targetTemp)
 
-			windowAdjusted := curPoolSizeTemp.Div(curPoolSizeTemp, targetTemp)
-
-			// Weight it exponentially. Be aware that this could at some point
-			// overflow if alpha or the number of blocks used is really large.
-			windowAdjusted = windowAdjusted.Lsh(windowAdjusted,
-				uint((b.chainParams.StakeDiffWindows-windowPeriod)*alpha))
+			// Weight it exponentially.  Be aware that the sum of the weights
+			// could at some point overflow if alpha or the number of blocks
+			// used is really large.
+			windowAdjusted.Lsh(uint((b.chainParams.StakeDiffWindows -
+				windowPeriod) * alpha))
 
 			// Sum up all the different weights incrementally.
 			weights += 1 << uint64((b.chainParams.StakeDiffWindows-windowPeriod)*
@@ -522,22 +724,18 @@ func (b *BlockChain) calcNextRequiredStakeDifficultyV1(curNode *blockNode) int64
 	}
 
 	// Sum up the weighted window periods.
-	weightedSum := big.NewInt(0)
+	weightedSum := new(fixed64_32)
 	for i := int64(0); i < b.chainParams.StakeDiffWindows; i++ {
-		weightedSum.Add(weightedSum, windowChanges[i])
+		weightedSum.Add(windowChanges[i])
 	}
 
-	// Divide by the sum of all weights.
+	// Divide by the sum of all weights and then multiply by the old stake
+	// diff before converting back to an integer.  Note that the division
+	// must happen first since it affects the rounding of the result.
 	weightsBig := big.NewInt(int64(weights))
-	weightedSumDiv := weightedSum.Div(weightedSum, weightsBig)
-
-	// Multiply by the old stake diff.
 	oldDiffBig := big.NewInt(oldDiff)
-	nextDiffBig := weightedSumDiv.Mul(weightedSumDiv, oldDiffBig)
-
-	// Right shift to restore the original padding (restore non-fixed point).
-	nextDiffBig = nextDiffBig.Rsh(nextDiffBig, 32)
-	nextDiffTicketPool := nextDiffBig.Int64()
+	nextDiffTicketPool := weightedSum.MulDiv(bigOne, weightsBig).
+		MulDiv(oldDiffBig, bigOne).ToInt().Int64()
 
 	// Check to see if we're over the limits for the maximum allowable retarget;
 	// if we are, return the maximum or minimum except in the case that oldDiff
@@ -557,7 +755,7 @@ func (b *BlockChain) calcNextRequiredStakeDifficultyV1(curNode *blockNode) int64
 		int64(b.chainParams.TicketsPerBlock)
 
 	// Regress through all of the previous blocks and store the percent changes
-	// per window period; use bigInts to emulate 64.32 bit fixed point.
+	// per window period using 64.32 bit fixed point.
 	oldNode = curNode
 	windowFreshStake := int64(0)
 	windowPeriod = int64(0)
@@ -574,17 +772,16 @@ func (b *BlockChain) calcNextRequiredStakeDifficultyV1(curNode *blockNode) int64
 				windowFreshStake = 1
 			}
 
-			freshTemp := big.NewInt(windowFreshStake)
-			freshTemp.Lsh(freshTemp, 32) // Add padding
-			targetTemp := big.NewInt(targetForWindow)
-
 			// Get the percentage change.
-			windowAdjusted := freshTemp.Div(freshTemp, targetTemp)
+			targetTemp := big.NewInt(targetForWindow)
+			windowAdjusted := new(fixed64_32).FromInt(windowFreshStake).
+				MulDiv(bigOne, targetTemp)
 
-			// Weight it exponentially. Be aware that this could at some point
-			// overflow if alpha or the number of blocks used is really large.
-			windowAdjusted = windowAdjusted.Lsh(windowAdjusted,
-				uint((b.chainParams.StakeDiffWindows-windowPeriod)*alpha))
+			// Weight it exponentially.  Be aware that the sum of the weights
+			// could at some point overflow if alpha or the number of blocks
+			// used is really large.
+			windowAdjusted.Lsh(uint((b.chainParams.StakeDiffWindows -
+				windowPeriod) * alpha))
 
 			// Sum up all the different weights incrementally.
 			weights += 1 <<
@@ -609,22 +806,18 @@ func (b *BlockChain) calcNextRequiredStakeDifficultyV1(curNode *blockNode) int64
 	}
 
 	// Sum up the weighted window periods.
-	weightedSum = big.NewInt(0)
+	weightedSum = new(fixed64_32)
 	for i := int64(0); i < b.chainParams.StakeDiffWindows; i++ {
-		weightedSum.Add(weightedSum, windowChanges[i])
+		weightedSum.Add(windowChanges[i])
 	}
 
-	// Divide by the sum of all weights.
+	// Divide by the sum of all weights and then multiply by the old stake
+	// diff before converting back to an integer.  Note that the division
+	// must happen first since it affects the rounding of the result.
 	weightsBig = big.NewInt(int64(weights))
-	weightedSumDiv = weightedSum.Div(weightedSum, weightsBig)
-
-	// Multiply by the old stake diff.
 	oldDiffBig = big.NewInt(oldDiff)
-	nextDiffBig = weightedSumDiv.Mul(weightedSumDiv, oldDiffBig)
-
-	// Right shift to restore the original padding (restore non-fixed point).
-	nextDiffBig = nextDiffBig.Rsh(nextDiffBig, 32)
-	nextDiffFreshStake := nextDiffBig.Int64()
+	nextDiffFreshStake := weightedSum.MulDiv(bigOne, weightsBig).
+		MulDiv(oldDiffBig, bigOne).ToInt().Int64()
 
 	// Check to see if we're over the limits for the maximum allowable retarget;
 	// if we are, return the maximum or minimum except in the case that oldDiff
@@ -672,8 +865,11 @@ func (b *BlockChain) calcNextRequiredStakeDifficultyV1(curNode *blockNode) int64
 // reduces the subsidy) and whether or not any of the prior blocks have been
 // invalidated by stakeholders thereby removing the PoW subsidy for them.
 //
+// The provided subsidy cache is used to account for the subsidy withheld by
+// the slow-start subsidy ramp, if any.
+//
 // This function is safe for concurrent access.
-func estimateSupply(params *chaincfg.Params, height int64) int64 {
+func estimateSupply(params *chaincfg.Params, subsidyCache *standalone.SubsidyCache, height int64) int64 {
 	if height <= 0 {
 		return 0
 	}
@@ -698,6 +894,10 @@ func estimateSupply(params *chaincfg.Params, height int64) int64 {
 	// which were also added above.
 	supply -= params.BaseSubsidy * 2
 
+	// Remove the portion of the full subsidy withheld from the blocks in the
+	// slow-start subsidy ramp as of the height.
+	supply -= subsidyCache.CalcSubsidyRampShortfall(height)
+
 	return supply
 }
 
@@ -726,13 +926,28 @@ func (b *BlockChain) sumPurchasedTickets(startNode *blockNode, numToSum int64) i
 // get the current difficulty, previous retarget interval's pool size plus
 // its immature tickets, as well as the current pool size plus immature tickets.
 //
+// The parameters must specify a positive ticket pool size and tickets per block
+// and the estimated supply as of the next height must be positive in order for
+// the stake difficulty to be bounded.  The minimum stake difficulty is returned
+// when that is not the case.
+//
 // This function is safe for concurrent access.
-func calcNextStakeDiffV2(params *chaincfg.Params, nextHeight, curDiff, prevPoolSizeAll, curPoolSizeAll int64) int64 {
+func calcNextStakeDiffV2(params *chaincfg.Params, subsidyCache *standalone.SubsidyCache, nextHeight, curDiff, prevPoolSizeAll, curPoolSizeAll int64) int64 {
 	// Shorter version of various parameter for convenience.
 	votesPerBlock := int64(params.TicketsPerBlock)
 	ticketPoolSize := int64(params.TicketPoolSize)
 	ticketMaturity := int64(params.TicketMaturity)
 
+	// The maximum stake difficulty is relative to the estimated supply and
+	// the target pool size, so there is no meaningful upper bound when
+	// either of them is not positive.  Use the minimum stake difficulty in
+	// that case rather than dividing by zero below.
+	targetPoolSizeAll := votesPerBlock * (ticketPoolSize + ticketMaturity)
+	estimatedSupply := estimateSupply(params, subsidyCache, nextHeight)
+	if ticketPoolSize <= 0 || targetPoolSizeAll <= 0 || estimatedSupply <= 0 {
+		return params.MinimumStakeDiff
+	}
+
 	// nolint: dupword
 	//
 	// Calculate the difficulty by multiplying the old stake difficulty
@@ -766,7 +981,6 @@ func calcNextStakeDiffV2(params *chaincfg.Params, nextHeight, curDiff, prevPoolS
 	//
 	// Further, the Sub parameter must calculate the denominator first using
 	// integer math.
-	targetPoolSizeAll := votesPerBlock * (ticketPoolSize + ticketMaturity)
 	curPoolSizeAllBig := big.NewInt(curPoolSizeAll)
 	nextDiffBig := big.NewInt(curDiff)
 	nextDiffBig.Mul(nextDiffBig, curPoolSizeAllBig)
@@ -782,7 +996,6 @@ func calcNextStakeDiffV2(params *chaincfg.Params, nextHeight, curDiff, prevPoolS
 	// ticketPoolSize parameter already contains the result of
 	// (targetPoolSize / votesPerBlock).
 	nextDiff := nextDiffBig.Int64()
-	estimatedSupply := estimateSupply(params, nextHeight)
 	maximumStakeDiff := estimatedSupply / ticketPoolSize
 	if nextDiff > maximumStakeDiff {
 		nextDiff = maximumStakeDiff
@@ -848,8 +1061,8 @@ func (b *BlockChain) calcNextRequiredStakeDifficultyV2(curNode *blockNode) int64
 
 	// Calculate and return the final next required difficulty.
 	curPoolSizeAll := int64(curNode.poolSize) + immatureTickets
-	return calcNextStakeDiffV2(b.chainParams, nextHeight, curDiff,
-		prevPoolSizeAll, curPoolSizeAll)
+	return calcNextStakeDiffV2(b.chainParams, b.subsidyCache, nextHeight,
+		curDiff, prevPoolSizeAll, curPoolSizeAll)
 }
 
 // calcNextRequiredStakeDifficulty calculates the required stake difficulty for
@@ -989,12 +1202,12 @@ func (b *BlockChain) estimateNextStakeDifficultyV1(curNode *blockNode, ticketsIn
 	targetForTicketPool := int64(b.chainParams.TicketsPerBlock) *
 		int64(b.chainParams.TicketPoolSize)
 
-	// Initialize bigInt slice for the percentage changes for each window period
-	// above or below the target.
-	windowChanges := make([]*big.Int, b.chainParams.StakeDiffWindows)
+	// Initialize fixed point slice for the percentage changes for each window
+	// period above or below the target.
+	windowChanges := make([]*fixed64_32, b.chainParams.StakeDiffWindows)
 
 	// Regress through all of the previous blocks and store the percent changes
-	// per window period; use bigInts to emulate 64.32 bit fixed point.
+	// per window period using 64.32 bit fixed point.
 	oldNode := topNode
 	windowPeriod := int64(0)
 	weights := uint64(0)
@@ -1013,16 +1226,15 @@ func (b *BlockChain) estimateNextStakeDifficultyV1(curNode *blockNode, ticketsIn
 				poolSizeSkew = 1
 			}
 
-			curPoolSizeTemp := big.NewInt(poolSizeSkew)
-			curPoolSizeTemp.Lsh(curPoolSizeTemp, 32) // Add padding
 			targetTemp := big.NewInt(targetForTicketPool)
+			windowAdjusted := new(fixed64_32).FromInt(poolSizeSkew).
+				MulDiv(bigOne, targetTemp)
 
-			windowAdjusted := curPoolSizeTemp.Div(curPoolSizeTemp, targetTemp)
-
-			// Weight it exponentially. Be aware that this could at some point
-			// overflow if alpha or the number of blocks used is really large.
-			windowAdjusted = windowAdjusted.Lsh(windowAdjusted,
-				uint((b.chainParams.StakeDiffWindows-windowPeriod)*alpha))
+			// Weight it exponentially.  Be aware that the sum of the weights
+			// could at some point overflow if alpha or the number of blocks
+			// used is really large.
+			windowAdjusted.Lsh(uint((b.chainParams.StakeDiffWindows -
+				windowPeriod) * alpha))
 
 			// Sum up all the different weights incrementally.
 			weights += 1 << uint64((b.chainParams.StakeDiffWindows-windowPeriod)*
@@ -1047,22 +1259,18 @@ func (b *BlockChain) estimateNextStakeDifficultyV1(curNode *blockNode, ticketsIn
 	}
 
 	// Sum up the weighted window periods.
-	weightedSum := big.NewInt(0)
+	weightedSum := new(fixed64_32)
 	for i := int64(0); i < b.chainParams.StakeDiffWindows; i++ {
-		weightedSum.Add(weightedSum, windowChanges[i])
+		weightedSum.Add(windowChanges[i])
 	}
 
-	// Divide by the sum of all weights.
+	// Divide by the sum of all weights and then multiply by the old stake
+	// diff before converting back to an integer.  Note that the division
+	// must happen first since it affects the rounding of the result.
 	weightsBig := big.NewInt(int64(weights))
-	weightedSumDiv := weightedSum.Div(weightedSum, weightsBig)
-
-	// Multiply by the old stake diff.
 	oldDiffBig := big.NewInt(oldDiff)
-	nextDiffBig := weightedSumDiv.Mul(weightedSumDiv, oldDiffBig)
-
-	// Right shift to restore the original padding (restore non-fixed point).
-	nextDiffBig = nextDiffBig.Rsh(nextDiffBig, 32)
-	nextDiffTicketPool := nextDiffBig.Int64()
+	nextDiffTicketPool := weightedSum.MulDiv(bigOne, weightsBig).
+		MulDiv(oldDiffBig, bigOne).ToInt().Int64()
 
 	// Check to see if we're over the limits for the maximum allowable retarget;
 	// if we are, return the maximum or minimum except in the case that oldDiff
@@ -1082,7 +1290,7 @@ func (b *BlockChain) estimateNextStakeDifficultyV1(curNode *blockNode, ticketsIn
 		int64(b.chainParams.TicketsPerBlock)
 
 	// Regress through all of the previous blocks and store the percent changes
-	// per window period; use bigInts to emulate 64.32 bit fixed point.
+	// per window period using 64.32 bit fixed point.
 	oldNode = topNode
 	windowFreshStake := int64(0)
 	windowPeriod = int64(0)
@@ -1099,17 +1307,16 @@ func (b *BlockChain) estimateNextStakeDifficultyV1(curNode *blockNode, ticketsIn
 				windowFreshStake = 1
 			}
 
-			freshTemp := big.NewInt(windowFreshStake)
-			freshTemp.Lsh(freshTemp, 32) // Add padding
-			targetTemp := big.NewInt(targetForWindow)
-
 			// Get the percentage change.
-			windowAdjusted := freshTemp.Div(freshTemp, targetTemp)
+			targetTemp := big.NewInt(targetForWindow)
+			windowAdjusted := new(fixed64_32).FromInt(windowFreshStake).
+				MulDiv(bigOne, targetTemp)
 
-			// Weight it exponentially. Be aware that this could at some point
-			// overflow if alpha or the number of blocks used is really large.
-			windowAdjusted = windowAdjusted.Lsh(windowAdjusted,
-				uint((b.chainParams.StakeDiffWindows-windowPeriod)*alpha))
+			// Weight it exponentially.  Be aware that the sum of the weights
+			// could at some point overflow if alpha or the number of blocks
+			// used is really large.
+			windowAdjusted.Lsh(uint((b.chainParams.StakeDiffWindows -
+				windowPeriod) * alpha))
 
 			// Sum up all the different weights incrementally.
 			weights += 1 <<
@@ -1134,22 +1341,18 @@ func (b *BlockChain) estimateNextStakeDifficultyV1(curNode *blockNode, ticketsIn
 	}
 
 	// Sum up the weighted window periods.
-	weightedSum = big.NewInt(0)
+	weightedSum = new(fixed64_32)
 	for i := int64(0); i < b.chainParams.StakeDiffWindows; i++ {
-		weightedSum.Add(weightedSum, windowChanges[i])
+		weightedSum.Add(windowChanges[i])
 	}
 
-	// Divide by the sum of all weights.
+	// Divide by the sum of all weights and then multiply by the old stake
+	// diff before converting back to an integer.  Note that the division
+	// must happen first since it affects the rounding of the result.
 	weightsBig = big.NewInt(int64(weights))
-	weightedSumDiv = weightedSum.Div(weightedSum, weightsBig)
-
-	// Multiply by the old stake diff.
 	oldDiffBig = big.NewInt(oldDiff)
-	nextDiffBig = weightedSumDiv.Mul(weightedSumDiv, oldDiffBig)
-
-	// Right shift to restore the original padding (restore non-fixed point).
-	nextDiffBig = nextDiffBig.Rsh(nextDiffBig, 32)
-	nextDiffFreshStake := nextDiffBig.Int64()
+	nextDiffFreshStake := weightedSum.MulDiv(bigOne, weightsBig).
+		MulDiv(oldDiffBig, bigOne).ToInt().Int64()
 
 	// Check to see if we're over the limits for the maximum allowable retarget;
 	// if we are, return the maximum or minimum except in the case that oldDiff
@@ -1331,8 +1534,8 @@ func (b *BlockChain) estimateNextStakeDifficultyV2(curNode *blockNode, newTicket
 	estimatedPoolSizeAll := estimatedPoolSize + remainingImmatureTickets
 
 	// Calculate and return the final estimated difficulty.
-	return calcNextStakeDiffV2(b.chainParams, nextRetargetHeight, curDiff,
-		prevPoolSizeAll, estimatedPoolSizeAll), nil
+	return calcNextStakeDiffV2(b.chainParams, b.subsidyCache,
+		nextRetargetHeight, curDiff, prevPoolSizeAll, estimatedPoolSizeAll), nil
 }
 
 // estimateNextStakeDifficulty estimates the next stake difficulty by pretending
@@ -6,12 +6,14 @@
 package blockchain
 
 import (
+	"math/big"
 	"runtime"
 	"testing"
 	"time"
 
 	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/dcrd/wire"
+	"vigil.network/node/blockchain/standalone"
 )
 
 // TestEstimateSupply ensures the supply estimation function used in the stake
@@ -24,6 +26,7 @@ func TestEstimateSupply(t *testing.T) {
 	baseSubsidy := params.BaseSubsidy
 	reduxInterval := params.SubsidyReductionInterval
 	blockOneSubsidy := params.BlockOneSubsidy()
+	subsidyCache := standalone.NewSubsidyCache(params)
 
 	// intervalSubsidy is a helper function to return the full block subsidy
 	// for the given reduction interval.
@@ -62,7 +65,7 @@ func TestEstimateSupply(t *testing.T) {
 	for _, test := range tests {
 		// Ensure the function to calculate the estimated supply is
 		// working properly.
-		gotSupply := estimateSupply(params, test.height)
+		gotSupply := estimateSupply(params, subsidyCache, test.height)
 		if gotSupply != test.expected {
 			t.Errorf("estimateSupply (height %d): did not get "+
 				"expected supply - got %d, want %d", test.height,
@@ -72,6 +75,93 @@ func TestEstimateSupply(t *testing.T) {
 	}
 }
 
+// TestEstimateSupplyRamp ensures the supply estimation accounts for the reduced
+// subsidy of the blocks in the slow-start subsidy ramp.
+func TestEstimateSupplyRamp(t *testing.T) {
+	t.Parallel()
+
+	// Create mainnet params with and without a subsidy ramp.
+	noRampParams := chaincfg.MainNetParams()
+	params := chaincfg.MainNetParams()
+	params.SubsidyRampBlocks = 100
+	params.SubsidyRampProportion = 25
+	noRampSubsidyCache := standalone.NewSubsidyCache(noRampParams)
+	subsidyCache := standalone.NewSubsidyCache(params)
+
+	// Ensure the estimated supply is reduced by the amount withheld from each
+	// block in the ramp as of every height through a few blocks after the
+	// ramp completes.
+	var shortfall int64
+	for height := int64(0); height < params.SubsidyRampBlocks+5; height++ {
+		if height > 1 {
+			shortfall += params.BaseSubsidy -
+				subsidyCache.CalcBlockSubsidy(height)
+		}
+
+		want := estimateSupply(noRampParams, noRampSubsidyCache, height) -
+			shortfall
+		got := estimateSupply(params, subsidyCache, height)
+		if got != want {
+			t.Fatalf("estimateSupply (height %d): did not get expected "+
+				"supply - got %d, want %d", height, got, want)
+		}
+	}
+}
+
+// TestEstimateSupplyVigil ensures the supply estimation with the Vigil main
+// network parameters produces sane values that only ever increase with the
+// height.
+func TestEstimateSupplyVigil(t *testing.T) {
+	t.Parallel()
+
+	params := &chaincfg.VigilMainNetParams
+	if params.BaseSubsidy <= 0 || params.SubsidyReductionInterval <= 0 ||
+		params.MulSubsidy <= 0 || params.DivSubsidy <= 0 {
+
+		t.Fatalf("invalid subsidy params: base %d, interval %d, mul %d, "+
+			"div %d", params.BaseSubsidy, params.SubsidyReductionInterval,
+			params.MulSubsidy, params.DivSubsidy)
+	}
+	blockOneSubsidy := params.BlockOneSubsidy()
+	if blockOneSubsidy <= 0 {
+		t.Fatalf("invalid block one subsidy %d", blockOneSubsidy)
+	}
+	subsidyCache := standalone.NewSubsidyCache(params)
+
+	// Ensure the estimated supply matches the block one subsidy and the base
+	// subsidy of the blocks after it prior to the first reduction.
+	reduxInterval := params.SubsidyReductionInterval
+	for _, height := range []int64{1, 2, 3, reduxInterval - 1} {
+		want := blockOneSubsidy + params.BaseSubsidy*(height-1)
+		if got := estimateSupply(params, subsidyCache, height); got != want {
+			t.Fatalf("estimateSupply (height %d): did not get expected "+
+				"supply - got %d, want %d", height, got, want)
+		}
+	}
+
+	// Ensure the estimated supply strictly increases with the height across
+	// several reduction intervals and never exceeds the block one subsidy plus
+	// the sum of the geometric series of the full subsidy of every interval.
+	maxSupply := blockOneSubsidy + params.BaseSubsidy*reduxInterval*
+		params.DivSubsidy/(params.DivSubsidy-params.MulSubsidy)
+	prevSupply := estimateSupply(params, subsidyCache, 0)
+	heights := []int64{1, 2, 100, reduxInterval - 1, reduxInterval,
+		reduxInterval + 1, reduxInterval * 2, reduxInterval * 10,
+		reduxInterval * 100, reduxInterval * 500}
+	for _, height := range heights {
+		supply := estimateSupply(params, subsidyCache, height)
+		if supply <= prevSupply {
+			t.Fatalf("estimateSupply (height %d): supply %d is not more "+
+				"than previous supply %d", height, supply, prevSupply)
+		}
+		if supply > maxSupply {
+			t.Fatalf("estimateSupply (height %d): supply %d exceeds max %d",
+				height, supply, maxSupply)
+		}
+		prevSupply = supply
+	}
+}
+
 // assertStakeDiffParamsMainNet ensure the passed params have the values used in
 // the tests related to mainnet stake difficulty calculation.
 func assertStakeDiffParamsMainNet(t *testing.T, params *chaincfg.Params) {
@@ -431,6 +521,57 @@ nextTest:
 	}
 }
 
+// TestCalcNextStakeDiffV2Bounds ensures the stake diff calculation for the
+// algorithm defined by DCP0001 does not panic and returns the minimum stake
+// difficulty when the parameters or estimated supply do not allow the maximum
+// stake difficulty to be determined.
+func TestCalcNextStakeDiffV2Bounds(t *testing.T) {
+	t.Parallel()
+
+	mainNetParams := chaincfg.MainNetParams()
+	minStakeDiff := mainNetParams.MinimumStakeDiff
+
+	// Create a copy of the main network parameters with zeroed stake
+	// parameters.
+	zeroedParams := *mainNetParams
+	zeroedParams.TicketPoolSize = 0
+	zeroedParams.TicketsPerBlock = 0
+	zeroedParams.TicketMaturity = 0
+
+	// Create a copy of the main network parameters with only the ticket pool
+	// size zeroed.
+	zeroPoolParams := *mainNetParams
+	zeroPoolParams.TicketPoolSize = 0
+
+	tests := []struct {
+		name       string
+		params     *chaincfg.Params
+		nextHeight int64
+	}{{
+		name:       "zeroed stake params",
+		params:     &zeroedParams,
+		nextHeight: 4096,
+	}, {
+		name:       "zero ticket pool size",
+		params:     &zeroPoolParams,
+		nextHeight: 4096,
+	}, {
+		name:       "no estimated supply",
+		params:     mainNetParams,
+		nextHeight: 0,
+	}}
+
+	for _, test := range tests {
+		subsidyCache := standalone.NewSubsidyCache(test.params)
+		gotDiff := calcNextStakeDiffV2(test.params, subsidyCache,
+			test.nextHeight, minStakeDiff*10, 40960, 40960)
+		if gotDiff != minStakeDiff {
+			t.Errorf("%q: unexpected stake diff -- got %d, want %d",
+				test.name, gotDiff, minStakeDiff)
+		}
+	}
+}
+
 // TestEstimateNextStakeDiffV2 ensures the function that estimates the stake
 // diff calculation for the algorithm defined by DCP0001 works as expected.
 func TestEstimateNextStakeDiffV2(t *testing.T) {
@@ -1135,3 +1276,819 @@ func TestMinDifficultyReduction(t *testing.T) {
 		}
 	}
 }
+
+// TestVigilTestNetMinDifficultyReduction ensures the required difficulty on the
+// Vigil test network is reduced to the minimum once more than the minimum
+// difficulty reduction time has elapsed without mining a block and reverts to
+// the last real difficulty otherwise.
+func TestVigilTestNetMinDifficultyReduction(t *testing.T) {
+	params := chaincfg.VigilTestNetParams
+	if !params.ReduceMinDifficulty {
+		t.Fatal("vigil test network does not reduce the min difficulty")
+	}
+	const realBits = 0x1d00ffff
+	minBits := params.PowLimitBits
+	reductionTime := params.MinDiffReductionTime
+
+	// Create a synthetic chain with blocks at a real difficulty that are
+	// mined at the target time per block.
+	bc := newFakeChain(&params)
+	node := bc.bestChain.Tip()
+	blockTime := time.Unix(node.timestamp, 0)
+	for i := 0; i < 10; i++ {
+		blockTime = blockTime.Add(params.TargetTimePerBlock)
+		node = newFakeNode(node, 1, 1, realBits, blockTime)
+		bc.index.AddNode(node)
+		bc.bestChain.SetTip(node)
+	}
+
+	// Ensure the real difficulty is required up to and including the
+	// reduction time and the minimum difficulty is required after it.
+	got := bc.calcNextBlake256Diff(node, blockTime.Add(reductionTime))
+	if got != realBits {
+		t.Fatalf("unexpected difficulty at reduction time -- got %08x, "+
+			"want %08x", got, uint32(realBits))
+	}
+	afterReduction := blockTime.Add(reductionTime + time.Second)
+	got = bc.calcNextBlake256Diff(node, afterReduction)
+	if got != minBits {
+		t.Fatalf("unexpected difficulty after reduction time -- got %08x, "+
+			"want %08x", got, minBits)
+	}
+
+	// Ensure the last real difficulty is required again for a block mined
+	// within the desired timeframe after a minimum difficulty block.
+	node = newFakeNode(node, 1, 1, minBits, afterReduction)
+	bc.index.AddNode(node)
+	bc.bestChain.SetTip(node)
+	nextTime := afterReduction.Add(params.TargetTimePerBlock)
+	got = bc.calcNextBlake256Diff(node, nextTime)
+	if got != realBits {
+		t.Fatalf("unexpected difficulty after min difficulty block -- got "+
+			"%08x, want %08x", got, uint32(realBits))
+	}
+
+	// Ensure the difficulty is not reduced when the network does not allow
+	// it.
+	params.ReduceMinDifficulty = false
+	got = bc.calcNextBlake256Diff(node, nextTime.Add(reductionTime*2))
+	if got != minBits {
+		t.Fatalf("unexpected difficulty without reduction -- got %08x, "+
+			"want %08x", got, minBits)
+	}
+}
+
+// TestMinDifficultyReductionTestNet3 ensures the special minimum difficulty
+// reduction rule is only applied on version 3 of the test network prior to the
+// max diff activation height.
+func TestMinDifficultyReductionTestNet3(t *testing.T) {
+	params := chaincfg.TestNet3Params()
+	if !params.ReduceMinDifficulty {
+		t.Fatal("test network does not reduce the min difficulty")
+	}
+	const realBits = 0x1d00ffff
+	minBits := params.PowLimitBits
+	reductionTime := params.MinDiffReductionTime
+
+	tests := []struct {
+		name       string // test description
+		nextHeight int64  // height of the block to calculate the diff for
+		elapsed    bool   // whether the reduction time has elapsed
+		want       uint32 // expected difficulty
+	}{{
+		name:       "before activation, reduction time not elapsed",
+		nextHeight: testNet3MaxDiffActivationHeight - 1,
+		elapsed:    false,
+		want:       realBits,
+	}, {
+		name:       "before activation, reduction time elapsed",
+		nextHeight: testNet3MaxDiffActivationHeight - 1,
+		elapsed:    true,
+		want:       minBits,
+	}, {
+		name:       "after activation, reduction time not elapsed",
+		nextHeight: testNet3MaxDiffActivationHeight + 1,
+		elapsed:    false,
+		want:       realBits,
+	}, {
+		name:       "after activation, reduction time elapsed",
+		nextHeight: testNet3MaxDiffActivationHeight + 1,
+		elapsed:    true,
+		want:       realBits,
+	}}
+
+	for _, test := range tests {
+		// Ensure the test is not at a retarget boundary since the rule only
+		// applies between them.
+		if test.nextHeight%params.WorkDiffWindowSize == 0 {
+			t.Fatalf("%q: height %d is a retarget boundary", test.name,
+				test.nextHeight)
+		}
+
+		// Create a node with the real difficulty at the height prior to the
+		// one under test.
+		bc := newFakeChain(params)
+		genesis := bc.bestChain.Genesis()
+		prevTime := time.Unix(genesis.timestamp, 0).Add(time.Hour)
+		prevNode := newFakeNode(genesis, 1, 1, realBits, prevTime)
+		prevNode.height = test.nextHeight - 1
+
+		blockTime := prevTime.Add(reductionTime)
+		if test.elapsed {
+			blockTime = blockTime.Add(time.Second)
+		}
+		got := bc.calcNextBlake256Diff(prevNode, blockTime)
+		if got != test.want {
+			t.Errorf("%q: unexpected difficulty -- got %08x, want %08x",
+				test.name, got, test.want)
+		}
+	}
+}
+
+// TestTestNetDifficultyCap ensures the required difficulty on test networks
+// that impose a maximum difficulty never exceeds it for both the blake256 and
+// the ASERT difficulty algorithms while it is allowed to exceed it otherwise.
+func TestTestNetDifficultyCap(t *testing.T) {
+	params := chaincfg.VigilTestNetParams
+	params.ReduceMinDifficulty = false
+	params.WorkDiffV2HalfLifeSecs = 43200
+	capTarget, capBits := testNetDifficultyCap(&params)
+	if capTarget == nil {
+		t.Fatal("vigil test network does not impose a max difficulty")
+	}
+	params.WorkDiffV2KawPowStartBits = capBits
+
+	// Create a synthetic chain at the maximum difficulty with blocks that are
+	// mined much faster than the target time per block so the difficulty
+	// would otherwise increase at the next retarget boundary.
+	bc := newFakeChain(&params)
+	genesis := bc.bestChain.Genesis()
+	node := genesis
+	blockTime := time.Unix(node.timestamp, 0)
+	for i := int64(1); i < params.WorkDiffWindowSize*2; i++ {
+		blockTime = blockTime.Add(time.Second)
+		node = newFakeNode(node, 1, 1, capBits, blockTime)
+		bc.index.AddNode(node)
+		bc.bestChain.SetTip(node)
+	}
+	nextTime := blockTime.Add(time.Second)
+
+	// Ensure the difficulty is capped for both algorithms.
+	if got := bc.calcNextBlake256Diff(node, nextTime); got != capBits {
+		t.Fatalf("unexpected blake256 difficulty -- got %08x, want %08x",
+			got, capBits)
+	}
+	if got := bc.calcNextBlake3DiffFromAnchor(node, genesis); got != capBits {
+		t.Fatalf("unexpected ASERT difficulty -- got %08x, want %08x", got,
+			capBits)
+	}
+
+	// Ensure the difficulty exceeds the cap for both algorithms without it,
+	// meaning the target is lower, to prove the cap is actually applied
+	// above.
+	bc.minTestNetTarget, bc.minTestNetDiffBits = nil, 0
+	got := bc.calcNextBlake256Diff(node, nextTime)
+	if standalone.CompactToBig(got).Cmp(capTarget) >= 0 {
+		t.Fatalf("uncapped blake256 difficulty %08x does not exceed the "+
+			"cap %08x", got, capBits)
+	}
+	got = bc.calcNextBlake3DiffFromAnchor(node, genesis)
+	if standalone.CompactToBig(got).Cmp(capTarget) >= 0 {
+		t.Fatalf("uncapped ASERT difficulty %08x does not exceed the cap "+
+			"%08x", got, capBits)
+	}
+
+	// Ensure networks that do not impose a maximum difficulty do not have a
+	// cap and that a misconfigured cap is limited to the proof of work limit.
+	if target, _ := testNetDifficultyCap(chaincfg.MainNetParams()); target != nil {
+		t.Fatalf("unexpected max difficulty target %064x for mainnet", target)
+	}
+	params.MinTestNetTarget = new(big.Int).Lsh(params.PowLimit, 1)
+	target, bits := testNetDifficultyCap(&params)
+	if target.Cmp(params.PowLimit) != 0 || bits != params.PowLimitBits {
+		t.Fatalf("unexpected max difficulty target %064x (bits %08x) for "+
+			"target above pow limit", target, bits)
+	}
+}
+
+// TestCalcNextBlake256DiffWeightOverflow ensures the exponential weights of
+// the windows used in the blake256 difficulty calculation are limited such that
+// their sum does not overflow when the parameters would otherwise cause it to.
+func TestCalcNextBlake256DiffWeightOverflow(t *testing.T) {
+	// Create a synthetic chain that ends just prior to a retarget boundary
+	// after enough blocks for every window to be used in the calculation with
+	// alternating windows of blocks that are mined faster and slower than the
+	// target time per block.
+	params := cloneParams(chaincfg.MainNetParams())
+	const bits = 0x1b01ffff
+	bc := newFakeChain(params)
+	node := bc.bestChain.Genesis()
+	blockTime := time.Unix(node.timestamp, 0)
+	numNodes := params.WorkDiffWindowSize*params.WorkDiffWindows - 1
+	for i := int64(1); i <= numNodes; i++ {
+		blockSpacing := params.TargetTimePerBlock / 2
+		if (i/params.WorkDiffWindowSize)%2 != 0 {
+			blockSpacing = params.TargetTimePerBlock * 3 / 2
+		}
+		blockTime = blockTime.Add(blockSpacing)
+		node = newFakeNode(node, 1, 1, bits, blockTime)
+		bc.index.AddNode(node)
+		bc.bestChain.SetTip(node)
+	}
+	nextTime := blockTime.Add(params.TargetTimePerBlock)
+
+	// Calculate the difficulty with the max alpha that does not overflow and
+	// ensure it is the same as an alpha that would otherwise overflow.
+	params.WorkDiffAlpha = maxWorkDiffWeightShift / params.WorkDiffWindows
+	want := bc.calcNextBlake256Diff(node, nextTime)
+	params.WorkDiffAlpha++
+	got := bc.calcNextBlake256Diff(node, nextTime)
+	if got != want {
+		t.Fatalf("unexpected difficulty with overflowing alpha %d -- got "+
+			"%08x, want %08x", params.WorkDiffAlpha, got, want)
+	}
+}
+
+// TestGetDifficultyRatio ensures the proof-of-work difficulty ratio is
+// calculated relative to the compact proof of work limit and that targets that
+// are not valid difficulties result in a ratio of zero.
+func TestGetDifficultyRatio(t *testing.T) {
+	params := chaincfg.MainNetParams()
+	tests := []struct {
+		name string  // test description
+		bits uint32  // compact target difficulty
+		want float64 // expected difficulty ratio
+	}{{
+		name: "pow limit",
+		bits: params.PowLimitBits,
+		want: 1,
+	}, {
+		name: "exact multiple of the pow limit",
+		bits: 0x1c00ffff,
+		want: 256,
+	}, {
+		name: "rounded to 8 decimal places",
+		bits: 0x1b01ffff,
+		want: 32767.74999809,
+	}, {
+		name: "high difficulty",
+		bits: 0x181f2f79,
+		want: 35256672611.3862,
+	}, {
+		name: "zero target",
+		bits: 0,
+		want: 0,
+	}, {
+		name: "zero mantissa",
+		bits: 0x1d000000,
+		want: 0,
+	}, {
+		name: "negative target",
+		bits: 0x1d80ffff,
+		want: 0,
+	}}
+
+	for _, test := range tests {
+		got := GetDifficultyRatio(test.bits, params)
+		if got != test.want {
+			t.Errorf("%q: unexpected difficulty ratio -- got %v, want %v",
+				test.name, got, test.want)
+		}
+	}
+}
+
+// TestFindPrevTestNetDifficulty ensures finding the difficulty of the most
+// recent block that did not have the special testnet minimum difficulty rule
+// applied works as expected, including when the search spans a difficulty
+// window boundary and a boundary of a full set of difficulty windows.
+func TestFindPrevTestNetDifficulty(t *testing.T) {
+	params := chaincfg.RegNetParams()
+	params.ReduceMinDifficulty = true
+	params.WorkDiffWindowSize = 144
+	params.WorkDiffWindows = 20
+	blocksPerRetarget := params.WorkDiffWindowSize * params.WorkDiffWindows
+	minBits := params.PowLimitBits
+	const realBits = 0x1e00ffff
+	const retargetBits = 0x1d00ffff
+
+	tests := []struct {
+		name     string
+		numNodes int64                     // num nodes to create after genesis
+		bits     func(height int64) uint32 // bits for node at given height
+		want     uint32                    // expected difficulty
+	}{{
+		name:     "all min difficulty spanning retarget boundary",
+		numNodes: blocksPerRetarget + 6,
+		bits:     func(height int64) uint32 { return minBits },
+		want:     minBits,
+	}, {
+		name:     "min difficulty prior to first retarget boundary",
+		numNodes: blocksPerRetarget - 1,
+		bits: func(height int64) uint32 {
+			if height < 10 {
+				return realBits
+			}
+			return minBits
+		},
+		want: realBits,
+	}, {
+		name:     "min difficulty spanning difficulty window boundary",
+		numNodes: params.WorkDiffWindowSize + 6,
+		bits: func(height int64) uint32 {
+			if height < 10 {
+				return realBits
+			}
+			return minBits
+		},
+		want: realBits,
+	}, {
+		name:     "retarget boundary with real difficulty",
+		numNodes: blocksPerRetarget + 6,
+		bits: func(height int64) uint32 {
+			switch {
+			case height < blocksPerRetarget:
+				return realBits
+			case height == blocksPerRetarget:
+				return retargetBits
+			}
+			return minBits
+		},
+		want: retargetBits,
+	}, {
+		name:     "retarget boundary that retargeted to min difficulty",
+		numNodes: blocksPerRetarget + 6,
+		bits: func(height int64) uint32 {
+			if height < blocksPerRetarget {
+				return realBits
+			}
+			return minBits
+		},
+		want: minBits,
+	}, {
+		name:     "real difficulty after retarget boundary",
+		numNodes: blocksPerRetarget + 6,
+		bits: func(height int64) uint32 {
+			if height <= blocksPerRetarget+2 {
+				return realBits
+			}
+			return minBits
+		},
+		want: realBits,
+	}}
+
+	for _, test := range tests {
+		bc := newFakeChain(params)
+		node := bc.bestChain.Tip()
+		blockTime := time.Unix(node.timestamp, 0)
+		for i := int64(1); i <= test.numNodes; i++ {
+			blockTime = blockTime.Add(params.TargetTimePerBlock)
+			node = newFakeNode(node, 1, 1, test.bits(i), blockTime)
+			bc.index.AddNode(node)
+			bc.bestChain.SetTip(node)
+		}
+
+		got := bc.findPrevTestNetDifficulty(node)
+		if got != test.want {
+			t.Errorf("%q: unexpected difficulty -- got %08x, want %08x",
+				test.name, got, test.want)
+		}
+	}
+
+	// Ensure the minimum difficulty is returned when there is no start node.
+	bc := newFakeChain(params)
+	if got := bc.findPrevTestNetDifficulty(nil); got != minBits {
+		t.Errorf("unexpected difficulty for nil start node -- got %08x, "+
+			"want %08x", got, minBits)
+	}
+}
+
+// TestDiffWindowStats ensures the statistics about the progress of the current
+// difficulty retarget window and the implied retarget direction are calculated
+// as expected for synthetic chains.
+func TestDiffWindowStats(t *testing.T) {
+	params := chaincfg.RegNetParams()
+	params.TargetTimePerBlock = time.Minute * 2
+	params.WorkDiffWindowSize = 10
+
+	tests := []struct {
+		name          string
+		numBlocks     int64
+		blockInterval time.Duration
+		wantBlocks    int64
+		wantElapsed   time.Duration
+		wantExpected  time.Duration
+		wantDirection string
+	}{{
+		name:          "blocks faster than target",
+		numBlocks:     15,
+		blockInterval: time.Minute,
+		wantBlocks:    5,
+		wantElapsed:   time.Minute * 5,
+		wantExpected:  time.Minute * 10,
+		wantDirection: "increase",
+	}, {
+		name:          "blocks slower than target",
+		numBlocks:     17,
+		blockInterval: time.Minute * 3,
+		wantBlocks:    7,
+		wantElapsed:   time.Minute * 21,
+		wantExpected:  time.Minute * 14,
+		wantDirection: "decrease",
+	}, {
+		name:          "blocks on target",
+		numBlocks:     12,
+		blockInterval: time.Minute * 2,
+		wantBlocks:    2,
+		wantElapsed:   time.Minute * 4,
+		wantExpected:  time.Minute * 4,
+		wantDirection: "unchanged",
+	}, {
+		name:          "tip starts window",
+		numBlocks:     20,
+		blockInterval: time.Minute,
+		wantBlocks:    0,
+		wantElapsed:   0,
+		wantExpected:  0,
+		wantDirection: "unchanged",
+	}}
+
+	for _, test := range tests {
+		bc := newFakeChain(params)
+		node := bc.bestChain.Tip()
+		blockTime := time.Unix(node.timestamp, 0)
+		for i := int64(0); i < test.numBlocks; i++ {
+			blockTime = blockTime.Add(test.blockInterval)
+			node = newFakeNode(node, 1, 1, params.PowLimitBits, blockTime)
+			bc.index.AddNode(node)
+			bc.bestChain.SetTip(node)
+		}
+
+		stats := bc.DiffWindowStats()
+		if stats.WindowSize != params.WorkDiffWindowSize {
+			t.Errorf("%q: unexpected window size -- got %d, want %d",
+				test.name, stats.WindowSize, params.WorkDiffWindowSize)
+		}
+		if stats.BlocksIntoWindow != test.wantBlocks {
+			t.Errorf("%q: unexpected blocks into window -- got %d, want %d",
+				test.name, stats.BlocksIntoWindow, test.wantBlocks)
+		}
+		if stats.ElapsedTime != test.wantElapsed {
+			t.Errorf("%q: unexpected elapsed time -- got %v, want %v",
+				test.name, stats.ElapsedTime, test.wantElapsed)
+		}
+		if stats.ExpectedTime != test.wantExpected {
+			t.Errorf("%q: unexpected expected time -- got %v, want %v",
+				test.name, stats.ExpectedTime, test.wantExpected)
+		}
+		if dir := stats.Direction(); dir != test.wantDirection {
+			t.Errorf("%q: unexpected direction -- got %q, want %q",
+				test.name, dir, test.wantDirection)
+		}
+	}
+}
+
+// TestSimulateDifficulty ensures simulating the required difficulty over a
+// hypothetical sequence of blocks produces the same results as the difficulty
+// calculation for an equivalent chain and that a sequence of blocks on target
+// holds the difficulty constant.
+func TestSimulateDifficulty(t *testing.T) {
+	// Create chain params based on regnet params, but set the fields related to
+	// proof-of-work difficulty to specific values expected by the tests.
+	params := chaincfg.RegNetParams()
+	params.ReduceMinDifficulty = false
+	params.TargetTimePerBlock = time.Second * 150
+	params.WorkDiffAlpha = 1
+	params.WorkDiffWindowSize = 8
+	params.WorkDiffWindows = 4
+	params.TargetTimespan = params.TargetTimePerBlock *
+		time.Duration(params.WorkDiffWindowSize)
+	params.RetargetAdjustmentFactor = 4
+
+	// extendChain extends the best chain of the provided fake chain with the
+	// given number of blocks spaced by the provided interval and using the
+	// required difficulty for each block.
+	extendChain := func(bc *BlockChain, numBlocks int64, interval time.Duration) {
+		t.Helper()
+
+		node := bc.bestChain.Tip()
+		for i := int64(0); i < numBlocks; i++ {
+			blockTime := time.Unix(node.timestamp, 0).Add(interval)
+			diff, err := bc.calcNextRequiredDifficulty(node, blockTime)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			node = newFakeNode(node, 1, 1, diff, blockTime)
+			bc.index.AddNode(node)
+			bc.bestChain.SetTip(node)
+		}
+	}
+
+	// Create a chain that has a difficulty above the minimum by mining a
+	// couple of windows of fast blocks followed by enough windows of blocks on
+	// target to ensure only the on-target windows are considered.
+	windowSize := params.WorkDiffWindowSize
+	bc := newFakeChain(params)
+	extendChain(bc, windowSize*2, time.Second)
+	extendChain(bc, windowSize*(params.WorkDiffWindows+1),
+		params.TargetTimePerBlock)
+	tip := bc.bestChain.Tip()
+	if tip.bits == params.PowLimitBits {
+		t.Fatalf("test chain difficulty unexpectedly at the minimum")
+	}
+
+	// Ensure simulating several windows of blocks on target holds the
+	// difficulty constant.
+	targetSecs := int64(params.TargetTimePerBlock / time.Second)
+	onTarget := make([]int64, windowSize*3)
+	for i := range onTarget {
+		onTarget[i] = targetSecs
+	}
+	results, err := bc.SimulateDifficulty(onTarget)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(results) != len(onTarget) {
+		t.Fatalf("unexpected number of results -- got %d, want %d",
+			len(results), len(onTarget))
+	}
+	for i, result := range results {
+		if result.Bits != tip.bits {
+			t.Fatalf("unexpected difficulty for on target block %d -- got "+
+				"%08x, want %08x", i, result.Bits, tip.bits)
+		}
+		wantHeight := tip.height + int64(i) + 1
+		if result.Height != wantHeight {
+			t.Fatalf("unexpected height for on target block %d -- got %d, "+
+				"want %d", i, result.Height, wantHeight)
+		}
+		wantTimestamp := tip.timestamp + targetSecs*(int64(i)+1)
+		if result.Timestamp != wantTimestamp {
+			t.Fatalf("unexpected timestamp for on target block %d -- got "+
+				"%d, want %d", i, result.Timestamp, wantTimestamp)
+		}
+	}
+
+	// Ensure simulating a schedule of fast blocks results in the same
+	// difficulties as actually extending the chain with those blocks.
+	fast := make([]int64, windowSize*3)
+	for i := range fast {
+		fast[i] = targetSecs / 3
+	}
+	results, err = bc.SimulateDifficulty(fast)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if bc.bestChain.Tip() != tip {
+		t.Fatal("simulating difficulty modified the best chain")
+	}
+	extendChain(bc, int64(len(fast)), time.Second*time.Duration(targetSecs/3))
+	for i, result := range results {
+		node := bc.bestChain.NodeByHeight(result.Height)
+		if result.Bits != node.bits {
+			t.Fatalf("unexpected difficulty for fast block %d -- got %08x, "+
+				"want %08x", i, result.Bits, node.bits)
+		}
+	}
+	if results[len(results)-1].Bits == tip.bits {
+		t.Fatal("difficulty did not change for fast blocks")
+	}
+}
+
+// TestKawPowActivationDifficulty ensures the required difficulty for the first
+// block after the activation of the agenda that switches proof of work to
+// KawPoW is reset to the configured KawPoW starting difficulty as opposed to
+// carrying over the difficulty of the blocks prior to the switch.
+func TestKawPowActivationDifficulty(t *testing.T) {
+	// Use starting difficulties that differ from both each other and the
+	// difficulty of the blocks prior to activation so that a carried over or
+	// incorrectly selected difficulty is detected.
+	params := cloneParams(chaincfg.RegNetParams())
+	params.WorkDiffV2Blake3StartBits = 0x1f00ffff
+	params.WorkDiffV2KawPowStartBits = 0x1e7fffff
+	const preSwitchBits = 0x1d00ffff
+
+	// Create a synthetic chain that extends to the final block of a rule
+	// change activation interval past stake validation height and treat that
+	// block as the final block prior to activation by caching the state of
+	// the agenda in the block nodes.
+	rcai := int64(params.RuleChangeActivationInterval)
+	svh := params.StakeValidationHeight
+	anchorHeight := calcWantHeight(svh, rcai, svh+2*rcai)
+	bc := newFakeChain(params)
+	node := bc.bestChain.Tip()
+	blockTime := time.Unix(node.timestamp, 0)
+	for node.height < anchorHeight {
+		blockTime = blockTime.Add(params.TargetTimePerBlock)
+		node = newFakeNode(node, 1, 1, preSwitchBits, blockTime)
+		bc.index.AddNode(node)
+	}
+	bc.bestChain.SetTip(node)
+	anchor := node
+	for n := anchor; n != nil; n = n.parent {
+		n.nextPowAlgo = powAlgoBlake256
+	}
+	anchor.nextPowAlgo = powAlgoBlake3
+
+	// Ensure the difficulty for the first block after activation is reset to
+	// the KawPoW starting difficulty.
+	targetSecs := int64(params.TargetTimePerBlock.Seconds())
+	want := standalone.CalcASERTDiff(params.WorkDiffV2KawPowStartBits,
+		params.PowLimit, targetSecs, 0, 0, params.WorkDiffV2HalfLifeSecs)
+	nextBlockTime := blockTime.Add(params.TargetTimePerBlock)
+	got, err := bc.calcNextRequiredDifficulty(anchor, nextBlockTime)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != want {
+		t.Fatalf("unexpected activation difficulty -- got %08x, want %08x",
+			got, want)
+	}
+	if got == preSwitchBits {
+		t.Fatalf("activation difficulty %08x carried over pre-switch bits",
+			got)
+	}
+	blake3Diff := standalone.CalcASERTDiff(params.WorkDiffV2Blake3StartBits,
+		params.PowLimit, targetSecs, 0, 0, params.WorkDiffV2HalfLifeSecs)
+	if got == blake3Diff {
+		t.Fatalf("activation difficulty %08x is based on blake3 start bits",
+			got)
+	}
+
+	// Ensure the difficulty of descendants continues to be calculated
+	// relative to the KawPoW starting difficulty.
+	blockTime = nextBlockTime
+	next := newFakeNode(anchor, 1, 1, got, blockTime)
+	next.nextPowAlgo = powAlgoBlake3
+	bc.index.AddNode(next)
+	bc.bestChain.SetTip(next)
+	want = standalone.CalcASERTDiff(params.WorkDiffV2KawPowStartBits,
+		params.PowLimit, targetSecs, next.timestamp-anchor.timestamp, 1,
+		params.WorkDiffV2HalfLifeSecs)
+	got, err = bc.calcNextRequiredDifficulty(next,
+		blockTime.Add(params.TargetTimePerBlock))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != want {
+		t.Fatalf("unexpected post-activation difficulty -- got %08x, want "+
+			"%08x", got, want)
+	}
+}
+
+// DiffMismatch describes a block for which the blake256 windowed difficulty
+// algorithm and the KawPoW ASERT difficulty algorithm adjust the required
+// difficulty in different directions relative to the difficulty each of them
+// required for the previous block.
+type DiffMismatch struct {
+	// Height is the height of the block the difficulties are required for.
+	Height int64
+
+	// Blake256Bits and KawPowBits are the difficulties in compact form
+	// required by the blake256 and KawPoW algorithms, respectively.
+	Blake256Bits uint32
+	KawPowBits   uint32
+
+	// Blake256Change and KawPowChange are the directions each algorithm
+	// adjusted the difficulty.  A value of 1 means the difficulty increased,
+	// -1 means it decreased, and 0 means it was unchanged.
+	Blake256Change int
+	KawPowChange   int
+}
+
+// compareDifficultyAlgos runs both the blake256 windowed difficulty algorithm
+// and the KawPoW ASERT difficulty algorithm over the provided chain of nodes
+// and returns the blocks for which they adjust the difficulty in different
+// directions.
+//
+// The first node is treated as the ASERT anchor and the KawPoW starting
+// difficulty in the chain parameters is the initial ASERT difficulty, while
+// the blake256 algorithm starts from the difficulty of the first node.
+func (b *BlockChain) compareDifficultyAlgos(nodes []*blockNode) []DiffMismatch {
+	// change returns the direction the difficulty changed from the previous
+	// bits to the next bits.  Note that smaller targets are higher
+	// difficulties.
+	change := func(prevBits, nextBits uint32) int {
+		prevTarget := standalone.CompactToBig(prevBits)
+		nextTarget := standalone.CompactToBig(nextBits)
+		return prevTarget.Cmp(nextTarget)
+	}
+
+	var mismatches []DiffMismatch
+	anchor := nodes[0]
+	prevBlake256Bits := anchor.bits
+	prevKawPowBits := b.chainParams.WorkDiffV2KawPowStartBits
+	for _, node := range nodes {
+		blockTime := time.Unix(node.timestamp, 0).Add(
+			b.chainParams.TargetTimePerBlock)
+		blake256Bits := b.calcNextBlake256Diff(node, blockTime)
+		kawPowBits := b.calcNextBlake3DiffFromAnchor(node, anchor)
+		blake256Change := change(prevBlake256Bits, blake256Bits)
+		kawPowChange := change(prevKawPowBits, kawPowBits)
+		if blake256Change != kawPowChange {
+			mismatches = append(mismatches, DiffMismatch{
+				Height:         node.height + 1,
+				Blake256Bits:   blake256Bits,
+				KawPowBits:     kawPowBits,
+				Blake256Change: blake256Change,
+				KawPowChange:   kawPowChange,
+			})
+		}
+		prevBlake256Bits, prevKawPowBits = blake256Bits, kawPowBits
+	}
+	return mismatches
+}
+
+// TestCompareDifficultyAlgos ensures the blake256 windowed difficulty algorithm
+// and the KawPoW ASERT difficulty algorithm both hold the difficulty steady for
+// a schedule of blocks on target and documents their different responsiveness
+// to blocks that are slower than the target.
+func TestCompareDifficultyAlgos(t *testing.T) {
+	// Create chain params based on regnet params, but set the fields related to
+	// proof-of-work difficulty to specific values expected by the tests.
+	params := chaincfg.RegNetParams()
+	params.ReduceMinDifficulty = false
+	params.TargetTimePerBlock = time.Second * 150
+	params.WorkDiffAlpha = 1
+	params.WorkDiffWindowSize = 8
+	params.WorkDiffWindows = 4
+	params.TargetTimespan = params.TargetTimePerBlock *
+		time.Duration(params.WorkDiffWindowSize)
+	params.RetargetAdjustmentFactor = 4
+	targetSecs := int64(params.TargetTimePerBlock / time.Second)
+	params.WorkDiffV2HalfLifeSecs = targetSecs * 12
+
+	// extendChain extends the best chain of the provided fake chain with the
+	// given number of blocks spaced by the provided interval and using the
+	// blake256 required difficulty for each block.  It returns the new nodes.
+	extendChain := func(bc *BlockChain, numBlocks int64, interval time.Duration) []*blockNode {
+		t.Helper()
+
+		var nodes []*blockNode
+		node := bc.bestChain.Tip()
+		for i := int64(0); i < numBlocks; i++ {
+			blockTime := time.Unix(node.timestamp, 0).Add(interval)
+			diff := bc.calcNextBlake256Diff(node, blockTime)
+			node = newFakeNode(node, 1, 1, diff, blockTime)
+			bc.index.AddNode(node)
+			bc.bestChain.SetTip(node)
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
+	// Create a chain that has a difficulty above the minimum by mining a
+	// couple of windows of fast blocks followed by enough windows of blocks on
+	// target to ensure only the on-target windows are considered.  Use the
+	// resulting difficulty as the KawPoW starting difficulty so both
+	// algorithms start from the same difficulty and are free to move in
+	// either direction.
+	windowSize := params.WorkDiffWindowSize
+	bc := newFakeChain(params)
+	extendChain(bc, windowSize*2, time.Second)
+	extendChain(bc, windowSize*(params.WorkDiffWindows+1),
+		params.TargetTimePerBlock)
+	tip := bc.bestChain.Tip()
+	if tip.bits == params.PowLimitBits {
+		t.Fatalf("test chain difficulty unexpectedly at the minimum")
+	}
+	params.WorkDiffV2KawPowStartBits = tip.bits
+
+	// Ensure both algorithms hold the difficulty steady and agree for several
+	// windows of blocks on target.
+	onTarget := append([]*blockNode{tip}, extendChain(bc, windowSize*3,
+		params.TargetTimePerBlock)...)
+	if mismatches := bc.compareDifficultyAlgos(onTarget); len(mismatches) != 0 {
+		t.Fatalf("unexpected mismatches for on target blocks: %+v", mismatches)
+	}
+	for _, node := range onTarget {
+		blake256Bits := bc.calcNextBlake256Diff(node, time.Time{})
+		kawPowBits := bc.calcNextBlake3DiffFromAnchor(node, onTarget[0])
+		if blake256Bits != tip.bits || kawPowBits != tip.bits {
+			t.Fatalf("difficulty changed for on target block %d -- got "+
+				"blake256 %08x, kawpow %08x, want %08x", node.height+1,
+				blake256Bits, kawPowBits, tip.bits)
+		}
+	}
+
+	// Ensure a window of slow blocks results in the ASERT algorithm reducing
+	// the difficulty for every block while the windowed algorithm only does so
+	// at the window boundary.  This is the expected difference in
+	// responsiveness between the algorithms.
+	slowStart := bc.bestChain.Tip()
+	slow := append([]*blockNode{slowStart}, extendChain(bc, windowSize,
+		params.TargetTimePerBlock*2)...)
+	mismatches := bc.compareDifficultyAlgos(slow)
+	if len(mismatches) == 0 {
+		t.Fatal("no mismatches for slow blocks")
+	}
+	for _, m := range mismatches {
+		if m.KawPowChange != -1 || m.Blake256Change != 0 {
+			t.Fatalf("unexpected mismatch for slow block %d: %+v", m.Height,
+				m)
+		}
+		if m.Height%windowSize == 0 {
+			t.Fatalf("unexpected mismatch at window boundary height %d",
+				m.Height)
+		}
+	}
+}
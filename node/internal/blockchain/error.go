@@ -85,6 +85,10 @@ const (
 	// the current time.
 	ErrTimeTooNew = ErrorKind("ErrTimeTooNew")
 
+	// ErrHeightTooFarAhead indicates a block header claims a height in a
+	// KawPoW epoch that is too far beyond the epoch of the best known header.
+	ErrHeightTooFarAhead = ErrorKind("ErrHeightTooFarAhead")
+
 	// ErrUnexpectedDifficulty indicates specified bits do not align with
 	// the expected value either because it doesn't match the calculated
 	// value based on difficulty regarding the rules or it is out of the
@@ -95,6 +99,16 @@ const (
 	// lower than the required target difficultly.
 	ErrHighHash = ErrorKind("ErrHighHash")
 
+	// ErrBadMixDigest indicates the mix digest committed to by a block header
+	// does not match the one produced while calculating its KawPoW proof of
+	// work hash.
+	ErrBadMixDigest = ErrorKind("ErrBadMixDigest")
+
+	// ErrInvalidPoW indicates the proof of work of a block could not be
+	// verified for a reason other than a difficulty mismatch, a high hash, or
+	// a bad mix digest.
+	ErrInvalidPoW = ErrorKind("ErrInvalidPoW")
+
 	// ErrBadMerkleRoot indicates the calculated merkle root does not match
 	// the expected value.
 	ErrBadMerkleRoot = ErrorKind("ErrBadMerkleRoot")
@@ -223,6 +237,10 @@ const (
 	// coinbase input was incorrect.
 	ErrBadCoinbaseAmountIn = ErrorKind("ErrBadCoinbaseAmountIn")
 
+	// ErrTooManyCoinbaseOutputs indicates a coinbase transaction has more
+	// outputs than are required to pay out the subsidy.
+	ErrTooManyCoinbaseOutputs = ErrorKind("ErrTooManyCoinbaseOutputs")
+
 	// ErrBadStakebaseAmountIn indicates that the AmountIn (=subsidy) for a
 	// stakebase input was incorrect.
 	ErrBadStakebaseAmountIn = ErrorKind("ErrBadStakebaseAmountIn")
@@ -27,8 +27,11 @@ func TestErrorKindStringer(t *testing.T) {
 		{ErrInvalidTime, "ErrInvalidTime"},
 		{ErrTimeTooOld, "ErrTimeTooOld"},
 		{ErrTimeTooNew, "ErrTimeTooNew"},
+		{ErrHeightTooFarAhead, "ErrHeightTooFarAhead"},
 		{ErrUnexpectedDifficulty, "ErrUnexpectedDifficulty"},
 		{ErrHighHash, "ErrHighHash"},
+		{ErrBadMixDigest, "ErrBadMixDigest"},
+		{ErrInvalidPoW, "ErrInvalidPoW"},
 		{ErrBadMerkleRoot, "ErrBadMerkleRoot"},
 		{ErrBadCommitmentRoot, "ErrBadCommitmentRoot"},
 		{ErrForkTooOld, "ErrForkTooOld"},
@@ -60,6 +63,7 @@ func TestErrorKindStringer(t *testing.T) {
 		{ErrBadCoinbaseOutpoint, "ErrBadCoinbaseOutpoint"},
 		{ErrBadCoinbaseFraudProof, "ErrBadCoinbaseFraudProof"},
 		{ErrBadCoinbaseAmountIn, "ErrBadCoinbaseAmountIn"},
+		{ErrTooManyCoinbaseOutputs, "ErrTooManyCoinbaseOutputs"},
 		{ErrBadStakebaseAmountIn, "ErrBadStakebaseAmountIn"},
 		{ErrBadStakebaseScriptLen, "ErrBadStakebaseScriptLen"},
 		{ErrBadStakebaseScrVal, "ErrBadStakebaseScrVal"},
@@ -0,0 +1,67 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"math/big"
+)
+
+var (
+	// bigOne is 1 represented as a big.Int.  It is defined here to avoid
+	// the overhead of creating it multiple times.
+	bigOne = big.NewInt(1)
+)
+
+// fixedPointShift is the number of fractional bits in a 64.32 fixed point
+// number.
+const fixedPointShift = 32
+
+// fixed64_32 is a 64.32 fixed point number that is backed by a big integer so
+// that intermediate results can't overflow.  It is used by the exponentially
+// weighted difficulty algorithms in order to avoid floating point math.
+//
+// The methods all modify and return the receiver in the same manner as
+// big.Int, so they may be chained.  The zero value is 0.
+//
+// nolint: revive
+type fixed64_32 struct {
+	v big.Int
+}
+
+// FromInt sets the fixed point number to the passed integer and returns it.
+func (f *fixed64_32) FromInt(n int64) *fixed64_32 {
+	f.v.SetInt64(n)
+	f.v.Lsh(&f.v, fixedPointShift)
+	return f
+}
+
+// MulDiv sets the fixed point number to f * mul / div using Euclidean division
+// and returns it.  The multiplication is performed first, so pass bigOne for
+// either argument in order to only divide or only multiply.
+//
+// This will panic if div is zero.
+func (f *fixed64_32) MulDiv(mul, div *big.Int) *fixed64_32 {
+	f.v.Mul(&f.v, mul)
+	f.v.Div(&f.v, div)
+	return f
+}
+
+// Lsh sets the fixed point number to f * 2^n and returns it.
+func (f *fixed64_32) Lsh(n uint) *fixed64_32 {
+	f.v.Lsh(&f.v, n)
+	return f
+}
+
+// Add sets the fixed point number to f + x and returns it.
+func (f *fixed64_32) Add(x *fixed64_32) *fixed64_32 {
+	f.v.Add(&f.v, &x.v)
+	return f
+}
+
+// ToInt returns a new big integer that houses the integer portion of the fixed
+// point number with the fractional bits discarded.
+func (f *fixed64_32) ToInt() *big.Int {
+	return new(big.Int).Rsh(&f.v, fixedPointShift)
+}
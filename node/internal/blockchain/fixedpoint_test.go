@@ -0,0 +1,105 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// TestFixed64_32 ensures the 64.32 fixed point helpers produce results that
+// are identical to the inline big integer math the difficulty algorithms
+// historically used for a variety of random inputs.
+func TestFixed64_32(t *testing.T) {
+	t.Parallel()
+
+	// inlineCalc performs the weighted window calculation with the inline big
+	// integer math the difficulty algorithms originally used.
+	inlineCalc := func(values []int64, target int64, alpha int64, oldDiff *big.Int) *big.Int {
+		numWindows := int64(len(values))
+		weightedSum := big.NewInt(0)
+		var weights uint64
+		for i, value := range values {
+			valueBig := big.NewInt(value)
+			valueBig.Lsh(valueBig, 32)
+			targetBig := big.NewInt(target)
+			windowAdjusted := targetBig.Div(valueBig, targetBig)
+			windowAdjusted.Lsh(windowAdjusted, uint((numWindows-int64(i))*alpha))
+			weights += 1 << uint64((numWindows-int64(i))*alpha)
+			weightedSum.Add(weightedSum, windowAdjusted)
+		}
+		weightedSum.Div(weightedSum, big.NewInt(int64(weights)))
+		weightedSum.Mul(weightedSum, oldDiff)
+		return weightedSum.Rsh(weightedSum, 32)
+	}
+
+	// fixedCalc performs the same weighted window calculation with the fixed
+	// point helpers.
+	fixedCalc := func(values []int64, target int64, alpha int64, oldDiff *big.Int) *big.Int {
+		numWindows := int64(len(values))
+		weightedSum := new(fixed64_32)
+		var weights uint64
+		for i, value := range values {
+			windowAdjusted := new(fixed64_32).FromInt(value).
+				MulDiv(bigOne, big.NewInt(target))
+			windowAdjusted.Lsh(uint((numWindows - int64(i)) * alpha))
+			weights += 1 << uint64((numWindows-int64(i))*alpha)
+			weightedSum.Add(windowAdjusted)
+		}
+		return weightedSum.MulDiv(bigOne, big.NewInt(int64(weights))).
+			MulDiv(oldDiff, bigOne).ToInt()
+	}
+
+	// Use a unique random seed each test instance and log it if the tests fail.
+	seed := time.Now().Unix()
+	rng := rand.New(rand.NewSource(seed))
+	defer func(t *testing.T, seed int64) {
+		if t.Failed() {
+			t.Logf("random seed: %d", seed)
+		}
+	}(t, seed)
+
+	for i := 0; i < 1000; i++ {
+		// Choose random window values that include negative values since
+		// time differences may be negative and a random target that is
+		// always positive.
+		numWindows := rng.Int63n(20) + 1
+		alpha := rng.Int63n(3) + 1
+		values := make([]int64, numWindows)
+		for j := range values {
+			values[j] = rng.Int63n(1<<32) - 1<<16
+		}
+		target := rng.Int63n(1<<32) + 1
+		oldDiff := new(big.Int).Rand(rng, new(big.Int).Lsh(bigOne, 255))
+
+		want := inlineCalc(values, target, alpha, oldDiff)
+		got := fixedCalc(values, target, alpha, oldDiff)
+		if got.Cmp(want) != 0 {
+			t.Fatalf("mismatched result -- got %x, want %x (values %v, "+
+				"target %d, alpha %d, old diff %x)", got, want, values,
+				target, alpha, oldDiff)
+		}
+	}
+
+	// Ensure conversion to and from integers round trips and that the
+	// fractional bits are discarded when converting back.
+	for i := 0; i < 1000; i++ {
+		n := rng.Int63() - rng.Int63()
+		if got := new(fixed64_32).FromInt(n).ToInt(); got.Int64() != n {
+			t.Fatalf("mismatched round trip -- got %d, want %d", got, n)
+		}
+
+		div := rng.Int63n(1<<16) + 1
+		want := big.NewInt(n)
+		want.Div(want, big.NewInt(div))
+		got := new(fixed64_32).FromInt(n).MulDiv(bigOne, big.NewInt(div)).ToInt()
+		if got.Cmp(want) != 0 {
+			t.Fatalf("mismatched truncation of %d / %d -- got %d, want %d",
+				n, div, got, want)
+		}
+	}
+}
@@ -7,12 +7,12 @@ package blockchain
 import (
 	"fmt"
 
-	"github.com/decred/dcrd/blockchain/standalone/v2"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/database/v3"
 	"github.com/decred/dcrd/dcrutil/v4"
 	"github.com/decred/dcrd/gcs/v4"
 	"github.com/decred/dcrd/wire"
+	"vigil.network/node/blockchain/standalone"
 )
 
 const (
@@ -0,0 +1,174 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/wire"
+	"vigil.network/node/blockchain/standalone/kawpow"
+)
+
+// kawPowCachePrewarmBlocks is the number of blocks prior to a KawPoW epoch
+// boundary at which the verification cache for the next epoch is prepared so
+// that it is resident by the time the first block of the epoch arrives.
+const kawPowCachePrewarmBlocks = 16
+
+// kawPowParams returns the KawPoW parameters defined by the provided network
+// parameters.
+func kawPowParams(params *chaincfg.Params) kawpow.Params {
+	return kawpow.Params{
+		EpochLength:        params.KawPow.EpochLength,
+		DatasetInitBytes:   params.KawPow.DatasetInitBytes,
+		DatasetGrowthBytes: params.KawPow.DatasetGrowthBytes,
+		CacheInitBytes:     params.KawPow.CacheInitBytes,
+		CacheGrowthBytes:   params.KawPow.CacheGrowthBytes,
+		CacheRounds:        params.KawPow.CacheRounds,
+	}
+}
+
+// checkKawPowASICResistance ensures the KawPoW parameters defined by the
+// provided network parameters specify an initial dataset that is large enough
+// for the proof of work to remain memory hard.
+//
+// Parameters that fail the check are an error for the main network since they
+// would defeat the ASIC resistance KawPoW is chosen for.  They only result in
+// a warning for the other networks since a small dataset might be intentional
+// on them in order to reduce resource usage.
+func checkKawPowASICResistance(params *chaincfg.Params) error {
+	kpParams := kawPowParams(params)
+	err := kpParams.CheckASICResistance()
+	if err == nil {
+		return nil
+	}
+	if params.Net == wire.MainNet {
+		return fmt.Errorf("invalid KawPoW parameters for network %s: %w",
+			params.Name, err)
+	}
+	log.Warnf("KawPoW parameters for network %s are not ASIC resistant: %v",
+		params.Name, err)
+	return nil
+}
+
+// calcNextEpochHeight returns the height of the first block of the KawPoW
+// epoch that follows the epoch containing the provided height along with the
+// number of blocks remaining until that height is reached according to the
+// provided KawPoW parameters.
+func calcNextEpochHeight(params *kawpow.Params, height int64) (int64, int64) {
+	epochLen := params.EpochLength
+	boundaries := params.EpochBoundaryHeights(height+1, height+epochLen)
+	nextHeight := boundaries[0]
+	return nextHeight, nextHeight - height
+}
+
+// KawPowParams returns the KawPoW parameters of the network the chain is
+// associated with.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) KawPowParams() kawpow.Params {
+	return b.kawPow.Params()
+}
+
+// DAGManager returns the manager of the KawPoW DAG files cached on disk for the
+// network the chain is associated with.  It is the same manager the full
+// hasher used when full DAG verification is enabled persists the DAGs through.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) DAGManager() *kawpow.DAGManager {
+	return b.dagManager
+}
+
+// NextEpochHeight returns the height at which the next KawPoW epoch begins
+// relative to the current best chain tip, the number of blocks remaining
+// until that height is reached, and an estimate of when it will be reached
+// based on the target time per block.
+//
+// Miners make use of this to plan generation of the DAG for the next epoch
+// ahead of time.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) NextEpochHeight() (int64, int64, time.Time) {
+	tip := b.bestChain.Tip()
+	kpParams := b.kawPow.Params()
+	nextHeight, remaining := calcNextEpochHeight(&kpParams, tip.height)
+	targetTime := b.chainParams.TargetTimePerBlock
+	estTime := time.Unix(tip.timestamp, 0).Add(time.Duration(remaining) *
+		targetTime)
+	return nextHeight, remaining, estTime
+}
+
+// SeedHashForTip returns the KawPoW seed hash and epoch for the block that
+// would be mined on top of the current best chain tip, which is the block at
+// the tip height plus one.
+//
+// The seed hash only depends on the epoch, so it is the same for every block
+// in the epoch regardless of its timestamp.  Callers that provide work to
+// miners should make use of this to ensure they all agree on the seed.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) SeedHashForTip() (chainhash.Hash, int64) {
+	tip := b.bestChain.Tip()
+	kpParams := b.kawPow.Params()
+	height := tip.height + 1
+
+	// Calculating the seed hash never fails, so the error is ignored.
+	seed, _ := kpParams.CalcSeedHash(height, 0)
+	return seed, kpParams.Epoch(height)
+}
+
+// prepareKawPowCaches ensures the KawPoW verification cache for the epoch of
+// the block after the provided tip height is resident and also prepares the
+// cache for the next epoch once the tip is within kawPowCachePrewarmBlocks
+// blocks of it.  This prevents block verification from stalling to generate a
+// cache at epoch boundaries.
+//
+// Only the verification cache is prepared since the full dataset is only
+// required for mining.
+func (b *BlockChain) prepareKawPowCaches(tipHeight int64) {
+	b.kawPow.PrepareCache(tipHeight + 1)
+
+	kpParams := b.kawPow.Params()
+	nextHeight, remaining := calcNextEpochHeight(&kpParams, tipHeight)
+	if remaining <= kawPowCachePrewarmBlocks {
+		b.kawPow.PrepareCache(nextHeight)
+	}
+}
+
+// checkHeaderEpochLookahead ensures the provided header does not claim a height
+// that is implausibly far beyond the provided best known header height.  This
+// is done prior to any proof of work verification so that headers claiming
+// heights in far future epochs can't be used to force the generation of
+// caches or DAGs for those epochs.
+//
+// Headers are allowed to claim heights in epochs up to the provided maximum
+// number of epochs beyond the epoch of the highest header that could be
+// received in a single headers message that builds on the best known header.
+func checkHeaderEpochLookahead(header *wire.BlockHeader, bestHeaderHeight int64, kpParams *kawpow.Params, maxLookahead uint32) error {
+	height := int64(header.Height)
+	maxEpoch := kpParams.Epoch(bestHeaderHeight+wire.MaxBlockHeadersPerMsg) +
+		int64(maxLookahead)
+	if epoch := kpParams.Epoch(height); epoch > maxEpoch {
+		str := fmt.Sprintf("block header height %d is in KawPoW epoch %d "+
+			"which is beyond the max allowed epoch %d given the best known "+
+			"header height %d", height, epoch, maxEpoch, bestHeaderHeight)
+		return ruleError(ErrHeightTooFarAhead, str)
+	}
+	return nil
+}
+
+// checkKawPowEpochLookahead ensures the provided header does not claim a height
+// that is implausibly far beyond the best known header as described by
+// checkHeaderEpochLookahead using the configured max epoch lookahead.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) checkKawPowEpochLookahead(header *wire.BlockHeader) error {
+	bestHeader := b.index.BestHeader()
+	kpParams := b.kawPow.Params()
+	return checkHeaderEpochLookahead(header, bestHeader.height, &kpParams,
+		b.maxKawPowEpochLookahead)
+}
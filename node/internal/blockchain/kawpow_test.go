@@ -0,0 +1,354 @@
+// Copyright (c) 2025 The Vigil Developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/wire"
+	"vigil.network/node/blockchain/standalone/kawpow"
+)
+
+// TestNextEpochHeight ensures the next KawPoW epoch height, the number of
+// blocks remaining until it, and the estimated time it will be reached are
+// calculated as expected for tips both near and far from an epoch boundary.
+func TestNextEpochHeight(t *testing.T) {
+	const epochLen = kawpow.KawPowEpochLength
+
+	tests := []struct {
+		name          string
+		tipHeight     int64
+		wantHeight    int64
+		wantRemaining int64
+	}{{
+		name:          "tip far from first boundary",
+		tipHeight:     10,
+		wantHeight:    epochLen,
+		wantRemaining: epochLen - 10,
+	}, {
+		name:          "tip one block before boundary",
+		tipHeight:     epochLen - 1,
+		wantHeight:    epochLen,
+		wantRemaining: 1,
+	}, {
+		name:          "tip exactly at boundary",
+		tipHeight:     epochLen,
+		wantHeight:    2 * epochLen,
+		wantRemaining: epochLen,
+	}, {
+		name:          "tip just after boundary",
+		tipHeight:     epochLen + 1,
+		wantHeight:    2 * epochLen,
+		wantRemaining: epochLen - 1,
+	}}
+
+	params := chaincfg.MainNetParams()
+	for _, test := range tests {
+		chain := newFakeChain(params)
+		nodes := chainedFakeNodes(chain.bestChain.Genesis(), int(test.tipHeight))
+		tip := branchTip(nodes)
+		chain.bestChain.SetTip(tip)
+
+		height, remaining, estTime := chain.NextEpochHeight()
+		if height != test.wantHeight {
+			t.Errorf("%q: unexpected next epoch height -- got %d, want %d",
+				test.name, height, test.wantHeight)
+			continue
+		}
+		if remaining != test.wantRemaining {
+			t.Errorf("%q: unexpected blocks remaining -- got %d, want %d",
+				test.name, remaining, test.wantRemaining)
+			continue
+		}
+		wantTime := time.Unix(tip.timestamp, 0).Add(
+			time.Duration(test.wantRemaining) * params.TargetTimePerBlock)
+		if !estTime.Equal(wantTime) {
+			t.Errorf("%q: unexpected estimated time -- got %v, want %v",
+				test.name, estTime, wantTime)
+			continue
+		}
+	}
+}
+
+// TestSeedHashForTip ensures the seed hash and epoch returned for the block
+// that would be mined on top of the current best chain tip are those of the
+// epoch that contains the next block and that the seed only changes at epoch
+// boundaries.
+func TestSeedHashForTip(t *testing.T) {
+	// Use the simulation network since it has short epochs.
+	params := chaincfg.SimNetParams()
+	epochLen := params.KawPow.EpochLength
+	kpParams := kawPowParams(params)
+
+	tests := []struct {
+		name      string
+		tipHeight int64
+		wantEpoch int64
+	}{{
+		name:      "next block two before boundary",
+		tipHeight: epochLen - 2,
+		wantEpoch: 0,
+	}, {
+		name:      "next block at boundary",
+		tipHeight: epochLen - 1,
+		wantEpoch: 1,
+	}, {
+		name:      "next block just after boundary",
+		tipHeight: epochLen,
+		wantEpoch: 1,
+	}, {
+		name:      "next block at final block of epoch",
+		tipHeight: 2*epochLen - 2,
+		wantEpoch: 1,
+	}, {
+		name:      "next block at second boundary",
+		tipHeight: 2*epochLen - 1,
+		wantEpoch: 2,
+	}}
+
+	seedsByEpoch := make(map[int64]chainhash.Hash)
+	for _, test := range tests {
+		chain := newFakeChain(params)
+		nodes := chainedFakeNodes(chain.bestChain.Genesis(), int(test.tipHeight))
+		tip := branchTip(nodes)
+		chain.bestChain.SetTip(tip)
+
+		seed, epoch := chain.SeedHashForTip()
+		if epoch != test.wantEpoch {
+			t.Errorf("%q: unexpected epoch -- got %d, want %d", test.name,
+				epoch, test.wantEpoch)
+			continue
+		}
+		wantSeed, err := kpParams.CalcSeedHash(test.tipHeight+1, 0)
+		if err != nil {
+			t.Fatalf("%q: unexpected error calculating seed hash: %v",
+				test.name, err)
+		}
+		if seed != wantSeed {
+			t.Errorf("%q: unexpected seed hash -- got %v, want %v", test.name,
+				seed, wantSeed)
+			continue
+		}
+
+		// Ensure the seed is the same for all blocks in the same epoch and
+		// differs from the seeds of all other epochs.
+		if epochSeed, ok := seedsByEpoch[epoch]; ok && epochSeed != seed {
+			t.Errorf("%q: seed hash %v differs from seed hash %v of another "+
+				"block in epoch %d", test.name, seed, epochSeed, epoch)
+			continue
+		}
+		for otherEpoch, otherSeed := range seedsByEpoch {
+			if otherEpoch != epoch && otherSeed == seed {
+				t.Errorf("%q: seed hash %v for epoch %d is the same as the "+
+					"seed hash for epoch %d", test.name, seed, epoch,
+					otherEpoch)
+			}
+		}
+		seedsByEpoch[epoch] = seed
+	}
+}
+
+// TestKawPowNetworkParams ensures the KawPoW parameters defined by the network
+// parameters flow through to the hasher the chain constructs and therefore to
+// the selection of the seed hash and DAG for each height.
+func TestKawPowNetworkParams(t *testing.T) {
+	params := chaincfg.SimNetParams()
+	epochLen := params.KawPow.EpochLength
+	if epochLen >= kawpow.KawPowEpochLength {
+		t.Fatalf("simnet epoch length %d is not shorter than the default %d",
+			epochLen, kawpow.KawPowEpochLength)
+	}
+
+	// Ensure the hasher constructed by the chain uses the network parameters.
+	chain := newFakeChain(params)
+	kpParams := chain.kawPow.Params()
+	if kpParams != kawPowParams(params) {
+		t.Fatalf("unexpected hasher params -- got %+v, want %+v", kpParams,
+			kawPowParams(params))
+	}
+
+	// Ensure the next epoch height is based on the shorter epoch length.
+	nodes := chainedFakeNodes(chain.bestChain.Genesis(), 10)
+	chain.bestChain.SetTip(branchTip(nodes))
+	height, remaining, _ := chain.NextEpochHeight()
+	if height != epochLen || remaining != epochLen-10 {
+		t.Fatalf("unexpected next epoch height -- got %d (%d remaining), "+
+			"want %d (%d remaining)", height, remaining, epochLen,
+			epochLen-10)
+	}
+
+	// Ensure the seed hash, and therefore the DAG, changes at the shorter
+	// epoch boundary while it remains the same as the default parameters
+	// prior to it.
+	const timestamp = 0x61c402e0
+	prevSeed, err := kpParams.CalcSeedHash(epochLen-1, timestamp)
+	if err != nil {
+		t.Fatalf("unexpected seed hash error: %v", err)
+	}
+	seed, err := kpParams.CalcSeedHash(epochLen, timestamp)
+	if err != nil {
+		t.Fatalf("unexpected seed hash error: %v", err)
+	}
+	defaultSeed, err := kawpow.CalcSeedHash(epochLen, timestamp)
+	if err != nil {
+		t.Fatalf("unexpected seed hash error: %v", err)
+	}
+	if seed == prevSeed {
+		t.Fatal("seed hash did not change at simnet epoch boundary")
+	}
+	if prevSeed != defaultSeed {
+		t.Fatal("simnet seed hash differs from default in first epoch")
+	}
+	if epoch := kpParams.Epoch(epochLen); epoch != 1 {
+		t.Fatalf("unexpected DAG epoch at boundary -- got %d, want 1", epoch)
+	}
+}
+
+// TestPrepareKawPowCaches ensures the KawPoW verification cache for the next
+// epoch is prepared once the tip approaches an epoch boundary such that it is
+// already resident when the chain crosses the boundary.
+func TestPrepareKawPowCaches(t *testing.T) {
+	// Use the simulation network since it has short epochs.
+	params := chaincfg.SimNetParams()
+	epochLen := params.KawPow.EpochLength
+	chain := newFakeChain(params)
+
+	// Simulate the tip advancing through the first epoch and ensure the cache
+	// for the next epoch is only prepared once the tip is within the prewarm
+	// window of the boundary.
+	for tipHeight := int64(0); tipHeight < epochLen; tipHeight++ {
+		chain.prepareKawPowCaches(tipHeight)
+		if !chain.kawPow.CacheReady(tipHeight + 1) {
+			t.Fatalf("cache for block after tip %d is not ready", tipHeight)
+		}
+		remaining := epochLen - tipHeight
+		wantReady := remaining <= kawPowCachePrewarmBlocks
+		if gotReady := chain.kawPow.CacheReady(epochLen); gotReady != wantReady {
+			t.Fatalf("tip %d: unexpected next epoch cache readiness -- got "+
+				"%v, want %v", tipHeight, gotReady, wantReady)
+		}
+	}
+
+	// Ensure the caches for both the previous and current epochs remain
+	// resident after crossing the boundary.
+	chain.prepareKawPowCaches(epochLen)
+	if !chain.kawPow.CacheReady(epochLen-1) || !chain.kawPow.CacheReady(epochLen) {
+		t.Fatal("caches for the epochs around the boundary are not resident")
+	}
+}
+
+// TestCheckHeaderEpochLookahead ensures headers that claim a height in a KawPoW
+// epoch too far beyond the epoch of the best known header are rejected and that
+// doing so does not prepare the verification cache for the claimed epoch.
+func TestCheckHeaderEpochLookahead(t *testing.T) {
+	// Use the simulation network since it has short epochs.
+	params := chaincfg.SimNetParams()
+	epochLen := params.KawPow.EpochLength
+	kpParams := kawPowParams(params)
+	const maxLookahead = 2
+
+	// The highest allowed epoch is the max lookahead beyond the epoch of the
+	// highest header a single headers message may contain.
+	const bestHeight = 1000
+	maxEpoch := (bestHeight+wire.MaxBlockHeadersPerMsg)/epochLen + maxLookahead
+	maxHeight := (maxEpoch+1)*epochLen - 1
+
+	tests := []struct {
+		name    string
+		height  int64
+		wantErr error
+	}{{
+		name:    "height of best header",
+		height:  bestHeight,
+		wantErr: nil,
+	}, {
+		name:    "final block of max allowed epoch",
+		height:  maxHeight,
+		wantErr: nil,
+	}, {
+		name:    "first block of epoch after max allowed epoch",
+		height:  maxHeight + 1,
+		wantErr: ErrHeightTooFarAhead,
+	}, {
+		name:    "thousands of epochs ahead",
+		height:  bestHeight + 5000*epochLen,
+		wantErr: ErrHeightTooFarAhead,
+	}}
+
+	for _, test := range tests {
+		header := &wire.BlockHeader{Height: uint32(test.height)}
+		err := checkHeaderEpochLookahead(header, bestHeight, &kpParams,
+			maxLookahead)
+		if !errors.Is(err, test.wantErr) {
+			t.Errorf("%q: mismatched err -- got %v, want %v", test.name, err,
+				test.wantErr)
+		}
+	}
+
+	// Ensure a batch of headers with a header claiming a height thousands of
+	// epochs ahead of the best known header is rejected without preparing the
+	// verification cache for the claimed epoch.
+	chain := newFakeChain(params)
+	chain.maxKawPowEpochLookahead = maxLookahead
+	farHeight := 5000 * epochLen
+	headers := []*wire.BlockHeader{
+		{Height: 1},
+		{Height: uint32(farHeight)},
+	}
+	err := chain.CheckHeadersProofOfWork(headers)
+	if !errors.Is(err, ErrHeightTooFarAhead) {
+		t.Fatalf("mismatched err -- got %v, want %v", err,
+			ErrHeightTooFarAhead)
+	}
+	if chain.kawPow.CacheReady(1) || chain.kawPow.CacheReady(farHeight) {
+		t.Fatal("verification cache was prepared for rejected batch")
+	}
+}
+
+// TestCheckKawPowASICResistance ensures network parameters that specify a
+// KawPoW initial dataset too small to remain ASIC resistant are rejected for
+// the main network and only warned about for the other networks.
+func TestCheckKawPowASICResistance(t *testing.T) {
+	tests := []struct {
+		name             string
+		params           *chaincfg.Params
+		datasetInitBytes uint64
+		err              error
+	}{{
+		name:             "mainnet default parameters",
+		params:           chaincfg.MainNetParams(),
+		datasetInitBytes: chaincfg.MainNetParams().KawPow.DatasetInitBytes,
+		err:              nil,
+	}, {
+		name:             "mainnet sub-threshold dataset",
+		params:           chaincfg.MainNetParams(),
+		datasetInitBytes: kawpow.MinASICResistantDatasetBytes - 128,
+		err:              kawpow.ErrDatasetTooSmall,
+	}, {
+		name:             "mainnet tiny dataset",
+		params:           chaincfg.MainNetParams(),
+		datasetInitBytes: 128 * 8,
+		err:              kawpow.ErrDatasetTooSmall,
+	}, {
+		name:             "regnet tiny dataset only warns",
+		params:           chaincfg.RegNetParams(),
+		datasetInitBytes: 128 * 8,
+		err:              nil,
+	}}
+
+	for _, test := range tests {
+		params := cloneParams(test.params)
+		params.KawPow.DatasetInitBytes = test.datasetInitBytes
+		err := checkKawPowASICResistance(params)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: unexpected error -- got %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
@@ -4,18 +4,9 @@ import (
 	"math/big"
 	
 	"github.com/decred/dcrd/chaincfg/chainhash"
-	"github.com/decred/dcrd/wire"
 )
 
 // Stub implementations for missing methods
 func (b *BlockChain) determineCheckTxFlags(node *blockNode) (AgendaFlags, error) {
 	return AgendaFlags{}, nil
 }
-
-func (b *BlockChain) checkBlockContext(block *wire.MsgBlock, prevNode *blockNode) error {
-	return nil
-}
-
-func (b *BlockChain) checkConnectBlock(block *wire.MsgBlock, prevNode *blockNode) error {
-	return nil
-}
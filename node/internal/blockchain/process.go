@@ -56,6 +56,39 @@ func (b *BlockChain) checkKnownInvalidBlock(node *blockNode) error {
 	return nil
 }
 
+// maybeCacheInvalidBlock adds the provided block hash along with the error it
+// failed validation with to the cache of recent blocks that are known to be
+// invalid when the error is a rule violation that applies to any block with
+// the same hash.
+//
+// Errors that might be the result of the block data being modified without
+// changing the hash, such as duplicate transactions or merkle root and size
+// mismatches, are not cached since that would otherwise allow an attacker to
+// cause the valid version of the block to be rejected.  Similarly, errors that
+// might no longer apply in the future, such as those due to a missing parent
+// or a timestamp or height that is too far in the future, and errors that are already
+// tracked by the block index are not cached either.
+func (b *BlockChain) maybeCacheInvalidBlock(hash *chainhash.Hash, err error) {
+	var rErr RuleError
+	if !errors.As(err, &rErr) {
+		return
+	}
+	switch {
+	case errors.Is(err, ErrBlockTooBig),
+		errors.Is(err, ErrWrongBlockSize),
+		errors.Is(err, ErrBadMerkleRoot),
+		errors.Is(err, ErrDuplicateTx),
+		errors.Is(err, ErrMissingParent),
+		errors.Is(err, ErrTimeTooNew),
+		errors.Is(err, ErrHeightTooFarAhead),
+		errors.Is(err, ErrKnownInvalidBlock),
+		errors.Is(err, ErrInvalidAncestorBlock):
+
+		return
+	}
+	b.recentInvalidBlocks.Put(*hash, err)
+}
+
 // maybeSetForkRejectionCheckpoint attempts to discover and set the old fork
 // rejection checkpoint node when it has not already been discovered and old
 // forks are not allowed for the current network.
@@ -177,12 +210,25 @@ func (b *BlockChain) maybeAcceptBlockHeader(header *wire.BlockHeader, checkHeade
 		return node, nil
 	}
 
-	// Perform context-free sanity checks on the block header.
+	// Reject headers that claim a height implausibly far beyond the best
+	// known header prior to performing any proof of work verification.
+	if err := b.checkKawPowEpochLookahead(header); err != nil {
+		return nil, err
+	}
+
+	// Perform context-free sanity checks on the block header and ensure its
+	// proof of work is valid.  Mark the header as having passed proof of work
+	// verification once it succeeds so the check can be skipped when the
+	// associated block data is later processed.
 	if checkHeaderSanity {
 		err := checkBlockHeaderSanity(header, b.timeSource, BFNone, b.chainParams)
 		if err != nil {
 			return nil, err
 		}
+		if err := b.checkBlockProofOfWork(header, BFNone); err != nil {
+			return nil, err
+		}
+		b.recentPowChecks.Put(hash)
 	}
 
 	// Orphan headers are not allowed and this function should never be called
@@ -477,13 +523,42 @@ func (b *BlockChain) ProcessBlock(block *dcrutil.Block) (int64, error) {
 		}
 	}
 
-	// Perform preliminary sanity checks on the block and its transactions.
-	// This is done prior to any attempts to accept the block data and connect
-	// the block to quickly eliminate blocks that are obviously incorrect and
-	// significantly increase the cost to attackers.  Of particular note is that
-	// the checks include proof-of-work validation which means a significant
-	// amount of work must have been done in order to pass this check.
-	err := checkBlockSanity(block, b.timeSource, BFNone, b.chainParams)
+	// Similarly, reject blocks that recently failed validation with the same
+	// error without validating them again.  This notably includes blocks
+	// that failed the sanity checks prior to having a block index entry.
+	//
+	// When there is a block index entry for the block, which will be the case
+	// if the header was accepted since the block was rejected, mark it as
+	// having failed validation and all of its descendants as having an
+	// invalid ancestor the same as if the block were validated again.
+	if err, ok := b.recentInvalidBlocks.Get(*blockHash); ok {
+		if node != nil {
+			b.index.MarkBlockFailedValidation(node)
+		}
+		return 0, err
+	}
+
+	// Perform preliminary sanity checks on the block and its transactions
+	// along with proof-of-work validation.  This is done prior to any attempts
+	// to accept the block data and connect the block to quickly eliminate
+	// blocks that are obviously incorrect and significantly increase the cost
+	// to attackers.  Of particular note is that the proof-of-work validation
+	// means a significant amount of work must have been done in order to pass
+	// these checks.
+	//
+	// The proof of work check is skipped when the header is already known to
+	// have recently passed it, such as when the block data arrives after its
+	// header was accepted during the initial headers sync, since calculating
+	// the proof of work hash is comparatively expensive.  Otherwise, the
+	// header is only marked as having passed it once it succeeds.
+	sanityFlags := BFNone
+	if b.recentPowChecks.Contains(*blockHash) {
+		sanityFlags |= BFNoPoWCheck
+	}
+	err := checkBlockSanity(block, b.timeSource, sanityFlags, b.chainParams)
+	if err == nil {
+		err = b.checkBlockProofOfWork(&block.MsgBlock().Header, sanityFlags)
+	}
 	if err != nil {
 		// When there is a block index entry for the block, which will be the
 		// case if the header was previously seen and passed all validation,
@@ -492,8 +567,10 @@ func (b *BlockChain) ProcessBlock(block *dcrutil.Block) (int64, error) {
 		if node != nil {
 			b.index.MarkBlockFailedValidation(node)
 		}
+		b.maybeCacheInvalidBlock(blockHash, err)
 		return 0, err
 	}
+	b.recentPowChecks.Put(*blockHash)
 
 	// Potentially accept the header to the block index when it does not already
 	// exist.
@@ -508,6 +585,7 @@ func (b *BlockChain) ProcessBlock(block *dcrutil.Block) (int64, error) {
 		header := &block.MsgBlock().Header
 		node, err = b.maybeAcceptBlockHeader(header, checkHeaderSanity)
 		if err != nil {
+			b.maybeCacheInvalidBlock(blockHash, err)
 			return 0, err
 		}
 	}
@@ -534,6 +612,7 @@ func (b *BlockChain) ProcessBlock(block *dcrutil.Block) (int64, error) {
 	// are now eligible for validation.
 	linkedNodes, err := b.maybeAcceptBlockData(node, block, flags)
 	if err != nil {
+		b.maybeCacheInvalidBlock(blockHash, err)
 		return 0, err
 	}
 
@@ -824,6 +903,7 @@ func (b *BlockChain) ReconsiderBlock(hash *chainhash.Hash) error {
 			}
 			b.index.unsetStatusFlags(n, statusValidateFailed|statusInvalidAncestor)
 			b.recentContextChecks.Delete(n.hash)
+			b.recentInvalidBlocks.Delete(n.hash)
 		}
 
 		if b.index.canValidate(n) && n.workSum.GtEq(&curBestTip.workSum) {
@@ -5,6 +5,7 @@
 package blockchain
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
@@ -13,6 +14,8 @@ import (
 	"github.com/decred/dcrd/blockchain/v5/chaingen"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/decred/dcrd/txscript/v4"
 	"github.com/decred/dcrd/wire"
 )
 
@@ -2067,3 +2070,158 @@ func TestAssumeValid(t *testing.T) {
 			"assumed valid node is nil")
 	}
 }
+
+// TestRecentPowChecks ensures the cache of blocks that recently passed proof of
+// work verification is only populated by headers and blocks that actually pass
+// the check and that the block data associated with a previously verified
+// header is still accepted.
+func TestRecentPowChecks(t *testing.T) {
+	t.Parallel()
+
+	// Create a test harness initialized with the genesis block as the tip.
+	params := chaincfg.RegNetParams()
+	g := newChaingenHarness(t, params)
+
+	// isCached returns whether or not the block associated with the given
+	// name in the harness generator is in the recent proof of work cache.
+	isCached := func(blockName string) bool {
+		t.Helper()
+
+		blockHash := g.BlockByName(blockName).BlockHash()
+		g.chain.chainLock.Lock()
+		defer g.chain.chainLock.Unlock()
+		return g.chain.recentPowChecks.Contains(blockHash)
+	}
+
+	// Produce an unsolved initial block and ensure it is rejected without
+	// being added to the cache.
+	//
+	//   genesis
+	//          \-> bfbunsolved
+	bfbunsolved := g.CreateBlockOne("bfbunsolved", 0)
+	{
+		origHash := bfbunsolved.BlockHash()
+		for g.IsSolved(&bfbunsolved.Header) {
+			bfbunsolved.Header.Nonce++
+		}
+		g.UpdateBlockState("bfbunsolved", origHash, "bfbunsolved", bfbunsolved)
+	}
+	g.RejectTipBlock(ErrHighHash)
+	if isCached("bfbunsolved") {
+		t.Fatal("unsolved block bfbunsolved was added to the proof of work " +
+			"cache")
+	}
+
+	// Produce a valid and solved initial block and ensure its header is
+	// added to the cache once accepted and that the block data is then
+	// accepted as well.
+	//
+	//   genesis -> bfb
+	g.SetTip("genesis")
+	g.CreateBlockOne("bfb", 0)
+	if isCached("bfb") {
+		t.Fatal("unprocessed block bfb is in the proof of work cache")
+	}
+	g.AcceptHeader("bfb")
+	if !isCached("bfb") {
+		t.Fatal("accepted header bfb was not added to the proof of work cache")
+	}
+	g.AcceptTipBlock()
+
+	// Produce another block and ensure it is added to the cache when the
+	// block data is processed without the header having been seen first.
+	//
+	//   genesis -> bfb -> b2
+	g.NextBlock("b2", nil, nil)
+	g.AcceptTipBlock()
+	if !isCached("b2") {
+		t.Fatal("accepted block b2 was not added to the proof of work cache")
+	}
+}
+
+// TestRecentInvalidBlocks ensures blocks that fail validation are added to the
+// cache of known invalid blocks, that repeated submissions of them are
+// rejected from the cache without validating them again, and that failures
+// that could be caused by modifying the block data without changing the hash
+// are not cached.
+func TestRecentInvalidBlocks(t *testing.T) {
+	t.Parallel()
+
+	// Create a test harness initialized with the genesis block as the tip.
+	params := chaincfg.RegNetParams()
+	g := newChaingenHarness(t, params)
+
+	// cachedErr returns the error for the block associated with the given name
+	// in the harness generator from the cache of known invalid blocks and
+	// whether or not it is in the cache.
+	cachedErr := func(blockName string) (error, bool) {
+		t.Helper()
+
+		blockHash := g.BlockByName(blockName).BlockHash()
+		return g.chain.recentInvalidBlocks.Get(blockHash)
+	}
+
+	//   genesis -> bfb -> b2
+	g.CreateBlockOne("bfb", 0)
+	g.AcceptTipBlock()
+	g.NextBlock("b2", nil, nil)
+	g.AcceptTipBlock()
+
+	// Produce a block with more coinbase outputs than allowed and ensure it
+	// is rejected and added to the cache.
+	//
+	//   genesis -> bfb -> b2
+	//                       \-> b3bad
+	g.NextBlock("b3bad", nil, nil, func(b *wire.MsgBlock) {
+		coinbase := b.Transactions[0]
+		for len(coinbase.TxOut) <= maxCoinbaseOutputs(coinbase.Version) {
+			coinbase.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_TRUE}))
+		}
+	})
+	g.RejectTipBlock(ErrTooManyCoinbaseOutputs)
+	if err, ok := cachedErr("b3bad"); !ok {
+		t.Fatal("invalid block b3bad was not added to the invalid block cache")
+	} else if !errors.Is(err, ErrTooManyCoinbaseOutputs) {
+		t.Fatalf("unexpected cached error for b3bad -- got %v, want %v", err,
+			ErrTooManyCoinbaseOutputs)
+	}
+
+	// Replace the cached error with one that validation would never produce
+	// and ensure a second submission of the block is rejected with it, which
+	// proves the block was rejected from the cache without validating it
+	// again.
+	b3badHash := g.BlockByName("b3bad").BlockHash()
+	replacedErr := ruleError(ErrTooManyCoinbaseOutputs, "cached rejection")
+	g.chain.recentInvalidBlocks.Put(b3badHash, replacedErr)
+	block := dcrutil.NewBlock(g.BlockByName("b3bad"))
+	if _, err := g.chain.ProcessBlock(block); err != replacedErr {
+		t.Fatalf("unexpected error for resubmitted b3bad -- got %v, want %v",
+			err, replacedErr)
+	}
+
+	// Produce a block with a stake root that does not match its stake
+	// transactions and ensure it is rejected without being added to the cache
+	// since the same header could be relayed with the correct transactions.
+	//
+	//   genesis -> bfb -> b2
+	//                       \-> b3badroot
+	g.SetTip("b2")
+	g.NextBlock("b3badroot", nil, nil, func(b *wire.MsgBlock) {
+		b.Header.StakeRoot[0] ^= 0x01
+	})
+	g.RejectTipBlock(ErrBadMerkleRoot)
+	if _, ok := cachedErr("b3badroot"); ok {
+		t.Fatal("block b3badroot with a bad stake root was added to the " +
+			"invalid block cache")
+	}
+
+	// Ensure a valid block is still accepted and is not in the cache.
+	//
+	//   genesis -> bfb -> b2 -> b3
+	g.SetTip("b2")
+	g.NextBlock("b3", nil, nil)
+	g.AcceptTipBlock()
+	if _, ok := cachedErr("b3"); ok {
+		t.Fatal("valid block b3 is in the invalid block cache")
+	}
+}
@@ -8,9 +8,9 @@ import (
 	"fmt"
 
 	"github.com/decred/dcrd/blockchain/stake/v5"
-	"github.com/decred/dcrd/blockchain/standalone/v2"
 	"github.com/decred/dcrd/dcrutil/v4"
 	"github.com/decred/dcrd/wire"
+	"vigil.network/node/blockchain/standalone"
 )
 
 // SequenceLock represents the minimum timestamp and minimum block height after
@@ -7,7 +7,9 @@ package blockchain
 
 import (
 	"bytes"
+	"fmt"
 
+	"github.com/decred/dcrd/blockchain/stake/v5"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/dcrutil/v4"
 	"github.com/decred/dcrd/txscript/v4/stdaddr"
@@ -31,6 +33,86 @@ func (b *BlockChain) lotteryDataForNode(node *blockNode) ([]chainhash.Hash, int,
 	return stakeNode.Winners(), stakeNode.PoolSize(), stakeNode.FinalState(), nil
 }
 
+// calcLotteryWinners deterministically selects the provided number of unique
+// winning tickets from the provided live tickets, which must be sorted, using
+// a PRNG initialized with the provided lottery initialization vector.  It also
+// returns the final state of the lottery which commits to the winners and the
+// final state of the PRNG.
+//
+// The winners are returned in the order they were selected which is the same
+// order used by the stake node.
+func calcLotteryWinners(liveTickets []chainhash.Hash, lotteryIV chainhash.Hash, numWinners uint16) ([]chainhash.Hash, [6]byte, error) {
+	numLiveTickets := uint32(len(liveTickets))
+	if numLiveTickets < uint32(numWinners) {
+		str := fmt.Sprintf("the live ticket pool only has %d tickets which is "+
+			"less than the required minimum to choose %d winners",
+			numLiveTickets, numWinners)
+		return nil, [6]byte{}, AssertError(str)
+	}
+
+	// Select the winners while ensuring the same ticket is not selected more
+	// than once.
+	winners := make([]chainhash.Hash, 0, numWinners)
+	prng := stake.NewHash256PRNGFromIV(lotteryIV)
+	usedOffsets := make(map[uint32]struct{}, numWinners)
+	for uint16(len(winners)) < numWinners {
+		ticketIndex := prng.UniformRandom(numLiveTickets)
+		if _, exists := usedOffsets[ticketIndex]; !exists {
+			usedOffsets[ticketIndex] = struct{}{}
+			winners = append(winners, liveTickets[ticketIndex])
+		}
+	}
+
+	// The final state is the first 6 bytes of the hash of the winners followed
+	// by the final state of the PRNG.
+	stateBuffer := make([]byte, 0, (len(winners)+1)*chainhash.HashSize)
+	for i := range winners {
+		stateBuffer = append(stateBuffer, winners[i][:]...)
+	}
+	lastHash := prng.StateHash()
+	stateBuffer = append(stateBuffer, lastHash[:]...)
+	var finalState [6]byte
+	copy(finalState[:], chainhash.HashB(stateBuffer)[0:6])
+
+	return winners, finalState, nil
+}
+
+// lotteryWinners re-derives the tickets selected by the ticket lottery to vote
+// on the block after the provided node from the live ticket pool as of that
+// node and its lottery initialization vector.  It returns nil when voting is
+// not yet required for the block after the provided node.
+//
+// The re-derived final state of the lottery is checked against the final state
+// of the stake node for the provided node to ensure the live ticket pool and
+// the winners are consistent.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) lotteryWinners(prevNode *blockNode) ([]chainhash.Hash, error) {
+	// Voting does not start until stake validation height, so there are not
+	// any winners prior to that point.
+	if prevNode.height < b.chainParams.StakeValidationHeight-1 {
+		return nil, nil
+	}
+
+	stakeNode, err := b.fetchStakeNode(prevNode)
+	if err != nil {
+		return nil, err
+	}
+	winners, finalState, err := calcLotteryWinners(stakeNode.LiveTickets(),
+		prevNode.lotteryIV(), b.chainParams.TicketsPerBlock)
+	if err != nil {
+		return nil, err
+	}
+	if finalState != stakeNode.FinalState() {
+		str := fmt.Sprintf("re-derived lottery final state %x for block %s "+
+			"(height %d) does not match the stake node final state %x",
+			finalState, prevNode.hash, prevNode.height, stakeNode.FinalState())
+		return nil, AssertError(str)
+	}
+
+	return winners, nil
+}
+
 // lotteryDataForBlock takes a node block hash and returns the next tickets
 // eligible for voting, the number of tickets in the ticket pool, and the
 // final state of the PRNG.
@@ -10,11 +10,11 @@ import (
 	"fmt"
 
 	"github.com/decred/dcrd/blockchain/stake/v5"
-	"github.com/decred/dcrd/blockchain/standalone/v2"
 	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/dcrd/dcrutil/v4"
 	"github.com/decred/dcrd/txscript/v4"
 	"github.com/decred/dcrd/wire"
+	"vigil.network/node/blockchain/standalone"
 )
 
 // blockOneCoinbasePaysTokens checks to see if the first block coinbase pays
@@ -52,6 +52,19 @@ func blockOneCoinbasePaysTokens(tx *dcrutil.Tx, params *chaincfg.Params) error {
 		return ruleError(ErrBlockOneOutputs, str)
 	}
 
+	// Ensure the coinbase pays exactly the special block one subsidy defined
+	// by the ledger.
+	var totalOut int64
+	for _, txOut := range tx.MsgTx().TxOut {
+		totalOut += txOut.Value
+	}
+	if blockOneSubsidy := params.BlockOneSubsidy(); totalOut != blockOneSubsidy {
+		str := fmt.Sprintf("block 1 coinbase pays %v instead of the required "+
+			"block one subsidy of %v", dcrutil.Amount(totalOut),
+			dcrutil.Amount(blockOneSubsidy))
+		return ruleError(ErrBadCoinbaseValue, str)
+	}
+
 	// Check the addresses and output amounts against those in the ledger.
 	const consensusScriptVersion = 0
 	for i, txOut := range tx.MsgTx().TxOut {
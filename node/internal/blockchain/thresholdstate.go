@@ -824,6 +824,24 @@ func (b *BlockChain) isBlake3PowAgendaForcedActive() bool {
 	return state != nil && state.State == ThresholdActive
 }
 
+// powAlgorithm identifies a proof of work algorithm that may be active for a
+// block.
+type powAlgorithm uint8
+
+const (
+	// powAlgoUnknown indicates the active proof of work algorithm has not been
+	// determined.
+	powAlgoUnknown powAlgorithm = iota
+
+	// powAlgoBlake256 indicates the original proof of work algorithm and
+	// difficulty rules prior to the blake3 proof of work agenda are active.
+	powAlgoBlake256
+
+	// powAlgoBlake3 indicates the proof of work algorithm and difficulty rules
+	// defined by the blake3 proof of work agenda are active.
+	powAlgoBlake3
+)
+
 // isBlake3PowAgendaActive returns whether or not the agenda to change the proof
 // of work hash function to blake3, as defined in DCP0011, has passed and is now
 // active from the point of view of the passed block node.
@@ -833,8 +851,23 @@ func (b *BlockChain) isBlake3PowAgendaForcedActive() bool {
 // desired.  In other words, the returned deployment state is for the block
 // AFTER the passed node.
 //
+// The result is cached in the passed block node since the agenda is consulted
+// by every difficulty calculation and validation of a block.  The state of the
+// agenda for the block AFTER a given node only depends on the node and its
+// ancestors, which never change, so the cached result remains valid across
+// reorganizations.
+//
 // This function MUST be called with the chain state lock held (for writes).
 func (b *BlockChain) isBlake3PowAgendaActive(prevNode *blockNode) (bool, error) {
+	if prevNode != nil {
+		switch prevNode.nextPowAlgo {
+		case powAlgoBlake256:
+			return false, nil
+		case powAlgoBlake3:
+			return true, nil
+		}
+	}
+
 	const deploymentID = chaincfg.VoteIDBlake3Pow
 	deployment, ok := b.deploymentData[deploymentID]
 	if !ok {
@@ -846,7 +879,14 @@ func (b *BlockChain) isBlake3PowAgendaActive(prevNode *blockNode) (bool, error)
 	// here because there is only one possible choice that can be active for
 	// the agenda, which is yes, so there is no need to check it.
 	state := b.deploymentState(prevNode, &deployment)
-	return state.State == ThresholdActive, nil
+	isActive := state.State == ThresholdActive
+	if prevNode != nil {
+		prevNode.nextPowAlgo = powAlgoBlake256
+		if isActive {
+			prevNode.nextPowAlgo = powAlgoBlake3
+		}
+	}
+	return isActive, nil
 }
 
 // IsBlake3PowAgendaActive returns whether or not the agenda to change the proof
@@ -1,25 +1,695 @@
 package blockchain
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"math/big"
-	
+	"time"
+
+	"github.com/decred/dcrd/blockchain/stake/v5"
 	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/decred/dcrd/txscript/v4"
 	"github.com/decred/dcrd/wire"
-	"github.com/decred/dcrd/blockchain/standalone"
+	"vigil.network/node/blockchain/standalone"
+	"vigil.network/node/blockchain/standalone/kawpow"
 )
 
-func checkProofOfWork(header *wire.BlockHeader, powLimit *big.Int) error {
-	// For Vigil, we only use KawPoW (V2) for all blocks
-	powHash := header.PowHashV2()
-	
-	// Verify the PoW using KawPoW algorithm
-	err := standalone.CheckProofOfWork(&powHash, header.Bits, powLimit, &header.MixDigest)
+const (
+	// MaxSigOpsPerBlock is the maximum number of signature operations
+	// allowed for a block.  This really should be based upon the max
+	// allowed block size for a network and any votes that might change it,
+	// however, since it was not updated to be based upon it before
+	// release, it will require a hard fork and associated vote agenda to
+	// change it.  The original max block size for the protocol was 1MiB,
+	// so that is what this is based on.
+	MaxSigOpsPerBlock = 1000000 / 200
+
+	// MaxTimeOffsetSeconds is the maximum number of seconds a block time is
+	// allowed to be ahead of the current time.  This is currently 2 hours.
+	MaxTimeOffsetSeconds = 2 * 60 * 60
+)
+
+// checkProofOfWork ensures the KawPoW proof of work hash of the block header is
+// less than the target difficulty claimed by the header bits and that the mix
+// digest committed to by the header is the one produced while calculating it.
+//
+// The hash is calculated with the provided hasher, which defines the KawPoW
+// parameters for the network and whether the light verification cache or the
+// full DAG is used.
+func checkProofOfWork(header *wire.BlockHeader, powLimit *big.Int, kp *kawpow.KawPow) error {
+	// Note that headers that claim a target difficulty outside of the allowed
+	// range are rejected prior to incurring the cost of hashing.
+	err := standalone.CheckKawPoWProof(header, powLimit, kp)
 	return standaloneToChainRuleError(err)
 }
 
+// checkBlockProofOfWork ensures the KawPoW proof of work of the provided block
+// header is valid according to the KawPoW parameters of the network.
+//
+// The flags modify the behavior of this function as follows:
+//   - BFNoPoWCheck: The check is not performed, which is intended for headers
+//     that are already known to have recently passed it
+func (b *BlockChain) checkBlockProofOfWork(header *wire.BlockHeader, flags BehaviorFlags) error {
+	if flags&BFNoPoWCheck == BFNoPoWCheck {
+		return nil
+	}
+
+	return checkProofOfWork(header, b.chainParams.PowLimit, b.powHasher())
+}
+
+// powHasher returns the KawPoW hasher used to verify the proof of work of
+// blocks.  It is the full hasher when full DAG verification is enabled and the
+// light hasher otherwise.
+func (b *BlockChain) powHasher() *kawpow.KawPow {
+	if b.kawPowFull != nil {
+		return b.kawPowFull
+	}
+	return b.kawPow
+}
+
+// standaloneToChainRuleError attempts to convert the passed error from a
+// standalone.RuleError to a blockchain.RuleError with the equivalent error
+// kind.  Errors encountered while calculating the proof of work hash, such as
+// for a malformed header, are converted to an invalid proof of work rule error.
 func standaloneToChainRuleError(err error) error {
 	if err == nil {
 		return nil
 	}
-	return ruleError(ErrInvalidPoW, err.Error())
+
+	var rErr standalone.RuleError
+	if !errors.As(err, &rErr) {
+		str := fmt.Sprintf("unable to calculate proof of work hash: %v", err)
+		return ruleError(ErrInvalidPoW, str)
+	}
+	switch {
+	case errors.Is(err, standalone.ErrUnexpectedDifficulty):
+		return ruleError(ErrUnexpectedDifficulty, rErr.Description)
+	case errors.Is(err, standalone.ErrHighHash):
+		return ruleError(ErrHighHash, rErr.Description)
+	case errors.Is(err, standalone.ErrBadMixDigest):
+		return ruleError(ErrBadMixDigest, rErr.Description)
+	}
+	return ruleError(ErrInvalidPoW, rErr.Description)
+}
+
+// checkHeadersProofOfWork ensures the proof of work of every header in the
+// provided batch of headers is valid according to the provided function, which
+// is expected to verify the proof of work of a single header with the provided
+// light hasher.
+//
+// The headers are expected to be in height order as they are when received
+// during the headers sync process, so the verification cache for the epoch of
+// each run of headers in the same epoch is prepared once prior to verifying
+// all of the headers in that epoch together.
+//
+// The entire batch is rejected on the first header with an invalid proof of
+// work.
+func checkHeadersProofOfWork(headers []*wire.BlockHeader, kp *kawpow.KawPow, checkPoW func(*wire.BlockHeader) error) error {
+	kpParams := kp.Params()
+	prevEpoch := int64(-1)
+	for i, header := range headers {
+		height := int64(header.Height)
+		if epoch := kpParams.Epoch(height); epoch != prevEpoch {
+			kp.PrepareCache(height)
+			prevEpoch = epoch
+		}
+
+		if err := checkPoW(header); err != nil {
+			var rErr RuleError
+			if errors.As(err, &rErr) {
+				rErr.Description = fmt.Sprintf("header %d (hash %v, height "+
+					"%d) in batch: %s", i, header.BlockHash(), height,
+					rErr.Description)
+				return rErr
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckHeadersProofOfWork ensures the KawPoW proof of work of every header in
+// the provided batch of headers is valid.  It is intended to be used to
+// cheaply reject batches of headers that contain any low-work headers during
+// the initial headers sync process prior to processing them individually.
+//
+// Headers that claim a height implausibly far beyond the best known header are
+// rejected before any proof of work verification is performed.  The headers
+// must be in height order and the verification cache for each epoch the batch
+// spans is only prepared once.  The entire batch is rejected
+// on the first header with an invalid proof of work.
+//
+// The light verification cache is always used since this is only intended to
+// cheaply reject batches.  The proof of work of each header is verified again
+// with the full DAG as it is processed when full DAG verification is enabled.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) CheckHeadersProofOfWork(headers []*wire.BlockHeader) error {
+	// Reject the batch when any of the headers claim a height implausibly far
+	// beyond the best known header prior to performing any proof of work
+	// verification.
+	for _, header := range headers {
+		if err := b.checkKawPowEpochLookahead(header); err != nil {
+			return err
+		}
+	}
+
+	powLimit := b.chainParams.PowLimit
+	return checkHeadersProofOfWork(headers, b.kawPow,
+		func(header *wire.BlockHeader) error {
+			return checkProofOfWork(header, powLimit, b.kawPow)
+		})
+}
+
+// maxAllowedBlockSize returns the largest of the maximum block sizes permitted
+// by the provided network parameters.
+func maxAllowedBlockSize(chainParams *chaincfg.Params) int {
+	var maxSize int
+	for _, size := range chainParams.MaximumBlockSizes {
+		if size > maxSize {
+			maxSize = size
+		}
+	}
+	return maxSize
+}
+
+// maxCoinbaseWorkOutputs is the maximum number of outputs a coinbase
+// transaction may use to pay out the work subsidy and fees.  Miners may split
+// the payout among several outputs, for example to directly pay pool members,
+// but allowing an unbounded number would permit blocks to be bloated with
+// coinbase outputs.
+const maxCoinbaseWorkOutputs = 16
+
+// maxCoinbaseOutputs returns the maximum number of outputs a coinbase
+// transaction with the provided transaction version is permitted to have.
+//
+// Coinbase transactions have the following outputs:
+//   - Treasury output prior to the decentralized treasury agenda
+//   - Output that includes the block height and potential extra nonce
+//   - Up to maxCoinbaseWorkOutputs outputs that pay the work subsidy and fees
+//     to the miner
+//
+// The stake subsidy is paid by the votes in the stake tree and the treasury
+// subsidy is paid by the treasurybase once the decentralized treasury agenda
+// is active, so neither requires any additional coinbase outputs.  The agenda
+// is identified by the coinbase transaction version since it must be the
+// treasury version once the agenda is active.
+func maxCoinbaseOutputs(txVersion uint16) int {
+	if txVersion >= wire.TxVersionTreasury {
+		return 1 + maxCoinbaseWorkOutputs
+	}
+	return 2 + maxCoinbaseWorkOutputs
+}
+
+// checkCoinbaseOutputs ensures the provided coinbase transaction of a block at
+// the given height does not contain more outputs than are required to pay out
+// the subsidy.  This prevents blocks from being bloated with coinbase outputs
+// that serve no purpose.
+//
+// The genesis block and block one when it pays out the initial token ledger
+// are exempt since their outputs are defined by the network parameters.
+func checkCoinbaseOutputs(tx *wire.MsgTx, height int64, chainParams *chaincfg.Params) error {
+	if height == 0 || (height == 1 && len(chainParams.BlockOneLedger) != 0) {
+		return nil
+	}
+
+	maxOutputs := maxCoinbaseOutputs(tx.Version)
+	if len(tx.TxOut) > maxOutputs {
+		str := fmt.Sprintf("coinbase transaction has %d outputs which is "+
+			"more than the max allowed of %d", len(tx.TxOut), maxOutputs)
+		return ruleError(ErrTooManyCoinbaseOutputs, str)
+	}
+
+	return nil
+}
+
+// checkCoinbaseOutpoint ensures the provided coinbase transaction has a single
+// input that references the null outpoint, which is the all-zero hash along
+// with the maximum previous output index.  Since a coinbase creates new coins
+// as opposed to spending existing outputs, any other outpoint is malformed.
+func checkCoinbaseOutpoint(tx *wire.MsgTx) error {
+	if len(tx.TxIn) != 1 {
+		str := fmt.Sprintf("coinbase transaction has %d inputs instead of "+
+			"the required single input", len(tx.TxIn))
+		return ruleError(ErrBadCoinbaseOutpoint, str)
+	}
+
+	prevOut := &tx.TxIn[0].PreviousOutPoint
+	if prevOut.Hash != zeroHash || prevOut.Index != wire.MaxPrevOutIndex {
+		str := fmt.Sprintf("coinbase transaction input references outpoint "+
+			"%v instead of the null outpoint", prevOut)
+		return ruleError(ErrBadCoinbaseOutpoint, str)
+	}
+
+	return nil
+}
+
+// checkBlockHeaderSanity performs some preliminary checks on a block header to
+// ensure it is sane before continuing with processing.  These checks are
+// context free.
+//
+// This currently ensures the timestamp of the header is not more than
+// MaxTimeOffsetSeconds ahead of the provided adjusted network time.
+//
+// The flags do not modify the behavior of this function directly, however they
+// are needed to pass along to the functions it calls.
+func checkBlockHeaderSanity(header *wire.BlockHeader, timeSource MedianTimeSource, flags BehaviorFlags, chainParams *chaincfg.Params) error {
+	// Ensure the block time is not too far in the future.
+	maxTimestamp := timeSource.AdjustedTime().Add(time.Second *
+		MaxTimeOffsetSeconds)
+	if header.Timestamp.After(maxTimestamp) {
+		str := fmt.Sprintf("block timestamp of %v is too far in the future "+
+			"- max allowed %v", header.Timestamp, maxTimestamp)
+		return ruleError(ErrTimeTooNew, str)
+	}
+
+	return nil
+}
+
+// checkBlockSanity performs some preliminary checks on a block to ensure it is
+// sane before continuing with block processing.  These checks are context
+// free.
+//
+// The flags do not modify the behavior of this function directly, however they
+// are needed to pass along to the functions it calls.
+func checkBlockSanity(block *dcrutil.Block, timeSource MedianTimeSource, flags BehaviorFlags, chainParams *chaincfg.Params) error {
+	msgBlock := block.MsgBlock()
+	header := &msgBlock.Header
+	err := checkBlockHeaderSanity(header, timeSource, flags, chainParams)
+	if err != nil {
+		return err
+	}
+
+	// A block must not exceed the maximum allowed block payload when
+	// serialized.
+	serializedSize := msgBlock.SerializeSize()
+	maxBlockSize := maxAllowedBlockSize(chainParams)
+	if serializedSize > maxBlockSize {
+		str := fmt.Sprintf("serialized block is too big - got %d, max %d",
+			serializedSize, maxBlockSize)
+		return ruleError(ErrBlockTooBig, str)
+	}
+
+	// The size claimed by the header must match the actual serialized size
+	// of the block.
+	if header.Size != uint32(serializedSize) {
+		str := fmt.Sprintf("serialized block is not size indicated in header "+
+			"- got %d, expected %d", header.Size, serializedSize)
+		return ruleError(ErrWrongBlockSize, str)
+	}
+
+	// Build the merkle tree for the stake transaction tree and ensure the
+	// calculated merkle root matches the entry in the block header.
+	wantStakeRoot := standalone.CalcStakeMerkleRoot(msgBlock.STransactions)
+	if header.StakeRoot != wantStakeRoot {
+		str := fmt.Sprintf("block stake merkle root is invalid - block header "+
+			"indicates %v, but calculated value is %v", header.StakeRoot,
+			wantStakeRoot)
+		return ruleError(ErrBadMerkleRoot, str)
+	}
+
+	// The coinbase must reference the null outpoint and must not contain more
+	// outputs than are required to pay out the subsidy.
+	if len(msgBlock.Transactions) > 0 {
+		coinbase := msgBlock.Transactions[0]
+		if err := checkCoinbaseOutpoint(coinbase); err != nil {
+			return err
+		}
+		err := checkCoinbaseOutputs(coinbase, int64(header.Height),
+			chainParams)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Check for duplicate transactions across both the regular and stake
+	// transaction trees since they would otherwise corrupt the merkle roots
+	// and the utxo set.
+	numTxns := len(msgBlock.Transactions) + len(msgBlock.STransactions)
+	existingTxHashes := make(map[chainhash.Hash]struct{}, numTxns)
+	for _, txns := range [][]*dcrutil.Tx{block.Transactions(),
+		block.STransactions()} {
+
+		for _, tx := range txns {
+			hash := tx.Hash()
+			if _, exists := existingTxHashes[*hash]; exists {
+				str := fmt.Sprintf("block contains duplicate transaction %v",
+					hash)
+				return ruleError(ErrDuplicateTx, str)
+			}
+			existingTxHashes[*hash] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+// CheckBlockSanity performs some preliminary checks on a block to ensure it is
+// sane before continuing with block processing.  These checks are context
+// free.
+func CheckBlockSanity(block *dcrutil.Block, timeSource MedianTimeSource, chainParams *chaincfg.Params) error {
+	return checkBlockSanity(block, timeSource, BFNone, chainParams)
+}
+
+// checkBlockTimestamp ensures the timestamp of the provided block header is
+// after the median time of the last several blocks ending with the provided
+// previous node and is not more than the provided maximum regression before
+// the timestamp of the previous node itself.
+//
+// The regression is limited independently of the median time in order to
+// bound the negative time delta between a block and its parent observed by
+// the difficulty calculations, which depend on the time elapsed since prior
+// blocks.
+func checkBlockTimestamp(header *wire.BlockHeader, prevNode *blockNode, maxRegression time.Duration) error {
+	medianTime := prevNode.CalcPastMedianTime()
+	if !header.Timestamp.After(medianTime) {
+		str := fmt.Sprintf("block timestamp of %v is not after expected %v",
+			header.Timestamp, medianTime)
+		return ruleError(ErrTimeTooOld, str)
+	}
+
+	prevTime := time.Unix(prevNode.timestamp, 0)
+	minTime := prevTime.Add(-maxRegression)
+	if header.Timestamp.Before(minTime) {
+		str := fmt.Sprintf("block timestamp of %v is more than %v before "+
+			"the timestamp %v of its parent block %v", header.Timestamp,
+			maxRegression, prevTime, prevNode.hash)
+		return ruleError(ErrTimeTooOld, str)
+	}
+
+	return nil
+}
+
+// checkBlockContext performs several validation checks on the block which depend
+// on its position within the block chain and having the full block data for
+// all of its ancestors available.
+//
+// This currently ensures the timestamp is after the median time of the previous
+// blocks and does not regress too far before its parent, the header commits to
+// the height the block connects at, the difficulty bits in the header match
+// the required difficulty calculated from the previous block, and the block
+// does not exceed the maximum block size in effect for it.
+//
+// The flags do not modify the behavior of this function directly, however they
+// are needed to pass along to the checks it performs.
+//
+// This function MUST be called with the chain lock held (for writes).
+func (b *BlockChain) checkBlockContext(block *dcrutil.Block, prevNode *blockNode, flags BehaviorFlags) error {
+	// The genesis block is valid by definition.
+	if prevNode == nil {
+		return nil
+	}
+
+	// Ensure the timestamp is sane relative to the previous blocks prior to
+	// anything that makes use of it, such as the difficulty calculations.
+	header := &block.MsgBlock().Header
+	err := checkBlockTimestamp(header, prevNode,
+		b.chainParams.MaxBlockTimeRegression)
+	if err != nil {
+		return err
+	}
+
+	// Ensure the header commits to the correct height based on the height it
+	// actually connects in the blockchain.
+	blockHeight := prevNode.height + 1
+	if int64(header.Height) != blockHeight {
+		str := fmt.Sprintf("block header commitment to height %d does not "+
+			"match chain height %d", header.Height, blockHeight)
+		return ruleError(ErrBadBlockHeight, str)
+	}
+
+	// Ensure the difficulty specified in the block header matches the
+	// calculated difficulty based on the previous block and difficulty
+	// retarget rules.
+	expDiff, err := b.calcNextRequiredDifficulty(prevNode, header.Timestamp)
+	if err != nil {
+		return err
+	}
+	if header.Bits != expDiff {
+		str := fmt.Sprintf("block difficulty of %08x is not the expected "+
+			"value of %08x", header.Bits, expDiff)
+		return ruleError(ErrUnexpectedDifficulty, str)
+	}
+
+	// A block must not exceed the maximum allowed size as defined by the
+	// entry of the network parameters that is in effect for the block.  The
+	// context free sanity checks only limit the size to the largest entry.
+	//
+	// Note that the size claimed by the header is used since the sanity
+	// checks have already ensured it matches the actual serialized size.
+	maxBlockSize := b.maxBlockSize(prevNode)
+	if serializedSize := int64(header.Size); serializedSize > maxBlockSize {
+		str := fmt.Sprintf("serialized block is too big - got %d, max %d",
+			serializedSize, maxBlockSize)
+		return ruleError(ErrBlockTooBig, str)
+	}
+
+	return nil
+}
+
+// voteBitsApproveParent returns whether or not the passed vote bits indicate
+// the regular transaction tree of the parent block should be considered valid.
+func voteBitsApproveParent(voteBits uint16) bool {
+	return dcrutil.IsFlagSet16(voteBits, dcrutil.BlockValid)
+}
+
+// headerApprovesParent returns whether or not the vote bits in the passed
+// header indicate the regular transaction tree of the parent block should be
+// considered valid.
+func headerApprovesParent(header *wire.BlockHeader) bool {
+	return voteBitsApproveParent(header.VoteBits)
+}
+
+// checkVoteBits ensures the number of voters in the header of the provided
+// block matches the number of votes it contains and that the parent approval
+// bit of the header vote bits is consistent with the majority of the votes.
+// The parent is only approved when strictly more than half of the votes
+// approve it.
+func checkVoteBits(block *dcrutil.Block) error {
+	header := &block.MsgBlock().Header
+	var totalVotes, yesVotes int
+	for _, stx := range block.MsgBlock().STransactions {
+		if !stake.IsSSGen(stx) {
+			continue
+		}
+		totalVotes++
+		if voteBitsApproveParent(stake.SSGenVoteBits(stx)) {
+			yesVotes++
+		}
+	}
+
+	if totalVotes != int(header.Voters) {
+		str := fmt.Sprintf("block %s header commits to %d voters, but the "+
+			"block contains %d votes", block.Hash(), header.Voters,
+			totalVotes)
+		return ruleError(ErrVotesMismatch, str)
+	}
+
+	votesApprove := yesVotes*2 > totalVotes
+	if headerApprovesParent(header) != votesApprove {
+		str := fmt.Sprintf("block %s header parent approval bit is %v, but "+
+			"%d of %d votes approve the parent", block.Hash(),
+			headerApprovesParent(header), yesVotes, totalVotes)
+		return ruleError(ErrIncongruentVotebit, str)
+	}
+	return nil
+}
+
+// checkVoteTickets ensures every vote in the provided block spends a ticket
+// that was selected by the ticket lottery to vote on the block and that no
+// selected ticket is voted with more than once.
+func checkVoteTickets(block *dcrutil.Block, winners []chainhash.Hash) error {
+	eligible := make(map[chainhash.Hash]struct{}, len(winners))
+	for i := range winners {
+		eligible[winners[i]] = struct{}{}
+	}
+	for _, stx := range block.MsgBlock().STransactions {
+		if !stake.IsSSGen(stx) {
+			continue
+		}
+
+		// The ticket spent by a vote is always the second input.
+		ticketHash := stx.TxIn[1].PreviousOutPoint.Hash
+		if _, ok := eligible[ticketHash]; !ok {
+			str := fmt.Sprintf("block %s contains vote %s that spends ticket "+
+				"%s which was not selected to vote on the block", block.Hash(),
+				stx.TxHash(), ticketHash)
+			return ruleError(ErrTicketUnavailable, str)
+		}
+		delete(eligible, ticketHash)
+	}
+	return nil
+}
+
+// checkPoolSize ensures the ticket pool size committed to by the provided block
+// header matches the provided size of the live ticket pool as of its parent.
+//
+// The live ticket pool as of the parent is the pool of the grandparent plus the
+// tickets that matured in the parent less the tickets that were voted, revoked,
+// or expired by it, which is the pool the lottery for the block selects from.
+func checkPoolSize(header *wire.BlockHeader, parentPoolSize int) error {
+	if int64(header.PoolSize) != int64(parentPoolSize) {
+		str := fmt.Sprintf("block header commitment to pool size %d does "+
+			"not match expected size %d", header.PoolSize, parentPoolSize)
+		return ruleError(ErrPoolSize, str)
+	}
+	return nil
+}
+
+// IsFinalizedTransaction determines whether or not a transaction is finalized
+// for a block at the provided height and time.
+//
+// A transaction is finalized when its lock time is zero, when its lock time
+// has already passed, or when all of its inputs have a max sequence number.
+// The lock time is interpreted as a block height when it is less than
+// txscript.LockTimeThreshold and as a timestamp otherwise.
+func IsFinalizedTransaction(tx *dcrutil.Tx, blockHeight int64, blockTime time.Time) bool {
+	// Lock time of zero means the transaction is finalized.
+	msgTx := tx.MsgTx()
+	lockTime := msgTx.LockTime
+	if lockTime == 0 {
+		return true
+	}
+
+	// The lock time field of a transaction is either a block height at which
+	// the transaction is finalized or a timestamp depending on if the value is
+	// before the txscript.LockTimeThreshold.  When it is under the threshold
+	// it is a block height.
+	blockTimeOrHeight := blockTime.Unix()
+	if lockTime < txscript.LockTimeThreshold {
+		blockTimeOrHeight = blockHeight
+	}
+	if int64(lockTime) < blockTimeOrHeight {
+		return true
+	}
+
+	// At this point, the transaction's lock time hasn't occurred yet, but the
+	// transaction might still be finalized if the sequence number for all
+	// transaction inputs is maxed out.
+	for _, txIn := range msgTx.TxIn {
+		if txIn.Sequence != math.MaxUint32 {
+			return false
+		}
+	}
+	return true
+}
+
+// checkTxnsFinalized ensures all of the transactions in the provided block,
+// other than the coinbase, are finalized for the provided block height and
+// time.
+func checkTxnsFinalized(block *dcrutil.Block, blockHeight int64, blockTime time.Time) error {
+	checkTxns := func(txns []*dcrutil.Tx) error {
+		for _, tx := range txns {
+			if !IsFinalizedTransaction(tx, blockHeight, blockTime) {
+				str := fmt.Sprintf("block contains unfinalized transaction "+
+					"%v", tx.Hash())
+				return ruleError(ErrUnfinalizedTx, str)
+			}
+		}
+		return nil
+	}
+
+	// The coinbase is the first transaction in the regular tree.
+	if txns := block.Transactions(); len(txns) > 1 {
+		if err := checkTxns(txns[1:]); err != nil {
+			return err
+		}
+	}
+	return checkTxns(block.STransactions())
+}
+
+// checkConnectBlock performs several checks to confirm connecting the passed
+// block to the chain represented by the passed view does not violate any
+// rules.
+//
+// This currently ensures all non-coinbase transactions in the block are
+// finalized, that the coinbase of block one pays out exactly the initial token
+// ledger, that the header vote bits are consistent with the votes in the
+// block once stake validation is active, that the header commits to the
+// size of the live ticket pool as of the parent block, and that all votes in
+// the block spend tickets that were selected by the ticket lottery as of the
+// parent block.
+//
+// The view is updated to connect the block, which includes disconnecting all
+// of the transactions in the regular tree of the parent block when the block
+// disapproves it, and the header commitment data is populated accordingly.
+// When the 'stxos' argument is not nil, it will be updated to append an entry
+// for each spent txout.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) checkConnectBlock(node *blockNode, block, parent *dcrutil.Block, view *UtxoViewpoint, stxos *[]spentTxOut, hdrCommitments *headerCommitmentData) error {
+	// The genesis block is valid by definition.
+	if node.parent == nil {
+		return nil
+	}
+
+	// Ensure all transactions in the block are finalized.  The past median
+	// time of the parent is used for the block time rather than the timestamp
+	// of the block itself so that lock times are not subject to manipulation
+	// of the block timestamp by miners.
+	blockTime := node.parent.CalcPastMedianTime()
+	err := checkTxnsFinalized(block, node.height, blockTime)
+	if err != nil {
+		return err
+	}
+
+	// Ensure the coinbase of block one pays out exactly the initial token
+	// ledger defined by the network.
+	if node.height == 1 {
+		err := blockOneCoinbasePaysTokens(block.Transactions()[0],
+			b.chainParams)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Ensure the parent approval bit is consistent with the votes once they
+	// are required.
+	if node.height >= b.chainParams.StakeValidationHeight {
+		if err := checkVoteBits(block); err != nil {
+			return err
+		}
+	}
+
+	// Ensure the header commits to the correct pool size since it is used
+	// by the stake difficulty calculations and would otherwise allow the
+	// ticket price to be manipulated.
+	parentStakeNode, err := b.fetchStakeNode(node.parent)
+	if err != nil {
+		return err
+	}
+	header := &block.MsgBlock().Header
+	if err := checkPoolSize(header, parentStakeNode.PoolSize()); err != nil {
+		return err
+	}
+
+	winners, err := b.lotteryWinners(node.parent)
+	if err != nil {
+		return err
+	}
+	if err := checkVoteTickets(block, winners); err != nil {
+		return err
+	}
+
+	// Update the view to connect the block.  The regular transactions of the
+	// parent are rolled back when the block disapproves the parent.
+	isTreasuryEnabled, err := b.isTreasuryAgendaActive(node.parent)
+	if err != nil {
+		return err
+	}
+	err = view.connectBlock(b.db, block, parent, stxos, isTreasuryEnabled)
+	if err != nil {
+		return err
+	}
+
+	filter, err := b.loadOrCreateFilter(block, view)
+	if err != nil {
+		return err
+	}
+	hdrCommitments.filter = filter
+	hdrCommitments.filterHash = filter.Hash()
+	return nil
 }
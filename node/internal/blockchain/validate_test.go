@@ -18,11 +18,11 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/decred/dcrd/blockchain/stake/v5"
-	"github.com/decred/dcrd/blockchain/standalone/v2"
 	"github.com/decred/dcrd/blockchain/v5/chaingen"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/chaincfg/v3"
@@ -30,6 +30,8 @@ import (
 	"github.com/decred/dcrd/dcrutil/v4"
 	"github.com/decred/dcrd/txscript/v4"
 	"github.com/decred/dcrd/wire"
+	"vigil.network/node/blockchain/standalone"
+	"vigil.network/node/blockchain/standalone/kawpow"
 )
 
 // TestBlockchainSpendJournal tests for whether or not the spend journal is being
@@ -60,8 +62,9 @@ func TestBlockchainSpendJournal(t *testing.T) {
 	}}
 	params.GenesisHash = params.GenesisBlock.BlockHash()
 
-	// Create a new database and chain instance to run tests against.
-	chain, err := chainSetup(t, params)
+	// Create a new database and chain instance to run tests against.  Note
+	// that the legacy genesis block does not commit to its coinbase.
+	chain, err := legacyChainSetup(t, params)
 	if err != nil {
 		t.Errorf("Failed to setup chain instance: %v", err)
 		return
@@ -274,6 +277,1267 @@ func TestCheckBlockSanity(t *testing.T) {
 	}
 }
 
+// fixedTimeSource provides an implementation of the MedianTimeSource interface
+// whose adjusted time is always the provided time.
+type fixedTimeSource time.Time
+
+// AdjustedTime returns the fixed time.  It is part of the MedianTimeSource
+// interface implementation.
+func (f fixedTimeSource) AdjustedTime() time.Time {
+	return time.Time(f)
+}
+
+// AddTimeSample ignores the provided time sample.  It is part of the
+// MedianTimeSource interface implementation.
+func (f fixedTimeSource) AddTimeSample(string, time.Time) {}
+
+// Offset always returns zero.  It is part of the MedianTimeSource interface
+// implementation.
+func (f fixedTimeSource) Offset() time.Duration {
+	return 0
+}
+
+// TestCheckBlockSanityTimeTooNew ensures the block sanity checks reject blocks
+// whose timestamp is more than the maximum allowed offset ahead of the adjusted
+// network time and accept those that are at or before it.
+func TestCheckBlockSanityTimeTooNew(t *testing.T) {
+	params := chaincfg.RegNetParams()
+	now := time.Unix(time.Now().Unix(), 0)
+	timeSource := fixedTimeSource(now)
+	maxTimestamp := now.Add(MaxTimeOffsetSeconds * time.Second)
+
+	// Create a copy of the genesis block with the correct size in the header.
+	msgBlock := *params.GenesisBlock
+	msgBlock.Header.Size = uint32(msgBlock.SerializeSize())
+
+	tests := []struct {
+		name      string
+		timestamp time.Time
+		err       error
+	}{{
+		name:      "timestamp at adjusted time",
+		timestamp: now,
+		err:       nil,
+	}, {
+		name:      "timestamp exactly at max offset",
+		timestamp: maxTimestamp,
+		err:       nil,
+	}, {
+		name:      "timestamp one second beyond max offset",
+		timestamp: maxTimestamp.Add(time.Second),
+		err:       ErrTimeTooNew,
+	}}
+
+	for _, test := range tests {
+		testBlock := msgBlock
+		testBlock.Header.Timestamp = test.timestamp
+		err := checkBlockHeaderSanity(&testBlock.Header, timeSource, BFNone,
+			params)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: unexpected header error -- got %v, want %v",
+				test.name, err, test.err)
+		}
+
+		block := dcrutil.NewBlock(&testBlock)
+		err = CheckBlockSanity(block, timeSource, params)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: unexpected error -- got %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
+
+// TestCheckProofOfWorkSearch ensures a nonce found by the KawPoW nonce search
+// used for mining results in a header that passes the proof of work checks
+// performed by consensus.
+func TestCheckProofOfWorkSearch(t *testing.T) {
+	params := chaincfg.RegNetParams()
+	header := wire.BlockHeader{
+		Version:   1,
+		PrevBlock: params.GenesisHash,
+		Bits:      params.PowLimitBits,
+		Height:    1,
+		Timestamp: params.GenesisBlock.Header.Timestamp.Add(time.Minute),
+	}
+
+	kp := kawpow.NewLight()
+	target := standalone.CompactToBig(header.Bits)
+	nonce, mixDigest, found := kp.Search(header.BytesNoNonce(), target, 0, nil)
+	if !found {
+		t.Fatal("failed to find a nonce for an easy target difficulty")
+	}
+	header.Nonce = nonce
+	header.MixDigest = mixDigest
+
+	err := checkProofOfWork(&header, params.PowLimit, kp)
+	if err != nil {
+		t.Fatalf("header solved by search failed proof of work check: %v",
+			err)
+	}
+}
+
+// TestCheckBlockProofOfWorkNoPoWCheck ensures the proof of work check performed
+// while processing blocks rejects headers with an invalid proof of work unless
+// the flag to skip it is set.
+func TestCheckBlockProofOfWorkNoPoWCheck(t *testing.T) {
+	params := chaincfg.RegNetParams()
+	chain := &BlockChain{
+		chainParams: params,
+		kawPow:      kawpow.NewLightWithParams(kawPowParams(params)),
+	}
+
+	// Create a header that commits to a mix digest other than the one that is
+	// calculated for it so the proof of work check fails regardless of the
+	// resulting hash.
+	header := wire.BlockHeader{
+		Version:   1,
+		PrevBlock: params.GenesisHash,
+		Bits:      params.PowLimitBits,
+		Height:    1,
+		Timestamp: params.GenesisBlock.Header.Timestamp.Add(time.Minute),
+	}
+	mixDigest, err := chain.kawPow.ComputeMixDigest(header.BytesNoNonce(),
+		header.Nonce)
+	if err != nil {
+		t.Fatalf("unexpected error computing mix digest: %v", err)
+	}
+	header.MixDigest = mixDigest
+	header.MixDigest[0] ^= 0x01
+
+	err = chain.checkBlockProofOfWork(&header, BFNone)
+	if !errors.Is(err, ErrBadMixDigest) {
+		t.Fatalf("unexpected error -- got %v, want %v", err, ErrBadMixDigest)
+	}
+	if err := chain.checkBlockProofOfWork(&header, BFNoPoWCheck); err != nil {
+		t.Fatalf("unexpected error with proof of work check skipped: %v", err)
+	}
+}
+
+// TestCheckBlockSanitySize ensures the block sanity checks reject blocks whose
+// header claims a size that differs from the actual serialized size of the
+// block and accept those where it matches.
+func TestCheckBlockSanitySize(t *testing.T) {
+	params := chaincfg.RegNetParams()
+	timeSource := NewMedianTime()
+
+	// Create a copy of the genesis block with the correct size in the header.
+	msgBlock := *params.GenesisBlock
+	msgBlock.Header.Size = uint32(msgBlock.SerializeSize())
+
+	tests := []struct {
+		name     string
+		sizeDiff int64
+		err      error
+	}{{
+		name:     "correct header size",
+		sizeDiff: 0,
+		err:      nil,
+	}, {
+		name:     "header size too large",
+		sizeDiff: 1,
+		err:      ErrWrongBlockSize,
+	}, {
+		name:     "header size too small",
+		sizeDiff: -1,
+		err:      ErrWrongBlockSize,
+	}}
+
+	for _, test := range tests {
+		testBlock := msgBlock
+		testBlock.Header.Size = uint32(int64(msgBlock.Header.Size) +
+			test.sizeDiff)
+		block := dcrutil.NewBlock(&testBlock)
+		err := CheckBlockSanity(block, timeSource, params)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: unexpected error -- got %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
+
+// TestCheckBlockSanityStakeRoot ensures the stake merkle root committed to by a
+// block header must match the merkle root calculated from the stake
+// transactions in the block.
+func TestCheckBlockSanityStakeRoot(t *testing.T) {
+	params := chaincfg.RegNetParams()
+	timeSource := NewMedianTime()
+
+	// Create a stake transaction for use in the tests.
+	stakeTx := wire.NewMsgTx()
+	stakeTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, 0, nil))
+	stakeTx.AddTxOut(wire.NewTxOut(100000, []byte{txscript.OP_TRUE}))
+
+	tests := []struct {
+		name      string
+		stakeTxns []*wire.MsgTx
+		stakeRoot func(stakeTxns []*wire.MsgTx) chainhash.Hash
+		err       error
+	}{{
+		name:      "no stake txns with correct root",
+		stakeTxns: nil,
+		stakeRoot: standalone.CalcStakeMerkleRoot,
+		err:       nil,
+	}, {
+		name:      "stake tx with correct root",
+		stakeTxns: []*wire.MsgTx{stakeTx},
+		stakeRoot: standalone.CalcStakeMerkleRoot,
+		err:       nil,
+	}, {
+		name:      "stake tx with root that omits it",
+		stakeTxns: []*wire.MsgTx{stakeTx},
+		stakeRoot: func([]*wire.MsgTx) chainhash.Hash {
+			return standalone.CalcStakeMerkleRoot(nil)
+		},
+		err: ErrBadMerkleRoot,
+	}, {
+		name:      "stake tx with root of the regular tree",
+		stakeTxns: []*wire.MsgTx{stakeTx},
+		stakeRoot: func([]*wire.MsgTx) chainhash.Hash {
+			return params.GenesisBlock.Header.MerkleRoot
+		},
+		err: ErrBadMerkleRoot,
+	}}
+
+	for _, test := range tests {
+		// Create a copy of the genesis block with the test stake
+		// transactions, the test stake root, and the correct size in the
+		// header.
+		msgBlock := *params.GenesisBlock
+		msgBlock.STransactions = test.stakeTxns
+		msgBlock.Header.StakeRoot = test.stakeRoot(test.stakeTxns)
+		msgBlock.Header.Size = uint32(msgBlock.SerializeSize())
+
+		block := dcrutil.NewBlock(&msgBlock)
+		err := CheckBlockSanity(block, timeSource, params)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: unexpected error -- got %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
+
+// TestCheckBlockSanityCoinbaseOutputs ensures the block sanity checks reject
+// blocks with coinbase transactions that contain more outputs than are
+// required to pay out the subsidy and accept canonical coinbases.
+func TestCheckBlockSanityCoinbaseOutputs(t *testing.T) {
+	params := chaincfg.RegNetParams()
+	timeSource := NewMedianTime()
+
+	// createCoinbase returns a coinbase with the provided transaction version
+	// that has a treasury output prior to the decentralized treasury agenda, a
+	// height output, and the provided number of work payout outputs.
+	createCoinbase := func(txVersion uint16, numWorkOutputs int) *wire.MsgTx {
+		tx := wire.NewMsgTx()
+		tx.Version = txVersion
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+				wire.MaxPrevOutIndex, wire.TxTreeRegular),
+			Sequence:        wire.MaxTxInSequenceNum,
+			BlockHeight:     wire.NullBlockHeight,
+			BlockIndex:      wire.NullBlockIndex,
+			SignatureScript: []byte{txscript.OP_0, txscript.OP_0},
+		})
+		if txVersion < wire.TxVersionTreasury {
+			tx.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_TRUE}))
+		}
+		tx.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_RETURN,
+			txscript.OP_DATA_4, 0x02, 0x00, 0x00, 0x00}))
+		for i := 0; i < numWorkOutputs; i++ {
+			tx.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+		}
+		return tx
+	}
+
+	tests := []struct {
+		name     string
+		height   uint32
+		coinbase *wire.MsgTx
+		err      error
+	}{{
+		name:     "canonical coinbase",
+		height:   2,
+		coinbase: createCoinbase(wire.TxVersionTreasury, 1),
+		err:      nil,
+	}, {
+		name:     "canonical coinbase prior to treasury agenda",
+		height:   2,
+		coinbase: createCoinbase(1, 1),
+		err:      nil,
+	}, {
+		name:     "max work outputs",
+		height:   2,
+		coinbase: createCoinbase(wire.TxVersionTreasury, maxCoinbaseWorkOutputs),
+		err:      nil,
+	}, {
+		name:     "max work outputs prior to treasury agenda",
+		height:   2,
+		coinbase: createCoinbase(1, maxCoinbaseWorkOutputs),
+		err:      nil,
+	}, {
+		name:   "extra unauthorized output",
+		height: 2,
+		coinbase: createCoinbase(wire.TxVersionTreasury,
+			maxCoinbaseWorkOutputs+1),
+		err: ErrTooManyCoinbaseOutputs,
+	}, {
+		name:     "extra unauthorized output prior to treasury agenda",
+		height:   2,
+		coinbase: createCoinbase(1, maxCoinbaseWorkOutputs+1),
+		err:      ErrTooManyCoinbaseOutputs,
+	}, {
+		name:   "treasury agenda coinbase with one too many outputs",
+		height: 2,
+		coinbase: func() *wire.MsgTx {
+			tx := createCoinbase(1, maxCoinbaseWorkOutputs)
+			tx.Version = wire.TxVersionTreasury
+			return tx
+		}(),
+		err: ErrTooManyCoinbaseOutputs,
+	}}
+
+	for _, test := range tests {
+		// Create a copy of the genesis block with the test coinbase, the test
+		// height, and the correct size in the header.
+		msgBlock := *params.GenesisBlock
+		msgBlock.Transactions = []*wire.MsgTx{test.coinbase}
+		msgBlock.Header.Height = test.height
+		msgBlock.Header.Size = uint32(msgBlock.SerializeSize())
+
+		block := dcrutil.NewBlock(&msgBlock)
+		err := CheckBlockSanity(block, timeSource, params)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: unexpected error -- got %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
+
+// TestCheckBlockSanityCoinbaseOutpoint ensures the block sanity checks reject
+// blocks with coinbase transactions that do not reference the null outpoint
+// and accept those with the canonical form.
+func TestCheckBlockSanityCoinbaseOutpoint(t *testing.T) {
+	params := chaincfg.RegNetParams()
+	timeSource := NewMedianTime()
+
+	// createCoinbase returns a copy of the genesis block coinbase with its
+	// outpoint modified by the provided function.
+	genesisCoinbase := params.GenesisBlock.Transactions[0]
+	createCoinbase := func(modify func(prevOut *wire.OutPoint)) *wire.MsgTx {
+		tx := genesisCoinbase.Copy()
+		modify(&tx.TxIn[0].PreviousOutPoint)
+		return tx
+	}
+
+	tests := []struct {
+		name     string
+		coinbase *wire.MsgTx
+		err      error
+	}{{
+		name:     "canonical null outpoint",
+		coinbase: createCoinbase(func(*wire.OutPoint) {}),
+		err:      nil,
+	}, {
+		name: "non-zero hash",
+		coinbase: createCoinbase(func(prevOut *wire.OutPoint) {
+			prevOut.Hash[0] = 0x01
+		}),
+		err: ErrBadCoinbaseOutpoint,
+	}, {
+		name: "non-max index",
+		coinbase: createCoinbase(func(prevOut *wire.OutPoint) {
+			prevOut.Index = 0
+		}),
+		err: ErrBadCoinbaseOutpoint,
+	}, {
+		name: "references a regular outpoint",
+		coinbase: createCoinbase(func(prevOut *wire.OutPoint) {
+			prevOut.Hash = params.GenesisBlock.Header.MerkleRoot
+			prevOut.Index = 1
+		}),
+		err: ErrBadCoinbaseOutpoint,
+	}, {
+		name: "multiple inputs",
+		coinbase: func() *wire.MsgTx {
+			tx := genesisCoinbase.Copy()
+			tx.AddTxIn(tx.TxIn[0])
+			return tx
+		}(),
+		err: ErrBadCoinbaseOutpoint,
+	}}
+
+	for _, test := range tests {
+		// Create a copy of the genesis block with the test coinbase and the
+		// correct size in the header.
+		msgBlock := *params.GenesisBlock
+		msgBlock.Transactions = []*wire.MsgTx{test.coinbase}
+		msgBlock.Header.Size = uint32(msgBlock.SerializeSize())
+
+		block := dcrutil.NewBlock(&msgBlock)
+		err := CheckBlockSanity(block, timeSource, params)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: unexpected error -- got %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
+
+// TestCheckBlockSanityDuplicateTxns ensures the block sanity checks reject
+// blocks that contain the same transaction more than once in either or both of
+// the transaction trees and accept blocks with distinct transactions.
+func TestCheckBlockSanityDuplicateTxns(t *testing.T) {
+	params := chaincfg.RegNetParams()
+	timeSource := NewMedianTime()
+
+	// Create a couple of distinct transactions for use in the tests.
+	coinbase := params.GenesisBlock.Transactions[0]
+	tx1 := wire.NewMsgTx()
+	tx1.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, 0, nil))
+	tx1.AddTxOut(wire.NewTxOut(100000, []byte{txscript.OP_TRUE}))
+	tx2 := wire.NewMsgTx()
+	tx2.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 2}, 0, nil))
+	tx2.AddTxOut(wire.NewTxOut(100000, []byte{txscript.OP_TRUE}))
+
+	tests := []struct {
+		name      string
+		txns      []*wire.MsgTx
+		stakeTxns []*wire.MsgTx
+		err       error
+	}{{
+		name:      "distinct txns",
+		txns:      []*wire.MsgTx{coinbase, tx1},
+		stakeTxns: []*wire.MsgTx{tx2},
+		err:       nil,
+	}, {
+		name:      "duplicate regular txn",
+		txns:      []*wire.MsgTx{coinbase, tx1, tx1},
+		stakeTxns: nil,
+		err:       ErrDuplicateTx,
+	}, {
+		name:      "duplicate stake txn",
+		txns:      []*wire.MsgTx{coinbase},
+		stakeTxns: []*wire.MsgTx{tx2, tx2},
+		err:       ErrDuplicateTx,
+	}, {
+		name:      "txn duplicated across trees",
+		txns:      []*wire.MsgTx{coinbase, tx1},
+		stakeTxns: []*wire.MsgTx{tx1},
+		err:       ErrDuplicateTx,
+	}}
+
+	for _, test := range tests {
+		// Create a copy of the genesis block with the test transactions, the
+		// correct stake root, and the correct size in the header.
+		msgBlock := *params.GenesisBlock
+		msgBlock.Transactions = test.txns
+		msgBlock.STransactions = test.stakeTxns
+		msgBlock.Header.StakeRoot = standalone.CalcStakeMerkleRoot(
+			test.stakeTxns)
+		msgBlock.Header.Size = uint32(msgBlock.SerializeSize())
+
+		block := dcrutil.NewBlock(&msgBlock)
+		err := CheckBlockSanity(block, timeSource, params)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: unexpected error -- got %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
+
+// TestCheckBlockContextTimestamp ensures blocks with timestamps that are not
+// after the median time of the previous blocks or that are before the timestamp
+// of their parent are rejected.
+func TestCheckBlockContextTimestamp(t *testing.T) {
+	// Construct a synthetic block chain consisting of the following
+	// structure where each block is one second after its parent.
+	// 	genesis -> 1 -> 2 -> ... -> 15
+	params := chaincfg.RegNetParams()
+	chain := newFakeChain(params)
+	nodes := chainedFakeNodes(chain.bestChain.Genesis(), 15)
+	for _, node := range nodes {
+		chain.index.AddNode(node)
+	}
+	tip := branchTip(nodes)
+	chain.bestChain.SetTip(tip)
+	tipTime := time.Unix(tip.timestamp, 0)
+	medianTime := tip.CalcPastMedianTime()
+
+	tests := []struct {
+		name      string
+		timestamp time.Time
+		err       error
+	}{{
+		name:      "timestamp after parent",
+		timestamp: tipTime.Add(time.Second),
+		err:       nil,
+	}, {
+		name:      "timestamp same as parent",
+		timestamp: tipTime,
+		err:       nil,
+	}, {
+		name:      "timestamp before parent but after median time",
+		timestamp: tipTime.Add(-time.Second),
+		err:       ErrTimeTooOld,
+	}, {
+		name:      "timestamp at median time",
+		timestamp: medianTime,
+		err:       ErrTimeTooOld,
+	}, {
+		name:      "timestamp before median time",
+		timestamp: medianTime.Add(-time.Second),
+		err:       ErrTimeTooOld,
+	}}
+
+	for _, test := range tests {
+		bits, err := chain.calcNextRequiredDifficulty(tip, test.timestamp)
+		if err != nil {
+			t.Fatalf("%q: unexpected difficulty error: %v", test.name, err)
+		}
+		msgBlock := wire.MsgBlock{Header: wire.BlockHeader{
+			PrevBlock: tip.hash,
+			Height:    uint32(tip.height + 1),
+			Bits:      bits,
+			Timestamp: test.timestamp,
+		}}
+		block := dcrutil.NewBlock(&msgBlock)
+		err = chain.checkBlockContext(block, tip, BFNone)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: unexpected error -- got %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
+
+// TestCheckBlockContextTimeRegression ensures blocks with timestamps that are
+// after the median time of the previous blocks, but are more than the maximum
+// allowed regression before the timestamp of their parent, are rejected while
+// those that regress by no more than the maximum allowed are accepted.
+func TestCheckBlockContextTimeRegression(t *testing.T) {
+	// Construct a synthetic block chain consisting of the following
+	// structure where each block is one second after its parent except the
+	// final one which is ten minutes after its parent so that the median
+	// time is well before the timestamp of the tip.
+	// 	genesis -> 1 -> 2 -> ... -> 15 -> 16
+	const maxRegression = time.Minute
+	params := chaincfg.RegNetParams()
+	params.MaxBlockTimeRegression = maxRegression
+	chain := newFakeChain(params)
+	nodes := chainedFakeNodes(chain.bestChain.Genesis(), 15)
+	for _, node := range nodes {
+		chain.index.AddNode(node)
+	}
+	prevTip := branchTip(nodes)
+	tip := newFakeNode(prevTip, 1, 1, 0,
+		time.Unix(prevTip.timestamp, 0).Add(10*time.Minute))
+	chain.index.AddNode(tip)
+	chain.bestChain.SetTip(tip)
+	tipTime := time.Unix(tip.timestamp, 0)
+	medianTime := tip.CalcPastMedianTime()
+	if !tipTime.Add(-maxRegression * 2).After(medianTime) {
+		t.Fatalf("median time %v is not well before tip time %v", medianTime,
+			tipTime)
+	}
+
+	tests := []struct {
+		name      string
+		timestamp time.Time
+		err       error
+	}{{
+		name:      "timestamp after parent",
+		timestamp: tipTime.Add(time.Second),
+		err:       nil,
+	}, {
+		name:      "small regression within bounds",
+		timestamp: tipTime.Add(-time.Second),
+		err:       nil,
+	}, {
+		name:      "regression at max allowed",
+		timestamp: tipTime.Add(-maxRegression),
+		err:       nil,
+	}, {
+		name:      "regression just beyond max allowed",
+		timestamp: tipTime.Add(-maxRegression - time.Second),
+		err:       ErrTimeTooOld,
+	}, {
+		name:      "timestamp far below parent but after median time",
+		timestamp: medianTime.Add(time.Second),
+		err:       ErrTimeTooOld,
+	}}
+
+	for _, test := range tests {
+		bits, err := chain.calcNextRequiredDifficulty(tip, test.timestamp)
+		if err != nil {
+			t.Fatalf("%q: unexpected difficulty error: %v", test.name, err)
+		}
+		msgBlock := wire.MsgBlock{Header: wire.BlockHeader{
+			PrevBlock: tip.hash,
+			Height:    uint32(tip.height + 1),
+			Bits:      bits,
+			Timestamp: test.timestamp,
+		}}
+		block := dcrutil.NewBlock(&msgBlock)
+		err = chain.checkBlockContext(block, tip, BFNone)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: unexpected error -- got %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
+
+// TestCheckBlockContextHeight ensures blocks whose header commits to a height
+// other than the one after their parent are rejected.
+func TestCheckBlockContextHeight(t *testing.T) {
+	// Construct a synthetic block chain consisting of the following
+	// structure where each block is one second after its parent.
+	// 	genesis -> 1 -> 2 -> ... -> 15
+	params := chaincfg.RegNetParams()
+	chain := newFakeChain(params)
+	nodes := chainedFakeNodes(chain.bestChain.Genesis(), 15)
+	for _, node := range nodes {
+		chain.index.AddNode(node)
+	}
+	tip := branchTip(nodes)
+	chain.bestChain.SetTip(tip)
+	timestamp := time.Unix(tip.timestamp, 0).Add(time.Second)
+	bits, err := chain.calcNextRequiredDifficulty(tip, timestamp)
+	if err != nil {
+		t.Fatalf("unexpected difficulty error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		height int64
+		err    error
+	}{{
+		name:   "height after parent",
+		height: tip.height + 1,
+		err:    nil,
+	}, {
+		name:   "height same as parent",
+		height: tip.height,
+		err:    ErrBadBlockHeight,
+	}, {
+		name:   "height two after parent",
+		height: tip.height + 2,
+		err:    ErrBadBlockHeight,
+	}, {
+		name:   "height zero",
+		height: 0,
+		err:    ErrBadBlockHeight,
+	}}
+
+	for _, test := range tests {
+		msgBlock := wire.MsgBlock{Header: wire.BlockHeader{
+			PrevBlock: tip.hash,
+			Height:    uint32(test.height),
+			Bits:      bits,
+			Timestamp: timestamp,
+		}}
+		block := dcrutil.NewBlock(&msgBlock)
+		err := chain.checkBlockContext(block, tip, BFNone)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: unexpected error -- got %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
+
+// TestCheckBlockContextDifficulty ensures blocks whose header claims difficulty
+// bits other than the required difficulty calculated from their parent are
+// rejected.
+func TestCheckBlockContextDifficulty(t *testing.T) {
+	// Construct a synthetic block chain consisting of the following
+	// structure where each block is one second after its parent and has the
+	// proof of work limit difficulty.
+	// 	genesis -> 1 -> 2 -> ... -> 15
+	params := chaincfg.RegNetParams()
+	chain := newFakeChain(params)
+	tip := chain.bestChain.Genesis()
+	for i := 0; i < 15; i++ {
+		blockTime := time.Unix(tip.timestamp, 0).Add(time.Second)
+		tip = newFakeNode(tip, 1, 1, params.PowLimitBits, blockTime)
+		chain.index.AddNode(tip)
+	}
+	chain.bestChain.SetTip(tip)
+	timestamp := time.Unix(tip.timestamp, 0).Add(time.Second)
+	bits, err := chain.calcNextRequiredDifficulty(tip, timestamp)
+	if err != nil {
+		t.Fatalf("unexpected difficulty error: %v", err)
+	}
+	if bits == 0 {
+		t.Fatal("required difficulty is zero")
+	}
+
+	tests := []struct {
+		name string
+		bits uint32
+		err  error
+	}{{
+		name: "required difficulty",
+		bits: bits,
+		err:  nil,
+	}, {
+		name: "difficulty one more than required",
+		bits: bits + 1,
+		err:  ErrUnexpectedDifficulty,
+	}, {
+		name: "difficulty one less than required",
+		bits: bits - 1,
+		err:  ErrUnexpectedDifficulty,
+	}, {
+		name: "zero difficulty",
+		bits: 0,
+		err:  ErrUnexpectedDifficulty,
+	}}
+
+	for _, test := range tests {
+		msgBlock := wire.MsgBlock{Header: wire.BlockHeader{
+			PrevBlock: tip.hash,
+			Height:    uint32(tip.height + 1),
+			Bits:      test.bits,
+			Timestamp: timestamp,
+		}}
+		block := dcrutil.NewBlock(&msgBlock)
+		err := chain.checkBlockContext(block, tip, BFNone)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: unexpected error -- got %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
+
+// TestCheckBlockContextSize ensures blocks that exceed the maximum block size in
+// effect for them are rejected even when they do not exceed the largest
+// maximum block size defined by the network parameters.
+func TestCheckBlockContextSize(t *testing.T) {
+	// Construct a synthetic block chain consisting of the following
+	// structure where each block is one second after its parent.
+	// 	genesis -> 1 -> 2 -> ... -> 15
+	params := chaincfg.RegNetParams()
+	chain := newFakeChain(params)
+	nodes := chainedFakeNodes(chain.bestChain.Genesis(), 15)
+	for _, node := range nodes {
+		chain.index.AddNode(node)
+	}
+	tip := branchTip(nodes)
+	chain.bestChain.SetTip(tip)
+	timestamp := time.Unix(tip.timestamp, 0).Add(time.Second)
+	bits, err := chain.calcNextRequiredDifficulty(tip, timestamp)
+	if err != nil {
+		t.Fatalf("unexpected difficulty error: %v", err)
+	}
+
+	// Ensure the max block size in effect is the initial size and that it
+	// is smaller than the largest size so the test is meaningful.
+	maxSize := chain.maxBlockSize(tip)
+	if maxSize != int64(params.MaximumBlockSizes[0]) {
+		t.Fatalf("unexpected max block size %d", maxSize)
+	}
+	if int64(maxAllowedBlockSize(params)) <= maxSize {
+		t.Fatalf("max block size %d is already the largest size", maxSize)
+	}
+
+	tests := []struct {
+		name string
+		size int64
+		err  error
+	}{{
+		name: "size below max",
+		size: maxSize - 1,
+		err:  nil,
+	}, {
+		name: "size at max",
+		size: maxSize,
+		err:  nil,
+	}, {
+		name: "size one byte beyond max",
+		size: maxSize + 1,
+		err:  ErrBlockTooBig,
+	}, {
+		name: "size at largest max",
+		size: int64(maxAllowedBlockSize(params)),
+		err:  ErrBlockTooBig,
+	}}
+
+	for _, test := range tests {
+		msgBlock := wire.MsgBlock{Header: wire.BlockHeader{
+			PrevBlock: tip.hash,
+			Height:    uint32(tip.height + 1),
+			Bits:      bits,
+			Size:      uint32(test.size),
+			Timestamp: timestamp,
+		}}
+		block := dcrutil.NewBlock(&msgBlock)
+		err := chain.checkBlockContext(block, tip, BFNone)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q: unexpected error -- got %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
+
+// TestCalcLotteryWinners ensures the ticket lottery winners re-derived from a
+// fixed live ticket pool and lottery initialization vector are deterministic
+// and match the winners selected by the stake package.
+func TestCalcLotteryWinners(t *testing.T) {
+	// Create a sorted live ticket pool with fake ticket hashes.
+	liveTickets := make([]chainhash.Hash, 20)
+	for i := range liveTickets {
+		liveTickets[i] = chainhash.HashH([]byte{byte(i)})
+	}
+	sort.Slice(liveTickets, func(i, j int) bool {
+		return bytes.Compare(liveTickets[i][:], liveTickets[j][:]) < 0
+	})
+	lotteryIV := chainhash.HashH([]byte("lottery"))
+
+	// Ensure the expected winners and final state are selected.
+	const numWinners = 5
+	winners, finalState, err := calcLotteryWinners(liveTickets, lotteryIV,
+		numWinners)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantIdxs := []int{3, 13, 10, 14, 12}
+	wantWinners := make([]chainhash.Hash, 0, len(wantIdxs))
+	for _, idx := range wantIdxs {
+		wantWinners = append(wantWinners, liveTickets[idx])
+	}
+	if !reflect.DeepEqual(winners, wantWinners) {
+		t.Fatalf("mismatched winners -- got %v, want %v", winners, wantWinners)
+	}
+	wantFinalState := [6]byte{0x35, 0x92, 0xaf, 0x4b, 0x96, 0xef}
+	if finalState != wantFinalState {
+		t.Fatalf("mismatched final state -- got %x, want %x", finalState,
+			wantFinalState)
+	}
+
+	// Ensure selecting the winners again produces the same results.
+	winners2, finalState2, err := calcLotteryWinners(liveTickets, lotteryIV,
+		numWinners)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(winners, winners2) || finalState != finalState2 {
+		t.Fatal("lottery winners are not deterministic")
+	}
+
+	// Ensure a pool that is too small to select the winners is rejected.
+	_, _, err = calcLotteryWinners(liveTickets[:numWinners-1], lotteryIV,
+		numWinners)
+	var aerr AssertError
+	if !errors.As(err, &aerr) {
+		t.Fatalf("did not receive expected assert error -- got %v", err)
+	}
+}
+
+// TestDisapprovedParentRegularTxns ensures blocks with header vote bits that
+// are inconsistent with the majority of their votes are rejected and that a
+// block which disapproves its parent rolls back the regular transactions of
+// the parent.
+func TestDisapprovedParentRegularTxns(t *testing.T) {
+	// Create a test harness initialized with the genesis block as the tip.
+	params := chaincfg.RegNetParams()
+	g := newChaingenHarness(t, params)
+
+	const (
+		// vbDisapprovePrev and vbApprovePrev represent no and yes votes,
+		// respectively, on whether or not to approve the previous block.
+		vbDisapprovePrev = 0x0000
+		vbApprovePrev    = 0x0001
+	)
+
+	// isUnspent returns whether or not the provided outpoint is unspent as of
+	// the current tip.
+	isUnspent := func(outpoint wire.OutPoint) bool {
+		t.Helper()
+		entry, err := g.chain.FetchUtxoEntry(outpoint)
+		if err != nil {
+			t.Fatalf("unexpected error fetching utxo %v: %v", outpoint, err)
+		}
+		return entry != nil && !entry.IsSpent()
+	}
+
+	// ---------------------------------------------------------------------
+	// Generate and accept enough blocks to reach stake validation height.
+	// ---------------------------------------------------------------------
+
+	g.AdvanceToStakeValidationHeight()
+
+	// ---------------------------------------------------------------------
+	// Create a block with a regular transaction that spends a coinbase
+	// output.
+	//
+	//   ... -> bsv# -> bptx
+	// ---------------------------------------------------------------------
+
+	outs := g.OldestCoinbaseOuts()
+	spentOut := outs[0].PrevOut()
+	bptx := g.NextBlock("bptx", &outs[0], outs[1:])
+	g.SaveTipCoinbaseOuts()
+	g.AcceptTipBlock()
+	createdOut := wire.OutPoint{
+		Hash:  bptx.Transactions[1].TxHash(),
+		Index: 0,
+		Tree:  wire.TxTreeRegular,
+	}
+	if !isUnspent(createdOut) {
+		t.Fatalf("output %v created by bptx is not unspent", createdOut)
+	}
+	if isUnspent(spentOut) {
+		t.Fatalf("output %v spent by bptx is unspent", spentOut)
+	}
+
+	// ---------------------------------------------------------------------
+	// Create a block with a header that approves the parent even though all
+	// of the votes disapprove it.
+	//
+	//   ... -> bptx
+	//              \-> bbadvb
+	// ---------------------------------------------------------------------
+
+	outs = g.OldestCoinbaseOuts()
+	g.NextBlock("bbadvb", nil, outs[1:], g.ReplaceVoteBits(vbDisapprovePrev))
+	g.RejectTipBlock(ErrIncongruentVotebit)
+
+	// ---------------------------------------------------------------------
+	// Create a block that disapproves the parent and ensure the regular
+	// transactions of the parent are rolled back.
+	//
+	//   ... -> bptx -> bdisapprove
+	// ---------------------------------------------------------------------
+
+	g.SetTip("bptx")
+	g.NextBlock("bdisapprove", nil, outs[1:],
+		g.ReplaceVoteBits(vbDisapprovePrev),
+		func(b *wire.MsgBlock) {
+			b.Header.VoteBits &^= vbApprovePrev
+		})
+	g.AcceptTipBlock()
+	if isUnspent(createdOut) {
+		t.Fatalf("output %v created by disapproved bptx is unspent",
+			createdOut)
+	}
+	if !isUnspent(spentOut) {
+		t.Fatalf("output %v spent by disapproved bptx was not restored",
+			spentOut)
+	}
+}
+
+// TestCheckPoolSize ensures blocks with headers that commit to a ticket pool
+// size that does not match the size of the live ticket pool as of their parent
+// are rejected while those that commit to the correct size are accepted.
+func TestCheckPoolSize(t *testing.T) {
+	// Create a test harness initialized with the genesis block as the tip.
+	params := chaincfg.RegNetParams()
+	g := newChaingenHarness(t, params)
+
+	// ---------------------------------------------------------------------
+	// Generate and accept enough blocks to reach stake validation height.
+	// ---------------------------------------------------------------------
+
+	g.AdvanceToStakeValidationHeight()
+
+	// ---------------------------------------------------------------------
+	// Create blocks with headers that commit to pool sizes that are one
+	// more and one less than the correct size.
+	//
+	//   ... -> bsv#
+	//              \-> bpoolhigh
+	//              \-> bpoollow
+	// ---------------------------------------------------------------------
+
+	outs := g.OldestCoinbaseOuts()
+	tipName := g.TipName()
+	g.NextBlock("bpoolhigh", nil, outs[1:], func(b *wire.MsgBlock) {
+		b.Header.PoolSize++
+	})
+	g.RejectTipBlock(ErrPoolSize)
+
+	g.SetTip(tipName)
+	g.NextBlock("bpoollow", nil, outs[1:], func(b *wire.MsgBlock) {
+		b.Header.PoolSize--
+	})
+	g.RejectTipBlock(ErrPoolSize)
+
+	// ---------------------------------------------------------------------
+	// Create a block with a header that commits to the correct pool size.
+	//
+	//   ... -> bsv# -> bpoolok
+	// ---------------------------------------------------------------------
+
+	g.SetTip(tipName)
+	g.NextBlock("bpoolok", nil, outs[1:])
+	g.AcceptTipBlock()
+}
+
+// TestIsFinalizedTransaction ensures transactions are only considered
+// finalized when their lock time is zero, their lock time has passed, or all
+// of their inputs have a max sequence number.
+func TestIsFinalizedTransaction(t *testing.T) {
+	t.Parallel()
+
+	const blockHeight = 1000
+	blockTime := time.Unix(1600000000, 0)
+	tests := []struct {
+		name     string // test description
+		lockTime uint32 // transaction lock time
+		sequence uint32 // sequence number of the transaction input
+		want     bool   // expected result
+	}{{
+		name:     "zero lock time",
+		lockTime: 0,
+		sequence: 0,
+		want:     true,
+	}, {
+		name:     "lock height before block height",
+		lockTime: blockHeight - 1,
+		sequence: 0,
+		want:     true,
+	}, {
+		name:     "lock height equal to block height",
+		lockTime: blockHeight,
+		sequence: 0,
+		want:     false,
+	}, {
+		name:     "lock height above block height",
+		lockTime: blockHeight + 1,
+		sequence: 0,
+		want:     false,
+	}, {
+		name:     "lock height above block height with max sequence",
+		lockTime: blockHeight + 1,
+		sequence: wire.MaxTxInSequenceNum,
+		want:     true,
+	}, {
+		name:     "lock time before block time",
+		lockTime: uint32(blockTime.Unix() - 1),
+		sequence: 0,
+		want:     true,
+	}, {
+		name:     "lock time after block time",
+		lockTime: uint32(blockTime.Unix() + 1),
+		sequence: 0,
+		want:     false,
+	}, {
+		name:     "lock time after block time with max sequence",
+		lockTime: uint32(blockTime.Unix() + 1),
+		sequence: wire.MaxTxInSequenceNum,
+		want:     true,
+	}}
+
+	for _, test := range tests {
+		msgTx := wire.NewMsgTx()
+		msgTx.AddTxIn(&wire.TxIn{Sequence: test.sequence})
+		msgTx.LockTime = test.lockTime
+		tx := dcrutil.NewTx(msgTx)
+		got := IsFinalizedTransaction(tx, blockHeight, blockTime)
+		if got != test.want {
+			t.Errorf("%q: unexpected result -- got %v, want %v", test.name,
+				got, test.want)
+		}
+	}
+}
+
+// TestCheckTxnsFinalized ensures blocks that contain transactions with a lock
+// height above the height of the block are rejected while those that contain
+// finalized transactions are accepted.
+func TestCheckTxnsFinalized(t *testing.T) {
+	// Create a test harness initialized with the genesis block as the tip.
+	params := chaincfg.RegNetParams()
+	g := newChaingenHarness(t, params)
+
+	// ---------------------------------------------------------------------
+	// Generate and accept enough blocks to reach stake validation height.
+	// ---------------------------------------------------------------------
+
+	g.AdvanceToStakeValidationHeight()
+
+	// ---------------------------------------------------------------------
+	// Create a block that contains a transaction with a lock height above
+	// the height of the block and an input that does not have a max
+	// sequence number.  Note that the sequence number still disables the
+	// relative lock time so that only the absolute lock time applies.
+	//
+	//   ... -> bsv#
+	//              \-> bunfinalized
+	// ---------------------------------------------------------------------
+
+	outs := g.OldestCoinbaseOuts()
+	tipName := g.TipName()
+	g.NextBlock("bunfinalized", &outs[0], outs[1:], func(b *wire.MsgBlock) {
+		tx := b.Transactions[1]
+		tx.LockTime = b.Header.Height + 1
+		tx.TxIn[0].Sequence = wire.MaxTxInSequenceNum - 1
+	})
+	g.RejectTipBlock(ErrUnfinalizedTx)
+
+	// ---------------------------------------------------------------------
+	// Create a block that contains a transaction with a lock height that
+	// has already passed.
+	//
+	//   ... -> bsv# -> bfinalized
+	// ---------------------------------------------------------------------
+
+	g.SetTip(tipName)
+	g.NextBlock("bfinalized", &outs[0], outs[1:], func(b *wire.MsgBlock) {
+		tx := b.Transactions[1]
+		tx.LockTime = b.Header.Height - 1
+		tx.TxIn[0].Sequence = wire.MaxTxInSequenceNum - 1
+	})
+	g.AcceptTipBlock()
+}
+
+// TestBlockOneSubsidy ensures block one is only accepted when its coinbase
+// pays exactly the special block one subsidy to the initial token ledger.
+func TestBlockOneSubsidy(t *testing.T) {
+	// Create a test harness initialized with the genesis block as the tip.
+	params := chaincfg.RegNetParams()
+	g := newChaingenHarness(t, params)
+
+	// ---------------------------------------------------------------------
+	// Create a first block that pays more than the block one subsidy.
+	//
+	//   genesis -> bfbtoomuch
+	// ---------------------------------------------------------------------
+
+	g.CreateBlockOne("bfbtoomuch", 1)
+	g.RejectTipBlock(ErrBadCoinbaseValue)
+
+	// ---------------------------------------------------------------------
+	// Create a first block that pays less than the block one subsidy.
+	//
+	//   genesis -> bfbtoolittle
+	// ---------------------------------------------------------------------
+
+	g.SetTip("genesis")
+	g.CreateBlockOne("bfbtoolittle", -1)
+	g.RejectTipBlock(ErrBadCoinbaseValue)
+
+	// ---------------------------------------------------------------------
+	// Create a first block that pays the correct total block one subsidy,
+	// but does not pay the amounts required by the ledger to each output.
+	//
+	//   genesis -> bfbwrongsplit
+	// ---------------------------------------------------------------------
+
+	g.SetTip("genesis")
+	g.CreateBlockOne("bfbwrongsplit", 0, func(b *wire.MsgBlock) {
+		txOuts := b.Transactions[0].TxOut
+		txOuts[0].Value--
+		txOuts[1].Value++
+	})
+	g.RejectTipBlock(ErrBlockOneOutputs)
+
+	// ---------------------------------------------------------------------
+	// Create a first block that pays exactly the block one subsidy.
+	//
+	//   genesis -> bfb
+	// ---------------------------------------------------------------------
+
+	g.SetTip("genesis")
+	g.CreateBlockOne("bfb", 0)
+	g.AcceptTipBlock()
+}
+
+// TestCheckHeadersProofOfWork ensures batches of headers have the proof of
+// work of each header verified in order with the verification cache for each
+// epoch the batch spans prepared and that the entire batch is rejected on the
+// first header with an invalid proof of work.
+func TestCheckHeadersProofOfWork(t *testing.T) {
+	t.Parallel()
+
+	// Use small parameters so the epochs are short and the verification
+	// caches are cheap to generate.
+	kpParams := kawpow.Params{
+		EpochLength:        10,
+		DatasetInitBytes:   128 * 8,
+		DatasetGrowthBytes: 128,
+		CacheInitBytes:     1024,
+		CacheRounds:        3,
+	}
+
+	// makeHeaders returns a batch of headers for the provided inclusive range
+	// of heights.
+	makeHeaders := func(startHeight, endHeight uint32) []*wire.BlockHeader {
+		var headers []*wire.BlockHeader
+		for height := startHeight; height <= endHeight; height++ {
+			headers = append(headers, &wire.BlockHeader{Height: height})
+		}
+		return headers
+	}
+
+	// checkPoWFunc returns a function to check the proof of work of a single
+	// header that records the heights of the headers it checks and treats the
+	// header at the provided height as having an invalid proof of work.
+	errInvalid := ruleError(ErrInvalidPoW, "invalid proof of work")
+	checkPoWFunc := func(checked *[]uint32, badHeight uint32) func(*wire.BlockHeader) error {
+		return func(header *wire.BlockHeader) error {
+			*checked = append(*checked, header.Height)
+			if header.Height == badHeight {
+				return errInvalid
+			}
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name        string
+		startHeight uint32
+		endHeight   uint32
+		badHeight   uint32
+		wantErr     error
+		wantChecked []uint32
+		wantReady   []int64
+	}{{
+		name:        "all valid across epoch boundary",
+		startHeight: 7,
+		endHeight:   12,
+		badHeight:   0,
+		wantErr:     nil,
+		wantChecked: []uint32{7, 8, 9, 10, 11, 12},
+		wantReady:   []int64{7, 12},
+	}, {
+		name:        "one invalid after epoch boundary",
+		startHeight: 7,
+		endHeight:   14,
+		badHeight:   11,
+		wantErr:     ErrInvalidPoW,
+		wantChecked: []uint32{7, 8, 9, 10, 11},
+		wantReady:   []int64{7, 11},
+	}, {
+		name:        "first header invalid",
+		startHeight: 21,
+		endHeight:   24,
+		badHeight:   21,
+		wantErr:     ErrInvalidPoW,
+		wantChecked: []uint32{21},
+		wantReady:   []int64{21},
+	}}
+
+	for _, test := range tests {
+		kp := kawpow.NewLightWithParams(kpParams)
+		var checked []uint32
+		headers := makeHeaders(test.startHeight, test.endHeight)
+		err := checkHeadersProofOfWork(headers, kp, checkPoWFunc(&checked,
+			test.badHeight))
+		if !errors.Is(err, test.wantErr) {
+			t.Errorf("%q: mismatched err -- got %v, want %v", test.name, err,
+				test.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(checked, test.wantChecked) {
+			t.Errorf("%q: mismatched checked heights -- got %v, want %v",
+				test.name, checked, test.wantChecked)
+			continue
+		}
+		for _, height := range test.wantReady {
+			if !kp.CacheReady(height) {
+				t.Errorf("%q: cache for height %d is not ready", test.name,
+					height)
+			}
+		}
+	}
+}
+
 // TestCheckBlockHeaderContext tests that genesis block passes context headers
 // because its parent is nil.
 func TestCheckBlockHeaderContext(t *testing.T) {
@@ -2116,12 +3380,15 @@ func TestBlake3PowSemantics(t *testing.T) {
 	powLimit := params.PowLimit
 	isSolvedV1 := func(header *wire.BlockHeader) bool {
 		powHash := header.PowHashV1()
-		err := standalone.CheckProofOfWork(&powHash, header.Bits, powLimit, nil)
+		err := standalone.CheckProofOfWork(&powHash, header.Bits, powLimit)
 		return err == nil
 	}
 	isSolvedV2 := func(header *wire.BlockHeader) bool {
-		powHash := header.PowHashV2()
-		err := standalone.CheckProofOfWork(&powHash, header.Bits, powLimit, nil)
+		powHash, err := header.PowHashV2()
+		if err != nil {
+			t.Fatalf("unable to calculate proof of work hash: %v", err)
+		}
+		err = standalone.CheckProofOfWork(&powHash, header.Bits, powLimit)
 		return err == nil
 	}
 
@@ -73,6 +73,10 @@ const (
 	// rtForceRegen indicates the template should be regenerated even if
 	// it's not yet time for it to be regenerated.
 	rtForceRegen
+
+	// rtUpdateTxns indicates the current template should be updated with any
+	// new transactions without regenerating it.
+	rtUpdateTxns
 )
 
 // TemplateUpdateReason represents the type of a reason why a template is
@@ -125,6 +129,8 @@ type templateUpdate struct {
 //   - rtBlockDisconnected: *dcrutil.Block
 //   - rtVote:              *dcrutil.Tx
 //   - rtTemplateUpdated:   templateUpdate
+//   - rtForceRegen:        nil
+//   - rtUpdateTxns:        nil
 type regenEvent struct {
 	reason regenEventType
 	value  interface{}
@@ -1140,6 +1146,70 @@ func (g *BgBlkTmplGenerator) handleForceRegen(ctx context.Context, state *regenH
 	g.genTemplateAsync(ctx, turUnknown)
 }
 
+// updateTemplateTxnsAsync cancels any asynchronous block template that is
+// already currently being generated and launches a new goroutine to update the
+// provided template with any new transactions.  The current template associated
+// with the generator is only replaced, and subscribers notified, when the
+// update results in a new template.
+//
+// Failures to update the template are not treated as template errors since the
+// existing template is still valid in that case and will be periodically
+// regenerated as usual.
+func (g *BgBlkTmplGenerator) updateTemplateTxnsAsync(ctx context.Context, template *BlockTemplate) {
+	// Cancel any other templates that might currently be in the process of
+	// being generated and create a new context that can be cancelled for the
+	// updated template.
+	g.cancelTemplateMtx.Lock()
+	g.cancelTemplate()
+	ctx, g.cancelTemplate = context.WithCancel(ctx)
+	g.cancelTemplateMtx.Unlock()
+
+	go func(ctx context.Context) {
+		updated, err := g.tg.UpdateBlockTemplateTxns(template)
+		if err != nil {
+			log.Debugf("UpdateBlockTemplateTxns: %v", err)
+			return
+		}
+
+		// Don't update the state or notify subscribers when the update was
+		// cancelled or there were no new transactions to add.
+		if ctx.Err() != nil || updated == template {
+			return
+		}
+
+		g.setCurrentTemplate(updated, TURNewTxns, nil)
+		select {
+		case <-ctx.Done():
+		case g.notifySubscribers <- &TemplateNtfn{updated, TURNewTxns}:
+		}
+	}(ctx)
+}
+
+// handleUpdateTxns handles the rtUpdateTxns event by updating the current
+// template with any new transactions when it builds on the current tip or
+// generating a new template otherwise.
+//
+// This function is only intended for use by the regen handler goroutine.
+func (g *BgBlkTmplGenerator) handleUpdateTxns(ctx context.Context, state *regenHandlerState, chainTip *blockchain.BestState) {
+	// Ignore requests to update the template if the minimum amount of votes
+	// has been received and it's just waiting for the last ones to arrive.
+	// The template will be regenerated shortly in that case.
+	if state.maxVotesTimeout != nil {
+		return
+	}
+
+	g.templateMtx.Lock()
+	template := g.template
+	g.templateMtx.Unlock()
+	if template == nil || template.Block.Header.PrevBlock != chainTip.Hash {
+		state.stopRegenTimer()
+		state.failedGenRetryTimeout = nil
+		g.genTemplateAsync(ctx, TURNewTxns)
+		return
+	}
+	g.updateTemplateTxnsAsync(ctx, template)
+}
+
 // handleRegenEvent handles all regen events by determining the event reason and
 // reacting accordingly.  For example, it calls the appropriate associated event
 // handler for the events that have one and prevents templates from being
@@ -1228,6 +1298,9 @@ func (g *BgBlkTmplGenerator) handleRegenEvent(ctx context.Context, state *regenH
 
 	case rtForceRegen:
 		g.handleForceRegen(ctx, state)
+
+	case rtUpdateTxns:
+		g.handleUpdateTxns(ctx, state, chainTip)
 	}
 }
 
@@ -1465,6 +1538,25 @@ func (g *BgBlkTmplGenerator) ForceRegen() {
 	g.sendQueueRegenEvent(regenEvent{rtForceRegen, nil})
 }
 
+// UpdateTemplateTxns asks the background block template generator to update
+// the current template with any new transactions from the transaction source,
+// such as a newly received transaction that pays a high fee, without
+// regenerating the template.  This keeps the extra nonce layout of the
+// template intact so miners are able to switch to the updated work without
+// discarding their extra nonce progress.  Subscribers are notified of the
+// updated template with TURNewTxns.
+//
+// A new template is generated instead when the current template does not
+// build on the current tip.
+//
+// Note that there is no guarantee on whether the template will actually be
+// updated or when.  This function does _not_ block until it is updated.
+//
+// This function is safe for concurrent access.
+func (g *BgBlkTmplGenerator) UpdateTemplateTxns() {
+	g.sendQueueRegenEvent(regenEvent{rtUpdateTxns, nil})
+}
+
 // initialStartupHandler handles the initial startup of the background template
 // generation process.  This entails treating the tip block as if it was just
 // connected after potentially waiting for the initial chain sync to complete
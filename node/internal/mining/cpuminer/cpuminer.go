@@ -21,10 +21,10 @@ import (
 	"github.com/decred/dcrd/crypto/rand"
 	"github.com/decred/dcrd/dcrutil/v4"
 	"github.com/decred/dcrd/internal/blockchain"
-	"github.com/decred/dcrd/internal/kawpow"
 	"github.com/decred/dcrd/internal/mining"
 	"github.com/decred/dcrd/internal/staging/primitives"
 	"github.com/decred/dcrd/wire"
+	"vigil.network/node/blockchain/standalone/kawpow"
 )
 
 const (
@@ -69,6 +69,10 @@ type Config struct {
 	// associated with.
 	ChainParams *chaincfg.Params
 
+	// KawPowParams defines the KawPoW parameters of the network the CPU
+	// miner is associated with.
+	KawPowParams kawpow.Params
+
 	// PermitConnectionlessMining allows single node mining.
 	PermitConnectionlessMining bool
 
@@ -124,6 +128,13 @@ type CPUMiner struct {
 	speedStats        map[uint64]*speedStats
 	quit              chan struct{}
 
+	// kawPow is the KawPoW hasher used to solve blocks with the KawPoW
+	// parameters of the network.  It only makes use of the verification
+	// cache so mining does not require generating the multi-gigabyte full
+	// dataset, and it is shared by all workers so the cache is only
+	// generated once per epoch.
+	kawPow *kawpow.KawPow
+
 	// discretePrevTemplate is the template that was most recently mined by the
 	// discrete mining process.  It is used to provide a better user experience
 	// for the discrete mining process used in testing.
@@ -215,8 +226,11 @@ func (m *CPUMiner) submitBlock(block *dcrutil.Block) bool {
 	blockHash := block.Hash()
 	var powHashStr string
 	// KawPoW is the active PoW algorithm, so use PowHashV2.
-	powHash := block.MsgBlock().PowHashV2()
-	if powHash != *blockHash {
+	powHash, err := block.MsgBlock().Header.PowHashV2WithHasher(m.kawPow)
+	if err != nil {
+		log.Errorf("Unable to calculate proof of work hash for block %s: %v",
+			blockHash, err)
+	} else if powHash != *blockHash {
 		powHashStr = ", pow hash " + powHash.String()
 	}
 	log.Infof("Block submitted via CPU miner accepted (hash %s, height %d%s)",
@@ -246,7 +260,7 @@ func (m *CPUMiner) solveBlock(ctx context.Context, header *wire.BlockHeader,
 		return false
 	}
 
-	kp := kawpow.New()
+	kp := m.kawPow
 
 	for {
 		select {
@@ -767,6 +781,7 @@ func New(cfg *Config) *CPUMiner {
 		speedStats:        make(map[uint64]*speedStats),
 		minedOnParents:    make(map[chainhash.Hash]uint8),
 		quit:              make(chan struct{}),
+		kawPow:            kawpow.NewLightWithParams(cfg.KawPowParams),
 	}
 	miner.numWorkers.Store(defaultNumWorkers)
 	return miner
@@ -54,6 +54,15 @@ const (
 
 	// ErrSerializeHeader indicates an attempt to serialize a block header failed.
 	ErrSerializeHeader = ErrorKind("ErrSerializeHeader")
+
+	// ErrCoinbaseExtraTooLarge indicates the configured extra coinbase data
+	// would cause the coinbase signature script to exceed the maximum allowed
+	// length.
+	ErrCoinbaseExtraTooLarge = ErrorKind("ErrCoinbaseExtraTooLarge")
+
+	// ErrStaleTemplate indicates an attempt to update a block template that
+	// does not build on the current best block.
+	ErrStaleTemplate = ErrorKind("ErrStaleTemplate")
 )
 
 // Error satisfies the error interface and prints human-readable errors.
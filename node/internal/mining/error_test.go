@@ -27,6 +27,8 @@ func TestErrorKindStringer(t *testing.T) {
 		{ErrCalcCommitmentRoot, "ErrCalcCommitmentRoot"},
 		{ErrGetTicketInfo, "ErrGetTicketInfo"},
 		{ErrSerializeHeader, "ErrSerializeHeader"},
+		{ErrCoinbaseExtraTooLarge, "ErrCoinbaseExtraTooLarge"},
+		{ErrStaleTemplate, "ErrStaleTemplate"},
 	}
 
 	for i, test := range tests {
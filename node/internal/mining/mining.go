@@ -14,7 +14,6 @@ import (
 	"time"
 
 	"github.com/decred/dcrd/blockchain/stake/v5"
-	"github.com/decred/dcrd/blockchain/standalone/v2"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/dcrd/crypto/rand"
@@ -25,6 +24,7 @@ import (
 	"github.com/decred/dcrd/txscript/v4/stdaddr"
 	"github.com/decred/dcrd/txscript/v4/stdscript"
 	"github.com/decred/dcrd/wire"
+	"vigil.network/node/blockchain/standalone"
 )
 
 var (
@@ -279,6 +279,16 @@ const (
 	// sig.
 	coinbaseFlags = "/dcrd/"
 
+	// maxCoinbaseScriptLen is the maximum length a coinbase signature script
+	// is allowed to be by consensus.
+	maxCoinbaseScriptLen = 100
+
+	// MaxCoinbaseExtraLen is the maximum number of bytes of additional data
+	// that can be included in the coinbase signature script via the
+	// CoinbaseExtra policy setting.  It accounts for the two leading bytes
+	// and the standard coinbase flags that are always present.
+	MaxCoinbaseExtraLen = maxCoinbaseScriptLen - 2 - len(coinbaseFlags)
+
 	// kilobyte is the size of a kilobyte.
 	kilobyte = 1000
 )
@@ -472,6 +482,23 @@ func txIndexFromTxList(hash chainhash.Hash, list []*dcrutil.Tx) int {
 	return -1
 }
 
+// standardCoinbaseScript returns a standard coinbase signature script that
+// consists of two leading zero bytes followed by the standard coinbase flags and
+// the provided extra data.  An error is returned when the extra data would cause
+// the script to exceed the maximum allowed coinbase signature script length.
+func standardCoinbaseScript(extra []byte) ([]byte, error) {
+	if len(extra) > MaxCoinbaseExtraLen {
+		str := fmt.Sprintf("coinbase extra data of %d bytes exceeds the max "+
+			"allowed of %d bytes", len(extra), MaxCoinbaseExtraLen)
+		return nil, makeError(ErrCoinbaseExtraTooLarge, str)
+	}
+
+	coinbaseScript := make([]byte, 2, 2+len(coinbaseFlags)+len(extra))
+	coinbaseScript = append(coinbaseScript, coinbaseFlags...)
+	coinbaseScript = append(coinbaseScript, extra...)
+	return coinbaseScript, nil
+}
+
 // standardCoinbaseOpReturn creates a standard OP_RETURN output to insert into
 // coinbase. This function autogenerates the extranonce. The OP_RETURN pushes
 // 12 bytes.
@@ -830,8 +857,10 @@ func (g *BlkTmplGenerator) handleTooFewVoters(nextHeight int64,
 		block.Header = *tipHeader
 
 		// Create and populate a new coinbase.
-		coinbaseScript := make([]byte, len(coinbaseFlags)+2)
-		copy(coinbaseScript[2:], coinbaseFlags)
+		coinbaseScript, err := standardCoinbaseScript(g.cfg.Policy.CoinbaseExtra)
+		if err != nil {
+			return nil, err
+		}
 		opReturnPkScript, err := standardCoinbaseOpReturn(tipHeader.Height)
 		if err != nil {
 			return nil, err
@@ -1869,8 +1898,10 @@ nextPriorityQueueItem:
 	// to incorporate voters and potential voters.
 	//
 	// NOTE: we have to do this early to deal with stakebase.
-	coinbaseScript := []byte{0x00, 0x00}
-	coinbaseScript = append(coinbaseScript, []byte(coinbaseFlags)...)
+	coinbaseScript, err := standardCoinbaseScript(g.cfg.Policy.CoinbaseExtra)
+	if err != nil {
+		return nil, err
+	}
 
 	// Add a random coinbase nonce to ensure that tx prefix hash
 	// so that our merkle root is unique for lookups needed for
@@ -2350,6 +2381,280 @@ nextPriorityQueueItem:
 	return blockTemplate, nil
 }
 
+// UpdateBlockTemplateTxns returns a new block template that consists of the
+// passed block template with additional regular transactions from the
+// transaction source that are not already in it added, in order of their fee
+// per kilobyte, so long as they fit in the block.
+//
+// As opposed to generating a new template via NewBlockTemplate, the existing
+// transactions and stake tree are kept as is and only the coinbase output that
+// pays the work subsidy and fees, the merkle root, the commitment root, and the
+// block size are updated.  This means the coinbase output that houses the extra
+// nonce along with the header extra data keep the same layout, so miners are
+// able to switch to the updated work without discarding their extra nonce
+// progress.
+//
+// Transactions that spend outputs of transactions in the transaction source
+// that are not in the template are not added.
+//
+// The passed template is returned unmodified when there are no additional
+// transactions to add.  An error with ErrStaleTemplate is returned when the
+// template does not build on the current best block.
+func (g *BlkTmplGenerator) UpdateBlockTemplateTxns(template *BlockTemplate) (*BlockTemplate, error) {
+	best := g.cfg.BestSnapshot()
+	oldBlock := template.Block
+	prevHash := oldBlock.Header.PrevBlock
+	if prevHash != best.Hash {
+		str := fmt.Sprintf("block template builds on %v instead of the current "+
+			"best block %v", prevHash, best.Hash)
+		return nil, makeError(ErrStaleTemplate, str)
+	}
+	nextBlockHeight := int64(oldBlock.Header.Height)
+
+	isTreasuryEnabled, err := g.cfg.IsTreasuryAgendaActive(&prevHash)
+	if err != nil {
+		return nil, err
+	}
+	hdrCmtActive, err := g.cfg.IsHeaderCommitmentsAgendaActive(&prevHash)
+	if err != nil {
+		return nil, err
+	}
+	parentApproved := dcrutil.IsFlagSet16(oldBlock.Header.VoteBits,
+		dcrutil.BlockValid)
+
+	// Track the transactions that are already in the template along with the
+	// outputs they spend in order to avoid adding them again or adding double
+	// spends.  The index of the regular transactions is also tracked so
+	// transactions that spend their outputs are able to reference them.
+	numRegular := len(oldBlock.Transactions)
+	regularTxIdx := make(map[chainhash.Hash]uint32, numRegular)
+	inTemplate := make(map[chainhash.Hash]struct{}, numRegular+
+		len(oldBlock.STransactions))
+	spent := make(map[wire.OutPoint]struct{})
+	for i, tx := range oldBlock.Transactions {
+		txHash := tx.TxHash()
+		regularTxIdx[txHash] = uint32(i)
+		inTemplate[txHash] = struct{}{}
+		if i == 0 {
+			continue
+		}
+		for _, txIn := range tx.TxIn {
+			spent[txIn.PreviousOutPoint] = struct{}{}
+		}
+	}
+	for _, stx := range oldBlock.STransactions {
+		inTemplate[stx.TxHash()] = struct{}{}
+		for _, txIn := range stx.TxIn {
+			spent[txIn.PreviousOutPoint] = struct{}{}
+		}
+	}
+
+	// Gather the regular transactions from the transaction source that are
+	// candidates for inclusion and sort them by their fee per kilobyte.
+	var candidates []*TxDesc
+	for _, txDesc := range g.cfg.TxSource.MiningView().TxDescs() {
+		tx := txDesc.Tx
+		if txDesc.Type != stake.TxTypeRegular || tx.Tree() != wire.TxTreeRegular {
+			continue
+		}
+		if _, ok := inTemplate[*tx.Hash()]; ok {
+			continue
+		}
+		if standalone.IsCoinBaseTx(tx.MsgTx(), isTreasuryEnabled) {
+			continue
+		}
+		if !g.cfg.IsFinalizedTransaction(tx, nextBlockHeight, best.MedianTime) {
+			continue
+		}
+		candidates = append(candidates, txDesc)
+	}
+	feePerKb := func(txDesc *TxDesc) float64 {
+		return calcFeePerKb(txDesc, &TxAncestorStats{Fees: -1})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return feePerKb(candidates[i]) > feePerKb(candidates[j])
+	})
+
+	// Add the candidates that fit in the block and only spend outputs that are
+	// either available in the main chain or created by regular transactions in
+	// the block.  Note that the transactions are deep copied since the fraud
+	// proofs of their inputs are set.
+	blockSize := uint32(oldBlock.SerializeSize())
+	var blockSigOps int64
+	for _, numSigOps := range template.SigOpCounts {
+		blockSigOps += numSigOps
+	}
+	blockUtxos := g.cfg.NewUtxoViewpoint()
+	var addedTxns []*wire.MsgTx
+	var addedFees, addedSigOpCounts []int64
+	var totalAddedFees int64
+candidateLoop:
+	for _, txDesc := range candidates {
+		tx := txDesc.Tx
+		txSize := uint32(tx.MsgTx().SerializeSize())
+		if blockSize+txSize+wire.MaxVarIntPayload >= g.cfg.Policy.BlockMaxSize {
+			continue
+		}
+		numSigOps := int64(txDesc.TotalSigOps)
+		if blockSigOps+numSigOps > blockchain.MaxSigOpsPerBlock {
+			continue
+		}
+
+		utxos, err := g.cfg.FetchUtxoView(tx, parentApproved)
+		if err != nil {
+			log.Warnf("Unable to fetch utxo view for tx %s: %v", tx.Hash(),
+				err)
+			continue
+		}
+		txCopy := tx.MsgTx().Copy()
+		for _, txIn := range txCopy.TxIn {
+			prevOut := txIn.PreviousOutPoint
+			if _, ok := spent[prevOut]; ok {
+				continue candidateLoop
+			}
+			if idx, ok := regularTxIdx[prevOut.Hash]; ok {
+				if idx == 0 {
+					continue candidateLoop
+				}
+				var originTx *wire.MsgTx
+				if int(idx) < numRegular {
+					originTx = oldBlock.Transactions[idx]
+				} else {
+					originTx = addedTxns[int(idx)-numRegular]
+				}
+				if prevOut.Index >= uint32(len(originTx.TxOut)) {
+					continue candidateLoop
+				}
+				txIn.ValueIn = originTx.TxOut[prevOut.Index].Value
+				txIn.BlockHeight = uint32(nextBlockHeight)
+				txIn.BlockIndex = idx
+				continue
+			}
+			entry := utxos.LookupEntry(prevOut)
+			if entry == nil || entry.IsSpent() {
+				continue candidateLoop
+			}
+			txIn.ValueIn = entry.Amount()
+			txIn.BlockHeight = uint32(entry.BlockHeight())
+			txIn.BlockIndex = entry.BlockIndex()
+		}
+
+		txHash := txCopy.TxHash()
+		regularTxIdx[txHash] = uint32(numRegular + len(addedTxns))
+		for _, txIn := range txCopy.TxIn {
+			spent[txIn.PreviousOutPoint] = struct{}{}
+		}
+		mergeUtxoView(blockUtxos, utxos)
+		addedTxns = append(addedTxns, txCopy)
+		addedFees = append(addedFees, txDesc.Fee)
+		addedSigOpCounts = append(addedSigOpCounts, numSigOps)
+		totalAddedFees += txDesc.Fee
+		blockSize += txSize
+		blockSigOps += numSigOps
+	}
+	if len(addedTxns) == 0 {
+		return template, nil
+	}
+
+	// Scale the additional fees according to the number of voters once stake
+	// validation height is reached and pay them to the work subsidy output of
+	// a copy of the coinbase.
+	if nextBlockHeight >= g.cfg.ChainParams.StakeValidationHeight {
+		totalAddedFees *= int64(oldBlock.Header.Voters)
+		totalAddedFees /= int64(g.cfg.ChainParams.TicketsPerBlock)
+	}
+	coinbase := oldBlock.Transactions[0].Copy()
+	if nextBlockHeight > 1 {
+		powOutputIdx := 2
+		if isTreasuryEnabled {
+			powOutputIdx = 1
+		}
+		coinbase.TxOut[powOutputIdx].Value += totalAddedFees
+	}
+
+	// Assemble the updated block with the additional transactions after the
+	// existing ones.
+	var msgBlock wire.MsgBlock
+	msgBlock.Header = oldBlock.Header
+	msgBlock.Transactions = make([]*wire.MsgTx, 0, numRegular+len(addedTxns))
+	msgBlock.Transactions = append(msgBlock.Transactions, coinbase)
+	msgBlock.Transactions = append(msgBlock.Transactions,
+		oldBlock.Transactions[1:]...)
+	msgBlock.Transactions = append(msgBlock.Transactions, addedTxns...)
+	msgBlock.STransactions = oldBlock.STransactions
+
+	// Calculate the merkle root and, when the header commitments agenda is
+	// active, the commitment root, since it commits to the regular transactions
+	// as well.
+	msgBlock.Header.MerkleRoot = calcBlockMerkleRoot(msgBlock.Transactions,
+		msgBlock.STransactions, hdrCmtActive)
+	if hdrCmtActive {
+		for _, txns := range [][]*wire.MsgTx{oldBlock.Transactions[1:],
+			msgBlock.STransactions} {
+
+			for _, tx := range txns {
+				utxos, err := g.cfg.FetchUtxoView(dcrutil.NewTx(tx),
+					parentApproved)
+				if err != nil {
+					str := fmt.Sprintf("failed to fetch utxo view for tx "+
+						"%v: %s", tx.TxHash(), err)
+					return nil, makeError(ErrFetchTxStore, str)
+				}
+				mergeUtxoView(blockUtxos, utxos)
+			}
+		}
+		for i, tx := range msgBlock.Transactions {
+			blockUtxos.AddTxOuts(dcrutil.NewTx(tx), nextBlockHeight, uint32(i),
+				isTreasuryEnabled)
+		}
+		cmtRoot, err := calcBlockCommitmentRootV1(&msgBlock, blockUtxos)
+		if err != nil {
+			str := fmt.Sprintf("failed to calculate commitment root for block "+
+				"when updating block template: %v", err)
+			return nil, makeError(ErrCalcCommitmentRoot, str)
+		}
+		msgBlock.Header.StakeRoot = cmtRoot
+	}
+	msgBlock.Header.Size = uint32(msgBlock.SerializeSize())
+
+	// Perform a full check on the updated block against the chain consensus
+	// rules to ensure it properly connects to the current best chain with no
+	// issues.
+	block := dcrutil.NewBlockDeepCopyCoinbase(&msgBlock)
+	err = g.cfg.CheckConnectBlockTemplate(block)
+	if err != nil {
+		str := fmt.Sprintf("failed to do final check for check connect "+
+			"block when updating block template: %v", err)
+		return nil, makeError(ErrCheckConnectBlock, str)
+	}
+
+	// The fees and signature operation counts of the regular transactions
+	// precede those of the stake transactions, so insert the entries for the
+	// additional transactions between them.
+	fees := make([]int64, 0, len(template.Fees)+len(addedFees))
+	fees = append(fees, template.Fees[:numRegular]...)
+	fees = append(fees, addedFees...)
+	fees = append(fees, template.Fees[numRegular:]...)
+	fees[0] -= totalAddedFees
+	sigOpCounts := make([]int64, 0, len(template.SigOpCounts)+
+		len(addedSigOpCounts))
+	sigOpCounts = append(sigOpCounts, template.SigOpCounts[:numRegular]...)
+	sigOpCounts = append(sigOpCounts, addedSigOpCounts...)
+	sigOpCounts = append(sigOpCounts, template.SigOpCounts[numRegular:]...)
+
+	log.Debugf("Updated block template with %d additional transactions "+
+		"(%d in additional fees, %d bytes)", len(addedTxns), totalAddedFees,
+		msgBlock.Header.Size)
+
+	return &BlockTemplate{
+		Block:           &msgBlock,
+		Fees:            fees,
+		SigOpCounts:     sigOpCounts,
+		Height:          template.Height,
+		ValidPayAddress: template.ValidPayAddress,
+	}, nil
+}
+
 // UpdateBlockTime updates the timestamp in the passed header to the current
 // time while taking into account the median time of the last several blocks to
 // ensure the new time is after that time per the chain consensus rules.
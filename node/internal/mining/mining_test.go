@@ -5,6 +5,7 @@
 package mining
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"reflect"
@@ -17,6 +18,7 @@ import (
 	"github.com/decred/dcrd/txscript/v4"
 	"github.com/decred/dcrd/txscript/v4/stdaddr"
 	"github.com/decred/dcrd/wire"
+	"vigil.network/node/blockchain/standalone"
 )
 
 // TestNewBlockTemplateBasicErrorScenarios tests various basic error scenarios
@@ -189,6 +191,323 @@ func TestNewBlockTemplate(t *testing.T) {
 	}
 }
 
+// TestNewBlockTemplateConsistentWithTip ensures the header and coinbase of
+// generated block templates are consistent with the current chain tip.  That
+// is, the template builds on the tip, commits to the required difficulty, and
+// pays the expected subsidy plus the fees of the included transactions.
+func TestNewBlockTemplateConsistentWithTip(t *testing.T) {
+	t.Parallel()
+
+	// Create a new mining harness instance and mock a required difficulty that
+	// differs from the proof-of-work limit so it is detectable.
+	harness, spendableOuts, err := newMiningHarness(chaincfg.MainNetParams())
+	if err != nil {
+		t.Fatalf("error creating mining harness: %v", err)
+	}
+	const wantBits = 0x1b01ffff
+	harness.chain.calcNextRequiredDifficulty = wantBits
+
+	// Create a test address for use in template generation.
+	address, err := stdaddr.DecodeAddress("Dsi8CRt85xYyempXs7ZPL1rBxvDdAGZmgsg",
+		harness.chainParams)
+	if err != nil {
+		t.Fatalf("error decoding address: %v", err)
+	}
+
+	// Add a single fee-paying transaction to the tx source.
+	const fee = 10000
+	tx, err := harness.CreateSignedTx(spendableOuts, 1, func(tx *wire.MsgTx) {
+		tx.TxOut[0].Value -= fee
+	})
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	_, err = harness.AddTransactionToTxSource(tx)
+	if err != nil {
+		t.Fatalf("unable to add transaction to the tx source: %v", err)
+	}
+
+	// Generate a new block template.
+	blockTemplate, err := harness.generator.NewBlockTemplate(address)
+	if err != nil {
+		t.Fatalf("unexpected err generating block template: %v", err)
+	}
+	best := harness.chain.BestSnapshot()
+	header := &blockTemplate.Block.Header
+	wantHeight := best.Height + 1
+
+	// Ensure the header builds on the current tip with the required difficulty.
+	if header.PrevBlock != best.Hash {
+		t.Fatalf("unexpected prev block -- got %v, want %v", header.PrevBlock,
+			best.Hash)
+	}
+	if int64(header.Height) != wantHeight || blockTemplate.Height != wantHeight {
+		t.Fatalf("unexpected height -- got %d (template %d), want %d",
+			header.Height, blockTemplate.Height, wantHeight)
+	}
+	if header.Bits != wantBits {
+		t.Fatalf("unexpected bits -- got %08x, want %08x", header.Bits,
+			uint32(wantBits))
+	}
+	if !header.Timestamp.After(best.MedianTime) {
+		t.Fatalf("timestamp %v is not after the median time %v",
+			header.Timestamp, best.MedianTime)
+	}
+
+	// Ensure the fee-paying transaction was included and the merkle root
+	// commits to the regular transactions.
+	txns := blockTemplate.Block.Transactions
+	if len(txns) != 2 || txns[1].TxHash() != *tx.Hash() {
+		t.Fatalf("template does not contain the expected transactions")
+	}
+	wantMerkleRoot := standalone.CalcTxTreeMerkleRoot(txns)
+	if header.MerkleRoot != wantMerkleRoot {
+		t.Fatalf("unexpected merkle root -- got %v, want %v",
+			header.MerkleRoot, wantMerkleRoot)
+	}
+
+	// Ensure the coinbase pays the expected subsidy plus the fees.
+	subsidyCache := standalone.NewSubsidyCache(harness.chainParams)
+	wantSubsidy := subsidyCache.CalcWorkSubsidyV3(wantHeight, 0,
+		standalone.SSVOriginal) + subsidyCache.CalcTreasurySubsidy(wantHeight,
+		0, false)
+	coinbase := txns[0]
+	if coinbase.TxIn[0].ValueIn != wantSubsidy {
+		t.Fatalf("unexpected coinbase input value -- got %d, want %d",
+			coinbase.TxIn[0].ValueIn, wantSubsidy)
+	}
+	var totalOut int64
+	for _, txOut := range coinbase.TxOut {
+		totalOut += txOut.Value
+	}
+	if totalOut != wantSubsidy+fee {
+		t.Fatalf("unexpected coinbase output value -- got %d, want %d",
+			totalOut, wantSubsidy+fee)
+	}
+	if blockTemplate.Fees[0] != -fee {
+		t.Fatalf("unexpected coinbase fee -- got %d, want %d",
+			blockTemplate.Fees[0], -fee)
+	}
+}
+
+// TestUpdateBlockTemplateTxns ensures updating a block template with new
+// transactions from the transaction source adds them along with their fees
+// while keeping the extra nonce layout of the template intact.
+func TestUpdateBlockTemplateTxns(t *testing.T) {
+	t.Parallel()
+
+	// Create a new mining harness instance.
+	harness, spendableOuts, err := newMiningHarness(chaincfg.MainNetParams())
+	if err != nil {
+		t.Fatalf("error creating mining harness: %v", err)
+	}
+
+	// Create a test address for use in template generation.
+	address, err := stdaddr.DecodeAddress("Dsi8CRt85xYyempXs7ZPL1rBxvDdAGZmgsg",
+		harness.chainParams)
+	if err != nil {
+		t.Fatalf("error decoding address: %v", err)
+	}
+
+	// Add a fee-paying transaction to the tx source and generate a template
+	// that includes it.
+	const fee1 = 10000
+	tx1, err := harness.CreateSignedTx(spendableOuts, 2, func(tx *wire.MsgTx) {
+		tx.TxOut[0].Value -= fee1
+	})
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if _, err := harness.AddTransactionToTxSource(tx1); err != nil {
+		t.Fatalf("unable to add transaction to the tx source: %v", err)
+	}
+	template, err := harness.generator.NewBlockTemplate(address)
+	if err != nil {
+		t.Fatalf("unexpected err generating block template: %v", err)
+	}
+	oldBlock := template.Block
+
+	// Ensure updating the template without any new transactions returns the
+	// same template.
+	updated, err := harness.generator.UpdateBlockTemplateTxns(template)
+	if err != nil {
+		t.Fatalf("unexpected err updating block template: %v", err)
+	}
+	if updated != template {
+		t.Fatal("template without new transactions was not returned as is")
+	}
+
+	// Add a transaction that pays a higher fee and spends an output of the
+	// transaction that is already in the template, then update the template.
+	const fee2 = 50000
+	tx2, err := harness.CreateSignedTx([]spendableOutput{
+		txOutToSpendableOut(tx1, 1, wire.TxTreeRegular),
+	}, 1, func(tx *wire.MsgTx) {
+		tx.TxOut[0].Value -= fee2
+	})
+	if err != nil {
+		t.Fatalf("unable to create transaction: %v", err)
+	}
+	if _, err := harness.AddTransactionToTxSource(tx2); err != nil {
+		t.Fatalf("unable to add transaction to the tx source: %v", err)
+	}
+	updated, err = harness.generator.UpdateBlockTemplateTxns(template)
+	if err != nil {
+		t.Fatalf("unexpected err updating block template: %v", err)
+	}
+	newBlock := updated.Block
+
+	// Ensure the new transaction was added after the existing ones and the
+	// merkle root commits to the updated transactions.
+	txns := newBlock.Transactions
+	if len(txns) != 3 || txns[1].TxHash() != *tx1.Hash() ||
+		txns[2].TxHash() != *tx2.Hash() {
+
+		t.Fatal("updated template does not contain the expected transactions")
+	}
+	if newBlock.Header.MerkleRoot == oldBlock.Header.MerkleRoot {
+		t.Fatal("merkle root did not change")
+	}
+	wantMerkleRoot := calcBlockMerkleRoot(txns, newBlock.STransactions, true)
+	if newBlock.Header.MerkleRoot != wantMerkleRoot {
+		t.Fatalf("unexpected merkle root -- got %v, want %v",
+			newBlock.Header.MerkleRoot, wantMerkleRoot)
+	}
+	if newBlock.Header.Size != uint32(newBlock.SerializeSize()) {
+		t.Fatalf("unexpected block size -- got %d, want %d",
+			newBlock.Header.Size, newBlock.SerializeSize())
+	}
+	if txns[2].TxIn[0].BlockIndex != 1 {
+		t.Fatalf("unexpected fraud proof block index -- got %d, want 1",
+			txns[2].TxIn[0].BlockIndex)
+	}
+
+	// Ensure the extra nonce layout of the coinbase and header is unchanged
+	// and only the output that pays the work subsidy and fees changed.
+	oldCoinbase, newCoinbase := oldBlock.Transactions[0], txns[0]
+	if !bytes.Equal(newCoinbase.TxIn[0].SignatureScript,
+		oldCoinbase.TxIn[0].SignatureScript) {
+
+		t.Fatal("coinbase signature script changed")
+	}
+	if len(newCoinbase.TxOut) != len(oldCoinbase.TxOut) {
+		t.Fatalf("unexpected number of coinbase outputs -- got %d, want %d",
+			len(newCoinbase.TxOut), len(oldCoinbase.TxOut))
+	}
+	const extraNonceOutputIdx, powOutputIdx = 0, 1
+	if !bytes.Equal(newCoinbase.TxOut[extraNonceOutputIdx].PkScript,
+		oldCoinbase.TxOut[extraNonceOutputIdx].PkScript) {
+
+		t.Fatal("coinbase extra nonce output changed")
+	}
+	gotValue := newCoinbase.TxOut[powOutputIdx].Value
+	wantValue := oldCoinbase.TxOut[powOutputIdx].Value + fee2
+	if gotValue != wantValue {
+		t.Fatalf("unexpected coinbase work output value -- got %d, want %d",
+			gotValue, wantValue)
+	}
+	if newBlock.Header.ExtraData != oldBlock.Header.ExtraData {
+		t.Fatal("header extra data changed")
+	}
+	if oldBlock.Transactions[0].TxOut[powOutputIdx].Value !=
+		oldCoinbase.TxOut[powOutputIdx].Value || len(oldBlock.Transactions) != 2 {
+
+		t.Fatal("original template was modified")
+	}
+
+	// Ensure the fees and signature operation counts include the new
+	// transaction.
+	wantFees := []int64{-(fee1 + fee2), fee1, fee2}
+	if !reflect.DeepEqual(updated.Fees, wantFees) {
+		t.Fatalf("unexpected fees -- got %v, want %v", updated.Fees, wantFees)
+	}
+	if len(updated.SigOpCounts) != len(template.SigOpCounts)+1 {
+		t.Fatalf("unexpected number of sig op counts -- got %d, want %d",
+			len(updated.SigOpCounts), len(template.SigOpCounts)+1)
+	}
+
+	// Ensure a template that does not build on the current best block is
+	// rejected.
+	staleTemplate := *template
+	staleBlock := *template.Block
+	staleBlock.Header.PrevBlock = chainhash.Hash{0x01}
+	staleTemplate.Block = &staleBlock
+	_, err = harness.generator.UpdateBlockTemplateTxns(&staleTemplate)
+	if !errors.Is(err, ErrStaleTemplate) {
+		t.Fatalf("unexpected error -- got %v, want %v", err, ErrStaleTemplate)
+	}
+}
+
+// TestNewBlockTemplateCoinbaseExtra ensures the configured extra coinbase data
+// is included in the coinbase of generated block templates and that extra data
+// which exceeds the available space is rejected.
+func TestNewBlockTemplateCoinbaseExtra(t *testing.T) {
+	t.Parallel()
+
+	// Create a new mining harness instance.
+	harness, _, err := newMiningHarness(chaincfg.MainNetParams())
+	if err != nil {
+		t.Fatalf("error creating mining harness: %v", err)
+	}
+
+	// Create a test address for use in template generation.
+	address, err := stdaddr.DecodeAddress("Dsi8CRt85xYyempXs7ZPL1rBxvDdAGZmgsg",
+		harness.chainParams)
+	if err != nil {
+		t.Fatalf("error decoding address: %v", err)
+	}
+
+	// Generate a new block template with extra coinbase data configured.
+	coinbaseExtra := []byte("/testpool/")
+	harness.policy.CoinbaseExtra = coinbaseExtra
+	blockTemplate, err := harness.generator.NewBlockTemplate(address)
+	if err != nil {
+		t.Fatalf("unexpected err generating block template: %v", err)
+	}
+
+	// Ensure the coinbase signature script contains the standard flags followed
+	// by the configured extra data.
+	coinbaseScript := blockTemplate.Block.Transactions[0].TxIn[0].SignatureScript
+	wantScript := append([]byte{0x00, 0x00}, coinbaseFlags...)
+	wantScript = append(wantScript, coinbaseExtra...)
+	if !bytes.Equal(coinbaseScript, wantScript) {
+		t.Fatalf("unexpected coinbase script -- got %x, want %x",
+			coinbaseScript, wantScript)
+	}
+
+	// Ensure the block is sane which includes ensuring the merkle root commits
+	// to the coinbase with the extra data.
+	block := dcrutil.NewBlock(blockTemplate.Block)
+	err = blockchain.CheckBlockSanity(block, harness.generator.cfg.TimeSource,
+		harness.chainParams)
+	if err != nil {
+		t.Fatalf("unexpected error when checking block sanity: %v", err)
+	}
+
+	// Ensure the max allowed extra data is accepted and results in a coinbase
+	// script of the max allowed length.
+	harness.policy.CoinbaseExtra = bytes.Repeat([]byte{0x01},
+		MaxCoinbaseExtraLen)
+	blockTemplate, err = harness.generator.NewBlockTemplate(address)
+	if err != nil {
+		t.Fatalf("unexpected err generating block template: %v", err)
+	}
+	coinbaseScript = blockTemplate.Block.Transactions[0].TxIn[0].SignatureScript
+	if len(coinbaseScript) != maxCoinbaseScriptLen {
+		t.Fatalf("unexpected coinbase script length -- got %d, want %d",
+			len(coinbaseScript), maxCoinbaseScriptLen)
+	}
+
+	// Ensure extra data that exceeds the available space is rejected.
+	harness.policy.CoinbaseExtra = bytes.Repeat([]byte{0x01},
+		MaxCoinbaseExtraLen+1)
+	_, err = harness.generator.NewBlockTemplate(address)
+	if !errors.Is(err, ErrCoinbaseExtraTooLarge) {
+		t.Fatalf("unexpected error for oversized coinbase extra data -- got "+
+			"%v, want %v", err, ErrCoinbaseExtraTooLarge)
+	}
+}
+
 // TestNewBlockTemplateAutoRevocations tests the generation of a new block with
 // automatic ticket revocations enabled.
 func TestNewBlockTemplateAutoRevocations(t *testing.T) {
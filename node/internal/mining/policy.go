@@ -33,6 +33,11 @@ type Policy struct {
 
 	AggressiveMining bool
 
+	// CoinbaseExtra is optional data, such as a pool identifier, to include in
+	// the signature script of the coinbase of generated blocks after the
+	// standard coinbase flags.  It must not exceed MaxCoinbaseExtraLen bytes.
+	CoinbaseExtra []byte
+
 	// StandardVerifyFlags defines the function to retrieve the flags to
 	// use for verifying scripts for the block after the current best block.
 	// It must set the verification flags properly depending on the result
@@ -1320,6 +1320,16 @@ func (m *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
 		headerHashes = append(headerHashes, header.BlockHash())
 	}
 
+	// Ensure the proof of work of all of the received headers is valid prior
+	// to processing any of them so that batches of low-work headers are
+	// rejected as cheaply as possible.
+	if err := chain.CheckHeadersProofOfWork(headers); err != nil {
+		log.Debugf("Received block headers with invalid proof of work from "+
+			"peer %s: %v -- disconnecting", peer, err)
+		peer.Disconnect()
+		return
+	}
+
 	// Save the current best known header height prior to processing the headers
 	// so the code later is able to determine if any new useful headers were
 	// provided.
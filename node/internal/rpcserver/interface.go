@@ -24,6 +24,7 @@ import (
 	"github.com/decred/dcrd/rpc/jsonrpc/types/v4"
 	"github.com/decred/dcrd/txscript/v4/stdaddr"
 	"github.com/decred/dcrd/wire"
+	"vigil.network/node/blockchain/standalone/kawpow"
 )
 
 // ProfilerManager represents a profile server manager for use with the RPC
@@ -293,10 +294,19 @@ type Chain interface {
 	// exists in the live ticket treap of the best node.
 	CheckLiveTickets(hashes []chainhash.Hash) []bool
 
+	// CoinbaseMaturityHeight returns the first block height at which the
+	// outputs of a coinbase transaction mined in a block at the provided height
+	// may be spent.
+	CoinbaseMaturityHeight(minedHeight int64) int64
+
 	// CountVoteVersion returns the total number of version votes for the current
 	// rule change activation interval.
 	CountVoteVersion(version uint32) (uint32, error)
 
+	// DiffWindowStats returns statistics about the progress of the difficulty
+	// retarget window that contains the current best chain tip.
+	DiffWindowStats() *blockchain.DiffWindowStats
+
 	// EstimateNextStakeDifficulty estimates the next stake difficulty by pretending
 	// the provided number of tickets will be purchased in the remainder of the
 	// interval unless the flag to use max tickets is set in which case it will use
@@ -356,6 +366,11 @@ type Chain interface {
 	//  - Latest block has a timestamp newer than 24 hours ago
 	IsCurrent() bool
 
+	// LastValidationFailureTime returns the time a block was most recently
+	// found to have failed validation.  The zero time must be returned when no
+	// blocks have failed validation.
+	LastValidationFailureTime() time.Time
+
 	// LiveTickets returns all currently live tickets.
 	LiveTickets() ([]chainhash.Hash, error)
 
@@ -388,10 +403,22 @@ type Chain interface {
 	// or an error if it doesn't exist.
 	MedianTimeByHash(hash *chainhash.Hash) (time.Time, error)
 
+	// NextEpochHeight returns the height at which the next KawPoW epoch
+	// begins relative to the current best chain tip, the number of blocks
+	// remaining until that height is reached, and an estimate of when it will
+	// be reached.
+	NextEpochHeight() (int64, int64, time.Time)
+
 	// NextThresholdState returns the current rule change threshold state of the
 	// given deployment ID for the block AFTER the provided block hash.
 	NextThresholdState(hash *chainhash.Hash, deploymentID string) (blockchain.ThresholdStateTuple, error)
 
+	// SimulateDifficulty returns the required proof of work difficulty for each
+	// block in a hypothetical sequence of blocks that extends the current best
+	// chain tip where the passed intervals specify the number of seconds
+	// between each block and its parent.
+	SimulateDifficulty(intervals []int64) ([]blockchain.SimulatedDiff, error)
+
 	// StateLastChangedHeight returns the height at which the provided consensus
 	// deployment agenda last changed state.  Note that, unlike the
 	// NextThresholdState function, this function returns the information as of
@@ -515,6 +542,29 @@ type SanityChecker interface {
 	CheckBlockSanity(block *dcrutil.Block) error
 }
 
+// DAGManager represents a manager of the KawPoW DAG files cached on disk for
+// use with the RPC server.
+//
+// The interface contract requires that all of these methods are safe for
+// concurrent access.
+type DAGManager interface {
+	// RegenerateDAG removes the cached DAG file for the provided epoch and
+	// starts regenerating it in the background.  It returns the ID of the
+	// regeneration job without waiting for it to complete.
+	//
+	// kawpow.ErrDAGJobInProgress must be returned when another regeneration
+	// is still running.
+	RegenerateDAG(epoch int64) (uint64, error)
+
+	// LastJob returns the most recently started DAG regeneration job.  The
+	// returned flag is false when no job has been started.
+	LastJob() (kawpow.DAGJob, bool)
+
+	// DAGReady returns whether or not the complete DAG file for the provided
+	// epoch is cached on disk and is not currently being regenerated.
+	DAGReady(epoch int64) bool
+}
+
 // CPUMiner represents a CPU miner for use with the RPC server. The purpose of
 // this interface is to allow an alternative implementation to be used for
 // testing.
@@ -35,7 +35,6 @@ import (
 	"unicode/utf8"
 
 	"github.com/decred/dcrd/blockchain/stake/v5"
-	"github.com/decred/dcrd/blockchain/standalone/v2"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/dcrd/crypto/blake256"
@@ -44,6 +43,7 @@ import (
 	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
 	"github.com/decred/dcrd/dcrjson/v4"
 	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/decred/dcrd/gcs/v4"
 	"github.com/decred/dcrd/internal/blockchain"
 	"github.com/decred/dcrd/internal/mempool"
 	"github.com/decred/dcrd/internal/mining"
@@ -56,6 +56,8 @@ import (
 	"github.com/decred/dcrd/wire"
 	"github.com/gorilla/websocket"
 	"github.com/jrick/bitset"
+	"vigil.network/node/blockchain/standalone"
+	"vigil.network/node/blockchain/standalone/kawpow"
 )
 
 // API version constants.
@@ -104,6 +106,31 @@ const (
 	getworkDataLenBlake3 = ((wire.MaxBlockHeaderPayload + (blake3BlkSize - 1)) /
 		blake3BlkSize) * blake3BlkSize
 
+	// getworkNonceOffset is the offset of the 64-bit KawPoW nonce within the
+	// data field of the getwork RPC.  The nonce is part of the serialized
+	// block header, so it is encoded in the same little-endian byte order as
+	// all other integer header fields.  Miners that natively operate on
+	// big-endian nonces, such as those that are derived from ethash miners,
+	// must reverse the bytes of the nonce when submitting solved work.
+	getworkNonceOffset = 140
+
+	// getworkNonceSize is the number of bytes used to encode the KawPoW nonce
+	// within the data field of the getwork RPC.
+	getworkNonceSize = 8
+
+	// getworkMixDigestOffset is the offset of the KawPoW mix digest within the
+	// data field of the getwork RPC.
+	getworkMixDigestOffset = getworkNonceOffset + getworkNonceSize
+
+	// getworkMixDigestSize is the number of bytes in a KawPoW mix digest.
+	getworkMixDigestSize = 32
+
+	// getworkAlgoBlake256 and getworkAlgoKawPow are the values of the algo
+	// field of the getwork RPC result that identify the proof of work hash
+	// function miners must run to solve the provided work.
+	getworkAlgoBlake256 = "blake256"
+	getworkAlgoKawPow   = "kawpow"
+
 	// getworkExpirationDiff is the number of blocks below the current
 	// best block in height to begin pruning out old block work from
 	// the template pool.
@@ -117,6 +144,35 @@ const (
 	// of a block.
 	merkleRootPairSize = 64
 
+	// maxSimulateDifficultyIntervals is the maximum number of block intervals
+	// that may be provided to the simulatedifficulty RPC.
+	maxSimulateDifficultyIntervals = 10000
+
+	// maxKawPowSeedsPerRequest is the maximum number of epochs that may be
+	// requested by a single call to the getkawpowseeds RPC.
+	maxKawPowSeedsPerRequest = 1000
+
+	// healthMaxBlockAgeTargets is the number of target block times that may
+	// elapse since the timestamp of the best block before the gethealth RPC
+	// no longer considers the best block to be recent.  The odds of not
+	// finding a block for this many target block times with a steady hash
+	// rate are well under 0.01%.
+	healthMaxBlockAgeTargets = 10
+
+	// healthValidationFailureWindow is the amount of time a block failing
+	// validation causes the gethealth RPC to report recent validation
+	// errors.
+	healthValidationFailureWindow = time.Hour
+
+	// estimateConfirmTimeWindow is the number of most recent blocks the
+	// estimateconfirmtime RPC observes to determine the actual rate blocks
+	// are being found.
+	estimateConfirmTimeWindow = 144
+
+	// maxEstimateConfirmTimeConfs is the maximum number of confirmations
+	// that may be provided to the estimateconfirmtime RPC.
+	maxEstimateConfirmTimeConfs = 100000
+
 	// syncWait is the maximum time in seconds to wait for an index
 	// to sync with the main chain.
 	syncWait = time.Second * 3
@@ -175,8 +231,10 @@ var rpcHandlersBeforeInit = map[types.Method]commandHandler{
 	"createrawssrtx":        handleCreateRawSSRtx,
 	"createrawtransaction":  handleCreateRawTransaction,
 	"debuglevel":            handleDebugLevel,
+	"decodeblockheader":     handleDecodeBlockHeader,
 	"decoderawtransaction":  handleDecodeRawTransaction,
 	"decodescript":          handleDecodeScript,
+	"estimateconfirmtime":   handleEstimateConfirmTime,
 	"estimatefee":           handleEstimateFee,
 	"estimatesmartfee":      handleEstimateSmartFee,
 	"estimatestakediff":     handleEstimateStakeDiff,
@@ -195,16 +253,24 @@ var rpcHandlersBeforeInit = map[types.Method]commandHandler{
 	"getblockhash":          handleGetBlockHash,
 	"getblockheader":        handleGetBlockHeader,
 	"getblocksubsidy":       handleGetBlockSubsidy,
+	"getblocktemplate":      handleGetBlockTemplate,
+	"getcfilter":            handleGetCFilter,
+	"getcfilterheader":      handleGetCFilterHeader,
 	"getcfilterv2":          handleGetCFilterV2,
 	"getchaintips":          handleGetChainTips,
 	"getcoinsupply":         handleGetCoinSupply,
 	"getconnectioncount":    handleGetConnectionCount,
 	"getcurrentnet":         handleGetCurrentNet,
+	"getdaginfo":            handleGetDAGInfo,
 	"getdifficulty":         handleGetDifficulty,
+	"getdiffwindowstats":    handleGetDiffWindowStats,
 	"getgenerate":           handleGetGenerate,
 	"gethashespersec":       handleGetHashesPerSec,
 	"getheaders":            handleGetHeaders,
+	"gethealth":             handleGetHealth,
 	"getinfo":               handleGetInfo,
+	"getkawpowparams":       handleGetKawPowParams,
+	"getkawpowseeds":        handleGetKawPowSeeds,
 	"getmempoolinfo":        handleGetMempoolInfo,
 	"getmininginfo":         handleGetMiningInfo,
 	"getmixmessage":         handleGetMixMessage,
@@ -231,10 +297,12 @@ var rpcHandlersBeforeInit = map[types.Method]commandHandler{
 	"node":                  handleNode,
 	"ping":                  handlePing,
 	"reconsiderblock":       handleReconsiderBlock,
+	"regeneratedag":         handleRegenerateDAG,
 	"regentemplate":         handleRegenTemplate,
 	"sendrawmixmessage":     handleSendRawMixMessage,
 	"sendrawtransaction":    handleSendRawTransaction,
 	"setgenerate":           handleSetGenerate,
+	"simulatedifficulty":    handleSimulateDifficulty,
 	"startprofiler":         handleStartProfiler,
 	"stop":                  handleStop,
 	"stopprofiler":          handleStopProfiler,
@@ -353,8 +421,10 @@ var rpcLimited = map[string]struct{}{
 	"createrawsstx":        {},
 	"createrawssrtx":       {},
 	"createrawtransaction": {},
+	"decodeblockheader":    {},
 	"decoderawtransaction": {},
 	"decodescript":         {},
+	"estimateconfirmtime":  {},
 	"estimatefee":          {},
 	"estimatesmartfee":     {},
 	"estimatestakediff":    {},
@@ -371,13 +441,20 @@ var rpcLimited = map[string]struct{}{
 	"getblockhash":         {},
 	"getblockheader":       {},
 	"getblocksubsidy":      {},
+	"getcfilter":           {},
+	"getcfilterheader":     {},
 	"getcfilterv2":         {},
 	"getchaintips":         {},
 	"getcoinsupply":        {},
 	"getcurrentnet":        {},
+	"getdaginfo":           {},
 	"getdifficulty":        {},
+	"getdiffwindowstats":   {},
 	"getheaders":           {},
+	"gethealth":            {},
 	"getinfo":              {},
+	"getkawpowparams":      {},
+	"getkawpowseeds":       {},
 	"getmixmessage":        {},
 	"getmixpairrequests":   {},
 	"getnettotals":         {},
@@ -405,6 +482,60 @@ var rpcLimited = map[string]struct{}{
 	"version":              {},
 }
 
+// Commands that are available to a mining user.  Mining credentials are
+// intended for pool and miner software and are therefore restricted to the
+// methods required to obtain and submit work.
+var rpcMining = map[string]struct{}{
+	"getbestblockhash": {},
+	"getblockcount":    {},
+	"getblocktemplate": {},
+	"getdaginfo":       {},
+	"getkawpowseeds":   {},
+	"getmininginfo":    {},
+	"getwork":          {},
+	"regentemplate":    {},
+	"submitblock":      {},
+}
+
+// authScope identifies the set of RPC methods an authenticated client is
+// permitted to invoke.
+type authScope uint8
+
+const (
+	// authScopeLimited permits only the methods in rpcLimited.
+	authScopeLimited authScope = iota
+
+	// authScopeMining permits only the methods in rpcMining.
+	authScopeMining
+
+	// authScopeAdmin permits all methods, including those that change the
+	// state of the server.
+	authScopeAdmin
+)
+
+// authorized returns whether a client with the scope is permitted to invoke
+// the provided method.
+func (scope authScope) authorized(method string) bool {
+	switch scope {
+	case authScopeAdmin:
+		return true
+	case authScopeMining:
+		_, ok := rpcMining[method]
+		return ok
+	}
+	_, ok := rpcLimited[method]
+	return ok
+}
+
+// notAuthorizedMsg returns the error message returned to clients with the scope
+// that attempt to invoke a method they are not permitted to invoke.
+func (scope authScope) notAuthorizedMsg() string {
+	if scope == authScopeMining {
+		return "mining user not authorized for this method"
+	}
+	return "limited user not authorized for this method"
+}
+
 // rpcInternalErr is a convenience function to convert an internal error to an
 // RPC error with the appropriate code set.  It also logs the error to the RPC
 // server subsystem since internal errors really should not occur.  The context
@@ -1335,11 +1466,73 @@ func (s *Server) createTxRawResult(chainParams *chaincfg.Params,
 		txReply.Blocktime = blkHeader.Timestamp.Unix()
 		txReply.BlockHash = blkHash
 		txReply.Confirmations = confirmations
+
+		// Coinbase outputs may not be spent until they reach maturity, so
+		// include the height at which that happens to allow wallets to
+		// determine when they become spendable.
+		if standalone.IsCoinBaseTx(mtx, isTreasuryEnabled) {
+			txReply.MaturityHeight = s.cfg.Chain.CoinbaseMaturityHeight(
+				blkHeight)
+		}
 	}
 
 	return txReply, nil
 }
 
+// handleDecodeBlockHeader handles decodeblockheader commands.
+func handleDecodeBlockHeader(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.DecodeBlockHeaderCmd)
+
+	// Deserialize the block header.
+	hexStr := c.HexHeader
+	if len(hexStr)%2 != 0 {
+		hexStr = "0" + hexStr
+	}
+	serializedHeader, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, rpcDecodeHexError(hexStr)
+	}
+	var header wire.BlockHeader
+	if err := header.FromBytes(serializedHeader); err != nil {
+		return nil, rpcDeserializationError("Could not decode block "+
+			"header: %v", err)
+	}
+
+	// Create and return the result.  The proof of work hash is calculated
+	// with the light verification cache since it produces the same hash as
+	// the full dataset without the substantial cost of generating it.
+	hash := header.BlockHash()
+	powHash, err := header.PowHashV2WithHasher(s.kawPowHasher())
+	if err != nil {
+		return nil, rpcDeserializationError("Could not calculate proof of "+
+			"work hash: %v", err)
+	}
+	reply := types.DecodeBlockHeaderResult{
+		Hash:         hash.String(),
+		PowHash:      powHash.String(),
+		Version:      header.Version,
+		PreviousHash: header.PrevBlock.String(),
+		MerkleRoot:   header.MerkleRoot.String(),
+		StakeRoot:    header.StakeRoot.String(),
+		VoteBits:     header.VoteBits,
+		FinalState:   hex.EncodeToString(header.FinalState[:]),
+		Voters:       header.Voters,
+		FreshStake:   header.FreshStake,
+		Revocations:  header.Revocations,
+		PoolSize:     header.PoolSize,
+		Bits:         strconv.FormatInt(int64(header.Bits), 16),
+		SBits:        dcrutil.Amount(header.SBits).ToCoin(),
+		Height:       header.Height,
+		Size:         header.Size,
+		Time:         header.Timestamp.Unix(),
+		Nonce:        header.Nonce,
+		MixDigest:    hex.EncodeToString(header.MixDigest[:]),
+		ExtraData:    hex.EncodeToString(header.ExtraData[:]),
+		StakeVersion: header.StakeVersion,
+	}
+	return reply, nil
+}
+
 // handleDecodeRawTransaction handles decoderawtransaction commands.
 func handleDecodeRawTransaction(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
 	c := cmd.(*types.DecodeRawTransactionCmd)
@@ -1438,6 +1631,64 @@ func handleDecodeScript(_ context.Context, s *Server, cmd interface{}) (interfac
 	return reply, nil
 }
 
+// handleEstimateConfirmTime implements the estimateconfirmtime command.
+//
+// The expected interval between blocks is a blend of the target time per block
+// and the average interval observed over the most recent blocks, weighted by
+// the number of blocks observed, such that the estimate tracks the actual rate
+// blocks are being found without overreacting when only a few blocks exist.
+func handleEstimateConfirmTime(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.EstimateConfirmTimeCmd)
+
+	if c.Confirmations < 1 || c.Confirmations > maxEstimateConfirmTimeConfs {
+		return nil, rpcInvalidError("Number of confirmations must be "+
+			"between 1 and %d", maxEstimateConfirmTimeConfs)
+	}
+
+	chain := s.cfg.Chain
+	best := chain.BestSnapshot()
+	target := s.cfg.ChainParams.TargetTimePerBlock
+	numBlocks := best.Height
+	if numBlocks > estimateConfirmTimeWindow {
+		numBlocks = estimateConfirmTimeWindow
+	}
+
+	// Determine the average interval between the most recent blocks.  The
+	// target time per block is used when there are no prior blocks to
+	// observe.  Note that timestamps are not required to be monotonic, so the
+	// observed interval is clamped to a minimum of one second.
+	estimated := target
+	var observed time.Duration
+	if numBlocks > 0 {
+		tipHeader, err := chain.HeaderByHeight(best.Height)
+		if err != nil {
+			return nil, rpcInternalErr(err, "Could not obtain header")
+		}
+		startHeader, err := chain.HeaderByHeight(best.Height - numBlocks)
+		if err != nil {
+			return nil, rpcInternalErr(err, "Could not obtain header")
+		}
+		elapsed := tipHeader.Timestamp.Sub(startHeader.Timestamp)
+		observed = elapsed / time.Duration(numBlocks)
+		if observed < time.Second {
+			observed = time.Second
+		}
+
+		weightedSum := int64(target)*estimateConfirmTimeWindow +
+			int64(observed)*numBlocks
+		estimated = time.Duration(weightedSum /
+			(estimateConfirmTimeWindow + numBlocks))
+	}
+
+	return &types.EstimateConfirmTimeResult{
+		Confirmations:     c.Confirmations,
+		TargetInterval:    target.Seconds(),
+		ObservedInterval:  observed.Seconds(),
+		EstimatedInterval: estimated.Seconds(),
+		Seconds:           int64((estimated * time.Duration(c.Confirmations)).Seconds()),
+	}, nil
+}
+
 // handleEstimateFee implements the estimatefee command.
 // TODO this is a very basic implementation.  It should be
 // modified to match the bitcoin-core one.
@@ -1901,26 +2152,6 @@ func handleGetBestBlockHash(_ context.Context, s *Server, _ interface{}) (interf
 	return best.Hash.String(), nil
 }
 
-// getDifficultyRatio returns the proof-of-work difficulty as a multiple of the
-// minimum difficulty using the passed bits field from the header of a block.
-func getDifficultyRatio(bits uint32, params *chaincfg.Params) float64 {
-	// The minimum difficulty is the max possible proof-of-work limit bits
-	// converted back to a number.  Note this is not the same as the proof
-	// of work limit directly because the block difficulty is encoded in a
-	// block with the compact form which loses precision.
-	max := standalone.CompactToBig(params.PowLimitBits)
-	target := standalone.CompactToBig(bits)
-
-	difficulty := new(big.Rat).SetFrac(max, target)
-	outString := difficulty.FloatString(8)
-	diff, err := strconv.ParseFloat(outString, 64)
-	if err != nil {
-		log.Errorf("Cannot get difficulty: %v", err)
-		return 0
-	}
-	return diff
-}
-
 // handleGetBlock implements the getblock command.
 func handleGetBlock(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
 	c := cmd.(*types.GetBlockCmd)
@@ -1984,11 +2215,14 @@ func handleGetBlock(_ context.Context, s *Server, cmd interface{}) (interface{},
 	if err != nil {
 		return nil, err
 	}
-	powHashFn := blockHeader.PowHashV1
+	powHash := blockHeader.PowHashV1()
 	if isBlake3PowActive {
-		powHashFn = blockHeader.PowHashV2
+		powHash, err = blockHeader.PowHashV2WithHasher(s.kawPowHasher())
+		if err != nil {
+			return nil, rpcInternalErr(err, "Unable to calculate proof of "+
+				"work hash")
+		}
 	}
-	powHash := powHashFn()
 
 	blockReply := types.GetBlockVerboseResult{
 		Hash:          c.Hash,
@@ -1998,6 +2232,7 @@ func handleGetBlock(_ context.Context, s *Server, cmd interface{}) (interface{},
 		StakeRoot:     blockHeader.StakeRoot.String(),
 		PreviousHash:  blockHeader.PrevBlock.String(),
 		Nonce:         blockHeader.Nonce,
+		MixDigest:     hex.EncodeToString(blockHeader.MixDigest[:]),
 		VoteBits:      blockHeader.VoteBits,
 		FinalState:    hex.EncodeToString(blockHeader.FinalState[:]),
 		Voters:        blockHeader.Voters,
@@ -2012,7 +2247,7 @@ func handleGetBlock(_ context.Context, s *Server, cmd interface{}) (interface{},
 		Size:          int32(blk.MsgBlock().Header.Size),
 		Bits:          strconv.FormatInt(int64(blockHeader.Bits), 16),
 		SBits:         sbitsFloat,
-		Difficulty:    getDifficultyRatio(blockHeader.Bits, s.cfg.ChainParams),
+		Difficulty:    blockchain.GetDifficultyRatio(blockHeader.Bits, s.cfg.ChainParams),
 		ChainWork:     fmt.Sprintf("%064x", chainWork),
 		ExtraData:     hex.EncodeToString(blockHeader.ExtraData[:]),
 		NextHash:      nextHashString,
@@ -2179,7 +2414,7 @@ func handleGetBlockchainInfo(_ context.Context, s *Server, _ interface{}) (inter
 		VerificationProgress: verifyProgress,
 		BestBlockHash:        best.Hash.String(),
 		Difficulty:           best.Bits,
-		DifficultyRatio:      getDifficultyRatio(best.Bits, params),
+		DifficultyRatio:      blockchain.GetDifficultyRatio(best.Bits, params),
 		MaxBlockSize:         maxBlockSize,
 		Deployments:          dInfo,
 	}
@@ -2271,11 +2506,14 @@ func handleGetBlockHeader(_ context.Context, s *Server, cmd interface{}) (interf
 	if err != nil {
 		return nil, err
 	}
-	powHashFn := blockHeader.PowHashV1
+	powHash := blockHeader.PowHashV1()
 	if isBlake3PowActive {
-		powHashFn = blockHeader.PowHashV2
+		powHash, err = blockHeader.PowHashV2WithHasher(s.kawPowHasher())
+		if err != nil {
+			return nil, rpcInternalErr(err, "Unable to calculate proof of "+
+				"work hash")
+		}
 	}
-	powHash := powHashFn()
 
 	blockHeaderReply := types.GetBlockHeaderVerboseResult{
 		Hash:          c.Hash,
@@ -2299,7 +2537,7 @@ func handleGetBlockHeader(_ context.Context, s *Server, cmd interface{}) (interf
 		Nonce:         blockHeader.Nonce,
 		ExtraData:     hex.EncodeToString(blockHeader.ExtraData[:]),
 		StakeVersion:  blockHeader.StakeVersion,
-		Difficulty:    getDifficultyRatio(blockHeader.Bits, s.cfg.ChainParams),
+		Difficulty:    blockchain.GetDifficultyRatio(blockHeader.Bits, s.cfg.ChainParams),
 		ChainWork:     fmt.Sprintf("%064x", chainWork),
 		PreviousHash:  blockHeader.PrevBlock.String(),
 		NextHash:      nextHashString,
@@ -2399,10 +2637,62 @@ func handleGetCurrentNet(_ context.Context, s *Server, cmd interface{}) (interfa
 	return s.cfg.ChainParams.Net, nil
 }
 
+// handleGetDAGInfo implements the getdaginfo command.
+func handleGetDAGInfo(_ context.Context, s *Server, _ interface{}) (interface{}, error) {
+	// The epoch boundary heights are reported for the next two epochs worth
+	// of blocks after the current best chain tip.
+	kpParams := s.kawPowParams()
+	boundaryBlocks := 2 * kpParams.EpochLength
+
+	best := s.cfg.Chain.BestSnapshot()
+	nextHeight, remaining, estTime := s.cfg.Chain.NextEpochHeight()
+	result := &types.GetDAGInfoResult{
+		Epoch:           kpParams.Epoch(best.Height),
+		NextEpochHeight: nextHeight,
+		BlocksRemaining: remaining,
+		EstimatedTime:   estTime.Unix(),
+		EpochBoundaries: kpParams.EpochBoundaryHeights(best.Height+1,
+			best.Height+boundaryBlocks),
+	}
+
+	// Include the state of the most recent DAG regeneration job when there
+	// is one.
+	if job, ok := s.cfg.DAGManager.LastJob(); ok {
+		result.RegenJobID = job.ID
+		result.RegenEpoch = job.Epoch
+		result.RegenStatus = string(job.Status)
+		if job.Err != nil {
+			result.RegenError = job.Err.Error()
+		}
+	}
+
+	return result, nil
+}
+
 // handleGetDifficulty implements the getdifficulty command.
 func handleGetDifficulty(_ context.Context, s *Server, _ interface{}) (interface{}, error) {
+	// The stake difficulty is expressed as a multiple of the minimum stake
+	// difficulty in the same way the proof-of-work difficulty is expressed
+	// as a multiple of the minimum proof-of-work difficulty.
 	best := s.cfg.Chain.BestSnapshot()
-	return getDifficultyRatio(best.Bits, s.cfg.ChainParams), nil
+	params := s.cfg.ChainParams
+	stakeDiff := float64(best.NextStakeDiff) / float64(params.MinimumStakeDiff)
+	return &types.GetDifficultyResult{
+		Difficulty:      blockchain.GetDifficultyRatio(best.Bits, params),
+		StakeDifficulty: stakeDiff,
+	}, nil
+}
+
+// handleGetDiffWindowStats implements the getdiffwindowstats command.
+func handleGetDiffWindowStats(_ context.Context, s *Server, _ interface{}) (interface{}, error) {
+	stats := s.cfg.Chain.DiffWindowStats()
+	return &types.GetDiffWindowStatsResult{
+		WindowSize:       stats.WindowSize,
+		BlocksIntoWindow: stats.BlocksIntoWindow,
+		ElapsedTime:      int64(stats.ElapsedTime / time.Second),
+		ExpectedTime:     int64(stats.ExpectedTime / time.Second),
+		Direction:        stats.Direction(),
+	}, nil
 }
 
 // handleGetGenerate implements the getgenerate command.
@@ -2459,25 +2749,172 @@ func handleGetHeaders(_ context.Context, s *Server, cmd interface{}) (interface{
 	return &types.GetHeadersResult{Headers: hexBlockHeaders}, nil
 }
 
-// handleGetCFilterV2 implements the getcfilterv2 command.
-func handleGetCFilterV2(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
-	c := cmd.(*types.GetCFilterV2Cmd)
-	hash, err := chainhash.NewHashFromStr(c.BlockHash)
+// loadCFilterV2 parses the provided block hash and loads the version 2 block
+// filter committed to by that block along with its header inclusion proof.  The
+// returned errors are suitable for returning directly from RPC handlers.
+func loadCFilterV2(s *Server, blockHash string) (*gcs.FilterV2, *blockchain.HeaderProof, error) {
+	hash, err := chainhash.NewHashFromStr(blockHash)
 	if err != nil {
-		return nil, rpcDecodeHexError(c.BlockHash)
+		return nil, nil, rpcDecodeHexError(blockHash)
 	}
 
 	filter, proof, err := s.cfg.FiltererV2.FilterByBlockHash(hash)
 	if err != nil {
 		if errors.Is(err, blockchain.ErrNoFilter) {
-			return nil, &dcrjson.RPCError{
+			return nil, nil, &dcrjson.RPCError{
 				Code:    dcrjson.ErrRPCBlockNotFound,
 				Message: fmt.Sprintf("Block not found: %v", hash),
 			}
 		}
 
 		context := fmt.Sprintf("Failed to load filter for block %s", hash)
-		return nil, rpcInternalErr(err, context)
+		return nil, nil, rpcInternalErr(err, context)
+	}
+
+	return filter, proof, nil
+}
+
+// blockTemplateToResult converts the provided block template into the result
+// returned by the getblocktemplate command using the provided KawPoW seed hash
+// and target difficulty.
+//
+// The coinbase is not included in the returned transactions.  Instead, its
+// total output value is returned as the coinbase value.
+func blockTemplateToResult(t *mining.BlockTemplate, seed chainhash.Hash, target *big.Int) (*types.GetBlockTemplateResult, error) {
+	// txFee returns the fee for the transaction at the provided index in the
+	// fees of the template, which houses the fees for the regular transactions
+	// followed by the stake transactions.
+	txFee := func(index int) int64 {
+		if index < len(t.Fees) {
+			return t.Fees[index]
+		}
+		return 0
+	}
+
+	// templateTxns converts the provided transactions into the form used in
+	// the result.  The offset is the index of the first transaction within
+	// the fees of the template.
+	templateTxns := func(txns []*wire.MsgTx, offset int) ([]types.GetBlockTemplateResultTx, error) {
+		results := make([]types.GetBlockTemplateResultTx, 0, len(txns))
+		for i, tx := range txns {
+			txBytes, err := tx.Bytes()
+			if err != nil {
+				context := "Failed to serialize template transaction"
+				return nil, rpcInternalErr(err, context)
+			}
+			results = append(results, types.GetBlockTemplateResultTx{
+				Data: hex.EncodeToString(txBytes),
+				Hash: tx.TxHash().String(),
+				Fee:  txFee(offset + i),
+			})
+		}
+		return results, nil
+	}
+
+	msgBlock := t.Block
+	header := &msgBlock.Header
+	var coinbaseValue int64
+	var regularTxns []*wire.MsgTx
+	if len(msgBlock.Transactions) > 0 {
+		for _, txOut := range msgBlock.Transactions[0].TxOut {
+			coinbaseValue += txOut.Value
+		}
+		regularTxns = msgBlock.Transactions[1:]
+	}
+	transactions, err := templateTxns(regularTxns, 1)
+	if err != nil {
+		return nil, err
+	}
+	stakeTransactions, err := templateTxns(msgBlock.STransactions,
+		len(msgBlock.Transactions))
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.GetBlockTemplateResult{
+		Version:           header.Version,
+		PreviousBlockHash: header.PrevBlock.String(),
+		Transactions:      transactions,
+		STransactions:     stakeTransactions,
+		CoinbaseValue:     coinbaseValue,
+		Bits:              strconv.FormatInt(int64(header.Bits), 16),
+		Height:            int64(header.Height),
+		CurTime:           header.Timestamp.Unix(),
+		SeedHash:          seed.String(),
+		Target:            fmt.Sprintf("%064x", target),
+	}, nil
+}
+
+// handleGetBlockTemplate implements the getblocktemplate command.
+func handleGetBlockTemplate(_ context.Context, s *Server, _ interface{}) (interface{}, error) {
+	bt := s.cfg.BlockTemplater
+	template, err := bt.CurrentTemplate()
+	if err != nil {
+		return nil, rpcMiscError(fmt.Sprintf("no work is available: %v", err))
+	}
+	if template == nil {
+		return nil, rpcMiscError("no work is available during a chain " +
+			"reorganization")
+	}
+
+	// Update the time of the block template to the current time while
+	// accounting for the median time of the past several blocks per the chain
+	// consensus rules.  Note that the template and block are copied to avoid
+	// mutating the shared block template.
+	templateCopy := *template
+	blockCopy := *template.Block
+	bt.UpdateBlockTime(&blockCopy.Header)
+	templateCopy.Block = &blockCopy
+
+	// The KawPoW seed hash only depends on the epoch of the header height.
+	header := &blockCopy.Header
+	kpParams := s.kawPowParams()
+	seed, err := kpParams.CalcSeedHash(int64(header.Height), 0)
+	if err != nil {
+		return nil, rpcInternalErr(err, "Failed to calculate seed hash")
+	}
+	target := standalone.CompactToBig(header.Bits)
+	return blockTemplateToResult(&templateCopy, seed, target)
+}
+
+// handleGetCFilter implements the getcfilter command.
+func handleGetCFilter(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.GetCFilterCmd)
+	filter, _, err := loadCFilterV2(s, c.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.GetCFilterResult{
+		BlockHash: c.BlockHash,
+		Data:      hex.EncodeToString(filter.Bytes()),
+	}
+	return result, nil
+}
+
+// handleGetCFilterHeader implements the getcfilterheader command.
+func handleGetCFilterHeader(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.GetCFilterHeaderCmd)
+	filter, _, err := loadCFilterV2(s, c.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	filterHash := filter.Hash()
+	result := &types.GetCFilterHeaderResult{
+		BlockHash:      c.BlockHash,
+		FilterHash:     filterHash.String(),
+		CommitmentRoot: blockchain.CalcCommitmentRootV1(filterHash).String(),
+	}
+	return result, nil
+}
+
+// handleGetCFilterV2 implements the getcfilterv2 command.
+func handleGetCFilterV2(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.GetCFilterV2Cmd)
+	filter, proof, err := loadCFilterV2(s, c.BlockHash)
+	if err != nil {
+		return nil, err
 	}
 
 	var proofHashes []string
@@ -2509,7 +2946,7 @@ func handleGetInfo(_ context.Context, s *Server, _ interface{}) (interface{}, er
 		TimeOffset:      int64(s.cfg.TimeSource.Offset().Seconds()),
 		Connections:     s.cfg.ConnMgr.ConnectedCount(),
 		Proxy:           s.cfg.Proxy,
-		Difficulty:      getDifficultyRatio(best.Bits, s.cfg.ChainParams),
+		Difficulty:      blockchain.GetDifficultyRatio(best.Bits, s.cfg.ChainParams),
 		TestNet:         s.cfg.TestNet,
 		RelayFee:        s.cfg.MinRelayTxFee.ToCoin(),
 		TxIndex:         s.cfg.TxIndexer != nil,
@@ -2518,6 +2955,102 @@ func handleGetInfo(_ context.Context, s *Server, _ interface{}) (interface{}, er
 	return ret, nil
 }
 
+// handleGetHealth implements the gethealth command.
+func handleGetHealth(_ context.Context, s *Server, _ interface{}) (interface{}, error) {
+	chain := s.cfg.Chain
+	best := chain.BestSnapshot()
+	header, err := chain.HeaderByHash(&best.Hash)
+	if err != nil {
+		return nil, rpcInternalErr(err, "Could not fetch best header")
+	}
+
+	// The best block is considered recent when it was mined within several
+	// target block times.
+	maxBlockAge := s.cfg.ChainParams.TargetTimePerBlock *
+		healthMaxBlockAgeTargets
+	recentBlock := s.cfg.Clock.Since(header.Timestamp) <= maxBlockAge
+
+	// There are recent validation errors when a block failed validation
+	// within the validation failure window.
+	noRecentValidationErrors := true
+	lastFailure := chain.LastValidationFailureTime()
+	if !lastFailure.IsZero() {
+		noRecentValidationErrors = s.cfg.Clock.Since(lastFailure) >
+			healthValidationFailureWindow
+	}
+
+	kpParams := s.kawPowParams()
+	epoch := kpParams.Epoch(best.Height)
+	result := &types.GetHealthResult{
+		ChainCurrent:             chain.IsCurrent(),
+		DAGReady:                 s.cfg.DAGManager.DAGReady(epoch),
+		RecentBlock:              recentBlock,
+		NoRecentValidationErrors: noRecentValidationErrors,
+	}
+	result.Healthy = result.ChainCurrent && result.DAGReady &&
+		result.RecentBlock && result.NoRecentValidationErrors
+	return result, nil
+}
+
+// handleGetKawPowParams implements the getkawpowparams command.
+func handleGetKawPowParams(_ context.Context, s *Server, _ interface{}) (interface{}, error) {
+	kpParams := s.kawPowParams()
+	result := &types.GetKawPowParamsResult{
+		EpochLength:         kpParams.EpochLength,
+		DatasetInitBytes:    kpParams.DatasetInitBytes,
+		DatasetGrowthBytes:  kpParams.DatasetGrowthBytes,
+		CacheInitBytes:      kpParams.CacheInitBytes,
+		CacheGrowthBytes:    kpParams.CacheGrowthBytes,
+		CacheRounds:         kpParams.CacheRounds,
+		MinDatasetInitBytes: kawpow.MinASICResistantDatasetBytes,
+		ASICResistant:       kpParams.CheckASICResistance() == nil,
+	}
+	return result, nil
+}
+
+// handleGetKawPowSeeds implements the getkawpowseeds command.
+func handleGetKawPowSeeds(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.GetKawPowSeedsCmd)
+
+	if c.StartEpoch < 0 {
+		return nil, rpcInvalidError("Start epoch %d is negative",
+			c.StartEpoch)
+	}
+	if c.EndEpoch < c.StartEpoch {
+		return nil, rpcInvalidError("End epoch %d is before start epoch %d",
+			c.EndEpoch, c.StartEpoch)
+	}
+	if numEpochs := c.EndEpoch - c.StartEpoch + 1; numEpochs > maxKawPowSeedsPerRequest {
+		return nil, rpcInvalidError("Number of epochs %d exceeds the "+
+			"maximum allowed of %d", numEpochs, maxKawPowSeedsPerRequest)
+	}
+
+	// Seeds are only provided up to the epoch after the one the current best
+	// chain tip belongs to so that the DAG for the next epoch may be built
+	// ahead of time.
+	kpParams := s.kawPowParams()
+	best := s.cfg.Chain.BestSnapshot()
+	maxEpoch := kpParams.Epoch(best.Height) + 1
+	if c.EndEpoch > maxEpoch {
+		return nil, rpcInvalidError("End epoch %d is after the next epoch "+
+			"%d", c.EndEpoch, maxEpoch)
+	}
+
+	// The seeds are calculated with the same function consensus uses so they
+	// always agree with the seeds used to verify blocks.
+	results := make([]types.GetKawPowSeedsResult, 0,
+		c.EndEpoch-c.StartEpoch+1)
+	for epoch := c.StartEpoch; epoch <= c.EndEpoch; epoch++ {
+		seed := kawpow.EpochSeed(uint64(epoch))
+		results = append(results, types.GetKawPowSeedsResult{
+			Epoch:       epoch,
+			SeedHash:    seed.String(),
+			StartHeight: epoch * kpParams.EpochLength,
+		})
+	}
+	return results, nil
+}
+
 // handleGetMempoolInfo implements the getmempoolinfo command.
 func handleGetMempoolInfo(_ context.Context, s *Server, _ interface{}) (interface{}, error) {
 	mempoolTxns := s.cfg.TxMempooler.TxDescs()
@@ -2557,7 +3090,7 @@ func handleGetMiningInfo(ctx context.Context, s *Server, _ interface{}) (interfa
 		Blocks:           best.Height,
 		CurrentBlockSize: best.BlockSize,
 		CurrentBlockTx:   best.NumTxns,
-		Difficulty:       getDifficultyRatio(best.Bits, s.cfg.ChainParams),
+		Difficulty:       blockchain.GetDifficultyRatio(best.Bits, s.cfg.ChainParams),
 		StakeDifficulty:  best.NextStakeDiff,
 		Generate:         s.cfg.CPUMiner.IsMining(),
 		GenProcLimit:     s.cfg.CPUMiner.NumWorkers(),
@@ -3786,10 +4319,12 @@ func serializeGetWorkData(header *wire.BlockHeader, isBlake3PowActive bool) ([]b
 	// data below.
 	//
 	// For reference (0-index based, end value is exclusive):
-	// data[115:119] --> Bits
-	// data[135:139] --> Timestamp
-	// data[139:143] --> Nonce
-	// data[143:151] --> ExtraNonce
+	// data[116:120] --> Bits
+	// data[128:132] --> Height
+	// data[136:140] --> Timestamp
+	// data[140:148] --> Nonce (little endian, see getworkNonceOffset)
+	// data[148:180] --> MixDigest (see getworkMixDigestOffset)
+	// data[180:212] --> ExtraData (includes the extra nonce)
 	data := make([]byte, 0, getworkDataLen)
 	buf := bytes.NewBuffer(data)
 	err := header.Serialize(buf)
@@ -3805,6 +4340,42 @@ func serializeGetWorkData(header *wire.BlockHeader, isBlake3PowActive bool) ([]b
 	return data, nil
 }
 
+// decodeKawPowSolution decodes the provided hex-encoded KawPoW nonce and mix
+// digest submitted by a miner.  The nonce is expected to be encoded in the
+// same little-endian byte order used for the nonce in the getwork data.
+//
+// A decode error is returned unless the nonce and mix digest decode to exactly
+// getworkNonceSize and getworkMixDigestSize bytes, respectively, so malformed
+// submissions are rejected here as opposed to being silently truncated or
+// causing out of bounds slicing when they are later compared against the
+// calculated values.
+func decodeKawPowSolution(nonceHex, mixDigestHex string) (uint64, [getworkMixDigestSize]byte, error) {
+	var mixDigest [getworkMixDigestSize]byte
+	nonceBytes, err := hex.DecodeString(nonceHex)
+	if err != nil {
+		return 0, mixDigest, rpcDecodeHexError(nonceHex)
+	}
+	if len(nonceBytes) != getworkNonceSize {
+		str := fmt.Sprintf("KawPoW nonce must be %d bytes (not %d)",
+			getworkNonceSize, len(nonceBytes))
+		return 0, mixDigest, dcrjson.NewRPCError(dcrjson.ErrRPCDecodeHexString,
+			str)
+	}
+	mixDigestBytes, err := hex.DecodeString(mixDigestHex)
+	if err != nil {
+		return 0, mixDigest, rpcDecodeHexError(mixDigestHex)
+	}
+	if len(mixDigestBytes) != getworkMixDigestSize {
+		str := fmt.Sprintf("KawPoW mix digest must be %d bytes (not %d)",
+			getworkMixDigestSize, len(mixDigestBytes))
+		return 0, mixDigest, dcrjson.NewRPCError(dcrjson.ErrRPCDecodeHexString,
+			str)
+	}
+
+	copy(mixDigest[:], mixDigestBytes)
+	return binary.LittleEndian.Uint64(nonceBytes), mixDigest, nil
+}
+
 // handleGetWorkRequest is a helper for handleGetWork which deals with
 // generating and returning work to the caller.
 func handleGetWorkRequest(ctx context.Context, s *Server) (interface{}, error) {
@@ -3905,14 +4476,18 @@ func handleGetWorkRequest(ctx context.Context, s *Server) (interface{}, error) {
 	// internal padding that makes the data ready for callers to make use of
 	// only the final chunk along with the midstate for the rest when solving
 	// the block.
-	isBlake3PowActive, err := s.isBlake3PowAgendaActive(&headerCopy.PrevBlock)
+	isKawPowActive, err := s.isKawPowActive(&headerCopy.PrevBlock)
 	if err != nil {
 		return nil, err
 	}
-	data, err := serializeGetWorkData(&headerCopy, isBlake3PowActive)
+	data, err := serializeGetWorkData(&headerCopy, isKawPowActive)
 	if err != nil {
 		return nil, err
 	}
+	algo := getworkAlgoBlake256
+	if isKawPowActive {
+		algo = getworkAlgoKawPow
+	}
 
 	// Add the template to the template pool.  Since the key is a combination
 	// of the merkle and stake root fields, this will not add duplicate entries
@@ -3930,6 +4505,7 @@ func handleGetWorkRequest(ctx context.Context, s *Server) (interface{}, error) {
 	reply := &types.GetWorkResult{
 		Data:   hex.EncodeToString(data),
 		Target: hex.EncodeToString(target[:]),
+		Algo:   algo,
 	}
 	return reply, nil
 }
@@ -3997,22 +4573,27 @@ func handleGetWorkSubmission(_ context.Context, s *Server, hexData string) (inte
 		return false, nil // nolint: nilerr
 	}
 
-	// Choose the proof of work mining algorithm based on the result of the vote
-	// for the blake3 proof of work agenda.
-	isBlake3PowActive, err := s.isBlake3PowAgendaActive(prevBlkHash)
+	// Choose the proof of work mining algorithm based on whether or not KawPoW
+	// is active for the block.
+	isKawPowActive, err := s.isKawPowActive(prevBlkHash)
 	if err != nil {
 		return false, err
 	}
-	powHashFn := submittedHeader.PowHashV1
-	if isBlake3PowActive {
-		powHashFn = submittedHeader.PowHashV2
-	}
 
 	// Ensure the submitted proof of work hash is less than the target
-	// difficulty.
-	powHash := powHashFn()
-	err = standalone.CheckProofOfWork(&powHash, submittedHeader.Bits,
-		s.cfg.ChainParams.PowLimit, nil)
+	// difficulty.  Note that the KawPoW check rejects work claiming a target
+	// difficulty easier than the proof-of-work limit prior to hashing and is
+	// the same check the chain performs with a hasher for the KawPoW
+	// parameters of the network.
+	powLimit := s.cfg.ChainParams.PowLimit
+	if isKawPowActive {
+		err = standalone.CheckKawPoWProof(&submittedHeader, powLimit,
+			s.kawPowHasher())
+	} else {
+		powHash := submittedHeader.PowHashV1()
+		err = standalone.CheckProofOfWork(&powHash, submittedHeader.Bits,
+			powLimit)
+	}
 	if err != nil {
 		// Anything other than a rule violation is an unexpected error, so
 		// return that error as an internal error.
@@ -4060,18 +4641,17 @@ func handleGetWorkSubmission(_ context.Context, s *Server, hexData string) (inte
 			return false, rpcInternalErr(err, context)
 		}
 
-		log.Infof("Block submitted via getwork rejected: %v", err)
+		// Include the header fields that were changed from the template to
+		// aid in debugging miners that modify unexpected fields.
+		changedFields := submittedHeader.DiffFields(&templateBlock.Header)
+		log.Infof("Block submitted via getwork rejected: %v (header fields "+
+			"changed from template: %s)", err, strings.Join(changedFields, ", "))
 		return false, nil
 	}
 
 	// The block was accepted.
-	var powHashStr string
-	blockHash := block.Hash()
-	if *blockHash != powHash {
-		powHashStr = ", pow hash " + powHash.String()
-	}
-	log.Infof("Block submitted via getwork accepted: %s (height %d%s)",
-		blockHash, msgBlock.Header.Height, powHashStr)
+	log.Infof("Block submitted via getwork accepted: %s (height %d)",
+		block.Hash(), msgBlock.Header.Height)
 	return true, nil
 }
 
@@ -4286,6 +4866,25 @@ func handleRegenTemplate(_ context.Context, s *Server, _ interface{}) (interface
 	return nil, nil
 }
 
+// handleRegenerateDAG implements the regeneratedag command.
+func handleRegenerateDAG(_ context.Context, s *Server, _ interface{}) (interface{}, error) {
+	kpParams := s.kawPowParams()
+	best := s.cfg.Chain.BestSnapshot()
+	epoch := kpParams.Epoch(best.Height)
+	jobID, err := s.cfg.DAGManager.RegenerateDAG(epoch)
+	if err != nil {
+		if errors.Is(err, kawpow.ErrDAGJobInProgress) {
+			return nil, rpcMiscError(err.Error())
+		}
+		return nil, rpcInternalErr(err, "Unable to regenerate DAG")
+	}
+
+	return &types.RegenerateDAGResult{
+		JobID: jobID,
+		Epoch: epoch,
+	}, nil
+}
+
 // handleSendRawMixMessage implements the sendrawmixmessage command.
 func handleSendRawMixMessage(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
 	c := cmd.(*types.SendRawMixMessageCmd)
@@ -4460,6 +5059,39 @@ func handleSetGenerate(_ context.Context, s *Server, cmd interface{}) (interface
 	return nil, nil
 }
 
+// handleSimulateDifficulty implements the simulatedifficulty command.
+func handleSimulateDifficulty(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
+	c := cmd.(*types.SimulateDifficultyCmd)
+
+	if len(c.Intervals) > maxSimulateDifficultyIntervals {
+		return nil, rpcInvalidError("Number of intervals %d exceeds the "+
+			"maximum allowed of %d", len(c.Intervals),
+			maxSimulateDifficultyIntervals)
+	}
+	for i, interval := range c.Intervals {
+		if interval < 0 {
+			return nil, rpcInvalidError("Interval %d is negative: %d", i,
+				interval)
+		}
+	}
+
+	simulated, err := s.cfg.Chain.SimulateDifficulty(c.Intervals)
+	if err != nil {
+		return nil, rpcInternalErr(err, "Could not simulate difficulty")
+	}
+
+	results := make([]types.SimulateDifficultyResult, 0, len(simulated))
+	for _, sim := range simulated {
+		results = append(results, types.SimulateDifficultyResult{
+			Height:     sim.Height,
+			Time:       sim.Timestamp,
+			Bits:       strconv.FormatInt(int64(sim.Bits), 16),
+			Difficulty: blockchain.GetDifficultyRatio(sim.Bits, s.cfg.ChainParams),
+		})
+	}
+	return results, nil
+}
+
 // handleStartProfiler implements the startprofiler command.
 func handleStartProfiler(_ context.Context, s *Server, cmd interface{}) (interface{}, error) {
 	c := cmd.(*types.StartProfilerCmd)
@@ -5180,6 +5812,7 @@ type Server struct {
 	hmacMu                 sync.Mutex
 	authsha                [sha256.Size]byte
 	limitauthsha           [sha256.Size]byte
+	miningauthsha          [sha256.Size]byte
 	ntfnMgr                NtfnManager
 	statusLines            map[int]string
 	statusLock             sync.RWMutex
@@ -5192,6 +5825,12 @@ type Server struct {
 	// concurrently, and access requires the mutex.
 	blake256Hasher  hash.Hash
 	blake256HaserMu sync.Mutex
+
+	// kawPow is the KawPoW hasher used to calculate proof of work hashes with
+	// the KawPoW parameters of the network.  It only makes use of the
+	// verification cache and is created on first use by kawPowHasher.
+	kawPowOnce sync.Once
+	kawPow     *kawpow.KawPow
 }
 
 // isTreasuryAgendaActive returns if the treasury agenda is active or not for
@@ -5247,10 +5886,37 @@ func (s *Server) isBlake3PowAgendaActive(prevBlkHash *chainhash.Hash) (bool, err
 	return isActive, nil
 }
 
+// kawPowParams returns the KawPoW parameters defined by the network parameters
+// the server is configured with.
+func (s *Server) kawPowParams() kawpow.Params {
+	params := &s.cfg.ChainParams.KawPow
+	return kawpow.Params{
+		EpochLength:        params.EpochLength,
+		DatasetInitBytes:   params.DatasetInitBytes,
+		DatasetGrowthBytes: params.DatasetGrowthBytes,
+		CacheInitBytes:     params.CacheInitBytes,
+		CacheGrowthBytes:   params.CacheGrowthBytes,
+		CacheRounds:        params.CacheRounds,
+	}
+}
+
+// kawPowHasher returns the KawPoW hasher the server uses to calculate proof of
+// work hashes with the KawPoW parameters of the network.
+//
+// This function is safe for concurrent access.
+func (s *Server) kawPowHasher() *kawpow.KawPow {
+	s.kawPowOnce.Do(func() {
+		s.kawPow = kawpow.NewLightWithParams(s.kawPowParams())
+	})
+	return s.kawPow
+}
+
 // isKawPowActive returns whether KawPoW proof of work is active for the block
-// AFTER the provided block hash.
+// AFTER the provided block hash.  KawPoW is activated by the agenda that
+// changes the proof of work hash function, so this is the same as the result of
+// the vote for that agenda.
 func (s *Server) isKawPowActive(prevBlkHash *chainhash.Hash) (bool, error) {
-	return s.cfg.Chain.IsKawPowActive(prevBlkHash)
+	return s.isBlake3PowAgendaActive(prevBlkHash)
 }
 
 // isSubsidySplitR2AgendaActive returns if the modified subsidy split round 2
@@ -5431,31 +6097,36 @@ func (s *Server) authMAC(dst, auth []byte) []byte {
 // checkAuthMAC checks the HTTP Basic authentication string by comparing
 // it with the already generated hash.
 //
-// The first bool return value signifies auth success (true if successful) and
-// the second bool return value specifies whether the user can change the state
-// of the server (true) or whether the user is limited (false).
-func (s *Server) checkAuthMAC(auth, remoteAddr string) (bool, bool) {
+// The bool return value signifies auth success (true if successful) and the
+// authScope return value specifies the set of methods the user may invoke.
+func (s *Server) checkAuthMAC(auth, remoteAddr string) (bool, authScope) {
 	mac := make([]byte, 0, sha256.Size)
 	mac = s.authMAC(mac, []byte(auth))
 
 	cmp := subtle.ConstantTimeCompare(mac, s.authsha[:])
 	limitcmp := subtle.ConstantTimeCompare(mac, s.limitauthsha[:])
-	if cmp|limitcmp == 0 {
-		// Request's auth doesn't match either user
-		log.Warnf("RPC authentication failure from %s", remoteAddr)
-		return false, false
+	miningcmp := subtle.ConstantTimeCompare(mac, s.miningauthsha[:])
+	switch {
+	case cmp == 1:
+		return true, authScopeAdmin
+	case miningcmp == 1:
+		return true, authScopeMining
+	case limitcmp == 1:
+		return true, authScopeLimited
 	}
-	return true, cmp == 1
+
+	// Request's auth doesn't match any user.
+	log.Warnf("RPC authentication failure from %s", remoteAddr)
+	return false, authScopeLimited
 }
 
 // checkAuthUserPass checks the correctness of username and password by
 // generating the corresponding HTTP Basic authentication string then
 // compare the string with the already generated hash.
 //
-// The first bool return value signifies auth success (true if successful) and
-// the second bool return value specifies whether the user can change the state
-// of the server (true) or whether the user is limited (false).
-func (s *Server) checkAuthUserPass(user, pass, remoteAddr string) (bool, bool) {
+// The bool return value signifies auth success (true if successful) and the
+// authScope return value specifies the set of methods the user may invoke.
+func (s *Server) checkAuthUserPass(user, pass, remoteAddr string) (bool, authScope) {
 	login := user + ":" + pass
 	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
 	return s.checkAuthMAC(auth, remoteAddr)
@@ -5467,16 +6138,15 @@ func (s *Server) checkAuthUserPass(user, pass, remoteAddr string) (bool, bool) {
 //
 // This check is time-constant.
 //
-// The first bool return value signifies auth success (true if successful) and
-// the second bool return value specifies whether the user can change the state
-// of the server (true) or whether the user is limited (false). The second is
-// always false if the first is.
-func (s *Server) checkAuth(r *http.Request, require bool) (bool, bool, error) {
+// The bool return value signifies auth success (true if successful) and the
+// authScope return value specifies the set of methods the user may invoke.
+// The scope is always authScopeLimited when authentication fails.
+func (s *Server) checkAuth(r *http.Request, require bool) (bool, authScope, error) {
 	// If admin-level RPC user and pass options are not set, this always
 	// succeeds.  This will be the case when TLS client certificates are
 	// being used for authentication.
 	if s.authsha == ([32]byte{}) {
-		return true, true, nil
+		return true, authScopeAdmin, nil
 	}
 
 	authhdr := r.Header["Authorization"]
@@ -5484,17 +6154,17 @@ func (s *Server) checkAuth(r *http.Request, require bool) (bool, bool, error) {
 		if require {
 			log.Warnf("RPC authentication failure from %s",
 				r.RemoteAddr)
-			return false, false, errors.New("auth failure")
+			return false, authScopeLimited, errors.New("auth failure")
 		}
 
-		return false, false, nil
+		return false, authScopeLimited, nil
 	}
 
-	authed, isAdmin := s.checkAuthMAC(authhdr[0], r.RemoteAddr)
+	authed, scope := s.checkAuthMAC(authhdr[0], r.RemoteAddr)
 	if !authed {
-		return false, false, errors.New("auth failure")
+		return false, authScopeLimited, errors.New("auth failure")
 	}
-	return authed, isAdmin, nil
+	return authed, scope, nil
 }
 
 // parsedRPCCmd represents a JSON-RPC request object that has been parsed into
@@ -5573,15 +6243,12 @@ func createMarshalledReply(rpcVersion string, id interface{}, result interface{}
 
 // processRequest determines the incoming request type (single or batched),
 // parses it and returns a marshalled response.
-func (s *Server) processRequest(ctx context.Context, request *dcrjson.Request, isAdmin bool) []byte {
+func (s *Server) processRequest(ctx context.Context, request *dcrjson.Request, scope authScope) []byte {
 	var result interface{}
 	var jsonErr error
 
-	if !isAdmin {
-		if _, ok := rpcLimited[request.Method]; !ok {
-			jsonErr = rpcInvalidError("limited user not " +
-				"authorized for this method")
-		}
+	if !scope.authorized(request.Method) {
+		jsonErr = rpcInvalidError("%s", scope.notAuthorizedMsg())
 	}
 
 	if jsonErr == nil {
@@ -5624,7 +6291,7 @@ func (s *Server) processRequest(ctx context.Context, request *dcrjson.Request, i
 }
 
 // jsonRPCRead handles reading and responding to RPC messages.
-func (s *Server) jsonRPCRead(sCtx context.Context, w http.ResponseWriter, r *http.Request, isAdmin bool) {
+func (s *Server) jsonRPCRead(sCtx context.Context, w http.ResponseWriter, r *http.Request, scope authScope) {
 	select {
 	case <-sCtx.Done():
 		return
@@ -5707,7 +6374,7 @@ func (s *Server) jsonRPCRead(sCtx context.Context, w http.ResponseWriter, r *htt
 				log.Errorf("Failed to create reply: %v", err)
 			}
 		} else {
-			resp = s.processRequest(ctx, &req, isAdmin)
+			resp = s.processRequest(ctx, &req, scope)
 		}
 
 		if resp != nil {
@@ -5776,7 +6443,7 @@ func (s *Server) jsonRPCRead(sCtx context.Context, w http.ResponseWriter, r *htt
 						continue
 					}
 
-					resp = s.processRequest(ctx, &req, isAdmin)
+					resp = s.processRequest(ctx, &req, scope)
 					if resp != nil {
 						results = append(results, resp)
 					}
@@ -5903,19 +6570,19 @@ func (s *Server) route(ctx context.Context) *http.Server {
 		// Keep track of the number of connected clients.
 		s.incrementClients()
 		defer s.decrementClients()
-		_, isAdmin, err := s.checkAuth(r, true)
+		_, scope, err := s.checkAuth(r, true)
 		if err != nil {
 			jsonAuthFail(w)
 			return
 		}
 
 		// Read and respond to the request.
-		s.jsonRPCRead(r.Context(), w, r, isAdmin)
+		s.jsonRPCRead(r.Context(), w, r, scope)
 	})
 
 	// Websocket endpoint.
 	rpcServeMux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
-		authenticated, isAdmin, err := s.checkAuth(r, false)
+		authenticated, scope, err := s.checkAuth(r, false)
 		if err != nil {
 			jsonAuthFail(w)
 			return
@@ -5994,7 +6661,7 @@ func (s *Server) route(ctx context.Context) *http.Server {
 			ws.SetReadLimit(websocketReadLimitAuthenticated)
 		}
 		s.WebsocketHandler(r.Context(), ws, r.RemoteAddr, authenticated,
-			isAdmin)
+			scope)
 	})
 	return httpServer
 }
@@ -6117,6 +6784,9 @@ type Config struct {
 	BlockTemplater BlockTemplater
 	CPUMiner       CPUMiner
 
+	// DAGManager defines the manager of the KawPoW DAG files cached on disk.
+	DAGManager DAGManager
+
 	// TxIndexer defines the optional transaction indexer for the RPC server to
 	// use.
 	TxIndexer TxIndexer
@@ -6131,12 +6801,14 @@ type Config struct {
 	// Proxy defines the proxy that is being used for connections.
 	Proxy string
 
-	// These fields define the username and password for RPC connections and
-	// limited RPC connections.
-	RPCUser      string
-	RPCPass      string
-	RPCLimitUser string
-	RPCLimitPass string
+	// These fields define the username and password for RPC connections,
+	// limited RPC connections, and mining-only RPC connections.
+	RPCUser       string
+	RPCPass       string
+	RPCLimitUser  string
+	RPCLimitPass  string
+	RPCMiningUser string
+	RPCMiningPass string
 
 	// RPCMaxClients defines the max number of RPC clients for standard
 	// connections.
@@ -6201,6 +6873,12 @@ func New(config *Config) (*Server, error) {
 			base64.StdEncoding.EncodeToString([]byte(login))
 		rpc.authMAC(rpc.limitauthsha[:0], []byte(auth))
 	}
+	if config.RPCMiningUser != "" && config.RPCMiningPass != "" {
+		login := config.RPCMiningUser + ":" + config.RPCMiningPass
+		auth := "Basic " +
+			base64.StdEncoding.EncodeToString([]byte(login))
+		rpc.authMAC(rpc.miningauthsha[:0], []byte(auth))
+	}
 	rpc.ntfnMgr = newWsNotificationManager(&rpc)
 
 	return &rpc, nil
@@ -15,10 +15,12 @@ import (
 
 func TestCheckAuthUserPass(t *testing.T) {
 	s, err := New(&Config{
-		RPCUser:      "user",
-		RPCPass:      "pass",
-		RPCLimitUser: "limit",
-		RPCLimitPass: "limit",
+		RPCUser:       "user",
+		RPCPass:       "pass",
+		RPCLimitUser:  "limit",
+		RPCLimitPass:  "limit",
+		RPCMiningUser: "miner",
+		RPCMiningPass: "miner",
 	})
 	if err != nil {
 		t.Fatalf("unable to create RPC server: %v", err)
@@ -28,53 +30,153 @@ func TestCheckAuthUserPass(t *testing.T) {
 		user       string
 		pass       string
 		wantAuthed bool
-		wantAdmin  bool
+		wantScope  authScope
 	}{
 		{
 			name:       "correct admin",
 			user:       "user",
 			pass:       "pass",
 			wantAuthed: true,
-			wantAdmin:  true,
+			wantScope:  authScopeAdmin,
 		},
 		{
 			name:       "correct limited user",
 			user:       "limit",
 			pass:       "limit",
 			wantAuthed: true,
-			wantAdmin:  false,
+			wantScope:  authScopeLimited,
+		},
+		{
+			name:       "correct mining user",
+			user:       "miner",
+			pass:       "miner",
+			wantAuthed: true,
+			wantScope:  authScopeMining,
 		},
 		{
 			name:       "invalid admin",
 			user:       "user",
 			pass:       "p",
 			wantAuthed: false,
-			wantAdmin:  false,
+			wantScope:  authScopeLimited,
 		},
 		{
 			name:       "invalid limited user",
 			user:       "limit",
 			pass:       "",
 			wantAuthed: false,
-			wantAdmin:  false,
+			wantScope:  authScopeLimited,
+		},
+		{
+			name:       "invalid mining user",
+			user:       "miner",
+			pass:       "m",
+			wantAuthed: false,
+			wantScope:  authScopeLimited,
 		},
 		{
 			name:       "invalid empty user",
 			user:       "",
 			pass:       "",
 			wantAuthed: false,
-			wantAdmin:  false,
+			wantScope:  authScopeLimited,
 		},
 	}
 	for _, test := range tests {
-		authed, isAdmin := s.checkAuthUserPass(test.user, test.pass, "addr")
+		authed, scope := s.checkAuthUserPass(test.user, test.pass, "addr")
 		if authed != test.wantAuthed {
 			t.Errorf("%q: unexpected authed -- got %v, want %v", test.name, authed,
 				test.wantAuthed)
 		}
-		if isAdmin != test.wantAdmin {
-			t.Errorf("%q: unexpected isAdmin -- got %v, want %v", test.name, isAdmin,
-				test.wantAdmin)
+		if scope != test.wantScope {
+			t.Errorf("%q: unexpected scope -- got %v, want %v", test.name, scope,
+				test.wantScope)
+		}
+	}
+}
+
+// TestAuthScopeAuthorized ensures the RPC methods permitted for each
+// authentication scope are enforced as expected.
+func TestAuthScopeAuthorized(t *testing.T) {
+	tests := []struct {
+		name   string
+		scope  authScope
+		method string
+		want   bool
+	}{{
+		name:   "mining user may getwork",
+		scope:  authScopeMining,
+		method: "getwork",
+		want:   true,
+	}, {
+		name:   "mining user may submitblock",
+		scope:  authScopeMining,
+		method: "submitblock",
+		want:   true,
+	}, {
+		name:   "mining user may not call admin method",
+		scope:  authScopeMining,
+		method: "stop",
+		want:   false,
+	}, {
+		name:   "mining user may not call limited-only method",
+		scope:  authScopeMining,
+		method: "sendrawtransaction",
+		want:   false,
+	}, {
+		name:   "limited user may not getwork",
+		scope:  authScopeLimited,
+		method: "getwork",
+		want:   false,
+	}, {
+		name:   "limited user may not call admin method",
+		scope:  authScopeLimited,
+		method: "stop",
+		want:   false,
+	}, {
+		name:   "admin user may call admin method",
+		scope:  authScopeAdmin,
+		method: "stop",
+		want:   true,
+	}, {
+		name:   "admin user may getwork",
+		scope:  authScopeAdmin,
+		method: "getwork",
+		want:   true,
+	}}
+
+	for _, test := range tests {
+		got := test.scope.authorized(test.method)
+		if got != test.want {
+			t.Errorf("%q: unexpected result -- got %v, want %v", test.name,
+				got, test.want)
+		}
+	}
+}
+
+// TestAuthScopeNotAuthorizedMsg ensures the error message returned to clients
+// that are not permitted to invoke a method identifies the scope that denied
+// the call.
+func TestAuthScopeNotAuthorizedMsg(t *testing.T) {
+	tests := []struct {
+		name  string
+		scope authScope
+		want  string
+	}{{
+		name:  "limited user",
+		scope: authScopeLimited,
+		want:  "limited user not authorized for this method",
+	}, {
+		name:  "mining user",
+		scope: authScopeMining,
+		want:  "mining user not authorized for this method",
+	}}
+
+	for _, test := range tests {
+		got := test.scope.notAuthorizedMsg()
+		if got != test.want {
+			t.Errorf("%q: unexpected message -- got %q, want %q", test.name,
+				got, test.want)
 		}
 	}
 }
@@ -86,12 +188,13 @@ func TestCheckAuth(t *testing.T) {
 			t.Fatalf("unable to create RPC server: %v", err)
 		}
 		for i := 0; i <= 1; i++ {
-			authed, isAdmin, err := s.checkAuth(&http.Request{}, i == 0)
+			authed, scope, err := s.checkAuth(&http.Request{}, i == 0)
 			if !authed {
 				t.Errorf(" unexpected authed -- got %v, want %v", authed, true)
 			}
-			if !isAdmin {
-				t.Errorf("unexpected isAdmin -- got %v, want %v", isAdmin, true)
+			if scope != authScopeAdmin {
+				t.Errorf("unexpected scope -- got %v, want %v", scope,
+					authScopeAdmin)
 			}
 			if err != nil {
 				t.Errorf("unexpected err -- got %v, want %v", err, nil)
@@ -109,12 +212,13 @@ func TestCheckAuth(t *testing.T) {
 			t.Fatalf("unable to create RPC server: %v", err)
 		}
 		for i := 0; i <= 1; i++ {
-			authed, isAdmin, err := s.checkAuth(&http.Request{}, i == 0)
+			authed, scope, err := s.checkAuth(&http.Request{}, i == 0)
 			if authed {
 				t.Errorf(" unexpected authed -- got %v, want %v", authed, false)
 			}
-			if isAdmin {
-				t.Errorf("unexpected isAdmin -- got %v, want %v", isAdmin, false)
+			if scope != authScopeLimited {
+				t.Errorf("unexpected scope -- got %v, want %v", scope,
+					authScopeLimited)
 			}
 			if i == 0 && err == nil {
 				t.Errorf("unexpected err -- got %v, want auth failure", err)
@@ -125,12 +229,13 @@ func TestCheckAuth(t *testing.T) {
 		for i := 0; i <= 1; i++ {
 			r := &http.Request{Header: make(map[string][]string, 1)}
 			r.Header["Authorization"] = []string{"Basic Nothing"}
-			authed, isAdmin, err := s.checkAuth(r, i == 0)
+			authed, scope, err := s.checkAuth(r, i == 0)
 			if authed {
 				t.Errorf(" unexpected authed -- got %v, want %v", authed, false)
 			}
-			if isAdmin {
-				t.Errorf("unexpected isAdmin -- got %v, want %v", isAdmin, false)
+			if scope != authScopeLimited {
+				t.Errorf("unexpected scope -- got %v, want %v", scope,
+					authScopeLimited)
 			}
 			if err == nil {
 				t.Errorf("unexpected err -- got %v, want auth failure", err)
@@ -151,3 +256,14 @@ func TestLimitedMethodsExist(t *testing.T) {
 		}
 	}
 }
+
+// TestMiningMethodsExist ensures all RPC methods listed in the mining RPC
+// methods map have associated handlers defined.
+func TestMiningMethodsExist(t *testing.T) {
+	for methodStr := range rpcMining {
+		method := types.Method(methodStr)
+		if _, ok := rpcHandlers[method]; !ok {
+			t.Errorf("no handler found for mining method %q", method)
+		}
+	}
+}
@@ -28,7 +28,6 @@ import (
 	"github.com/davecgh/go-spew/spew"
 	"github.com/decred/dcrd/addrmgr/v3"
 	"github.com/decred/dcrd/blockchain/stake/v5"
-	"github.com/decred/dcrd/blockchain/standalone/v2"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/dcrd/database/v3"
@@ -49,6 +48,8 @@ import (
 	"github.com/decred/dcrd/txscript/v4/stdaddr"
 	"github.com/decred/dcrd/txscript/v4/stdscript"
 	"github.com/decred/dcrd/wire"
+	"vigil.network/node/blockchain/standalone"
+	"vigil.network/node/blockchain/standalone/kawpow"
 )
 
 const (
@@ -151,8 +152,10 @@ type testRPCChain struct {
 	chainWorkErr                  error
 	checkLiveTicket               bool
 	checkLiveTickets              []bool
+	coinbaseMaturity              int64
 	countVoteVersion              uint32
 	countVoteVersionErr           error
+	diffWindowStats               *blockchain.DiffWindowStats
 	estimateNextStakeDifficultyFn func(hash *chainhash.Hash, newTickets int64, useMaxTickets bool) (diff int64, err error)
 	fetchUtxoEntry                UtxoEntry
 	fetchUtxoEntryErr             error
@@ -166,10 +169,12 @@ type testRPCChain struct {
 	headerByHashFn                func() wire.BlockHeader
 	headerByHashErr               error
 	headerByHeight                wire.BlockHeader
+	headerByHeightFn              func(height int64) wire.BlockHeader
 	headerByHeightErr             error
 	heightRangeFn                 func(startHeight, endHeight int64) ([]chainhash.Hash, error)
 	invalidateBlockErr            error
 	isCurrent                     bool
+	lastValidationFailureTime     time.Time
 	liveTickets                   []chainhash.Hash
 	liveTicketsErr                error
 	locateHeaders                 []wire.BlockHeader
@@ -182,9 +187,14 @@ type testRPCChain struct {
 	minedTSpendBlocks             []chainhash.Hash
 	missedTickets                 []chainhash.Hash
 	missedTicketsErr              error
+	nextEpochHeight               int64
+	nextEpochRemaining            int64
+	nextEpochTime                 time.Time
 	nextThresholdState            blockchain.ThresholdStateTuple
 	nextThresholdStateErr         error
 	reconsiderBlockErr            error
+	simulateDifficulty            []blockchain.SimulatedDiff
+	simulateDifficultyErr         error
 	stateLastChangedHeight        int64
 	stateLastChangedHeightErr     error
 	ticketPoolValue               dcrutil.Amount
@@ -264,12 +274,23 @@ func (c *testRPCChain) CheckLiveTickets(hashes []chainhash.Hash) []bool {
 	return c.checkLiveTickets
 }
 
+// CoinbaseMaturityHeight returns a mocked first block height at which the
+// outputs of a coinbase transaction mined at the provided height may be spent.
+func (c *testRPCChain) CoinbaseMaturityHeight(minedHeight int64) int64 {
+	return minedHeight + c.coinbaseMaturity
+}
+
 // CountVoteVersion returns a mocked total number of version votes for the current
 // rule change activation interval.
 func (c *testRPCChain) CountVoteVersion(version uint32) (uint32, error) {
 	return c.countVoteVersion, c.countVoteVersionErr
 }
 
+// DiffWindowStats returns mocked difficulty retarget window statistics.
+func (c *testRPCChain) DiffWindowStats() *blockchain.DiffWindowStats {
+	return c.diffWindowStats
+}
+
 // EstimateNextStakeDifficulty returns a mocked estimated next stake difficulty.
 func (c *testRPCChain) EstimateNextStakeDifficulty(hash *chainhash.Hash, newTickets int64, useMaxTickets bool) (int64, error) {
 	return c.estimateNextStakeDifficultyFn(hash, newTickets, useMaxTickets)
@@ -310,6 +331,9 @@ func (c *testRPCChain) HeaderByHash(hash *chainhash.Hash) (wire.BlockHeader, err
 
 // HeaderByHeight returns a mocked block header at the given height.
 func (c *testRPCChain) HeaderByHeight(height int64) (wire.BlockHeader, error) {
+	if c.headerByHeightFn != nil {
+		return c.headerByHeightFn(height), c.headerByHeightErr
+	}
 	return c.headerByHeight, c.headerByHeightErr
 }
 
@@ -331,6 +355,12 @@ func (c *testRPCChain) IsCurrent() bool {
 	return c.isCurrent
 }
 
+// LastValidationFailureTime returns a mocked time a block most recently failed
+// validation.
+func (c *testRPCChain) LastValidationFailureTime() time.Time {
+	return c.lastValidationFailureTime
+}
+
 // LiveTickets returns a mocked slice of all currently live tickets.
 func (c *testRPCChain) LiveTickets() ([]chainhash.Hash, error) {
 	return c.liveTickets, c.liveTicketsErr
@@ -372,6 +402,12 @@ func (c *testRPCChain) MissedTickets() ([]chainhash.Hash, error) {
 	return c.missedTickets, c.missedTicketsErr
 }
 
+// NextEpochHeight returns a mocked next KawPoW epoch height, the number of
+// blocks remaining until it, and the estimated time it will be reached.
+func (c *testRPCChain) NextEpochHeight() (int64, int64, time.Time) {
+	return c.nextEpochHeight, c.nextEpochRemaining, c.nextEpochTime
+}
+
 // NextThresholdState returns a mocked current rule change threshold state of
 // the given deployment ID for the block AFTER the provided block hash.
 func (c *testRPCChain) NextThresholdState(hash *chainhash.Hash, deploymentID string) (blockchain.ThresholdStateTuple, error) {
@@ -384,6 +420,11 @@ func (c *testRPCChain) ReconsiderBlock(hash *chainhash.Hash) error {
 	return c.reconsiderBlockErr
 }
 
+// SimulateDifficulty returns mocked simulated difficulties.
+func (c *testRPCChain) SimulateDifficulty(intervals []int64) ([]blockchain.SimulatedDiff, error) {
+	return c.simulateDifficulty, c.simulateDifficultyErr
+}
+
 // StateLastChangedHeight returns a mocked height at which the provided
 // consensus deployment agenda last changed state.
 func (c *testRPCChain) StateLastChangedHeight(hash *chainhash.Hash, deploymentID string) (int64, error) {
@@ -979,6 +1020,10 @@ func (a testAddr) Network() string {
 type testClock struct {
 	now   time.Time
 	since time.Duration
+
+	// sinces, when set, provides the durations returned by successive calls
+	// to Since in order.  Calls beyond its length return since.
+	sinces []time.Duration
 }
 
 // Now returns a mocked time.Time representing the current local time.
@@ -988,6 +1033,11 @@ func (c *testClock) Now() time.Time {
 
 // Since returns a mocked time.Duration representing the time elapsed since t.
 func (c *testClock) Since(t time.Time) time.Duration {
+	if len(c.sinces) > 0 {
+		since := c.sinces[0]
+		c.sinces = c.sinces[1:]
+		return since
+	}
 	return c.since
 }
 
@@ -1022,6 +1072,32 @@ func (l *testLogManager) ParseAndSetDebugLevels(debugLevel string) error {
 	return l.parseAndSetDebugLevelsErr
 }
 
+// testDAGManager provides a mock DAG manager by implementing the DAGManager
+// interface.
+type testDAGManager struct {
+	regenerateJobID uint64
+	regenerateErr   error
+	lastJob         kawpow.DAGJob
+	haveLastJob     bool
+	dagReady        bool
+}
+
+// RegenerateDAG returns a mocked DAG regeneration job ID.
+func (m *testDAGManager) RegenerateDAG(epoch int64) (uint64, error) {
+	return m.regenerateJobID, m.regenerateErr
+}
+
+// LastJob returns a mocked most recent DAG regeneration job.
+func (m *testDAGManager) LastJob() (kawpow.DAGJob, bool) {
+	return m.lastJob, m.haveLastJob
+}
+
+// DAGReady returns a mocked bool representing whether or not the DAG for the
+// provided epoch is ready.
+func (m *testDAGManager) DAGReady(epoch int64) bool {
+	return m.dagReady
+}
+
 // testSanityChecker provides a mock implementation that checks the sanity
 // state of a block.
 type testSanityChecker struct {
@@ -1422,6 +1498,7 @@ type rpcTest struct {
 	mockBlockTemplater    *testBlockTemplater
 	setBlockTemplaterNil  bool
 	mockSanityChecker     *testSanityChecker
+	mockDAGManager        *testDAGManager
 	mockProfManager       *testProfManager
 	mockAddrManager       *testAddrManager
 	mockFeeEstimator      *testFeeEstimator
@@ -1525,7 +1602,8 @@ func defaultMockRPCChain() *testRPCChain {
 			BranchLen: 500,
 			Status:    "active",
 		}},
-		chainWork: chainWork,
+		chainWork:        chainWork,
+		coinbaseMaturity: int64(defaultChainParams.CoinbaseMaturity),
 		estimateNextStakeDifficultyFn: func(*chainhash.Hash, int64, bool) (int64, error) {
 			return 14336790201, nil
 		},
@@ -1600,6 +1678,15 @@ func defaultMockSanityChecker() *testSanityChecker {
 	return &testSanityChecker{}
 }
 
+// defaultMockDAGManager provides a default mock DAG manager to be used
+// throughout the tests.  Tests can override these defaults by calling
+// defaultMockDAGManager, updating fields as necessary on the returned
+// *testDAGManager, and then setting rpcTest.mockDAGManager as that
+// *testDAGManager.
+func defaultMockDAGManager() *testDAGManager {
+	return &testDAGManager{}
+}
+
 // defaultMockMiningState provides a default mock mining state to be used
 // throughout the tests. Tests can override these defaults by calling
 // defaultMockMiningState, updating fields as necessary on the returned
@@ -1858,6 +1945,7 @@ func defaultMockConfig(chainParams *chaincfg.Params) *Config {
 		DB:              defaultMockDB(),
 		ConnMgr:         defaultMockConnManager(),
 		CPUMiner:        defaultMockCPUMiner(),
+		DAGManager:      defaultMockDAGManager(),
 		TxMempooler:     defaultMockTxMempooler(),
 		Clock:           &testClock{},
 		LogManager:      defaultMockLogManager(),
@@ -2710,6 +2798,65 @@ func TestHandleDebugLevel(t *testing.T) {
 	}})
 }
 
+func TestHandleDecodeBlockHeader(t *testing.T) {
+	t.Parallel()
+
+	const headerHex = "0100000001000000000000000000000000000000000000000000000000000000" +
+		"0000000002000000000000000000000000000000000000000000000000000000" +
+		"0000000003000000000000000000000000000000000000000000000000000000" +
+		"0000000001000102030405060500020100a00000ffff001d00c2eb0b00000000" +
+		"640000000004000000f153650807060504030201040000000000000000000000" +
+		"0000000000000000000000000000000000000000050000000000000000000000" +
+		"000000000000000000000000000000000000000009000000"
+
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleDecodeBlockHeader: ok",
+		handler: handleDecodeBlockHeader,
+		cmd: &types.DecodeBlockHeaderCmd{
+			HexHeader: headerHex,
+		},
+		result: types.DecodeBlockHeaderResult{
+			Hash:         "d7350a2e8e590101f06517a54620508bfa5db5f9477404eff5bd5200339a72bc",
+			PowHash:      "c5596c7c3d7cfb5cffc6b6e7ed0a5f2c559529f3509549baeba121c0e9c0a39f",
+			Version:      1,
+			PreviousHash: "0000000000000000000000000000000000000000000000000000000000000001",
+			MerkleRoot:   "0000000000000000000000000000000000000000000000000000000000000002",
+			StakeRoot:    "0000000000000000000000000000000000000000000000000000000000000003",
+			VoteBits:     1,
+			FinalState:   "010203040506",
+			Voters:       5,
+			FreshStake:   2,
+			Revocations:  1,
+			PoolSize:     40960,
+			Bits:         "1d00ffff",
+			SBits:        2,
+			Height:       100,
+			Size:         1024,
+			Time:         1700000000,
+			Nonce:        0x0102030405060708,
+			MixDigest:    "0400000000000000000000000000000000000000000000000000000000000000",
+			ExtraData:    "0500000000000000000000000000000000000000000000000000000000000000",
+			StakeVersion: 9,
+		},
+	}, {
+		name:    "handleDecodeBlockHeader: invalid hex",
+		handler: handleDecodeBlockHeader,
+		cmd: &types.DecodeBlockHeaderCmd{
+			HexHeader: "g" + headerHex,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCDecodeHexString,
+	}, {
+		name:    "handleDecodeBlockHeader: truncated header",
+		handler: handleDecodeBlockHeader,
+		cmd: &types.DecodeBlockHeaderCmd{
+			HexHeader: headerHex[:len(headerHex)-8],
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCDeserialization,
+	}})
+}
+
 func TestHandleDecodeRawTransaction(t *testing.T) {
 	t.Parallel()
 
@@ -2920,6 +3067,104 @@ func TestHandleDecodeScript(t *testing.T) {
 	}})
 }
 
+func TestHandleEstimateConfirmTime(t *testing.T) {
+	t.Parallel()
+
+	// syntheticChain returns a mock chain with the provided tip height whose
+	// blocks are all found the provided interval apart.
+	syntheticChain := func(tipHeight int64, interval time.Duration) *testRPCChain {
+		chain := defaultMockRPCChain()
+		chain.bestSnapshot.Height = tipHeight
+		chain.headerByHeightFn = func(height int64) wire.BlockHeader {
+			timestamp := time.Unix(1600000000, 0).Add(time.Duration(height) *
+				interval)
+			return wire.BlockHeader{
+				Height:    uint32(height),
+				Timestamp: timestamp,
+			}
+		}
+		return chain
+	}
+
+	// wantResult returns the expected result for the provided number of
+	// confirmations and observed and estimated intervals.
+	target := defaultChainParams.TargetTimePerBlock
+	wantResult := func(confs int64, observed, estimated time.Duration) *types.EstimateConfirmTimeResult {
+		return &types.EstimateConfirmTimeResult{
+			Confirmations:     confs,
+			TargetInterval:    target.Seconds(),
+			ObservedInterval:  observed.Seconds(),
+			EstimatedInterval: estimated.Seconds(),
+			Seconds:           int64((estimated * time.Duration(confs)).Seconds()),
+		}
+	}
+
+	testRPCServerHandler(t, []rpcTest{{
+		name:      "handleEstimateConfirmTime: blocks on target",
+		handler:   handleEstimateConfirmTime,
+		mockChain: syntheticChain(432100, target),
+		cmd:       &types.EstimateConfirmTimeCmd{Confirmations: 6},
+		result:    wantResult(6, target, target),
+	}, {
+		name:      "handleEstimateConfirmTime: blocks slower than target",
+		handler:   handleEstimateConfirmTime,
+		mockChain: syntheticChain(432100, target*2),
+		cmd:       &types.EstimateConfirmTimeCmd{Confirmations: 6},
+		result:    wantResult(6, target*2, target*3/2),
+	}, {
+		name:      "handleEstimateConfirmTime: blocks faster than target",
+		handler:   handleEstimateConfirmTime,
+		mockChain: syntheticChain(432100, target/2),
+		cmd:       &types.EstimateConfirmTimeCmd{Confirmations: 1},
+		result:    wantResult(1, target/2, target*3/4),
+	}, {
+		name:      "handleEstimateConfirmTime: few blocks weigh target more",
+		handler:   handleEstimateConfirmTime,
+		mockChain: syntheticChain(48, target*2),
+		cmd:       &types.EstimateConfirmTimeCmd{Confirmations: 2},
+		result:    wantResult(2, target*2, target*5/4),
+	}, {
+		name:      "handleEstimateConfirmTime: non-increasing timestamps",
+		handler:   handleEstimateConfirmTime,
+		mockChain: syntheticChain(432100, 0),
+		cmd:       &types.EstimateConfirmTimeCmd{Confirmations: 1},
+		result: wantResult(1, time.Second,
+			(target*estimateConfirmTimeWindow+time.Second*
+				estimateConfirmTimeWindow)/(2*estimateConfirmTimeWindow)),
+	}, {
+		name:      "handleEstimateConfirmTime: only genesis block",
+		handler:   handleEstimateConfirmTime,
+		mockChain: syntheticChain(0, target*2),
+		cmd:       &types.EstimateConfirmTimeCmd{Confirmations: 3},
+		result:    wantResult(3, 0, target),
+	}, {
+		name:    "handleEstimateConfirmTime: zero confirmations",
+		handler: handleEstimateConfirmTime,
+		cmd:     &types.EstimateConfirmTimeCmd{Confirmations: 0},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:    "handleEstimateConfirmTime: too many confirmations",
+		handler: handleEstimateConfirmTime,
+		cmd: &types.EstimateConfirmTimeCmd{
+			Confirmations: maxEstimateConfirmTimeConfs + 1,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:    "handleEstimateConfirmTime: unable to fetch header",
+		handler: handleEstimateConfirmTime,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.headerByHeightErr = errors.New("")
+			return chain
+		}(),
+		cmd:     &types.EstimateConfirmTimeCmd{Confirmations: 1},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}})
+}
+
 func TestHandleEstimateFee(t *testing.T) {
 	t.Parallel()
 
@@ -3894,6 +4139,7 @@ func TestHandleGetBlock(t *testing.T) {
 			StakeRoot:     blkHeader.StakeRoot.String(),
 			PreviousHash:  blkHeader.PrevBlock.String(),
 			Nonce:         blkHeader.Nonce,
+			MixDigest:     hex.EncodeToString(blkHeader.MixDigest[:]),
 			VoteBits:      blkHeader.VoteBits,
 			FinalState:    hex.EncodeToString(blkHeader.FinalState[:]),
 			Voters:        blkHeader.Voters,
@@ -3950,6 +4196,7 @@ func TestHandleGetBlock(t *testing.T) {
 			StakeRoot:     blkHeader.StakeRoot.String(),
 			PreviousHash:  blkHeader.PrevBlock.String(),
 			Nonce:         blkHeader.Nonce,
+			MixDigest:     hex.EncodeToString(blkHeader.MixDigest[:]),
 			VoteBits:      blkHeader.VoteBits,
 			FinalState:    hex.EncodeToString(blkHeader.FinalState[:]),
 			Voters:        blkHeader.Voters,
@@ -4308,6 +4555,293 @@ func TestHandleGetBlockSubsidy(t *testing.T) {
 	}})
 }
 
+// TestBlockTemplateToResult ensures converting a block template to the result
+// of the getblocktemplate command includes all of the transactions along with
+// their fees and that the difficulty fields match the template header.
+func TestBlockTemplateToResult(t *testing.T) {
+	t.Parallel()
+
+	// Create a template block with a coinbase, a couple of regular
+	// transactions, and a stake transaction.
+	newTx := func(prevHash chainhash.Hash, prevIndex uint32, values ...int64) *wire.MsgTx {
+		tx := wire.NewMsgTx()
+		tx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: *wire.NewOutPoint(&prevHash, prevIndex,
+				wire.TxTreeRegular),
+			SignatureScript: []byte{0x00, 0x00},
+		})
+		for _, value := range values {
+			tx.AddTxOut(wire.NewTxOut(value, []byte{txscript.OP_TRUE}))
+		}
+		return tx
+	}
+	block := wire.NewMsgBlock(&wire.BlockHeader{
+		Version:   9,
+		PrevBlock: chainhash.Hash{0x01},
+		Bits:      0x1b01ffff,
+		Height:    432100,
+		Timestamp: time.Unix(1592931302, 0),
+	})
+	block.AddTransaction(newTx(chainhash.Hash{}, wire.MaxPrevOutIndex, 3000,
+		4000))
+	block.AddTransaction(newTx(chainhash.Hash{0x02}, 0, 5000))
+	block.AddTransaction(newTx(chainhash.Hash{0x03}, 1, 6000))
+	block.AddSTransaction(newTx(chainhash.Hash{0x04}, 2, 7000))
+
+	// Create a template with a distinct fee for every transaction.  The first
+	// fee is for the coinbase and is the negative of the total fees.
+	numTxns := len(block.Transactions) + len(block.STransactions)
+	fees := make([]int64, numTxns)
+	for i := 1; i < numTxns; i++ {
+		fees[i] = int64(i * 1000)
+		fees[0] -= fees[i]
+	}
+	template := &mining.BlockTemplate{Block: block, Fees: fees}
+
+	header := &block.Header
+	seed, err := kawpow.CalcSeedHash(int64(header.Height),
+		header.Timestamp.Unix())
+	if err != nil {
+		t.Fatalf("unexpected error calculating seed hash: %v", err)
+	}
+	target := standalone.CompactToBig(header.Bits)
+	result, err := blockTemplateToResult(template, seed, target)
+	if err != nil {
+		t.Fatalf("unexpected error converting template: %v", err)
+	}
+
+	// checkTxns ensures the provided result transactions match the provided
+	// transactions and fees starting at the provided offset.
+	checkTxns := func(name string, got []types.GetBlockTemplateResultTx, want []*wire.MsgTx, offset int) {
+		t.Helper()
+
+		if len(got) != len(want) {
+			t.Fatalf("mismatched number of %s -- got %d, want %d", name,
+				len(got), len(want))
+		}
+		for i, tx := range want {
+			txBytes, err := tx.Bytes()
+			if err != nil {
+				t.Fatalf("unexpected error serializing tx: %v", err)
+			}
+			wantTx := types.GetBlockTemplateResultTx{
+				Data: hex.EncodeToString(txBytes),
+				Hash: tx.TxHash().String(),
+				Fee:  fees[offset+i],
+			}
+			if got[i] != wantTx {
+				t.Fatalf("mismatched %s %d -- got %+v, want %+v", name, i,
+					got[i], wantTx)
+			}
+		}
+	}
+	checkTxns("transactions", result.Transactions, block.Transactions[1:], 1)
+	checkTxns("stake transactions", result.STransactions,
+		block.STransactions, len(block.Transactions))
+
+	var wantCoinbaseValue int64
+	for _, txOut := range block.Transactions[0].TxOut {
+		wantCoinbaseValue += txOut.Value
+	}
+	if result.CoinbaseValue != wantCoinbaseValue {
+		t.Fatalf("mismatched coinbase value -- got %d, want %d",
+			result.CoinbaseValue, wantCoinbaseValue)
+	}
+	if result.Version != header.Version {
+		t.Fatalf("mismatched version -- got %d, want %d", result.Version,
+			header.Version)
+	}
+	if result.PreviousBlockHash != header.PrevBlock.String() {
+		t.Fatalf("mismatched previous block hash -- got %s, want %s",
+			result.PreviousBlockHash, header.PrevBlock)
+	}
+	if result.Height != int64(header.Height) {
+		t.Fatalf("mismatched height -- got %d, want %d", result.Height,
+			header.Height)
+	}
+	if result.CurTime != header.Timestamp.Unix() {
+		t.Fatalf("mismatched time -- got %d, want %d", result.CurTime,
+			header.Timestamp.Unix())
+	}
+
+	// Ensure the bits, target, and seed hash are all consistent with the
+	// header of the template.
+	bits, err := strconv.ParseUint(result.Bits, 16, 32)
+	if err != nil {
+		t.Fatalf("unexpected error parsing bits %q: %v", result.Bits, err)
+	}
+	if uint32(bits) != header.Bits {
+		t.Fatalf("mismatched bits -- got %08x, want %08x", bits, header.Bits)
+	}
+	if len(result.Target) != 64 {
+		t.Fatalf("unexpected target length -- got %d, want 64",
+			len(result.Target))
+	}
+	gotTarget, ok := new(big.Int).SetString(result.Target, 16)
+	if !ok {
+		t.Fatalf("unable to parse target %q", result.Target)
+	}
+	if gotTarget.Cmp(standalone.CompactToBig(header.Bits)) != 0 {
+		t.Fatalf("mismatched target -- got %064x, want %064x", gotTarget,
+			standalone.CompactToBig(header.Bits))
+	}
+	if result.SeedHash != seed.String() {
+		t.Fatalf("mismatched seed hash -- got %s, want %s", result.SeedHash,
+			seed)
+	}
+}
+
+func TestHandleGetBlockTemplate(t *testing.T) {
+	t.Parallel()
+
+	header := &block432100.Header
+	seed, err := kawpow.CalcSeedHash(int64(header.Height),
+		header.Timestamp.Unix())
+	if err != nil {
+		t.Fatalf("unexpected error calculating seed hash: %v", err)
+	}
+	template := &mining.BlockTemplate{Block: &block432100}
+	wantResult, err := blockTemplateToResult(template, seed,
+		standalone.CompactToBig(header.Bits))
+	if err != nil {
+		t.Fatalf("unexpected error converting template: %v", err)
+	}
+
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleGetBlockTemplate: ok",
+		handler: handleGetBlockTemplate,
+		cmd:     &types.GetBlockTemplateCmd{},
+		result:  wantResult,
+	}, {
+		name:    "handleGetBlockTemplate: unable to retrieve template",
+		handler: handleGetBlockTemplate,
+		cmd:     &types.GetBlockTemplateCmd{},
+		mockBlockTemplater: func() *testBlockTemplater {
+			templater := defaultMockBlockTemplater()
+			templater.currTemplateErr = errors.New("unable to retrieve template")
+			return templater
+		}(),
+		wantErr: true,
+		errCode: dcrjson.ErrRPCMisc,
+	}, {
+		name:    "handleGetBlockTemplate: no template during chain reorg",
+		handler: handleGetBlockTemplate,
+		cmd:     &types.GetBlockTemplateCmd{},
+		mockBlockTemplater: func() *testBlockTemplater {
+			templater := defaultMockBlockTemplater()
+			templater.currTemplate = nil
+			return templater
+		}(),
+		wantErr: true,
+		errCode: dcrjson.ErrRPCMisc,
+	}})
+}
+
+func TestHandleGetCFilter(t *testing.T) {
+	t.Parallel()
+
+	blkHashString := block432100.BlockHash().String()
+	filter := hex.EncodeToString(defaultMockFiltererV2().filterByBlockHash.Bytes())
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleGetCFilter: ok",
+		handler: handleGetCFilter,
+		cmd: &types.GetCFilterCmd{
+			BlockHash: blkHashString,
+		},
+		result: &types.GetCFilterResult{
+			BlockHash: blkHashString,
+			Data:      filter,
+		},
+	}, {
+		name:    "handleGetCFilter: invalid hash",
+		handler: handleGetCFilter,
+		cmd: &types.GetCFilterCmd{
+			BlockHash: "invalid",
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCDecodeHexString,
+	}, {
+		name:    "handleGetCFilter: block not found",
+		handler: handleGetCFilter,
+		cmd: &types.GetCFilterCmd{
+			BlockHash: blkHashString,
+		},
+		mockFiltererV2: func() *testFiltererV2 {
+			testFiltererV2 := defaultMockFiltererV2()
+			testFiltererV2.filterByBlockHashErr = blockchain.ErrNoFilter
+			return testFiltererV2
+		}(),
+		wantErr: true,
+		errCode: dcrjson.ErrRPCBlockNotFound,
+	}, {
+		name:    "handleGetCFilter: failed to load filter",
+		handler: handleGetCFilter,
+		cmd: &types.GetCFilterCmd{
+			BlockHash: blkHashString,
+		},
+		mockFiltererV2: func() *testFiltererV2 {
+			testFiltererV2 := defaultMockFiltererV2()
+			testFiltererV2.filterByBlockHashErr = errors.New("failed to load filter")
+			return testFiltererV2
+		}(),
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}})
+}
+
+func TestHandleGetCFilterHeader(t *testing.T) {
+	t.Parallel()
+
+	blkHashString := block432100.BlockHash().String()
+	filterHash := defaultMockFiltererV2().filterByBlockHash.Hash()
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleGetCFilterHeader: ok",
+		handler: handleGetCFilterHeader,
+		cmd: &types.GetCFilterHeaderCmd{
+			BlockHash: blkHashString,
+		},
+		result: &types.GetCFilterHeaderResult{
+			BlockHash:      blkHashString,
+			FilterHash:     filterHash.String(),
+			CommitmentRoot: filterHash.String(),
+		},
+	}, {
+		name:    "handleGetCFilterHeader: invalid hash",
+		handler: handleGetCFilterHeader,
+		cmd: &types.GetCFilterHeaderCmd{
+			BlockHash: "invalid",
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCDecodeHexString,
+	}, {
+		name:    "handleGetCFilterHeader: block not found",
+		handler: handleGetCFilterHeader,
+		cmd: &types.GetCFilterHeaderCmd{
+			BlockHash: blkHashString,
+		},
+		mockFiltererV2: func() *testFiltererV2 {
+			testFiltererV2 := defaultMockFiltererV2()
+			testFiltererV2.filterByBlockHashErr = blockchain.ErrNoFilter
+			return testFiltererV2
+		}(),
+		wantErr: true,
+		errCode: dcrjson.ErrRPCBlockNotFound,
+	}, {
+		name:    "handleGetCFilterHeader: failed to load filter",
+		handler: handleGetCFilterHeader,
+		cmd: &types.GetCFilterHeaderCmd{
+			BlockHash: blkHashString,
+		},
+		mockFiltererV2: func() *testFiltererV2 {
+			testFiltererV2 := defaultMockFiltererV2()
+			testFiltererV2.filterByBlockHashErr = errors.New("failed to load filter")
+			return testFiltererV2
+		}(),
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}})
+}
+
 func TestHandleGetCFilterV2(t *testing.T) {
 	t.Parallel()
 
@@ -4411,6 +4945,88 @@ func TestHandleGetCurrentNet(t *testing.T) {
 	}})
 }
 
+func TestHandleGetDAGInfo(t *testing.T) {
+	t.Parallel()
+
+	// Use a network with a shorter KawPoW epoch than the default to ensure
+	// the network parameters are respected.
+	shortEpochParams := cloneParams(defaultChainParams)
+	shortEpochParams.KawPow.EpochLength = 100
+
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleGetDAGInfo: ok",
+		handler: handleGetDAGInfo,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.bestSnapshot.Height = 14999
+			chain.nextEpochHeight = 15000
+			chain.nextEpochRemaining = 1
+			chain.nextEpochTime = time.Unix(1750000150, 0)
+			return chain
+		}(),
+		cmd: &types.GetDAGInfoCmd{},
+		result: &types.GetDAGInfoResult{
+			Epoch:           1,
+			NextEpochHeight: 15000,
+			BlocksRemaining: 1,
+			EstimatedTime:   1750000150,
+			EpochBoundaries: []int64{15000, 22500},
+		},
+	}, {
+		name:    "handleGetDAGInfo: ok with failed regeneration job",
+		handler: handleGetDAGInfo,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.bestSnapshot.Height = 14999
+			chain.nextEpochHeight = 15000
+			chain.nextEpochRemaining = 1
+			chain.nextEpochTime = time.Unix(1750000150, 0)
+			return chain
+		}(),
+		mockDAGManager: &testDAGManager{
+			lastJob: kawpow.DAGJob{
+				ID:     2,
+				Epoch:  1,
+				Status: kawpow.DAGJobFailed,
+				Err:    errors.New("disk full"),
+			},
+			haveLastJob: true,
+		},
+		cmd: &types.GetDAGInfoCmd{},
+		result: &types.GetDAGInfoResult{
+			Epoch:           1,
+			NextEpochHeight: 15000,
+			BlocksRemaining: 1,
+			EstimatedTime:   1750000150,
+			EpochBoundaries: []int64{15000, 22500},
+			RegenJobID:      2,
+			RegenEpoch:      1,
+			RegenStatus:     "failed",
+			RegenError:      "disk full",
+		},
+	}, {
+		name:    "handleGetDAGInfo: ok with network epoch length",
+		handler: handleGetDAGInfo,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.bestSnapshot.Height = 149
+			chain.nextEpochHeight = 200
+			chain.nextEpochRemaining = 51
+			chain.nextEpochTime = time.Unix(1750000150, 0)
+			return chain
+		}(),
+		mockChainParams: shortEpochParams,
+		cmd:             &types.GetDAGInfoCmd{},
+		result: &types.GetDAGInfoResult{
+			Epoch:           1,
+			NextEpochHeight: 200,
+			BlocksRemaining: 51,
+			EstimatedTime:   1750000150,
+			EpochBoundaries: []int64{200, 300},
+		},
+	}})
+}
+
 func TestHandleGetDifficulty(t *testing.T) {
 	t.Parallel()
 
@@ -4420,10 +5036,62 @@ func TestHandleGetDifficulty(t *testing.T) {
 		mockChain: func() *testRPCChain {
 			chain := defaultMockRPCChain()
 			chain.bestSnapshot.Bits = defaultChainParams.PowLimitBits
+			chain.bestSnapshot.NextStakeDiff = 14428162590
 			return chain
 		}(),
-		cmd:    &types.GetDifficultyCmd{},
-		result: float64(1.0),
+		cmd: &types.GetDifficultyCmd{},
+		result: &types.GetDifficultyResult{
+			Difficulty:      float64(1.0),
+			StakeDifficulty: float64(72.14081295),
+		},
+	}})
+}
+
+func TestHandleGetDiffWindowStats(t *testing.T) {
+	t.Parallel()
+
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleGetDiffWindowStats: blocks faster than target",
+		handler: handleGetDiffWindowStats,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.diffWindowStats = &blockchain.DiffWindowStats{
+				WindowSize:       144,
+				BlocksIntoWindow: 72,
+				ElapsedTime:      time.Minute * 90,
+				ExpectedTime:     time.Minute * 360,
+			}
+			return chain
+		}(),
+		cmd: &types.GetDiffWindowStatsCmd{},
+		result: &types.GetDiffWindowStatsResult{
+			WindowSize:       144,
+			BlocksIntoWindow: 72,
+			ElapsedTime:      5400,
+			ExpectedTime:     21600,
+			Direction:        "increase",
+		},
+	}, {
+		name:    "handleGetDiffWindowStats: blocks slower than target",
+		handler: handleGetDiffWindowStats,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.diffWindowStats = &blockchain.DiffWindowStats{
+				WindowSize:       144,
+				BlocksIntoWindow: 10,
+				ElapsedTime:      time.Minute * 100,
+				ExpectedTime:     time.Minute * 50,
+			}
+			return chain
+		}(),
+		cmd: &types.GetDiffWindowStatsCmd{},
+		result: &types.GetDiffWindowStatsResult{
+			WindowSize:       144,
+			BlocksIntoWindow: 10,
+			ElapsedTime:      6000,
+			ExpectedTime:     3000,
+			Direction:        "decrease",
+		},
 	}})
 }
 
@@ -4502,6 +5170,134 @@ func TestHandleGetHeaders(t *testing.T) {
 	}})
 }
 
+func TestHandleGetHealth(t *testing.T) {
+	t.Parallel()
+
+	// Note that the handler queries the mock clock for the age of the best
+	// block before the time since the last validation failure.
+	healthyChain := func() *testRPCChain {
+		chain := defaultMockRPCChain()
+		chain.isCurrent = true
+		return chain
+	}
+	readyDAGManager := func() *testDAGManager {
+		dagManager := defaultMockDAGManager()
+		dagManager.dagReady = true
+		return dagManager
+	}
+	testRPCServerHandler(t, []rpcTest{{
+		name:           "handleGetHealth: healthy",
+		handler:        handleGetHealth,
+		cmd:            &types.GetHealthCmd{},
+		mockChain:      healthyChain(),
+		mockDAGManager: readyDAGManager(),
+		mockClock:      &testClock{since: time.Minute},
+		result: &types.GetHealthResult{
+			Healthy:                  true,
+			ChainCurrent:             true,
+			DAGReady:                 true,
+			RecentBlock:              true,
+			NoRecentValidationErrors: true,
+		},
+	}, {
+		name:    "handleGetHealth: healthy with old validation failure",
+		handler: handleGetHealth,
+		cmd:     &types.GetHealthCmd{},
+		mockChain: func() *testRPCChain {
+			chain := healthyChain()
+			chain.lastValidationFailureTime = time.Unix(1592931302, 0)
+			return chain
+		}(),
+		mockDAGManager: readyDAGManager(),
+		mockClock: &testClock{sinces: []time.Duration{
+			time.Minute, healthValidationFailureWindow + 1,
+		}},
+		result: &types.GetHealthResult{
+			Healthy:                  true,
+			ChainCurrent:             true,
+			DAGReady:                 true,
+			RecentBlock:              true,
+			NoRecentValidationErrors: true,
+		},
+	}, {
+		name:    "handleGetHealth: chain not current",
+		handler: handleGetHealth,
+		cmd:     &types.GetHealthCmd{},
+		mockChain: func() *testRPCChain {
+			chain := healthyChain()
+			chain.isCurrent = false
+			return chain
+		}(),
+		mockDAGManager: readyDAGManager(),
+		mockClock:      &testClock{since: time.Minute},
+		result: &types.GetHealthResult{
+			Healthy:                  false,
+			ChainCurrent:             false,
+			DAGReady:                 true,
+			RecentBlock:              true,
+			NoRecentValidationErrors: true,
+		},
+	}, {
+		name:           "handleGetHealth: DAG not ready",
+		handler:        handleGetHealth,
+		cmd:            &types.GetHealthCmd{},
+		mockChain:      healthyChain(),
+		mockDAGManager: defaultMockDAGManager(),
+		mockClock:      &testClock{since: time.Minute},
+		result: &types.GetHealthResult{
+			Healthy:                  false,
+			ChainCurrent:             true,
+			DAGReady:                 false,
+			RecentBlock:              true,
+			NoRecentValidationErrors: true,
+		},
+	}, {
+		name:           "handleGetHealth: stale best block",
+		handler:        handleGetHealth,
+		cmd:            &types.GetHealthCmd{},
+		mockChain:      healthyChain(),
+		mockDAGManager: readyDAGManager(),
+		mockClock:      &testClock{since: 24 * time.Hour},
+		result: &types.GetHealthResult{
+			Healthy:                  false,
+			ChainCurrent:             true,
+			DAGReady:                 true,
+			RecentBlock:              false,
+			NoRecentValidationErrors: true,
+		},
+	}, {
+		name:    "handleGetHealth: recent validation failure",
+		handler: handleGetHealth,
+		cmd:     &types.GetHealthCmd{},
+		mockChain: func() *testRPCChain {
+			chain := healthyChain()
+			chain.lastValidationFailureTime = time.Unix(1592931302, 0)
+			return chain
+		}(),
+		mockDAGManager: readyDAGManager(),
+		mockClock:      &testClock{since: time.Minute},
+		result: &types.GetHealthResult{
+			Healthy:                  false,
+			ChainCurrent:             true,
+			DAGReady:                 true,
+			RecentBlock:              true,
+			NoRecentValidationErrors: false,
+		},
+	}, {
+		name:    "handleGetHealth: unable to fetch best header",
+		handler: handleGetHealth,
+		cmd:     &types.GetHealthCmd{},
+		mockChain: func() *testRPCChain {
+			chain := healthyChain()
+			chain.headerByHashErr = errors.New("header not found")
+			return chain
+		}(),
+		mockDAGManager: readyDAGManager(),
+		wantErr:        true,
+		errCode:        dcrjson.ErrRPCInternal.Code,
+	}})
+}
+
 func TestHandleGetInfo(t *testing.T) {
 	t.Parallel()
 
@@ -4525,6 +5321,192 @@ func TestHandleGetInfo(t *testing.T) {
 	}})
 }
 
+func TestHandleGetKawPowSeeds(t *testing.T) {
+	t.Parallel()
+
+	// wantSeeds returns the expected result for the provided inclusive range
+	// of epochs based on the seed returned by kawpow.EpochSeed for each one.
+	wantSeeds := func(startEpoch, endEpoch int64) []types.GetKawPowSeedsResult {
+		var results []types.GetKawPowSeedsResult
+		for epoch := startEpoch; epoch <= endEpoch; epoch++ {
+			results = append(results, types.GetKawPowSeedsResult{
+				Epoch:       epoch,
+				SeedHash:    kawpow.EpochSeed(uint64(epoch)).String(),
+				StartHeight: epoch * kawpow.KawPowEpochLength,
+			})
+		}
+		return results
+	}
+
+	// Use a network with a shorter KawPoW epoch than the default to ensure
+	// the network parameters are respected.
+	shortEpochParams := cloneParams(defaultChainParams)
+	shortEpochParams.KawPow.EpochLength = 100
+
+	// The default mock chain tip is at height 432100, which is in epoch 57
+	// and therefore seeds through epoch 58 are available.
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleGetKawPowSeeds: ok from first epoch",
+		handler: handleGetKawPowSeeds,
+		cmd: &types.GetKawPowSeedsCmd{
+			StartEpoch: 0,
+			EndEpoch:   3,
+		},
+		result: wantSeeds(0, 3),
+	}, {
+		name:    "handleGetKawPowSeeds: ok known seeds",
+		handler: handleGetKawPowSeeds,
+		cmd: &types.GetKawPowSeedsCmd{
+			StartEpoch: 0,
+			EndEpoch:   2,
+		},
+		result: []types.GetKawPowSeedsResult{{
+			Epoch:       0,
+			SeedHash:    "0000000000000000000000000000000000000000000000000000000000000000",
+			StartHeight: 0,
+		}, {
+			Epoch:       1,
+			SeedHash:    "63e5f30e16932f36f608404895bca64bc86f3888a94503d6a8628b54d9ec0d29",
+			StartHeight: kawpow.KawPowEpochLength,
+		}, {
+			Epoch:       2,
+			SeedHash:    "d9612d9456c249821f5fc89cdcc081afadf6a900ab007b7fbfdd2808774e0e51",
+			StartHeight: 2 * kawpow.KawPowEpochLength,
+		}},
+	}, {
+		name:    "handleGetKawPowSeeds: ok through next epoch",
+		handler: handleGetKawPowSeeds,
+		cmd: &types.GetKawPowSeedsCmd{
+			StartEpoch: 55,
+			EndEpoch:   58,
+		},
+		result: wantSeeds(55, 58),
+	}, {
+		name:    "handleGetKawPowSeeds: ok single epoch",
+		handler: handleGetKawPowSeeds,
+		cmd: &types.GetKawPowSeedsCmd{
+			StartEpoch: 57,
+			EndEpoch:   57,
+		},
+		result: wantSeeds(57, 57),
+	}, {
+		name:    "handleGetKawPowSeeds: ok with network epoch length",
+		handler: handleGetKawPowSeeds,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.bestSnapshot.Height = 149
+			return chain
+		}(),
+		mockChainParams: shortEpochParams,
+		cmd: &types.GetKawPowSeedsCmd{
+			StartEpoch: 1,
+			EndEpoch:   2,
+		},
+		result: []types.GetKawPowSeedsResult{{
+			Epoch:       1,
+			SeedHash:    kawpow.EpochSeed(1).String(),
+			StartHeight: 100,
+		}, {
+			Epoch:       2,
+			SeedHash:    kawpow.EpochSeed(2).String(),
+			StartHeight: 200,
+		}},
+	}, {
+		name:    "handleGetKawPowSeeds: ok max epochs",
+		handler: handleGetKawPowSeeds,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.bestSnapshot.Height = maxKawPowSeedsPerRequest *
+				kawpow.KawPowEpochLength
+			return chain
+		}(),
+		cmd: &types.GetKawPowSeedsCmd{
+			StartEpoch: 1,
+			EndEpoch:   maxKawPowSeedsPerRequest,
+		},
+		result: wantSeeds(1, maxKawPowSeedsPerRequest),
+	}, {
+		name:    "handleGetKawPowSeeds: too many epochs",
+		handler: handleGetKawPowSeeds,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.bestSnapshot.Height = maxKawPowSeedsPerRequest *
+				kawpow.KawPowEpochLength
+			return chain
+		}(),
+		cmd: &types.GetKawPowSeedsCmd{
+			StartEpoch: 0,
+			EndEpoch:   maxKawPowSeedsPerRequest,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:    "handleGetKawPowSeeds: end epoch after next epoch",
+		handler: handleGetKawPowSeeds,
+		cmd: &types.GetKawPowSeedsCmd{
+			StartEpoch: 58,
+			EndEpoch:   59,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:    "handleGetKawPowSeeds: end epoch before start epoch",
+		handler: handleGetKawPowSeeds,
+		cmd: &types.GetKawPowSeedsCmd{
+			StartEpoch: 5,
+			EndEpoch:   4,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:    "handleGetKawPowSeeds: negative start epoch",
+		handler: handleGetKawPowSeeds,
+		cmd: &types.GetKawPowSeedsCmd{
+			StartEpoch: -1,
+			EndEpoch:   4,
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}})
+}
+
+// TestHandleGetKawPowParams ensures the getkawpowparams handler returns the
+// KawPoW parameters of the active network and reports parameters with an
+// initial DAG size below the minimum as not ASIC resistant.
+func TestHandleGetKawPowParams(t *testing.T) {
+	t.Parallel()
+
+	// wantResult returns the expected result for the provided network params.
+	wantResult := func(params *chaincfg.Params, asicResistant bool) *types.GetKawPowParamsResult {
+		return &types.GetKawPowParamsResult{
+			EpochLength:         params.KawPow.EpochLength,
+			DatasetInitBytes:    params.KawPow.DatasetInitBytes,
+			DatasetGrowthBytes:  params.KawPow.DatasetGrowthBytes,
+			CacheInitBytes:      params.KawPow.CacheInitBytes,
+			CacheGrowthBytes:    params.KawPow.CacheGrowthBytes,
+			CacheRounds:         params.KawPow.CacheRounds,
+			MinDatasetInitBytes: kawpow.MinASICResistantDatasetBytes,
+			ASICResistant:       asicResistant,
+		}
+	}
+
+	tinyDAGParams := cloneParams(defaultChainParams)
+	tinyDAGParams.KawPow.DatasetInitBytes = 128 * 8
+
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleGetKawPowParams: ok",
+		handler: handleGetKawPowParams,
+		cmd:     &types.GetKawPowParamsCmd{},
+		result:  wantResult(defaultChainParams, true),
+	}, {
+		name:            "handleGetKawPowParams: sub-threshold DAG size",
+		handler:         handleGetKawPowParams,
+		cmd:             &types.GetKawPowParamsCmd{},
+		mockChainParams: tinyDAGParams,
+		result:          wantResult(tinyDAGParams, false),
+	}})
+}
+
 func TestHandleGetMempoolInfo(t *testing.T) {
 	t.Parallel()
 
@@ -5723,6 +6705,141 @@ func TestHandleVerifyChain(t *testing.T) {
 	}})
 }
 
+// TestGetWorkNonceByteOrder ensures the KawPoW nonce is encoded in the data of
+// the getwork RPC in little-endian byte order at the documented offset and that
+// parsing submitted data in the same manner as getwork submissions recovers the
+// nonce.
+func TestGetWorkNonceByteOrder(t *testing.T) {
+	t.Parallel()
+
+	const nonce = uint64(0x0102030405060708)
+	wantLE := []byte{0x08, 0x07, 0x06, 0x05, 0x04, 0x03, 0x02, 0x01}
+	wantBE := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+
+	for _, isBlake3PowActive := range []bool{false, true} {
+		header := block432100.Header
+		header.Nonce = nonce
+		data, err := serializeGetWorkData(&header, isBlake3PowActive)
+		if err != nil {
+			t.Fatalf("unexpected serialize error: %v", err)
+		}
+
+		// Ensure the nonce is encoded in little-endian byte order at the
+		// documented offset.
+		gotNonceBytes := data[getworkNonceOffset : getworkNonceOffset+
+			getworkNonceSize]
+		if !bytes.Equal(gotNonceBytes, wantLE) {
+			t.Fatalf("unexpected nonce bytes (blake3 %v) -- got %x, want %x",
+				isBlake3PowActive, gotNonceBytes, wantLE)
+		}
+
+		// Ensure parsing the data the same way submissions are parsed
+		// recovers the nonce.
+		var parsedHeader wire.BlockHeader
+		bhBuf := bytes.NewReader(data[0:wire.MaxBlockHeaderPayload])
+		if err := parsedHeader.Deserialize(bhBuf); err != nil {
+			t.Fatalf("unexpected deserialize error: %v", err)
+		}
+		if parsedHeader.Nonce != nonce {
+			t.Fatalf("unexpected parsed nonce (blake3 %v) -- got %x, want %x",
+				isBlake3PowActive, parsedHeader.Nonce, nonce)
+		}
+
+		// Ensure a nonce submitted in big-endian byte order is not
+		// interpreted as the intended nonce.
+		copy(data[getworkNonceOffset:], wantBE)
+		bhBuf = bytes.NewReader(data[0:wire.MaxBlockHeaderPayload])
+		if err := parsedHeader.Deserialize(bhBuf); err != nil {
+			t.Fatalf("unexpected deserialize error: %v", err)
+		}
+		const swappedNonce = uint64(0x0807060504030201)
+		if parsedHeader.Nonce != swappedNonce {
+			t.Fatalf("unexpected parsed big-endian nonce (blake3 %v) -- got "+
+				"%x, want %x", isBlake3PowActive, parsedHeader.Nonce,
+				swappedNonce)
+		}
+	}
+}
+
+// TestDecodeKawPowSolution ensures submitted KawPoW nonces and mix digests are
+// decoded as expected and that values with incorrect lengths are rejected with
+// a decode error as opposed to being truncated or causing a panic.
+func TestDecodeKawPowSolution(t *testing.T) {
+	t.Parallel()
+
+	validNonce := "0807060504030201"
+	validMix := strings.Repeat("ab", getworkMixDigestSize)
+	tests := []struct {
+		name      string
+		nonce     string
+		mixDigest string
+		wantErr   bool
+	}{{
+		name:      "valid nonce and mix digest",
+		nonce:     validNonce,
+		mixDigest: validMix,
+	}, {
+		name:      "31-byte mix digest",
+		nonce:     validNonce,
+		mixDigest: strings.Repeat("ab", getworkMixDigestSize-1),
+		wantErr:   true,
+	}, {
+		name:      "33-byte mix digest",
+		nonce:     validNonce,
+		mixDigest: strings.Repeat("ab", getworkMixDigestSize+1),
+		wantErr:   true,
+	}, {
+		name:      "empty mix digest",
+		nonce:     validNonce,
+		mixDigest: "",
+		wantErr:   true,
+	}, {
+		name:      "invalid hex mix digest",
+		nonce:     validNonce,
+		mixDigest: strings.Repeat("zz", getworkMixDigestSize),
+		wantErr:   true,
+	}, {
+		name:      "7-byte nonce",
+		nonce:     validNonce[2:],
+		mixDigest: validMix,
+		wantErr:   true,
+	}, {
+		name:      "9-byte nonce",
+		nonce:     validNonce + "00",
+		mixDigest: validMix,
+		wantErr:   true,
+	}}
+
+	for _, test := range tests {
+		nonce, mixDigest, err := decodeKawPowSolution(test.nonce,
+			test.mixDigest)
+		if test.wantErr {
+			var rpcErr *dcrjson.RPCError
+			if !errors.As(err, &rpcErr) {
+				t.Errorf("%q: expected RPC error, got %v", test.name, err)
+				continue
+			}
+			if rpcErr.Code != dcrjson.ErrRPCDecodeHexString {
+				t.Errorf("%q: unexpected error code -- got %v, want %v",
+					test.name, rpcErr.Code, dcrjson.ErrRPCDecodeHexString)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.name, err)
+			continue
+		}
+		if nonce != 0x0102030405060708 {
+			t.Errorf("%q: unexpected nonce -- got %x, want %x", test.name,
+				nonce, uint64(0x0102030405060708))
+		}
+		if got := hex.EncodeToString(mixDigest[:]); got != validMix {
+			t.Errorf("%q: unexpected mix digest -- got %s, want %s",
+				test.name, got, validMix)
+		}
+	}
+}
+
 func TestHandleGetWork(t *testing.T) {
 	t.Parallel()
 
@@ -5760,11 +6877,22 @@ func TestHandleGetWork(t *testing.T) {
 	invalidPOWSub := buf.String()
 
 	// Create a mock block solved by blake3 based on the existing test block.
+	//
+	// Note that the mix digest is set along with the nonce since the KawPoW
+	// proof of work check also ensures it matches the calculated one.
 	solvedBlake3Block := func() *wire.MsgBlock {
+		testServer := &Server{cfg: *defaultMockConfig(defaultChainParams)}
+		kp := testServer.kawPowHasher()
 		isSolved := func(header *wire.BlockHeader) bool {
-			powHash := header.PowHashV2()
-			err := standalone.CheckProofOfWork(&powHash, header.Bits,
-				mockPowLimitBig, nil)
+			mix, result, err := kp.Hash(header.BytesNoNonce(), header.Nonce)
+			if err != nil {
+				t.Fatalf("unable to calculate proof of work hash: %v", err)
+			}
+			var powHash chainhash.Hash
+			copy(powHash[:], result)
+			copy(header.MixDigest[:], mix)
+			err = standalone.CheckProofOfWork(&powHash, header.Bits,
+				mockPowLimitBig)
 			return err == nil
 		}
 		blk := block432100
@@ -5776,6 +6904,21 @@ func TestHandleGetWork(t *testing.T) {
 		return &blk
 	}()
 
+	// Define the work expected to be returned for the mock block template.  It
+	// consists of the serialized block header followed by the internal padding
+	// of the hash function for the active proof of work algorithm, which is
+	// all zeros for the blake3-sized KawPoW work.
+	templateHeaderBytes, err := block432100.Header.Bytes()
+	if err != nil {
+		t.Fatalf("unable to serialize template header: %v", err)
+	}
+	templateHeaderHex := hex.EncodeToString(templateHeaderBytes)
+	wantWorkDataBlake256 := templateHeaderHex + hex.EncodeToString(blake256Pad)
+	wantWorkDataKawPow := templateHeaderHex +
+		strings.Repeat("00", getworkDataLenBlake3-wire.MaxBlockHeaderPayload)
+	const wantWorkTarget = "000000000000000000000000000000000000000000e20f27" +
+		"0000000000000000"
+
 	testRPCServerHandler(t, []rpcTest{{
 		name:    "handleGetWork: CPU IsMining enabled",
 		handler: handleGetWork,
@@ -5831,15 +6974,9 @@ func TestHandleGetWork(t *testing.T) {
 		}(),
 		mockMiningState: defaultMockMiningState(),
 		result: &types.GetWorkResult{
-			Data: "070000009c3c0efea268c124d46d7daeae2d9667e78daa0523a19725" +
-				"00000000000000000bc8a255edde9901ecc4cdb93e4e573cb38ae91e84" +
-				"495ecddc0c93c019351d5d7731998be0a78e955f6fb98d2f35479905c3" +
-				"279f6257beab42a51d556cef55b9010087ba86bb2e5204000100b1a000" +
-				"00e20f27181e4afc5b03000000e4970600de0a0000d2b46d5ef63e4a6d" +
-				"d6ab3b0000000000a200ca770000000000000000000000000000000000" +
-				"000000070000008000000100000000000005a0",
-			Target: "000000000000000000000000000000000000000000e20f27000000" +
-				"0000000000",
+			Data:   wantWorkDataBlake256,
+			Target: wantWorkTarget,
+			Algo:   "blake256",
 		},
 	}, {
 		name:            "handleGetWork: ok with no workstate entries",
@@ -5847,16 +6984,37 @@ func TestHandleGetWork(t *testing.T) {
 		cmd:             &types.GetWorkCmd{},
 		mockMiningState: defaultMockMiningState(),
 		result: &types.GetWorkResult{
-			Data: "070000009c3c0efea268c124d46d7daeae2d9667e78daa0523a19725" +
-				"00000000000000000bc8a255edde9901ecc4cdb93e4e573cb38ae91e84" +
-				"495ecddc0c93c019351d5d7731998be0a78e955f6fb98d2f35479905c3" +
-				"279f6257beab42a51d556cef55b9010087ba86bb2e5204000100b1a000" +
-				"00e20f27181e4afc5b03000000e4970600de0a0000d2b46d5ef63e4a6d" +
-				"d6ab3b0000000000a200ca770000000000000000000000000000000000" +
-				"000000070000008000000100000000000005a0",
-			Target: "000000000000000000000000000000000000000000e20f27000000" +
-				"0000000000",
+			Data:   wantWorkDataBlake256,
+			Target: wantWorkTarget,
+			Algo:   "blake256",
 		},
+	}, {
+		name:            "handleGetWork: ok with kawpow active",
+		handler:         handleGetWork,
+		cmd:             &types.GetWorkCmd{},
+		mockMiningState: defaultMockMiningState(),
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.blake3PowActive = true
+			return chain
+		}(),
+		result: &types.GetWorkResult{
+			Data:   wantWorkDataKawPow,
+			Target: wantWorkTarget,
+			Algo:   "kawpow",
+		},
+	}, {
+		name:            "handleGetWork: kawpow agenda status error",
+		handler:         handleGetWork,
+		cmd:             &types.GetWorkCmd{},
+		mockMiningState: defaultMockMiningState(),
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.blake3PowActiveErr = errors.New("unable to get agenda status")
+			return chain
+		}(),
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
 	}, {
 		name:            "handleGetWork: unable to retrieve template",
 		handler:         handleGetWork,
@@ -5918,7 +7076,7 @@ func TestHandleGetWork(t *testing.T) {
 				isSolved := func(header *wire.BlockHeader) bool {
 					powHash := header.PowHashV1()
 					err := standalone.CheckProofOfWork(&powHash, header.Bits,
-						mockPowLimitBig, nil)
+						mockPowLimitBig)
 					return err == nil
 				}
 				header := block432100.Header
@@ -6122,6 +7280,78 @@ func TestHandleSetGenerate(t *testing.T) {
 	}})
 }
 
+func TestHandleSimulateDifficulty(t *testing.T) {
+	t.Parallel()
+
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleSimulateDifficulty: ok",
+		handler: handleSimulateDifficulty,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.simulateDifficulty = []blockchain.SimulatedDiff{{
+				Height:    432101,
+				Timestamp: 1583000150,
+				Bits:      defaultChainParams.PowLimitBits,
+			}, {
+				Height:    432102,
+				Timestamp: 1583000300,
+				Bits:      defaultChainParams.PowLimitBits,
+			}}
+			return chain
+		}(),
+		cmd: &types.SimulateDifficultyCmd{
+			Intervals: []int64{150, 150},
+		},
+		result: []types.SimulateDifficultyResult{{
+			Height:     432101,
+			Time:       1583000150,
+			Bits:       strconv.FormatInt(int64(defaultChainParams.PowLimitBits), 16),
+			Difficulty: 1.0,
+		}, {
+			Height:     432102,
+			Time:       1583000300,
+			Bits:       strconv.FormatInt(int64(defaultChainParams.PowLimitBits), 16),
+			Difficulty: 1.0,
+		}},
+	}, {
+		name:    "handleSimulateDifficulty: no intervals",
+		handler: handleSimulateDifficulty,
+		cmd: &types.SimulateDifficultyCmd{
+			Intervals: []int64{},
+		},
+		result: []types.SimulateDifficultyResult{},
+	}, {
+		name:    "handleSimulateDifficulty: negative interval",
+		handler: handleSimulateDifficulty,
+		cmd: &types.SimulateDifficultyCmd{
+			Intervals: []int64{150, -1},
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:    "handleSimulateDifficulty: too many intervals",
+		handler: handleSimulateDifficulty,
+		cmd: &types.SimulateDifficultyCmd{
+			Intervals: make([]int64, maxSimulateDifficultyIntervals+1),
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInvalidParameter,
+	}, {
+		name:    "handleSimulateDifficulty: simulation error",
+		handler: handleSimulateDifficulty,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.simulateDifficultyErr = errors.New("simulation error")
+			return chain
+		}(),
+		cmd: &types.SimulateDifficultyCmd{
+			Intervals: []int64{150},
+		},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}})
+}
+
 func TestHandleReconsiderBlock(t *testing.T) {
 	t.Parallel()
 
@@ -6185,6 +7415,46 @@ func TestHandleReconsiderBlock(t *testing.T) {
 	}})
 }
 
+func TestHandleRegenerateDAG(t *testing.T) {
+	t.Parallel()
+
+	testRPCServerHandler(t, []rpcTest{{
+		name:    "handleRegenerateDAG: ok",
+		handler: handleRegenerateDAG,
+		mockChain: func() *testRPCChain {
+			chain := defaultMockRPCChain()
+			chain.bestSnapshot.Height = 15001
+			return chain
+		}(),
+		mockDAGManager: &testDAGManager{
+			regenerateJobID: 1,
+		},
+		cmd: &types.RegenerateDAGCmd{},
+		result: &types.RegenerateDAGResult{
+			JobID: 1,
+			Epoch: 2,
+		},
+	}, {
+		name:    "handleRegenerateDAG: regeneration already in progress",
+		handler: handleRegenerateDAG,
+		mockDAGManager: &testDAGManager{
+			regenerateErr: kawpow.ErrDAGJobInProgress,
+		},
+		cmd:     &types.RegenerateDAGCmd{},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCMisc,
+	}, {
+		name:    "handleRegenerateDAG: unable to remove DAG file",
+		handler: handleRegenerateDAG,
+		mockDAGManager: &testDAGManager{
+			regenerateErr: errors.New("permission denied"),
+		},
+		cmd:     &types.RegenerateDAGCmd{},
+		wantErr: true,
+		errCode: dcrjson.ErrRPCInternal.Code,
+	}})
+}
+
 func TestHandleRegenTemplate(t *testing.T) {
 	t.Parallel()
 
@@ -7458,6 +8728,77 @@ func TestHandleGetRawMempool(t *testing.T) {
 	}})
 }
 
+// TestCreateTxRawResultMaturity ensures the raw transaction results for
+// coinbase transactions in blocks include the height at which their outputs
+// mature while those for other transactions do not.
+func TestCreateTxRawResultMaturity(t *testing.T) {
+	t.Parallel()
+
+	// Create a block with a coinbase and a regular transaction.
+	coinbaseTx := wire.NewMsgTx()
+	coinbaseTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+			wire.MaxPrevOutIndex, wire.TxTreeRegular),
+		SignatureScript: []byte{0x00, 0x00},
+	})
+	coinbaseTx.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+	regularTx := wire.NewMsgTx()
+	regularTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{0x01}, 0,
+			wire.TxTreeRegular),
+	})
+	regularTx.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+	msgBlock := wire.NewMsgBlock(&wire.BlockHeader{
+		Height:    432100,
+		Timestamp: time.Unix(1592931302, 0),
+	})
+	msgBlock.AddTransaction(coinbaseTx)
+	msgBlock.AddTransaction(regularTx)
+
+	blk := dcrutil.NewBlock(msgBlock)
+	blkHeader := &msgBlock.Header
+	blkHeight := blk.Height()
+	testServer := &Server{cfg: *defaultMockConfig(defaultChainParams)}
+	wantMaturityHeight := blkHeight +
+		int64(defaultChainParams.CoinbaseMaturity)
+
+	tests := []struct {
+		name   string
+		tx     *dcrutil.Tx
+		header *wire.BlockHeader
+		want   int64
+	}{{
+		name:   "coinbase in block",
+		tx:     blk.Transactions()[0],
+		header: blkHeader,
+		want:   wantMaturityHeight,
+	}, {
+		name:   "coinbase not in block",
+		tx:     blk.Transactions()[0],
+		header: nil,
+		want:   0,
+	}, {
+		name:   "non-coinbase in block",
+		tx:     blk.Transactions()[1],
+		header: blkHeader,
+		want:   0,
+	}}
+
+	for _, test := range tests {
+		result, err := testServer.createTxRawResult(defaultChainParams,
+			test.tx.MsgTx(), test.tx.Hash().String(), 0, test.header,
+			blk.Hash().String(), blkHeight, 1, noTreasury)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.name, err)
+			continue
+		}
+		if result.MaturityHeight != test.want {
+			t.Errorf("%q: unexpected maturity height -- got %d, want %d",
+				test.name, result.MaturityHeight, test.want)
+		}
+	}
+}
+
 func TestHandleGetRawTransaction(t *testing.T) {
 	t.Parallel()
 
@@ -8208,6 +9549,9 @@ func testRPCServerHandler(t *testing.T, tests []rpcTest) {
 			if test.mockSanityChecker != nil {
 				rpcserverConfig.SanityChecker = test.mockSanityChecker
 			}
+			if test.mockDAGManager != nil {
+				rpcserverConfig.DAGManager = test.mockDAGManager
+			}
 			if test.mockFiltererV2 != nil {
 				rpcserverConfig.FiltererV2 = test.mockFiltererV2
 			}
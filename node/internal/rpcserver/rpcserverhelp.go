@@ -148,6 +148,33 @@ var helpDescsEnUS = map[string]string{
 	"txrawdecoderesult-vout":     "The transaction outputs as JSON objects",
 	"txrawdecoderesult-expiry":   "The transaction expiry",
 
+	// DecodeBlockHeaderResult help.
+	"decodeblockheaderresult-hash":              "The hash of the block header",
+	"decodeblockheaderresult-powhash":           "The KawPoW proof-of-work hash of the block header",
+	"decodeblockheaderresult-version":           "The block version",
+	"decodeblockheaderresult-previousblockhash": "The hash of the previous block",
+	"decodeblockheaderresult-merkleroot":        "The merkle root of the regular transaction tree",
+	"decodeblockheaderresult-stakeroot":         "The merkle root of the stake transaction tree",
+	"decodeblockheaderresult-votebits":          "The vote bits",
+	"decodeblockheaderresult-finalstate":        "The final state value of the ticket pool",
+	"decodeblockheaderresult-voters":            "The number of votes in the block",
+	"decodeblockheaderresult-freshstake":        "The number of new tickets in the block",
+	"decodeblockheaderresult-revocations":       "The number of revocations in the block",
+	"decodeblockheaderresult-poolsize":          "The size of the live ticket pool",
+	"decodeblockheaderresult-bits":              "The bits which represent the block difficulty",
+	"decodeblockheaderresult-sbits":             "The stake difficulty in coins",
+	"decodeblockheaderresult-height":            "The height of the block in the block chain",
+	"decodeblockheaderresult-size":              "The size of the block in bytes",
+	"decodeblockheaderresult-time":              "The block time in seconds since 1 Jan 1970 GMT",
+	"decodeblockheaderresult-nonce":             "The block nonce",
+	"decodeblockheaderresult-mixdigest":         "The KawPoW mix digest of the block",
+	"decodeblockheaderresult-extradata":         "Extra data field for the block",
+	"decodeblockheaderresult-stakeversion":      "The stake version of the block",
+
+	// DecodeBlockHeaderCmd help.
+	"decodeblockheader--synopsis": "Returns a JSON object representing the provided serialized, hex-encoded block header.",
+	"decodeblockheader-hexheader": "Serialized, hex-encoded block header",
+
 	// DecodeRawTransactionCmd help.
 	"decoderawtransaction--synopsis": "Returns a JSON object representing the provided serialized, hex-encoded transaction.",
 	"decoderawtransaction-hextx":     "Serialized, hex-encoded transaction",
@@ -260,19 +287,20 @@ var helpDescsEnUS = map[string]string{
 	"agendainfo-expiretime": "The expiry time of the voting period for the agenda.",
 
 	// TxRawResult help.
-	"txrawresult-hex":           "Hex-encoded transaction",
-	"txrawresult-txid":          "The hash of the transaction",
-	"txrawresult-version":       "The transaction version",
-	"txrawresult-locktime":      "The transaction lock time",
-	"txrawresult-vin":           "The transaction inputs as JSON objects",
-	"txrawresult-vout":          "The transaction outputs as JSON objects",
-	"txrawresult-blockhash":     "The hash of the block that contains the transaction",
-	"txrawresult-confirmations": "Number of confirmations of the block",
-	"txrawresult-time":          "Transaction time in seconds since 1 Jan 1970 GMT",
-	"txrawresult-blocktime":     "Block time in seconds since the 1 Jan 1970 GMT",
-	"txrawresult-blockindex":    "The index within the array of transactions contained by the block",
-	"txrawresult-blockheight":   "The height of the block that contains the transaction",
-	"txrawresult-expiry":        "The transacion expiry",
+	"txrawresult-hex":            "Hex-encoded transaction",
+	"txrawresult-txid":           "The hash of the transaction",
+	"txrawresult-version":        "The transaction version",
+	"txrawresult-locktime":       "The transaction lock time",
+	"txrawresult-vin":            "The transaction inputs as JSON objects",
+	"txrawresult-vout":           "The transaction outputs as JSON objects",
+	"txrawresult-blockhash":      "The hash of the block that contains the transaction",
+	"txrawresult-confirmations":  "Number of confirmations of the block",
+	"txrawresult-time":           "Transaction time in seconds since 1 Jan 1970 GMT",
+	"txrawresult-blocktime":      "Block time in seconds since the 1 Jan 1970 GMT",
+	"txrawresult-blockindex":     "The index within the array of transactions contained by the block",
+	"txrawresult-blockheight":    "The height of the block that contains the transaction",
+	"txrawresult-maturityheight": "The first block height at which the outputs of the transaction may be spent (coinbase txns in blocks only)",
+	"txrawresult-expiry":         "The transacion expiry",
 
 	// GetBlockVerboseResult help.
 	"getblockverboseresult-hash":              "The hash of the block (same as provided)",
@@ -287,6 +315,7 @@ var helpDescsEnUS = map[string]string{
 	"getblockverboseresult-time":              "The block time in seconds since 1 Jan 1970 GMT",
 	"getblockverboseresult-mediantime":        "The median block time over the last 11 blocks",
 	"getblockverboseresult-nonce":             "The block nonce",
+	"getblockverboseresult-mixdigest":         "The KawPoW mix digest of the block",
 	"getblockverboseresult-bits":              "The bits which represent the block difficulty",
 	"getblockverboseresult-difficulty":        "The proof-of-work difficulty as a multiple of the minimum difficulty",
 	"getblockverboseresult-chainwork":         "The total number of hashes expected to produce the chain up to the block in hex",
@@ -360,6 +389,43 @@ var helpDescsEnUS = map[string]string{
 	"getblocksubsidyresult-pow":       "The Proof-of-Work subsidy",
 	"getblocksubsidyresult-total":     "The total subsidy",
 
+	// GetBlockTemplateCmd help.
+	"getblocktemplate--synopsis": "Returns a block template for KawPoW miners that includes the transactions to mine, the seed hash of the epoch, and the target difficulty.",
+
+	// GetBlockTemplateResultTx help.
+	"getblocktemplateresulttx-data": "Hex-encoded serialized transaction",
+	"getblocktemplateresulttx-hash": "The hash of the transaction",
+	"getblocktemplateresulttx-fee":  "The fee paid by the transaction in atoms",
+
+	// GetBlockTemplateResult help.
+	"getblocktemplateresult-version":           "The block version",
+	"getblocktemplateresult-previousblockhash": "The hash of the previous block",
+	"getblocktemplateresult-transactions":      "The regular transactions to include in the block, excluding the coinbase",
+	"getblocktemplateresult-stransactions":     "The stake transactions to include in the block",
+	"getblocktemplateresult-coinbasevalue":     "The total value of the coinbase outputs in atoms",
+	"getblocktemplateresult-bits":              "The difficulty bits of the block in compact form",
+	"getblocktemplateresult-height":            "The height of the block",
+	"getblocktemplateresult-curtime":           "The timestamp of the block in seconds since 1 Jan 1970 GMT",
+	"getblocktemplateresult-seedhash":          "The KawPoW seed hash of the epoch the block belongs to",
+	"getblocktemplateresult-target":            "The hex-encoded target the proof of work hash must not exceed",
+
+	// GetCFilterCmd help.
+	"getcfilter--synopsis": "Returns the committed version 2 block filter for the given block",
+	"getcfilter-blockhash": "The block hash of the filter to retrieve",
+
+	// GetCFilterResult help.
+	"getcfilterresult-blockhash": "The block hash for which the filter includes data",
+	"getcfilterresult-data":      "Hex-encoded bytes of the serialized filter",
+
+	// GetCFilterHeaderCmd help.
+	"getcfilterheader--synopsis": "Returns the hash of the committed version 2 block filter for the given block along with the resulting header commitment root",
+	"getcfilterheader-blockhash": "The block hash of the filter header to retrieve",
+
+	// GetCFilterHeaderResult help.
+	"getcfilterheaderresult-blockhash":      "The block hash for which the filter includes data",
+	"getcfilterheaderresult-filterhash":     "The hash of the serialized filter",
+	"getcfilterheaderresult-commitmentroot": "The header commitment root that commits to the filter hash",
+
 	// GetCFilterV2Cmd help.
 	"getcfilterv2--synopsis": "Returns the version 2 block filter for the given block along with a proof that can be used to prove the filter is committed to by the block header",
 	"getcfilterv2-blockhash": "The block hash of the filter to retrieve",
@@ -394,9 +460,36 @@ var helpDescsEnUS = map[string]string{
 	"getcurrentnet--synopsis": "Get Decred network the server is running on.",
 	"getcurrentnet--result0":  "The network identifier",
 
+	// GetDAGInfoCmd help.
+	"getdaginfo--synopsis": "Returns information about the KawPoW epoch of the current best chain tip and when the DAG will next change.",
+
+	// GetDAGInfoResult help.
+	"getdaginforesult-epoch":           "The KawPoW epoch of the current best chain tip",
+	"getdaginforesult-nextepochheight": "The height of the first block of the next epoch",
+	"getdaginforesult-blocksremaining": "The number of blocks remaining until the next epoch begins",
+	"getdaginforesult-estimatedtime":   "The estimated time the next epoch will begin in seconds since 1 Jan 1970 GMT based on the target time per block",
+	"getdaginforesult-epochboundaries": "The heights at which a new epoch begins, and therefore the DAG must be switched, within the next two epochs worth of blocks",
+	"getdaginforesult-regenjobid":      "The ID of the most recent DAG regeneration job (omitted if no job was started)",
+	"getdaginforesult-regenepoch":      "The epoch the most recent DAG regeneration job is for (omitted if no job was started)",
+	"getdaginforesult-regenstatus":     "The status of the most recent DAG regeneration job: running, done, or failed (omitted if no job was started)",
+	"getdaginforesult-regenerror":      "The reason the most recent DAG regeneration job failed (omitted if it did not fail)",
+
 	// GetDifficultyCmd help.
-	"getdifficulty--synopsis": "Returns the proof-of-work difficulty as a multiple of the minimum difficulty.",
-	"getdifficulty--result0":  "The difficulty",
+	"getdifficulty--synopsis": "Returns the proof-of-work and stake difficulties as multiples of their respective minimum difficulties.",
+
+	// GetDifficultyResult help.
+	"getdifficultyresult-difficulty":      "The proof-of-work difficulty as a multiple of the minimum difficulty",
+	"getdifficultyresult-stakedifficulty": "The stake difficulty for the next block as a multiple of the minimum stake difficulty",
+
+	// GetDiffWindowStatsCmd help.
+	"getdiffwindowstats--synopsis": "Returns statistics about the progress of the current proof-of-work difficulty retarget window.",
+
+	// GetDiffWindowStatsResult help.
+	"getdiffwindowstatsresult-windowsize":       "The number of blocks in a difficulty retarget window",
+	"getdiffwindowstatsresult-blocksintowindow": "The number of blocks mined since the start of the current window",
+	"getdiffwindowstatsresult-elapsedtime":      "The number of seconds between the block that started the current window and the current best chain tip",
+	"getdiffwindowstatsresult-expectedtime":     "The number of seconds the blocks mined since the start of the current window were expected to take based on the target time per block",
+	"getdiffwindowstatsresult-direction":        "The direction the difficulty is trending based on the elapsed time versus the expected time: increase, decrease, or unchanged",
 
 	// GetStakeDifficultyCmd help.
 	"getstakedifficulty--synopsis":     "Returns the proof-of-stake difficulty.",
@@ -483,9 +576,42 @@ var helpDescsEnUS = map[string]string{
 	"getheaders-hashstop":      "Block hash to stop including block headers for. Set to zero to get as many blocks as possible",
 	"getheadersresult-headers": "Serialized block headers of all located blocks, limited to some arbitrary maximum number of hashes (currently 2000, which matches the wire protocol headers message, but this is not guaranteed)",
 
+	// GetHealthCmd help.
+	"gethealth--synopsis": "Returns the health of the server along with the status of the subsystems that determine it.",
+
+	// GetHealthResult help.
+	"gethealthresult-healthy":                  "Whether or not all of the subsystems are healthy",
+	"gethealthresult-chaincurrent":             "Whether or not the chain believes it is current",
+	"gethealthresult-dagready":                 "Whether or not the KawPoW DAG for the epoch of the current best block is cached and not being regenerated",
+	"gethealthresult-recentblock":              "Whether or not the best block was mined within 10 target block times",
+	"gethealthresult-norecentvalidationerrors": "Whether or not no blocks have failed validation within the last hour",
+
 	// GetInfoCmd help.
 	"getinfo--synopsis": "Returns a JSON object containing various state info.",
 
+	// GetKawPowParamsCmd help.
+	"getkawpowparams--synopsis": "Returns the KawPoW proof of work parameters of the active network along with whether or not the initial DAG size is large enough for the proof of work to remain ASIC resistant.",
+
+	// GetKawPowParamsResult help.
+	"getkawpowparamsresult-epochlength":         "The number of blocks in each KawPoW epoch",
+	"getkawpowparamsresult-datasetinitbytes":    "The size of the DAG in bytes for the first epoch",
+	"getkawpowparamsresult-datasetgrowthbytes":  "The number of bytes the DAG grows by for each epoch after the first one",
+	"getkawpowparamsresult-cacheinitbytes":      "The size of the verification cache in bytes for the first epoch",
+	"getkawpowparamsresult-cachegrowthbytes":    "The number of bytes the verification cache grows by for each epoch after the first one",
+	"getkawpowparamsresult-cacherounds":         "The number of rounds used to generate the verification cache",
+	"getkawpowparamsresult-mindatasetinitbytes": "The minimum size of the DAG in bytes for the first epoch required for the proof of work to remain ASIC resistant",
+	"getkawpowparamsresult-asicresistant":       "Whether or not the DAG size for the first epoch meets the minimum required for the proof of work to remain ASIC resistant",
+
+	// GetKawPowSeedsCmd help.
+	"getkawpowseeds--synopsis":  "Returns the KawPoW seed hash and first block height of each epoch in the provided inclusive range of epochs for use by external DAG generation services.  At most 1000 epochs may be requested at once and the range may not extend beyond the epoch after the one the current best chain tip belongs to.",
+	"getkawpowseeds-startepoch": "The first epoch to return the seed hash for",
+	"getkawpowseeds-endepoch":   "The final epoch to return the seed hash for",
+
+	// GetKawPowSeedsResult help.
+	"getkawpowseedsresult-epoch":       "The KawPoW epoch",
+	"getkawpowseedsresult-seedhash":    "The seed hash used to generate the cache and DAG for the epoch",
+	"getkawpowseedsresult-startheight": "The height of the first block of the epoch",
+
 	// GetMempoolInfoCmd help.
 	"getmempoolinfo--synopsis": "Returns memory pool information",
 
@@ -681,6 +807,7 @@ var helpDescsEnUS = map[string]string{
 	"getworkresult-hash1":    "(DEPRECATED) Hex-encoded formatted hash buffer",
 	"getworkresult-midstate": "(DEPRECATED) Hex-encoded precomputed hash state after hashing first half of the data",
 	"getworkresult-target":   "Hex-encoded little-endian hash target",
+	"getworkresult-algo":     "The proof of work hash function that must be used to solve the work (blake256 or kawpow)",
 
 	// GetWorkCmd help.
 	"getwork--synopsis":   "Returns formatted hash data to work on or checks and submits solved data.",
@@ -725,6 +852,16 @@ var helpDescsEnUS = map[string]string{
 	"setgenerate-generate":     "Use true to enable generation, false to disable it",
 	"setgenerate-genproclimit": "The number of processors (cores) to limit generation to or -1 for default",
 
+	// SimulateDifficultyCmd help.
+	"simulatedifficulty--synopsis": "Returns the required proof-of-work difficulty for each block in a hypothetical sequence of blocks that extends the current best chain tip using the currently active difficulty algorithm.",
+	"simulatedifficulty-intervals": "The number of seconds between each hypothetical block and its parent",
+
+	// SimulateDifficultyResult help.
+	"simulatedifficultyresult-height":     "The height of the hypothetical block",
+	"simulatedifficultyresult-time":       "The timestamp of the hypothetical block",
+	"simulatedifficultyresult-bits":       "The required difficulty of the hypothetical block in compact form",
+	"simulatedifficultyresult-difficulty": "The required difficulty of the hypothetical block as a multiple of the minimum difficulty",
+
 	// StartProfilerCmd help.
 	"startprofiler--synopsis":        "Starts the HTTP profile server listening on a given address.",
 	"startprofiler-addr":             "The interface/port to listen for profile server connections (e.g. 127.0.0.1:6060)",
@@ -838,6 +975,17 @@ var helpDescsEnUS = map[string]string{
 	"rescannedblock-hash":         "The hash of the block containing matching transactions.",
 	"rescannedblock-transactions": "Array of hex-encoded bytes of the serialized matching transactions.",
 
+	// EstimateConfirmTimeCmd help.
+	"estimateconfirmtime--synopsis":     "Returns the estimated time until a transaction mined in the next block reaches the provided number of confirmations.  The expected interval between blocks blends the target time per block with the average interval observed over the most recent 144 blocks.",
+	"estimateconfirmtime-confirmations": "The number of confirmations to estimate the time for",
+
+	// EstimateConfirmTimeResult help.
+	"estimateconfirmtimeresult-confirmations":     "The number of confirmations the estimate is for",
+	"estimateconfirmtimeresult-targetinterval":    "The target time per block in seconds",
+	"estimateconfirmtimeresult-observedinterval":  "The average interval between the most recent blocks in seconds, or 0 when there are no prior blocks",
+	"estimateconfirmtimeresult-estimatedinterval": "The expected interval between blocks in seconds used for the estimate",
+	"estimateconfirmtimeresult-seconds":           "The estimated number of seconds until the provided number of confirmations is reached",
+
 	// EstimateFee help.
 	"estimatefee--synopsis": "Returns the estimated fee in dcr/kb.",
 	"estimatefee-numblocks": "(unused)",
@@ -944,6 +1092,13 @@ var helpDescsEnUS = map[string]string{
 	"version--result0--key":   "Program or API name",
 	"version--result0--value": "Object containing the semantic version",
 
+	// RegenerateDAGCmd help.
+	"regeneratedag--synopsis": "Removes the cached KawPoW DAG file for the epoch of the current best chain tip and regenerates it in the background for recovery purposes.  The progress of the regeneration may be queried with getdaginfo.",
+
+	// RegenerateDAGResult help.
+	"regeneratedagresult-jobid": "The ID of the regeneration job",
+	"regeneratedagresult-epoch": "The epoch the DAG is being regenerated for",
+
 	// regentemplate help
 	"regentemplate--synopsis": "Asks the node to regenerate its block mining template.",
 }
@@ -957,8 +1112,10 @@ var rpcResultTypes = map[types.Method][]interface{}{
 	"createrawsstx":         {(*string)(nil)},
 	"createrawtransaction":  {(*string)(nil)},
 	"debuglevel":            {(*string)(nil), (*string)(nil)},
+	"decodeblockheader":     {(*types.DecodeBlockHeaderResult)(nil)},
 	"decoderawtransaction":  {(*types.TxRawDecodeResult)(nil)},
 	"decodescript":          {(*types.DecodeScriptResult)(nil)},
+	"estimateconfirmtime":   {(*types.EstimateConfirmTimeResult)(nil)},
 	"estimatefee":           {(*float64)(nil)},
 	"estimatesmartfee":      {(*types.EstimateSmartFeeResult)(nil)},
 	"estimatestakediff":     {(*types.EstimateStakeDiffResult)(nil)},
@@ -977,16 +1134,24 @@ var rpcResultTypes = map[types.Method][]interface{}{
 	"getblockhash":          {(*string)(nil)},
 	"getblockheader":        {(*string)(nil), (*types.GetBlockHeaderVerboseResult)(nil)},
 	"getblocksubsidy":       {(*types.GetBlockSubsidyResult)(nil)},
+	"getblocktemplate":      {(*types.GetBlockTemplateResult)(nil)},
+	"getcfilter":            {(*types.GetCFilterResult)(nil)},
+	"getcfilterheader":      {(*types.GetCFilterHeaderResult)(nil)},
 	"getcfilterv2":          {(*types.GetCFilterV2Result)(nil)},
 	"getchaintips":          {(*[]types.GetChainTipsResult)(nil)},
 	"getcoinsupply":         {(*int64)(nil)},
 	"getconnectioncount":    {(*int32)(nil)},
 	"getcurrentnet":         {(*uint32)(nil)},
-	"getdifficulty":         {(*float64)(nil)},
+	"getdaginfo":            {(*types.GetDAGInfoResult)(nil)},
+	"getdifficulty":         {(*types.GetDifficultyResult)(nil)},
+	"getdiffwindowstats":    {(*types.GetDiffWindowStatsResult)(nil)},
 	"getgenerate":           {(*bool)(nil)},
 	"gethashespersec":       {(*float64)(nil)},
 	"getheaders":            {(*types.GetHeadersResult)(nil)},
+	"gethealth":             {(*types.GetHealthResult)(nil)},
 	"getinfo":               {(*types.InfoChainResult)(nil)},
+	"getkawpowparams":       {(*types.GetKawPowParamsResult)(nil)},
+	"getkawpowseeds":        {(*[]types.GetKawPowSeedsResult)(nil)},
 	"getmempoolinfo":        {(*types.GetMempoolInfoResult)(nil)},
 	"getmininginfo":         {(*types.GetMiningInfoResult)(nil)},
 	"getmixmessage":         {(*types.GetMixMessageResult)(nil)},
@@ -1013,10 +1178,12 @@ var rpcResultTypes = map[types.Method][]interface{}{
 	"node":                  nil,
 	"ping":                  nil,
 	"reconsiderblock":       nil,
+	"regeneratedag":         {(*types.RegenerateDAGResult)(nil)},
 	"regentemplate":         nil,
 	"sendrawmixmessage":     nil,
 	"sendrawtransaction":    {(*string)(nil)},
 	"setgenerate":           nil,
+	"simulatedifficulty":    {(*[]types.SimulateDifficultyResult)(nil)},
 	"startprofiler":         {(*types.StartProfilerResult)(nil)},
 	"stop":                  {(*string)(nil)},
 	"stopprofiler":          {(*string)(nil)},
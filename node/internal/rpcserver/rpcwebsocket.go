@@ -19,7 +19,6 @@ import (
 	"time"
 
 	"github.com/decred/dcrd/blockchain/stake/v5"
-	"github.com/decred/dcrd/blockchain/standalone/v2"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/dcrd/crypto/rand"
@@ -34,6 +33,7 @@ import (
 	"github.com/decred/dcrd/txscript/v4/stdscript"
 	"github.com/decred/dcrd/wire"
 	"github.com/gorilla/websocket"
+	"vigil.network/node/blockchain/standalone"
 )
 
 const (
@@ -103,7 +103,7 @@ var wsHandlersBeforeInit = map[types.Method]wsCommandHandler{
 // must be run in a separate goroutine.  It should be invoked from the websocket
 // server handler which runs each new connection in a new goroutine thereby
 // satisfying the requirement.
-func (s *Server) WebsocketHandler(ctx context.Context, conn *websocket.Conn, remoteAddr string, authenticated bool, isAdmin bool) {
+func (s *Server) WebsocketHandler(ctx context.Context, conn *websocket.Conn, remoteAddr string, authenticated bool, scope authScope) {
 	// Clear the read deadline that was set before the websocket hijacked
 	// the connection.
 	conn.SetReadDeadline(timeZeroVal)
@@ -121,7 +121,7 @@ func (s *Server) WebsocketHandler(ctx context.Context, conn *websocket.Conn, rem
 	// Create a new websocket client to handle the new websocket connection
 	// and wait for it to shutdown.  Once it has shutdown (and hence
 	// disconnected), remove it and any notifications it registered for.
-	client, err := newWebsocketClient(s, conn, remoteAddr, authenticated, isAdmin)
+	client, err := newWebsocketClient(s, conn, remoteAddr, authenticated, scope)
 	if err != nil {
 		log.Errorf("Failed to serve client %s: %v", remoteAddr, err)
 		conn.Close()
@@ -1343,9 +1343,9 @@ type wsClient struct {
 	// and therefore is allowed to communicated over the websocket.
 	authenticated bool
 
-	// isAdmin specifies whether a client may change the state of the server;
-	// false means its access is only to the limited set of RPC calls.
-	isAdmin bool
+	// scope specifies the set of RPC calls the client may invoke.  Only
+	// clients with the admin scope may change the state of the server.
+	scope authScope
 
 	// sessionID is a random ID generated for each client when connected.
 	// These IDs may be queried by a client using the session RPC.  A change
@@ -1487,7 +1487,7 @@ out:
 				break out
 			case !c.authenticated:
 				// Check credentials.
-				c.authenticated, c.isAdmin = c.rpcServer.checkAuthUserPass(
+				c.authenticated, c.scope = c.rpcServer.checkAuthUserPass(
 					authCmd.Username, authCmd.Passphrase, c.addr)
 				if !c.authenticated {
 					break out
@@ -1506,24 +1506,22 @@ out:
 				continue
 			}
 
-			// Check if the client is using limited RPC credentials and
+			// Check if the client is using limited or mining RPC credentials and
 			// error when not authorized to call the supplied RPC.
-			if !c.isAdmin {
-				if _, ok := rpcLimited[req.Method]; !ok {
-					jsonErr := &dcrjson.RPCError{
-						Code:    dcrjson.ErrRPCInvalidParams.Code,
-						Message: "limited user not authorized for this method",
-					}
-					// Marshal and send response.
-					reply, err = createMarshalledReply("", req.ID, nil, jsonErr)
-					if err != nil {
-						log.Errorf("Failed to marshal parse failure "+
-							"reply: %v", err)
-						continue
-					}
-					c.SendMessage(reply, nil)
+			if !c.scope.authorized(req.Method) {
+				jsonErr := &dcrjson.RPCError{
+					Code:    dcrjson.ErrRPCInvalidParams.Code,
+					Message: c.scope.notAuthorizedMsg(),
+				}
+				// Marshal and send response.
+				reply, err = createMarshalledReply("", req.ID, nil, jsonErr)
+				if err != nil {
+					log.Errorf("Failed to marshal parse failure "+
+						"reply: %v", err)
 					continue
 				}
+				c.SendMessage(reply, nil)
+				continue
 			}
 
 			// Asynchronously handle the request.  A semaphore is used to
@@ -1695,7 +1693,7 @@ out:
 							break out
 						case !c.authenticated:
 							// Check credentials.
-							c.authenticated, c.isAdmin = c.rpcServer.checkAuthUserPass(
+							c.authenticated, c.scope = c.rpcServer.checkAuthUserPass(
 								authCmd.Username, authCmd.Passphrase, c.addr)
 							if !c.authenticated {
 								break out
@@ -1715,27 +1713,25 @@ out:
 							continue
 						}
 
-						// Check if the client is using limited RPC credentials and
+						// Check if the client is using limited or mining RPC credentials and
 						// error when not authorized to call the supplied RPC.
-						if !c.isAdmin {
-							if _, ok := rpcLimited[req.Method]; !ok {
-								jsonErr := &dcrjson.RPCError{
-									Code:    dcrjson.ErrRPCInvalidParams.Code,
-									Message: "limited user not authorized for this method",
-								}
-								// Marshal and send response.
-								reply, err = createMarshalledReply(req.Jsonrpc, req.ID, nil, jsonErr)
-								if err != nil {
-									log.Errorf("Failed to marshal parse failure "+
-										"reply: %v", err)
-									continue
-								}
-
-								if reply != nil {
-									results = append(results, reply)
-								}
+						if !c.scope.authorized(req.Method) {
+							jsonErr := &dcrjson.RPCError{
+								Code:    dcrjson.ErrRPCInvalidParams.Code,
+								Message: c.scope.notAuthorizedMsg(),
+							}
+							// Marshal and send response.
+							reply, err = createMarshalledReply(req.Jsonrpc, req.ID, nil, jsonErr)
+							if err != nil {
+								log.Errorf("Failed to marshal parse failure "+
+									"reply: %v", err)
 								continue
 							}
+
+							if reply != nil {
+								results = append(results, reply)
+							}
+							continue
 						}
 
 						// Lookup the websocket extension for the command, if it doesn't
@@ -2032,7 +2028,7 @@ func (c *wsClient) Run(ctx context.Context) {
 // incoming and outgoing messages in separate goroutines complete with queuing
 // and asynchronous handling for long-running operations.
 func newWebsocketClient(server *Server, conn *websocket.Conn,
-	remoteAddr string, authenticated bool, isAdmin bool) (*wsClient, error) {
+	remoteAddr string, authenticated bool, scope authScope) (*wsClient, error) {
 
 	sessionID := rand.Uint64()
 
@@ -2040,7 +2036,7 @@ func newWebsocketClient(server *Server, conn *websocket.Conn,
 		conn:              conn,
 		addr:              remoteAddr,
 		authenticated:     authenticated,
-		isAdmin:           isAdmin,
+		scope:             scope,
 		sessionID:         sessionID,
 		rpcServer:         server,
 		serviceRequestSem: makeSemaphore(server.cfg.RPCMaxConcurrentReqs),
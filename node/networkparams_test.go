@@ -10,8 +10,8 @@ import (
 	"testing"
 
 	"github.com/decred/base58"
-	"github.com/decred/dcrd/blockchain/standalone/v2"
 	"github.com/decred/dcrd/chaincfg/v3"
+	"vigil.network/node/blockchain/standalone"
 )
 
 // checkPowLimitsAreConsistent ensures PowLimit and PowLimitBits are consistent
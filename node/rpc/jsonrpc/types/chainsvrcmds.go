@@ -158,6 +158,19 @@ func NewDebugLevelCmd(levelSpec string) *DebugLevelCmd {
 	}
 }
 
+// DecodeBlockHeaderCmd defines the decodeblockheader JSON-RPC command.
+type DecodeBlockHeaderCmd struct {
+	HexHeader string
+}
+
+// NewDecodeBlockHeaderCmd returns a new instance which can be used to issue a
+// decodeblockheader JSON-RPC command.
+func NewDecodeBlockHeaderCmd(hexHeader string) *DecodeBlockHeaderCmd {
+	return &DecodeBlockHeaderCmd{
+		HexHeader: hexHeader,
+	}
+}
+
 // DecodeRawTransactionCmd defines the decoderawtransaction JSON-RPC command.
 type DecodeRawTransactionCmd struct {
 	HexTx string
@@ -185,6 +198,19 @@ func NewDecodeScriptCmd(hexScript string) *DecodeScriptCmd {
 	}
 }
 
+// EstimateConfirmTimeCmd defines the estimateconfirmtime JSON-RPC command.
+type EstimateConfirmTimeCmd struct {
+	Confirmations int64
+}
+
+// NewEstimateConfirmTimeCmd returns a new instance which can be used to issue
+// an estimateconfirmtime JSON-RPC command.
+func NewEstimateConfirmTimeCmd(confirmations int64) *EstimateConfirmTimeCmd {
+	return &EstimateConfirmTimeCmd{
+		Confirmations: confirmations,
+	}
+}
+
 // EstimateFeeCmd defines the estimatefee JSON-RPC command.
 type EstimateFeeCmd struct {
 	NumBlocks int64
@@ -434,6 +460,41 @@ func NewGetBlockSubsidyCmd(height int64, voters uint16) *GetBlockSubsidyCmd {
 	}
 }
 
+// GetBlockTemplateCmd defines the getblocktemplate JSON-RPC command.
+type GetBlockTemplateCmd struct{}
+
+// NewGetBlockTemplateCmd returns a new instance which can be used to issue a
+// getblocktemplate JSON-RPC command.
+func NewGetBlockTemplateCmd() *GetBlockTemplateCmd {
+	return &GetBlockTemplateCmd{}
+}
+
+// GetCFilterCmd defines the getcfilter JSON-RPC command.
+type GetCFilterCmd struct {
+	BlockHash string
+}
+
+// NewGetCFilterCmd returns a new instance which can be used to issue a
+// getcfilter JSON-RPC command.
+func NewGetCFilterCmd(hash string) *GetCFilterCmd {
+	return &GetCFilterCmd{
+		BlockHash: hash,
+	}
+}
+
+// GetCFilterHeaderCmd defines the getcfilterheader JSON-RPC command.
+type GetCFilterHeaderCmd struct {
+	BlockHash string
+}
+
+// NewGetCFilterHeaderCmd returns a new instance which can be used to issue a
+// getcfilterheader JSON-RPC command.
+func NewGetCFilterHeaderCmd(hash string) *GetCFilterHeaderCmd {
+	return &GetCFilterHeaderCmd{
+		BlockHash: hash,
+	}
+}
+
 // GetCFilterV2Cmd defines the getcfilterv2 JSON-RPC command.
 type GetCFilterV2Cmd struct {
 	BlockHash string
@@ -483,6 +544,15 @@ func NewGetCurrentNetCmd() *GetCurrentNetCmd {
 	return &GetCurrentNetCmd{}
 }
 
+// GetDAGInfoCmd defines the getdaginfo JSON-RPC command.
+type GetDAGInfoCmd struct{}
+
+// NewGetDAGInfoCmd returns a new instance which can be used to issue a
+// getdaginfo JSON-RPC command.
+func NewGetDAGInfoCmd() *GetDAGInfoCmd {
+	return &GetDAGInfoCmd{}
+}
+
 // GetDifficultyCmd defines the getdifficulty JSON-RPC command.
 type GetDifficultyCmd struct{}
 
@@ -492,6 +562,15 @@ func NewGetDifficultyCmd() *GetDifficultyCmd {
 	return &GetDifficultyCmd{}
 }
 
+// GetDiffWindowStatsCmd defines the getdiffwindowstats JSON-RPC command.
+type GetDiffWindowStatsCmd struct{}
+
+// NewGetDiffWindowStatsCmd returns a new instance which can be used to issue a
+// getdiffwindowstats JSON-RPC command.
+func NewGetDiffWindowStatsCmd() *GetDiffWindowStatsCmd {
+	return &GetDiffWindowStatsCmd{}
+}
+
 // GetGenerateCmd defines the getgenerate JSON-RPC command.
 type GetGenerateCmd struct{}
 
@@ -519,6 +598,30 @@ func NewGetInfoCmd() *GetInfoCmd {
 	return &GetInfoCmd{}
 }
 
+// GetKawPowParamsCmd defines the getkawpowparams JSON-RPC command.
+type GetKawPowParamsCmd struct{}
+
+// NewGetKawPowParamsCmd returns a new instance which can be used to issue a
+// getkawpowparams JSON-RPC command.
+func NewGetKawPowParamsCmd() *GetKawPowParamsCmd {
+	return &GetKawPowParamsCmd{}
+}
+
+// GetKawPowSeedsCmd defines the getkawpowseeds JSON-RPC command.
+type GetKawPowSeedsCmd struct {
+	StartEpoch int64
+	EndEpoch   int64
+}
+
+// NewGetKawPowSeedsCmd returns a new instance which can be used to issue a
+// getkawpowseeds JSON-RPC command.
+func NewGetKawPowSeedsCmd(startEpoch, endEpoch int64) *GetKawPowSeedsCmd {
+	return &GetKawPowSeedsCmd{
+		StartEpoch: startEpoch,
+		EndEpoch:   endEpoch,
+	}
+}
+
 // GetHeadersCmd defines the getheaders JSON-RPC command.
 type GetHeadersCmd struct {
 	BlockLocators []string `json:"blocklocators"`
@@ -534,6 +637,15 @@ func NewGetHeadersCmd(blockLocators []string, hashStop string) *GetHeadersCmd {
 	}
 }
 
+// GetHealthCmd defines the gethealth JSON-RPC command.
+type GetHealthCmd struct{}
+
+// NewGetHealthCmd returns a new instance which can be used to issue a
+// gethealth JSON-RPC command.
+func NewGetHealthCmd() *GetHealthCmd {
+	return &GetHealthCmd{}
+}
+
 // GetMempoolInfoCmd defines the getmempoolinfo JSON-RPC command.
 type GetMempoolInfoCmd struct{}
 
@@ -830,6 +942,15 @@ func NewGetWorkCmd(data *string) *GetWorkCmd {
 	}
 }
 
+// RegenerateDAGCmd defines the regeneratedag JSON-RPC command.
+type RegenerateDAGCmd struct{}
+
+// NewRegenerateDAGCmd returns a new instance which can be used to issue a
+// regeneratedag JSON-RPC command.
+func NewRegenerateDAGCmd() *RegenerateDAGCmd {
+	return &RegenerateDAGCmd{}
+}
+
 // RegenTemplateCmd defines the regentemplate JSON-RPC command.
 type RegenTemplateCmd struct{}
 
@@ -971,6 +1092,19 @@ func NewSetGenerateCmd(generate bool, genProcLimit *int) *SetGenerateCmd {
 	}
 }
 
+// SimulateDifficultyCmd defines the simulatedifficulty JSON-RPC command.
+type SimulateDifficultyCmd struct {
+	Intervals []int64
+}
+
+// NewSimulateDifficultyCmd returns a new instance which can be used to issue a
+// simulatedifficulty JSON-RPC command.
+func NewSimulateDifficultyCmd(intervals []int64) *SimulateDifficultyCmd {
+	return &SimulateDifficultyCmd{
+		Intervals: intervals,
+	}
+}
+
 // StartProfilerCmd defines the startprofiler JSON-RPC command.
 type StartProfilerCmd struct {
 	Addr             string
@@ -1151,8 +1285,10 @@ func init() {
 	dcrjson.MustRegister(Method("createrawsstx"), (*CreateRawSStxCmd)(nil), flags)
 	dcrjson.MustRegister(Method("createrawtransaction"), (*CreateRawTransactionCmd)(nil), flags)
 	dcrjson.MustRegister(Method("debuglevel"), (*DebugLevelCmd)(nil), flags)
+	dcrjson.MustRegister(Method("decodeblockheader"), (*DecodeBlockHeaderCmd)(nil), flags)
 	dcrjson.MustRegister(Method("decoderawtransaction"), (*DecodeRawTransactionCmd)(nil), flags)
 	dcrjson.MustRegister(Method("decodescript"), (*DecodeScriptCmd)(nil), flags)
+	dcrjson.MustRegister(Method("estimateconfirmtime"), (*EstimateConfirmTimeCmd)(nil), flags)
 	dcrjson.MustRegister(Method("estimatefee"), (*EstimateFeeCmd)(nil), flags)
 	dcrjson.MustRegister(Method("estimatesmartfee"), (*EstimateSmartFeeCmd)(nil), flags)
 	dcrjson.MustRegister(Method("estimatestakediff"), (*EstimateStakeDiffCmd)(nil), flags)
@@ -1171,16 +1307,24 @@ func init() {
 	dcrjson.MustRegister(Method("getblockhash"), (*GetBlockHashCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getblockheader"), (*GetBlockHeaderCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getblocksubsidy"), (*GetBlockSubsidyCmd)(nil), flags)
+	dcrjson.MustRegister(Method("getblocktemplate"), (*GetBlockTemplateCmd)(nil), flags)
+	dcrjson.MustRegister(Method("getcfilter"), (*GetCFilterCmd)(nil), flags)
+	dcrjson.MustRegister(Method("getcfilterheader"), (*GetCFilterHeaderCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getcfilterv2"), (*GetCFilterV2Cmd)(nil), flags)
 	dcrjson.MustRegister(Method("getchaintips"), (*GetChainTipsCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getcoinsupply"), (*GetCoinSupplyCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getconnectioncount"), (*GetConnectionCountCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getcurrentnet"), (*GetCurrentNetCmd)(nil), flags)
+	dcrjson.MustRegister(Method("getdaginfo"), (*GetDAGInfoCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getdifficulty"), (*GetDifficultyCmd)(nil), flags)
+	dcrjson.MustRegister(Method("getdiffwindowstats"), (*GetDiffWindowStatsCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getgenerate"), (*GetGenerateCmd)(nil), flags)
 	dcrjson.MustRegister(Method("gethashespersec"), (*GetHashesPerSecCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getheaders"), (*GetHeadersCmd)(nil), flags)
+	dcrjson.MustRegister(Method("gethealth"), (*GetHealthCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getinfo"), (*GetInfoCmd)(nil), flags)
+	dcrjson.MustRegister(Method("getkawpowparams"), (*GetKawPowParamsCmd)(nil), flags)
+	dcrjson.MustRegister(Method("getkawpowseeds"), (*GetKawPowSeedsCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getmempoolinfo"), (*GetMempoolInfoCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getmininginfo"), (*GetMiningInfoCmd)(nil), flags)
 	dcrjson.MustRegister(Method("getmixmessage"), (*GetMixMessageCmd)(nil), flags)
@@ -1207,10 +1351,12 @@ func init() {
 	dcrjson.MustRegister(Method("node"), (*NodeCmd)(nil), flags)
 	dcrjson.MustRegister(Method("ping"), (*PingCmd)(nil), flags)
 	dcrjson.MustRegister(Method("reconsiderblock"), (*ReconsiderBlockCmd)(nil), flags)
+	dcrjson.MustRegister(Method("regeneratedag"), (*RegenerateDAGCmd)(nil), flags)
 	dcrjson.MustRegister(Method("regentemplate"), (*RegenTemplateCmd)(nil), flags)
 	dcrjson.MustRegister(Method("sendrawmixmessage"), (*SendRawMixMessageCmd)(nil), flags)
 	dcrjson.MustRegister(Method("sendrawtransaction"), (*SendRawTransactionCmd)(nil), flags)
 	dcrjson.MustRegister(Method("setgenerate"), (*SetGenerateCmd)(nil), flags)
+	dcrjson.MustRegister(Method("simulatedifficulty"), (*SimulateDifficultyCmd)(nil), flags)
 	dcrjson.MustRegister(Method("startprofiler"), (*StartProfilerCmd)(nil), flags)
 	dcrjson.MustRegister(Method("stop"), (*StopCmd)(nil), flags)
 	dcrjson.MustRegister(Method("stopprofiler"), (*StopProfilerCmd)(nil), flags)
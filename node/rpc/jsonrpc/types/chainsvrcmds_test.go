@@ -94,6 +94,17 @@ func TestChainSvrCmds(t *testing.T) {
 				LevelSpec: "trace",
 			},
 		},
+		{
+			name: "decodeblockheader",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("decodeblockheader"), "123")
+			},
+			staticCmd: func() interface{} {
+				return NewDecodeBlockHeaderCmd("123")
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"decodeblockheader","params":["123"],"id":1}`,
+			unmarshalled: &DecodeBlockHeaderCmd{HexHeader: "123"},
+		},
 		{
 			name: "decoderawtransaction",
 			newCmd: func() (interface{}, error) {
@@ -129,6 +140,19 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"decodescript","params":["00",1],"id":1}`,
 			unmarshalled: &DecodeScriptCmd{HexScript: "00", Version: dcrjson.Uint16(1)},
 		},
+		{
+			name: "estimateconfirmtime",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("estimateconfirmtime"), 6)
+			},
+			staticCmd: func() interface{} {
+				return NewEstimateConfirmTimeCmd(6)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"estimateconfirmtime","params":[6],"id":1}`,
+			unmarshalled: &EstimateConfirmTimeCmd{
+				Confirmations: 6,
+			},
+		},
 		{
 			name: "estimatefee",
 			newCmd: func() (interface{}, error) {
@@ -341,6 +365,43 @@ func TestChainSvrCmds(t *testing.T) {
 				Voters: 256,
 			},
 		},
+		{
+			name: "getblocktemplate",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getblocktemplate"))
+			},
+			staticCmd: func() interface{} {
+				return NewGetBlockTemplateCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getblocktemplate","params":[],"id":1}`,
+			unmarshalled: &GetBlockTemplateCmd{},
+		},
+		{
+			name: "getcfilter",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getcfilter"), "123")
+			},
+			staticCmd: func() interface{} {
+				return NewGetCFilterCmd("123")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getcfilter","params":["123"],"id":1}`,
+			unmarshalled: &GetCFilterCmd{
+				BlockHash: "123",
+			},
+		},
+		{
+			name: "getcfilterheader",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getcfilterheader"), "123")
+			},
+			staticCmd: func() interface{} {
+				return NewGetCFilterHeaderCmd("123")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getcfilterheader","params":["123"],"id":1}`,
+			unmarshalled: &GetCFilterHeaderCmd{
+				BlockHash: "123",
+			},
+		},
 		{
 			name: "getcfilterv2",
 			newCmd: func() (interface{}, error) {
@@ -387,6 +448,17 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getcurrentnet","params":[],"id":1}`,
 			unmarshalled: &GetCurrentNetCmd{},
 		},
+		{
+			name: "getdaginfo",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getdaginfo"))
+			},
+			staticCmd: func() interface{} {
+				return NewGetDAGInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getdaginfo","params":[],"id":1}`,
+			unmarshalled: &GetDAGInfoCmd{},
+		},
 		{
 			name: "getdifficulty",
 			newCmd: func() (interface{}, error) {
@@ -398,6 +470,17 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getdifficulty","params":[],"id":1}`,
 			unmarshalled: &GetDifficultyCmd{},
 		},
+		{
+			name: "getdiffwindowstats",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getdiffwindowstats"))
+			},
+			staticCmd: func() interface{} {
+				return NewGetDiffWindowStatsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getdiffwindowstats","params":[],"id":1}`,
+			unmarshalled: &GetDiffWindowStatsCmd{},
+		},
 		{
 			name: "getgenerate",
 			newCmd: func() (interface{}, error) {
@@ -420,6 +503,17 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"gethashespersec","params":[],"id":1}`,
 			unmarshalled: &GetHashesPerSecCmd{},
 		},
+		{
+			name: "gethealth",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("gethealth"))
+			},
+			staticCmd: func() interface{} {
+				return NewGetHealthCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"gethealth","params":[],"id":1}`,
+			unmarshalled: &GetHealthCmd{},
+		},
 		{
 			name: "getinfo",
 			newCmd: func() (interface{}, error) {
@@ -431,6 +525,31 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getinfo","params":[],"id":1}`,
 			unmarshalled: &GetInfoCmd{},
 		},
+		{
+			name: "getkawpowparams",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getkawpowparams"))
+			},
+			staticCmd: func() interface{} {
+				return NewGetKawPowParamsCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getkawpowparams","params":[],"id":1}`,
+			unmarshalled: &GetKawPowParamsCmd{},
+		},
+		{
+			name: "getkawpowseeds",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("getkawpowseeds"), 2, 5)
+			},
+			staticCmd: func() interface{} {
+				return NewGetKawPowSeedsCmd(2, 5)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getkawpowseeds","params":[2,5],"id":1}`,
+			unmarshalled: &GetKawPowSeedsCmd{
+				StartEpoch: 2,
+				EndEpoch:   5,
+			},
+		},
 		{
 			name: "getmempoolinfo",
 			newCmd: func() (interface{}, error) {
@@ -825,6 +944,17 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"ping","params":[],"id":1}`,
 			unmarshalled: &PingCmd{},
 		},
+		{
+			name: "regeneratedag",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("regeneratedag"))
+			},
+			staticCmd: func() interface{} {
+				return NewRegenerateDAGCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"regeneratedag","params":[],"id":1}`,
+			unmarshalled: &RegenerateDAGCmd{},
+		},
 		{
 			name: "sendrawmixmessage",
 			newCmd: func() (interface{}, error) {
@@ -895,6 +1025,19 @@ func TestChainSvrCmds(t *testing.T) {
 				GenProcLimit: dcrjson.Int(6),
 			},
 		},
+		{
+			name: "simulatedifficulty",
+			newCmd: func() (interface{}, error) {
+				return dcrjson.NewCmd(Method("simulatedifficulty"), []int64{150, 300})
+			},
+			staticCmd: func() interface{} {
+				return NewSimulateDifficultyCmd([]int64{150, 300})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"simulatedifficulty","params":[[150,300]],"id":1}`,
+			unmarshalled: &SimulateDifficultyCmd{
+				Intervals: []int64{150, 300},
+			},
+		},
 		{
 			name: "startprofiler",
 			newCmd: func() (interface{}, error) {
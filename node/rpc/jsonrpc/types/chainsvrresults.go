@@ -17,6 +17,32 @@ type TxRawDecodeResult struct {
 	Vout     []Vout `json:"vout"`
 }
 
+// DecodeBlockHeaderResult models the data returned from the decodeblockheader
+// command.
+type DecodeBlockHeaderResult struct {
+	Hash         string  `json:"hash"`
+	PowHash      string  `json:"powhash"`
+	Version      int32   `json:"version"`
+	PreviousHash string  `json:"previousblockhash"`
+	MerkleRoot   string  `json:"merkleroot"`
+	StakeRoot    string  `json:"stakeroot"`
+	VoteBits     uint16  `json:"votebits"`
+	FinalState   string  `json:"finalstate"`
+	Voters       uint16  `json:"voters"`
+	FreshStake   uint8   `json:"freshstake"`
+	Revocations  uint8   `json:"revocations"`
+	PoolSize     uint32  `json:"poolsize"`
+	Bits         string  `json:"bits"`
+	SBits        float64 `json:"sbits"`
+	Height       uint32  `json:"height"`
+	Size         uint32  `json:"size"`
+	Time         int64   `json:"time"`
+	Nonce        uint64  `json:"nonce"`
+	MixDigest    string  `json:"mixdigest"`
+	ExtraData    string  `json:"extradata"`
+	StakeVersion uint32  `json:"stakeversion"`
+}
+
 // DecodeScriptResult models the data returned from the decodescript command.
 type DecodeScriptResult struct {
 	Asm       string   `json:"asm"`
@@ -26,6 +52,16 @@ type DecodeScriptResult struct {
 	P2sh      string   `json:"p2sh,omitempty"`
 }
 
+// EstimateConfirmTimeResult models the data returned from the
+// estimateconfirmtime command.
+type EstimateConfirmTimeResult struct {
+	Confirmations     int64   `json:"confirmations"`
+	TargetInterval    float64 `json:"targetinterval"`
+	ObservedInterval  float64 `json:"observedinterval"`
+	EstimatedInterval float64 `json:"estimatedinterval"`
+	Seconds           int64   `json:"seconds"`
+}
+
 // EstimateSmartFeeResult models the data returned from the estimatesmartfee
 // command.
 type EstimateSmartFeeResult struct {
@@ -76,6 +112,7 @@ type GetBlockVerboseResult struct {
 	Time          int64         `json:"time"`
 	MedianTime    int64         `json:"mediantime"`
 	Nonce         uint64        `json:"nonce"`
+	MixDigest     string        `json:"mixdigest"`
 	VoteBits      uint16        `json:"votebits"`
 	FinalState    string        `json:"finalstate"`
 	Voters        uint16        `json:"voters"`
@@ -173,6 +210,29 @@ type GetBlockSubsidyResult struct {
 	Total     int64 `json:"total"`
 }
 
+// GetBlockTemplateResultTx models the transactions field of the
+// getblocktemplate command.
+type GetBlockTemplateResultTx struct {
+	Data string `json:"data"`
+	Hash string `json:"hash"`
+	Fee  int64  `json:"fee"`
+}
+
+// GetBlockTemplateResult models the data returned from the getblocktemplate
+// command.
+type GetBlockTemplateResult struct {
+	Version           int32                      `json:"version"`
+	PreviousBlockHash string                     `json:"previousblockhash"`
+	Transactions      []GetBlockTemplateResultTx `json:"transactions"`
+	STransactions     []GetBlockTemplateResultTx `json:"stransactions"`
+	CoinbaseValue     int64                      `json:"coinbasevalue"`
+	Bits              string                     `json:"bits"`
+	Height            int64                      `json:"height"`
+	CurTime           int64                      `json:"curtime"`
+	SeedHash          string                     `json:"seedhash"`
+	Target            string                     `json:"target"`
+}
+
 // GetChainTipsResult models the data returns from the getchaintips command.
 type GetChainTipsResult struct {
 	Height    int64  `json:"height"`
@@ -181,6 +241,20 @@ type GetChainTipsResult struct {
 	Status    string `json:"status"`
 }
 
+// GetCFilterResult models the data returned from the getcfilter command.
+type GetCFilterResult struct {
+	BlockHash string `json:"blockhash"`
+	Data      string `json:"data"`
+}
+
+// GetCFilterHeaderResult models the data returned from the getcfilterheader
+// command.
+type GetCFilterHeaderResult struct {
+	BlockHash      string `json:"blockhash"`
+	FilterHash     string `json:"filterhash"`
+	CommitmentRoot string `json:"commitmentroot"`
+}
+
 // GetCFilterV2Result models the data returned from the getcfilterv2 command.
 type GetCFilterV2Result struct {
 	BlockHash   string   `json:"blockhash"`
@@ -189,12 +263,71 @@ type GetCFilterV2Result struct {
 	ProofHashes []string `json:"proofhashes"`
 }
 
+// GetDAGInfoResult models the data returned from the getdaginfo command.
+type GetDAGInfoResult struct {
+	Epoch           int64   `json:"epoch"`
+	NextEpochHeight int64   `json:"nextepochheight"`
+	BlocksRemaining int64   `json:"blocksremaining"`
+	EstimatedTime   int64   `json:"estimatedtime"`
+	EpochBoundaries []int64 `json:"epochboundaries"`
+	RegenJobID      uint64  `json:"regenjobid,omitempty"`
+	RegenEpoch      int64   `json:"regenepoch,omitempty"`
+	RegenStatus     string  `json:"regenstatus,omitempty"`
+	RegenError      string  `json:"regenerror,omitempty"`
+}
+
+// GetDifficultyResult models the data returned from the getdifficulty command.
+type GetDifficultyResult struct {
+	Difficulty      float64 `json:"difficulty"`
+	StakeDifficulty float64 `json:"stakedifficulty"`
+}
+
+// GetDiffWindowStatsResult models the data returned from the getdiffwindowstats
+// command.
+type GetDiffWindowStatsResult struct {
+	WindowSize       int64  `json:"windowsize"`
+	BlocksIntoWindow int64  `json:"blocksintowindow"`
+	ElapsedTime      int64  `json:"elapsedtime"`
+	ExpectedTime     int64  `json:"expectedtime"`
+	Direction        string `json:"direction"`
+}
+
 // GetHeadersResult models the data returned by the chain server getheaders
 // command.
 type GetHeadersResult struct {
 	Headers []string `json:"headers"`
 }
 
+// GetHealthResult models the data returned from the gethealth command.
+type GetHealthResult struct {
+	Healthy                  bool `json:"healthy"`
+	ChainCurrent             bool `json:"chaincurrent"`
+	DAGReady                 bool `json:"dagready"`
+	RecentBlock              bool `json:"recentblock"`
+	NoRecentValidationErrors bool `json:"norecentvalidationerrors"`
+}
+
+// GetKawPowParamsResult models the data returned from the getkawpowparams
+// command.
+type GetKawPowParamsResult struct {
+	EpochLength         int64  `json:"epochlength"`
+	DatasetInitBytes    uint64 `json:"datasetinitbytes"`
+	DatasetGrowthBytes  uint64 `json:"datasetgrowthbytes"`
+	CacheInitBytes      uint64 `json:"cacheinitbytes"`
+	CacheGrowthBytes    uint64 `json:"cachegrowthbytes"`
+	CacheRounds         int    `json:"cacherounds"`
+	MinDatasetInitBytes uint64 `json:"mindatasetinitbytes"`
+	ASICResistant       bool   `json:"asicresistant"`
+}
+
+// GetKawPowSeedsResult models the data returned for each epoch from the
+// getkawpowseeds command.
+type GetKawPowSeedsResult struct {
+	Epoch       int64  `json:"epoch"`
+	SeedHash    string `json:"seedhash"`
+	StartHeight int64  `json:"startheight"`
+}
+
 // InfoChainResult models the data returned by the chain server getinfo command.
 type InfoChainResult struct {
 	Version         int32   `json:"version"`
@@ -319,19 +452,20 @@ type GetRawMempoolVerboseResult struct {
 
 // TxRawResult models the data from the getrawtransaction command.
 type TxRawResult struct {
-	Hex           string `json:"hex"`
-	Txid          string `json:"txid"`
-	Version       int32  `json:"version"`
-	LockTime      uint32 `json:"locktime"`
-	Expiry        uint32 `json:"expiry"`
-	Vin           []Vin  `json:"vin"`
-	Vout          []Vout `json:"vout"`
-	BlockHash     string `json:"blockhash,omitempty"`
-	BlockHeight   int64  `json:"blockheight,omitempty"`
-	BlockIndex    uint32 `json:"blockindex,omitempty"`
-	Confirmations int64  `json:"confirmations,omitempty"`
-	Time          int64  `json:"time,omitempty"`
-	Blocktime     int64  `json:"blocktime,omitempty"`
+	Hex            string `json:"hex"`
+	Txid           string `json:"txid"`
+	Version        int32  `json:"version"`
+	LockTime       uint32 `json:"locktime"`
+	Expiry         uint32 `json:"expiry"`
+	Vin            []Vin  `json:"vin"`
+	Vout           []Vout `json:"vout"`
+	BlockHash      string `json:"blockhash,omitempty"`
+	BlockHeight    int64  `json:"blockheight,omitempty"`
+	BlockIndex     uint32 `json:"blockindex,omitempty"`
+	Confirmations  int64  `json:"confirmations,omitempty"`
+	MaturityHeight int64  `json:"maturityheight,omitempty"`
+	Time           int64  `json:"time,omitempty"`
+	Blocktime      int64  `json:"blocktime,omitempty"`
 }
 
 // GetStakeDifficultyResult models the data returned from the
@@ -471,6 +605,7 @@ type GetTreasurySpendVotesResult struct {
 type GetWorkResult struct {
 	Data   string `json:"data"`
 	Target string `json:"target"`
+	Algo   string `json:"algo"`
 }
 
 // Ticket is the structure representing a ticket.
@@ -485,6 +620,21 @@ type LiveTicketsResult struct {
 	Tickets []string `json:"tickets"`
 }
 
+// RegenerateDAGResult models the data returned from the regeneratedag command.
+type RegenerateDAGResult struct {
+	JobID uint64 `json:"jobid"`
+	Epoch int64  `json:"epoch"`
+}
+
+// SimulateDifficultyResult models the data returned for each hypothetical block
+// from the simulatedifficulty command.
+type SimulateDifficultyResult struct {
+	Height     int64   `json:"height"`
+	Time       int64   `json:"time"`
+	Bits       string  `json:"bits"`
+	Difficulty float64 `json:"difficulty"`
+}
+
 // StartProfilerResult models the data returned from the startprofiler command.
 type StartProfilerResult struct {
 	Listeners []string `json:"listeners"`
@@ -200,20 +200,21 @@ func (c *Client) GetBlockCount(ctx context.Context) (int64, error) {
 type FutureGetDifficultyResult cmdRes
 
 // Receive waits for the response promised by the future and returns the
-// proof-of-work difficulty as a multiple of the minimum difficulty.
-func (r *FutureGetDifficultyResult) Receive() (float64, error) {
+// proof-of-work and stake difficulties as multiples of their respective minimum
+// difficulties.
+func (r *FutureGetDifficultyResult) Receive() (*chainjson.GetDifficultyResult, error) {
 	res, err := receiveFuture(r.ctx, r.c)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	// Unmarshal the result as a float64.
-	var difficulty float64
+	// Unmarshal the result as a getdifficulty result object.
+	var difficulty chainjson.GetDifficultyResult
 	err = json.Unmarshal(res, &difficulty)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	return difficulty, nil
+	return &difficulty, nil
 }
 
 // GetDifficultyAsync returns an instance of a type that can be used to get the
@@ -226,9 +227,9 @@ func (c *Client) GetDifficultyAsync(ctx context.Context) *FutureGetDifficultyRes
 	return (*FutureGetDifficultyResult)(c.sendCmd(ctx, cmd))
 }
 
-// GetDifficulty returns the proof-of-work difficulty as a multiple of the
-// minimum difficulty.
-func (c *Client) GetDifficulty(ctx context.Context) (float64, error) {
+// GetDifficulty returns the proof-of-work and stake difficulties as multiples
+// of their respective minimum difficulties.
+func (c *Client) GetDifficulty(ctx context.Context) (*chainjson.GetDifficultyResult, error) {
 	return c.GetDifficultyAsync(ctx).Receive()
 }
 
@@ -26,7 +26,6 @@ import (
 
 	"github.com/decred/dcrd/addrmgr/v3"
 	"github.com/decred/dcrd/blockchain/stake/v5"
-	"github.com/decred/dcrd/blockchain/standalone/v2"
 	"github.com/decred/dcrd/certgen"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/chaincfg/v3"
@@ -52,6 +51,7 @@ import (
 	"github.com/decred/dcrd/txscript/v4"
 	"github.com/decred/dcrd/wire"
 	"github.com/syndtr/goleveldb/leveldb"
+	"vigil.network/node/blockchain/standalone"
 )
 
 const (
@@ -3934,16 +3934,21 @@ func newServer(ctx context.Context, profiler *profileServer,
 	})
 	s.chain, err = blockchain.New(ctx,
 		&blockchain.Config{
-			DB:              s.db,
-			UtxoBackend:     utxoBackend,
-			ChainParams:     s.chainParams,
-			AssumeValid:     assumeValid,
-			TimeSource:      s.timeSource,
-			Notifications:   s.handleBlockchainNotification,
-			SigCache:        s.sigCache,
-			SubsidyCache:    s.subsidyCache,
-			IndexSubscriber: s.indexSubscriber,
-			UtxoCache:       utxoCache,
+			DB:                      s.db,
+			UtxoBackend:             utxoBackend,
+			ChainParams:             s.chainParams,
+			AssumeValid:             assumeValid,
+			TimeSource:              s.timeSource,
+			Notifications:           s.handleBlockchainNotification,
+			SigCache:                s.sigCache,
+			SubsidyCache:            s.subsidyCache,
+			IndexSubscriber:         s.indexSubscriber,
+			UtxoCache:               utxoCache,
+			FullVerifyDAG:           cfg.FullVerifyDAG,
+			DAGDir:                  cfg.DAGDir,
+			MaxDAGDiskBytes:         cfg.MaxDAGDiskBytes,
+			TrimBlockIndex:          cfg.TrimBlockIndex,
+			MaxKawPowEpochLookahead: cfg.MaxEpochLookahead,
 		})
 	if err != nil {
 		return nil, err
@@ -4084,6 +4089,7 @@ func newServer(ctx context.Context, profiler *profileServer,
 			BlockMaxSize:     cfg.BlockMaxSize,
 			TxMinFreeFee:     cfg.minRelayTxFee,
 			AggressiveMining: !cfg.NonAggressive,
+			CoinbaseExtra:    []byte(cfg.CoinbaseExtra),
 			StandardVerifyFlags: func() (txscript.ScriptFlags, error) {
 				return standardScriptVerifyFlags(s.chain)
 			},
@@ -4147,6 +4153,7 @@ func newServer(ctx context.Context, profiler *profileServer,
 
 		s.cpuMiner = cpuminer.New(&cpuminer.Config{
 			ChainParams:                s.chainParams,
+			KawPowParams:               s.chain.KawPowParams(),
 			PermitConnectionlessMining: cfg.SimNet || cfg.RegNet,
 			BgBlkTmplGenerator:         s.bg,
 			ProcessBlock:               s.syncManager.ProcessBlock,
@@ -4278,6 +4285,7 @@ func newServer(ctx context.Context, profiler *profileServer,
 			DB:                   db,
 			TxMempooler:          s.txMemPool,
 			CPUMiner:             &rpcCPUMiner{s.cpuMiner},
+			DAGManager:           s.chain.DAGManager(),
 			NetInfo:              cfg.generateNetworkInfo(),
 			MinRelayTxFee:        cfg.minRelayTxFee,
 			Proxy:                cfg.Proxy,
@@ -4285,6 +4293,8 @@ func newServer(ctx context.Context, profiler *profileServer,
 			RPCPass:              cfg.RPCPass,
 			RPCLimitUser:         cfg.RPCLimitUser,
 			RPCLimitPass:         cfg.RPCLimitPass,
+			RPCMiningUser:        cfg.RPCMiningUser,
+			RPCMiningPass:        cfg.RPCMiningPass,
 			RPCMaxClients:        cfg.RPCMaxClients,
 			RPCMaxConcurrentReqs: cfg.RPCMaxConcurrentReqs,
 			RPCMaxWebsockets:     cfg.RPCMaxWebsockets,
@@ -9,10 +9,11 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"strconv"
 	"time"
 
 	"github.com/decred/dcrd/chaincfg/chainhash"
-	"github.com/decred/dcrd/internal/kawpow"
+	"vigil.network/node/blockchain/standalone/kawpow"
 )
 
 // MaxBlockHeaderPayload is the maximum number of bytes a block header can be.
@@ -21,8 +22,21 @@ import (
 // byte + Revocations 1 bytes + PoolSize 4 bytes + Bits 4 bytes + SBits 8 bytes
 // + Height 4 bytes + Size 4 bytes + Timestamp 4 bytes + Nonce 8 bytes +
 // MixDigest 32 bytes + ExtraData 32 bytes + StakeVersion 4 bytes.
-// --> Total 212 bytes.
-const MaxBlockHeaderPayload = 84 + (chainhash.HashSize * 4) + 4
+// --> Total 216 bytes.
+const MaxBlockHeaderPayload = 4 + (chainhash.HashSize * 3) + 2 + 6 + 2 + 1 +
+	1 + 4 + 4 + 8 + 4 + 4 + 4 + 8 + 32 + 32 + 4
+
+// sharedKawPow is the KawPoW hasher used to calculate the version 2 proof of
+// work hashes of block headers when a hasher is not provided.  It is shared so
+// the verification cache for the epoch of the headers being hashed is reused
+// across calls as opposed to generated for every hash.  It only makes use of
+// the verification cache since the full dataset is multiple gigabytes, and the
+// hashes are the same either way.
+//
+// The wire protocol is independent of the network, so it uses the default
+// KawPoW parameters.  Callers that hash headers for a specific network must
+// use PowHashV2WithHasher with a hasher for the parameters of that network.
+var sharedKawPow = kawpow.NewLight()
 
 // BlockHeader defines information about a block and is used in the decred
 // block (MsgBlock) and headers (MsgHeaders) messages.
@@ -87,8 +101,8 @@ type BlockHeader struct {
 }
 
 // blockHeaderLen is a constant that represents the number of bytes for a block
-// header.
-const blockHeaderLen = 180
+// header.  Block headers are fixed size, so it is the same as the maximum.
+const blockHeaderLen = MaxBlockHeaderPayload
 
 // BlockHash computes the block identifier hash for the given block header.
 func (h *BlockHeader) BlockHash() chainhash.Hash {
@@ -112,27 +126,50 @@ func (h *BlockHeader) PowHashV1() chainhash.Hash {
 
 // PowHashV2 calculates and returns the version 2 proof of work hash as defined
 // in DCP0011 for the block header.
-func (h *BlockHeader) PowHashV2() chainhash.Hash {
-	// KawPoW requires the full header bytes to calculate the hash.
-	// The `Bytes()` method returns the serialized header including nonce and mix digest.
-	headerBytes, err := h.Bytes()
-	if err != nil {
-		// This should ideally not happen if MaxBlockHeaderPayload is correct and
-		// serialization doesn't fail due to out-of-memory.
-		panic(fmt.Sprintf("Failed to serialize block header for KawPoW hash: %v", err))
-	}
+//
+// The hash is calculated with a KawPoW hasher that is shared by all callers and
+// only makes use of the verification cache for the epoch of the header, so
+// repeated calls for headers in the same epoch do not regenerate it and no
+// call requires the multi-gigabyte full dataset.
+//
+// The shared hasher uses the default KawPoW parameters, so the hash is only
+// correct for networks that use them.  Use PowHashV2WithHasher to calculate the
+// hash with a hasher for the KawPoW parameters of a specific network or one
+// that makes use of the full dataset for mining.
+//
+// An error is returned when the hash can't be calculated, such as when the
+// header is malformed.  Since headers are received from the network, callers
+// must treat such headers as invalid as opposed to assuming the hash is always
+// calculable.
+func (h *BlockHeader) PowHashV2() (chainhash.Hash, error) {
+	return h.powHashV2(sharedKawPow)
+}
 
-	// Create a new KawPoW hasher and compute the hash.
-	kp := kawpow.New()
-	finalHash, _, err := kp.Hash(headerBytes[:len(headerBytes)-32], h.Nonce) // Pass headerBytes excluding MixDigest
+// PowHashV2WithHasher calculates and returns the version 2 proof of work hash
+// for the block header using the provided KawPoW hasher.
+//
+// This allows callers to calculate the hash with KawPoW parameters that differ
+// from the defaults, such as those defined for a specific network.  See
+// PowHashV2 for details regarding the returned error.
+func (h *BlockHeader) PowHashV2WithHasher(kp *kawpow.KawPow) (chainhash.Hash, error) {
+	return h.powHashV2(kp)
+}
+
+// powHashV2 calculates and returns the version 2 proof of work hash for the
+// block header using the provided KawPoW hasher.
+func (h *BlockHeader) powHashV2(kp *kawpow.KawPow) (chainhash.Hash, error) {
+	var hash chainhash.Hash
+
+	// The header hash that seeds KawPoW must not depend on the nonce and mix
+	// digest since they are the values being solved for, so calculate it
+	// with both of them zeroed.
+	_, finalHash, err := kp.Hash(h.BytesNoNonce(), h.Nonce)
 	if err != nil {
-		// This should ideally not happen if the KawPoW implementation is solid.
-		panic(fmt.Sprintf("Failed to compute KawPoW hash: %v", err))
+		return hash, fmt.Errorf("failed to compute KawPoW hash: %w", err)
 	}
 
-	var hash chainhash.Hash
 	copy(hash[:], finalHash)
-	return hash
+	return hash, nil
 }
 
 // BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
@@ -188,14 +225,126 @@ func (h *BlockHeader) Bytes() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-// BytesNoNonce returns the serialized bytes of the block header without the nonce.
-// This is used by the KawPoW algorithm.
+// BytesNoNonce returns the serialized bytes of the block header with the nonce
+// and mix digest zeroed.  These are the bytes the KawPoW proof of work hash is
+// calculated from since it must not depend on the values being solved for.
 func (h *BlockHeader) BytesNoNonce() []byte {
 	buf := bytes.NewBuffer(make([]byte, 0, MaxBlockHeaderPayload))
 	_ = writeBlockHeaderNoNonce(buf, 0, h)
 	return buf.Bytes()
 }
 
+// blockHeaderStringLen is the maximum length of the string representation of a
+// block header.  It is used to allocate the buffer for it up front.
+const blockHeaderStringLen = 320
+
+// hexDigits are the digits used to encode the hex fields of the string
+// representation of a block header.
+const hexDigits = "0123456789abcdef"
+
+// appendHex appends the hex encoding of the provided bytes to the provided
+// buffer in reverse order when requested.
+func appendHex(buf []byte, b []byte, reverse bool) []byte {
+	for i := range b {
+		v := b[i]
+		if reverse {
+			v = b[len(b)-1-i]
+		}
+		buf = append(buf, hexDigits[v>>4], hexDigits[v&0x0f])
+	}
+	return buf
+}
+
+// appendHexUint appends the hex encoding of the provided value zero padded to
+// the provided number of bytes to the provided buffer.
+func appendHexUint(buf []byte, v uint64, numBytes int) []byte {
+	for shift := numBytes*8 - 4; shift >= 0; shift -= 4 {
+		buf = append(buf, hexDigits[(v>>uint(shift))&0x0f])
+	}
+	return buf
+}
+
+// String returns a compact human-readable representation of the block header
+// that is intended for debugging and logging.  It consists of the version,
+// height, previous block hash, merkle root, bits, stake difficulty, timestamp
+// in seconds since the Unix epoch, nonce, and the first 8 bytes of the mix
+// digest in a stable format.  For example:
+//
+//	version=1 height=100 prev=<hash> merkle=<hash> bits=1d00ffff
+//	sbits=200000000 time=1700000000 nonce=fedcba9876543210
+//	mix=0123456789abcdef
+//
+// The representation is on a single line.  It is only broken up above for
+// readability.
+func (h *BlockHeader) String() string {
+	if h == nil {
+		return "<nil>"
+	}
+
+	buf := make([]byte, 0, blockHeaderStringLen)
+	buf = append(buf, "version="...)
+	buf = strconv.AppendInt(buf, int64(h.Version), 10)
+	buf = append(buf, " height="...)
+	buf = strconv.AppendUint(buf, uint64(h.Height), 10)
+	buf = append(buf, " prev="...)
+	buf = appendHex(buf, h.PrevBlock[:], true)
+	buf = append(buf, " merkle="...)
+	buf = appendHex(buf, h.MerkleRoot[:], true)
+	buf = append(buf, " bits="...)
+	buf = appendHexUint(buf, uint64(h.Bits), 4)
+	buf = append(buf, " sbits="...)
+	buf = strconv.AppendInt(buf, h.SBits, 10)
+	buf = append(buf, " time="...)
+	buf = strconv.AppendInt(buf, h.Timestamp.Unix(), 10)
+	buf = append(buf, " nonce="...)
+	buf = appendHexUint(buf, h.Nonce, 8)
+	buf = append(buf, " mix="...)
+	buf = appendHex(buf, h.MixDigest[:8], false)
+	return string(buf)
+}
+
+// DiffFields returns the names of all fields of the block header that differ
+// from the provided block header in the order they are declared.  The
+// timestamps are compared with one second precision since the protocol doesn't
+// support better.
+//
+// This is primarily useful for debugging headers that are rejected due to not
+// matching an expected header such as one from a block template.
+func (h *BlockHeader) DiffFields(other *BlockHeader) []string {
+	var diffs []string
+	addIf := func(differs bool, name string) {
+		if differs {
+			diffs = append(diffs, name)
+		}
+	}
+	addIf(h.Version != other.Version, "Version")
+	addIf(h.PrevBlock != other.PrevBlock, "PrevBlock")
+	addIf(h.MerkleRoot != other.MerkleRoot, "MerkleRoot")
+	addIf(h.StakeRoot != other.StakeRoot, "StakeRoot")
+	addIf(h.VoteBits != other.VoteBits, "VoteBits")
+	addIf(h.FinalState != other.FinalState, "FinalState")
+	addIf(h.Voters != other.Voters, "Voters")
+	addIf(h.FreshStake != other.FreshStake, "FreshStake")
+	addIf(h.Revocations != other.Revocations, "Revocations")
+	addIf(h.PoolSize != other.PoolSize, "PoolSize")
+	addIf(h.Bits != other.Bits, "Bits")
+	addIf(h.SBits != other.SBits, "SBits")
+	addIf(h.Height != other.Height, "Height")
+	addIf(h.Size != other.Size, "Size")
+	addIf(h.Timestamp.Unix() != other.Timestamp.Unix(), "Timestamp")
+	addIf(h.Nonce != other.Nonce, "Nonce")
+	addIf(h.MixDigest != other.MixDigest, "MixDigest")
+	addIf(h.ExtraData != other.ExtraData, "ExtraData")
+	addIf(h.StakeVersion != other.StakeVersion, "StakeVersion")
+	return diffs
+}
+
+// Equals returns whether the block header is identical to the provided block
+// header.  See DiffFields for details on how the fields are compared.
+func (h *BlockHeader) Equals(other *BlockHeader) bool {
+	return len(h.DiffFields(other)) == 0
+}
+
 // NewBlockHeader returns a new BlockHeader using the provided previous block
 // hash, merkle root hash, difficulty bits, and nonce used to generate the
 // block with defaults for the remaining fields.
@@ -253,14 +402,14 @@ func writeBlockHeader(w io.Writer, pver uint32, bh *BlockHeader) error {
 		bh.StakeVersion)
 }
 
-// writeBlockHeaderNoNonce writes a Decred block header to w without the nonce.
-// See Serialize for encoding block headers to be stored to disk, such as in a
-// database, as opposed to encoding for the wire.
+// writeBlockHeaderNoNonce writes a Decred block header to w with the nonce and
+// mix digest zeroed.  See Serialize for encoding block headers to be stored to
+// disk, such as in a database, as opposed to encoding for the wire.
 func writeBlockHeaderNoNonce(w io.Writer, pver uint32, bh *BlockHeader) error {
 	sec := uint32(bh.Timestamp.Unix())
 	return writeElements(w, bh.Version, &bh.PrevBlock, &bh.MerkleRoot,
 		&bh.StakeRoot, bh.VoteBits, bh.FinalState, bh.Voters,
 		bh.FreshStake, bh.Revocations, bh.PoolSize, bh.Bits, bh.SBits,
-		bh.Height, bh.Size, sec, 0, bh.MixDigest, bh.ExtraData,
+		bh.Height, bh.Size, sec, uint64(0), [32]byte{}, bh.ExtraData,
 		bh.StakeVersion)
 }
@@ -8,12 +8,14 @@ package wire
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/decred/dcrd/chaincfg/chainhash"
+	"vigil.network/node/blockchain/standalone/kawpow"
 )
 
 // TestBlockHeader tests the BlockHeader API.
@@ -406,13 +408,14 @@ func TestBlockHeaderSerialize(t *testing.T) {
 
 func TestBlockHeaderHashing(t *testing.T) {
 	dummyHeader := "0000000049e0b48ade043f729d60095ed92642d96096fe6aba42f2eda" +
-		"632d461591a152267dc840ff27602ce1968a81eb30a43423517207617a0150b56c4f72" +
-		"b803e497f00000000000000000000000000000000000000000000000000000000000000" +
-		"00010000000000000000000000b7000000ffff7f20204e0000000000005800000060010" +
-		"0008b990956000000000000000000000000000000000000000000000000000000000000" +
+		"632d461591a152267dc840ff27602ce1968a81eb30a43423517207617a0150b56c4f72b" +
+		"803e497f000000000000000000000000000000000000000000000000000000000000000" +
+		"0010000000000000000000000b7000000ffff7f20204e00000000000058000000600100" +
+		"008b9909560000000000000000000000000000000000000000000000000000000000000" +
+		"00000000000000000000000000000000000000000000000000000000000000000000000" +
 		"0000000000000000ABCD"
 	// This hash has reversed endianness compared to what chainhash spits out.
-	hashStr := "0d40d58703482d81d711be0ffc1b313788d3c3937e1617e4876661d33a8c4c41"
+	hashStr := "f3408344dc6d463986acd3ffa4b375d2ad9adb89f3a2e8af2b521fb3faad3721"
 	hashB, _ := hex.DecodeString(hashStr)
 	hash, _ := chainhash.NewHash(hashB)
 
@@ -427,3 +430,519 @@ func TestBlockHeaderHashing(t *testing.T) {
 			hash2)
 	}
 }
+
+// TestBlockHeaderDiffFields ensures the block header comparison functions
+// detect exactly the fields that differ between two headers.
+func TestBlockHeaderDiffFields(t *testing.T) {
+	header := BlockHeader{
+		Version:      1,
+		PrevBlock:    mainNetGenesisHash,
+		MerkleRoot:   mainNetGenesisMerkleRoot,
+		Bits:         0x1d00ffff,
+		Height:       1,
+		Timestamp:    time.Unix(0x61c402e0, 0),
+		Nonce:        0x0123456789abcdef,
+		StakeVersion: 1,
+	}
+
+	// Ensure identical headers do not report any differences and compare as
+	// equal.
+	same := header
+	if diffs := header.DiffFields(&same); len(diffs) != 0 {
+		t.Fatalf("unexpected differences for identical headers: %v", diffs)
+	}
+	if !header.Equals(&same) {
+		t.Fatal("identical headers do not compare as equal")
+	}
+
+	// Ensure sub-second timestamp differences are ignored since they are not
+	// representable in the serialized header.
+	subSecond := header
+	subSecond.Timestamp = header.Timestamp.Add(time.Millisecond)
+	if !header.Equals(&subSecond) {
+		t.Fatal("headers with sub-second timestamp difference do not " +
+			"compare as equal")
+	}
+
+	// Ensure a changed nonce and merkle root are pinpointed.
+	changed := header
+	changed.Nonce++
+	changed.MerkleRoot = chainhash.Hash{0x01}
+	wantDiffs := []string{"MerkleRoot", "Nonce"}
+	diffs := header.DiffFields(&changed)
+	if !reflect.DeepEqual(diffs, wantDiffs) {
+		t.Fatalf("unexpected differences -- got %v, want %v", diffs, wantDiffs)
+	}
+	if header.Equals(&changed) {
+		t.Fatal("differing headers compare as equal")
+	}
+
+	// Ensure a changed KawPoW mix digest is pinpointed.
+	changed = header
+	changed.MixDigest[0] = 0x01
+	wantDiffs = []string{"MixDigest"}
+	diffs = header.DiffFields(&changed)
+	if !reflect.DeepEqual(diffs, wantDiffs) {
+		t.Fatalf("unexpected differences -- got %v, want %v", diffs, wantDiffs)
+	}
+}
+
+// TestBlockHeaderLen ensures a fully-populated block header serializes to the
+// number of bytes specified by the block header length constants.
+func TestBlockHeaderLen(t *testing.T) {
+	header := BlockHeader{
+		Version:      1,
+		PrevBlock:    mainNetGenesisHash,
+		MerkleRoot:   mainNetGenesisMerkleRoot,
+		StakeRoot:    mainNetGenesisMerkleRoot,
+		VoteBits:     0xffff,
+		FinalState:   [6]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06},
+		Voters:       0xffff,
+		FreshStake:   0xff,
+		Revocations:  0xff,
+		PoolSize:     0xffffffff,
+		Bits:         0x1d00ffff,
+		SBits:        0x7fffffffffffffff,
+		Height:       0xffffffff,
+		Size:         0xffffffff,
+		Timestamp:    time.Unix(0xffffffff, 0),
+		Nonce:        0xffffffffffffffff,
+		MixDigest:    [32]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef},
+		ExtraData:    [32]byte{0xfe, 0xdc, 0xba, 0x98, 0x76, 0x54, 0x32, 0x10},
+		StakeVersion: 0xffffffff,
+	}
+	serialized, err := header.Bytes()
+	if err != nil {
+		t.Fatalf("unable to serialize header: %v", err)
+	}
+	if len(serialized) != blockHeaderLen {
+		t.Fatalf("mismatched serialized length -- got %d, want %d",
+			len(serialized), blockHeaderLen)
+	}
+	if blockHeaderLen != MaxBlockHeaderPayload {
+		t.Fatalf("mismatched header length %d and max payload %d",
+			blockHeaderLen, MaxBlockHeaderPayload)
+	}
+}
+
+// TestBlockHeaderString ensures the string representation of block headers,
+// including the zero header and a nil header, is in the expected format and
+// does not exceed the length its buffer is allocated with.
+func TestBlockHeaderString(t *testing.T) {
+	header := BlockHeader{
+		Version:    1,
+		PrevBlock:  mainNetGenesisHash,
+		MerkleRoot: mainNetGenesisMerkleRoot,
+		Bits:       0x1d00ffff,
+		SBits:      200000000,
+		Height:     100,
+		Timestamp:  time.Unix(1700000000, 0),
+		Nonce:      0x0123456789abcdef,
+		MixDigest: [32]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
+			0xff},
+	}
+	maxHeader := BlockHeader{
+		Version:   -1 << 31,
+		Height:    0xffffffff,
+		Bits:      0xffffffff,
+		SBits:     -1 << 63,
+		Timestamp: time.Unix(-1<<62, 0),
+		Nonce:     0xffffffffffffffff,
+	}
+
+	tests := []struct {
+		name   string       // test description
+		header *BlockHeader // header to represent
+		want   string       // expected string
+	}{{
+		name:   "populated header",
+		header: &header,
+		want: "version=1 height=100 prev=" + mainNetGenesisHash.String() +
+			" merkle=" + mainNetGenesisMerkleRoot.String() +
+			" bits=1d00ffff sbits=200000000 time=1700000000 " +
+			"nonce=0123456789abcdef mix=0123456789abcdef",
+	}, {
+		name:   "zero header",
+		header: &BlockHeader{},
+		want: "version=0 height=0 prev=" + chainhash.Hash{}.String() +
+			" merkle=" + chainhash.Hash{}.String() + " bits=00000000 " +
+			"sbits=0 time=-62135596800 nonce=0000000000000000 " +
+			"mix=0000000000000000",
+	}, {
+		name:   "nil header",
+		header: nil,
+		want:   "<nil>",
+	}}
+	for _, test := range tests {
+		if got := test.header.String(); got != test.want {
+			t.Errorf("%q: mismatched string -- got %q, want %q", test.name,
+				got, test.want)
+		}
+	}
+
+	// Ensure the longest possible representation fits in the buffer it is
+	// allocated with, so it only requires the buffer and the string.
+	if got := len(maxHeader.String()); got > blockHeaderStringLen {
+		t.Fatalf("max header string is %d bytes, but at most %d bytes are "+
+			"allocated", got, blockHeaderStringLen)
+	}
+	allocs := testing.AllocsPerRun(10, func() { _ = maxHeader.String() })
+	if allocs > 2 {
+		t.Fatalf("unexpected number of allocations %v", allocs)
+	}
+}
+
+// TestPowHashV2Vectors ensures the full KawPoW proof of work pipeline produces
+// the expected proof of work hash and mix digest for a fixed header and nonce.
+// These vectors are the canonical guard against accidental changes to the
+// proof of work and must never be updated without a corresponding consensus
+// change.
+func TestPowHashV2Vectors(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   BlockHeader
+		wantHash string // expected proof of work hash
+		wantMix  string // expected mix digest
+	}{{
+		name: "epoch 0 header",
+		header: BlockHeader{
+			Version:      1,
+			PrevBlock:    mainNetGenesisHash,
+			MerkleRoot:   mainNetGenesisMerkleRoot,
+			StakeRoot:    mainNetGenesisMerkleRoot,
+			Bits:         0x1d00ffff,
+			Height:       1,
+			Timestamp:    time.Unix(0x61c402e0, 0),
+			Nonce:        0x0123456789abcdef,
+			StakeVersion: 0,
+		},
+		wantHash: "b63b44d9dc2a77f1e71a3d0248f10db59ba4a72576a55933e4a5dae14ff88fa7",
+		wantMix:  "e8a3c75d14eeebc9d7d402698d99fae2a02b6c0f21218203cf1b7caa3fbf03c2",
+	}, {
+		name: "epoch 1 header",
+		header: BlockHeader{
+			Version:      1,
+			PrevBlock:    mainNetGenesisHash,
+			MerkleRoot:   mainNetGenesisMerkleRoot,
+			StakeRoot:    mainNetGenesisMerkleRoot,
+			Bits:         0x1d00ffff,
+			Height:       kawpow.KawPowEpochLength + 1,
+			Timestamp:    time.Unix(0x61c402e0, 0),
+			Nonce:        0xfedcba9876543210,
+			StakeVersion: 0,
+		},
+		wantHash: "4ba2496a95d9242abcf80a2995e6eb015eca0b0efa29d99200876fba53224360",
+		wantMix:  "923a673402fc4e72950f7e493e0bb4d4d242ba64d340b70bd30911c2f505f659",
+	}}
+
+	for _, test := range tests {
+		hash, err := test.header.PowHashV2()
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.name, err)
+			continue
+		}
+		if hash.String() != test.wantHash {
+			t.Errorf("%q: mismatched hash -- got %v, want %v", test.name,
+				hash, test.wantHash)
+		}
+
+		mixDigest, err := kawpow.NewLight().ComputeMixDigest(
+			test.header.BytesNoNonce(), test.header.Nonce)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.name, err)
+			continue
+		}
+		if got := hex.EncodeToString(mixDigest[:]); got != test.wantMix {
+			t.Errorf("%q: mismatched mix digest -- got %v, want %v",
+				test.name, got, test.wantMix)
+		}
+	}
+}
+
+// TestPowHashV2Error ensures errors from the KawPoW hasher are returned from
+// the version 2 proof of work hash functions as opposed to panicking.
+func TestPowHashV2Error(t *testing.T) {
+	// Use parameters with a dataset that is too small to hold a single
+	// dataset entry so hashing fails.
+	kp := kawpow.NewLightWithParams(kawpow.Params{
+		EpochLength:      10,
+		DatasetInitBytes: 128,
+		CacheInitBytes:   1024,
+		CacheRounds:      3,
+	})
+	header := BlockHeader{
+		Version:   1,
+		PrevBlock: mainNetGenesisHash,
+		Bits:      0x1d00ffff,
+		Height:    1,
+		Timestamp: time.Unix(0x61c402e0, 0),
+		Nonce:     0x0123456789abcdef,
+	}
+	_, err := header.PowHashV2WithHasher(kp)
+	if !errors.Is(err, kawpow.ErrInvalidHashInput) {
+		t.Fatalf("unexpected error -- got %v, want %v", err,
+			kawpow.ErrInvalidHashInput)
+	}
+}
+
+// TestPowHashV2Shared ensures the version 2 proof of work hash calculated with
+// the shared hasher matches the hash calculated with a new hasher for headers
+// in different epochs, including when the epochs are revisited.
+func TestPowHashV2Shared(t *testing.T) {
+	header := BlockHeader{
+		Version:   1,
+		PrevBlock: mainNetGenesisHash,
+		Bits:      0x1d00ffff,
+		Timestamp: time.Unix(0x61c402e0, 0),
+		Nonce:     0x0123456789abcdef,
+	}
+	heights := []uint32{1, kawpow.KawPowEpochLength + 1, 2}
+	for _, height := range heights {
+		header.Height = height
+		want, err := header.PowHashV2WithHasher(kawpow.NewLight())
+		if err != nil {
+			t.Fatalf("height %d: unexpected error: %v", height, err)
+		}
+		for i := 0; i < 2; i++ {
+			hash, err := header.PowHashV2()
+			if err != nil {
+				t.Fatalf("height %d: unexpected error: %v", height, err)
+			}
+			if hash != want {
+				t.Fatalf("height %d: mismatched hash -- got %v, want %v",
+					height, hash, want)
+			}
+		}
+	}
+}
+
+// TestPowHashV2Seed ensures the header bytes that seed the version 2 proof of
+// work hash do not depend on the nonce or mix digest, and therefore the hash
+// does not depend on the mix digest, while the other fields are unchanged.
+func TestPowHashV2Seed(t *testing.T) {
+	header := BlockHeader{
+		Version:      1,
+		PrevBlock:    mainNetGenesisHash,
+		MerkleRoot:   mainNetGenesisMerkleRoot,
+		Bits:         0x1d00ffff,
+		Height:       1,
+		Timestamp:    time.Unix(0x61c402e0, 0),
+		Nonce:        0x0123456789abcdef,
+		MixDigest:    [32]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef},
+		ExtraData:    [32]byte{0xfe, 0xdc, 0xba, 0x98, 0x76, 0x54, 0x32, 0x10},
+		StakeVersion: 9,
+	}
+	other := header
+	other.Nonce = 0xfedcba9876543210
+	other.MixDigest = [32]byte{0xff, 0xee, 0xdd, 0xcc}
+
+	// Ensure the seed bytes are the serialized header with the nonce and mix
+	// digest zeroed.
+	zeroed := header
+	zeroed.Nonce = 0
+	zeroed.MixDigest = [32]byte{}
+	want, err := zeroed.Bytes()
+	if err != nil {
+		t.Fatalf("unable to serialize header: %v", err)
+	}
+	if got := header.BytesNoNonce(); !bytes.Equal(got, want) {
+		t.Fatalf("mismatched seed bytes -- got %x, want %x", got, want)
+	}
+	if got := other.BytesNoNonce(); !bytes.Equal(got, want) {
+		t.Fatalf("seed bytes depend on the nonce or mix digest -- got %x, "+
+			"want %x", got, want)
+	}
+
+	// Ensure the proof of work hash does not depend on the mix digest.
+	other.Nonce = header.Nonce
+	hash, err := header.PowHashV2()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	otherHash, err := other.PowHashV2()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hash != otherHash {
+		t.Fatalf("hash depends on the mix digest -- got %v, want %v",
+			otherHash, hash)
+	}
+}
+
+// TestPowHashV2FinalHash ensures the version 2 proof of work hash is the final
+// KawPoW hash as opposed to the mix digest.
+func TestPowHashV2FinalHash(t *testing.T) {
+	header := BlockHeader{
+		Version:    1,
+		PrevBlock:  mainNetGenesisHash,
+		MerkleRoot: mainNetGenesisMerkleRoot,
+		Bits:       0x1d00ffff,
+		Height:     1,
+		Timestamp:  time.Unix(0x61c402e0, 0),
+		Nonce:      0x0123456789abcdef,
+	}
+	kp := kawpow.NewLight()
+	mixDigest, finalHash, err := kp.Hash(header.BytesNoNonce(), header.Nonce)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	computedMix, err := kp.ComputeMixDigest(header.BytesNoNonce(),
+		header.Nonce)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(computedMix[:], mixDigest) {
+		t.Fatalf("mismatched mix digest -- got %x, want %x", computedMix,
+			mixDigest)
+	}
+
+	hash, err := header.PowHashV2()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(hash[:], finalHash) {
+		t.Fatalf("proof of work hash is not the final hash -- got %x, "+
+			"want %x", hash[:], finalHash)
+	}
+	if hash == chainhash.Hash(computedMix) {
+		t.Fatalf("proof of work hash is the mix digest %x", computedMix)
+	}
+}
+
+// TestPowHashV2HeaderFields ensures the height and timestamp KawPoW extracts
+// from the header bytes used to calculate the version 2 proof of work hash
+// match the header that was serialized.
+func TestPowHashV2HeaderFields(t *testing.T) {
+	header := BlockHeader{
+		Version:      1,
+		PrevBlock:    mainNetGenesisHash,
+		MerkleRoot:   mainNetGenesisMerkleRoot,
+		StakeRoot:    mainNetGenesisMerkleRoot,
+		VoteBits:     1,
+		FinalState:   [6]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06},
+		Voters:       5,
+		FreshStake:   2,
+		Revocations:  1,
+		PoolSize:     40960,
+		Bits:         0x1d00ffff,
+		SBits:        200000000,
+		Height:       123456,
+		Size:         1024,
+		Timestamp:    time.Unix(1700000000, 0),
+		Nonce:        0xfedcba9876543210,
+		MixDigest:    [32]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef},
+		ExtraData:    [32]byte{0xfe, 0xdc, 0xba, 0x98, 0x76, 0x54, 0x32, 0x10},
+		StakeVersion: 9,
+	}
+	// Use the same header bytes that are hashed for the proof of work.
+	headerBytes := header.BytesNoNonce()
+	height, err := kawpow.HeaderHeight(headerBytes)
+	if err != nil {
+		t.Fatalf("unexpected header height error: %v", err)
+	}
+	if height != header.Height {
+		t.Fatalf("mismatched height -- got %d, want %d", height,
+			header.Height)
+	}
+	timestamp, err := kawpow.HeaderTimestamp(headerBytes)
+	if err != nil {
+		t.Fatalf("unexpected header timestamp error: %v", err)
+	}
+	if want := uint32(header.Timestamp.Unix()); timestamp != want {
+		t.Fatalf("mismatched timestamp -- got %d, want %d", timestamp, want)
+	}
+}
+
+// fuzzBlockHeaderSeed returns a serialized block header with non-zero values
+// in all fields for use as a seed for the fuzz tests.
+func fuzzBlockHeaderSeed(f *testing.F) []byte {
+	header := BlockHeader{
+		Version:      1,
+		PrevBlock:    mainNetGenesisHash,
+		MerkleRoot:   mainNetGenesisMerkleRoot,
+		StakeRoot:    mainNetGenesisMerkleRoot,
+		VoteBits:     1,
+		FinalState:   [6]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06},
+		Voters:       5,
+		FreshStake:   2,
+		Revocations:  1,
+		PoolSize:     40960,
+		Bits:         0x1d00ffff,
+		SBits:        200000000,
+		Height:       100,
+		Size:         1024,
+		Timestamp:    time.Unix(1700000000, 0),
+		Nonce:        0xfedcba9876543210,
+		MixDigest:    [32]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef},
+		ExtraData:    [32]byte{0xfe, 0xdc, 0xba, 0x98, 0x76, 0x54, 0x32, 0x10},
+		StakeVersion: 9,
+	}
+	serialized, err := header.Bytes()
+	if err != nil {
+		f.Fatalf("unable to serialize seed header: %v", err)
+	}
+	return serialized
+}
+
+// FuzzReadBlockHeader ensures deserializing arbitrary bytes as a block header
+// never panics and that any successfully deserialized header serializes back
+// to the bytes it was deserialized from.
+func FuzzReadBlockHeader(f *testing.F) {
+	seed := fuzzBlockHeaderSeed(f)
+	f.Add(seed)
+	f.Add(seed[:len(seed)-1])
+	f.Add(seed[:MaxBlockHeaderPayload-32-4])
+	f.Add(append(append([]byte(nil), seed...), 0x00))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var header BlockHeader
+		if err := header.FromBytes(data); err != nil {
+			if len(data) >= MaxBlockHeaderPayload {
+				t.Fatalf("unexpected error deserializing %d bytes: %v",
+					len(data), err)
+			}
+			return
+		}
+		if len(data) < MaxBlockHeaderPayload {
+			t.Fatalf("deserialized truncated header of %d bytes", len(data))
+		}
+
+		serialized, err := header.Bytes()
+		if err != nil {
+			t.Fatalf("unable to serialize deserialized header: %v", err)
+		}
+		if !bytes.Equal(serialized, data[:MaxBlockHeaderPayload]) {
+			t.Fatalf("mismatched serialized header -- got %x, want %x",
+				serialized, data[:MaxBlockHeaderPayload])
+		}
+	})
+}
+
+// FuzzBlockHeaderRoundTrip ensures mutating any byte of a valid serialized
+// block header results in a header that deserializes and serializes back to
+// the mutated bytes.
+func FuzzBlockHeaderRoundTrip(f *testing.F) {
+	seed := fuzzBlockHeaderSeed(f)
+	f.Add(uint(0), byte(0xff))
+	f.Add(uint(MaxBlockHeaderPayload-32-32-4-1), byte(0x80))
+	f.Add(uint(MaxBlockHeaderPayload-1), byte(0x01))
+
+	f.Fuzz(func(t *testing.T, offset uint, value byte) {
+		mutated := append([]byte(nil), seed...)
+		mutated[offset%uint(len(mutated))] ^= value
+
+		var header BlockHeader
+		if err := header.FromBytes(mutated); err != nil {
+			t.Fatalf("unable to deserialize mutated header: %v", err)
+		}
+		serialized, err := header.Bytes()
+		if err != nil {
+			t.Fatalf("unable to serialize mutated header: %v", err)
+		}
+		if !bytes.Equal(serialized, mutated) {
+			t.Fatalf("mismatched serialized header -- got %x, want %x",
+				serialized, mutated)
+		}
+	})
+}
@@ -503,6 +503,14 @@ func writeElement(w io.Writer, element interface{}) error {
 		}
 		return nil
 
+	// KawPoW block header mix digest and extra data.
+	case [32]byte:
+		_, err := w.Write(e[:])
+		if err != nil {
+			return err
+		}
+		return nil
+
 	case *[32]byte:
 		_, err := w.Write(e[:])
 		if err != nil {
@@ -6,9 +6,16 @@ require (
 	github.com/davecgh/go-spew v1.1.1
 	github.com/decred/dcrd/chaincfg/chainhash v1.0.4
 	lukechampine.com/blake3 v1.3.0
+	vigil.network/node/blockchain/standalone/kawpow v0.0.0
 )
 
 require (
 	github.com/decred/dcrd/crypto/blake256 v1.0.1 // indirect
 	github.com/klauspost/cpuid/v2 v2.0.9 // indirect
+	vigil.network/node/chaincfg/chainhash v0.0.0 // indirect
+)
+
+replace (
+	vigil.network/node/blockchain/standalone/kawpow => ../blockchain/standalone/kawpow
+	vigil.network/node/chaincfg/chainhash => ../chaincfg/chainhash
 )
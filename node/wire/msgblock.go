@@ -369,8 +369,9 @@ func (msg *MsgBlock) PowHashV1() chainhash.Hash {
 }
 
 // PowHashV2 calculates and returns the version 2 proof of work hash as defined
-// in DCP0011 for the block.
-func (msg *MsgBlock) PowHashV2() chainhash.Hash {
+// in DCP0011 for the block.  See BlockHeader.PowHashV2 for details regarding
+// the returned error.
+func (msg *MsgBlock) PowHashV2() (chainhash.Hash, error) {
 	return msg.Header.PowHashV2()
 }
 
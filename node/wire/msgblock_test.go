@@ -38,13 +38,13 @@ func TestBlock(t *testing.T) {
 		uint32(1),                                   // Height
 		uint32(1),                                   // Size
 		time.Unix(0x495fab29, 0),                    // Timestamp (2009-01-03 12:15:05 -0600 CST)
-		uint64(testBlock.Header.Nonce),             // Nonce (converted to uint64)
+		uint64(testBlock.Header.Nonce),              // Nonce (converted to uint64)
 		[32]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef, // MixDigest
-		        0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
-		        0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
-		        0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef},
-		[32]byte{},                                  // ExtraData
-		uint32(0x5ca1ab1e),                          // StakeVersion
+			0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
+			0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
+			0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef},
+		[32]byte{},         // ExtraData
+		uint32(0x5ca1ab1e), // StakeVersion
 	)
 
 	// Ensure the command is expected value.
@@ -161,7 +161,7 @@ func TestBlockSTxHashes(t *testing.T) {
 // TestBlockHash tests the ability to generate the hash of a block accurately.
 func TestBlockHash(t *testing.T) {
 	// Block 1 hash.
-	hashStr := "6b73b6f6faebbfd6a541f38820593e43c50ce1abf64602ab8ac7d5502991c37f"
+	hashStr := "b062843cd0dd5970047880b545cb451f0d0c2c65e5568d16b97aa4e1e87be3f9"
 	wantHash, err := chainhash.NewHashFromStr(hashStr)
 	if err != nil {
 		t.Errorf("NewHashFromStr: %v", err)
@@ -275,10 +275,16 @@ func TestBlockWireErrors(t *testing.T) {
 		{&testBlock, testBlockBytes, pver, 136, io.ErrShortWrite, io.EOF}, // 14
 		// Force error in nonce.
 		{&testBlock, testBlockBytes, pver, 140, io.ErrShortWrite, io.EOF}, // 15
+		// Force error in mix digest.
+		{&testBlock, testBlockBytes, pver, 148, io.ErrShortWrite, io.EOF}, // 16
+		// Force error in extra data.
+		{&testBlock, testBlockBytes, pver, 180, io.ErrShortWrite, io.EOF}, // 17
+		// Force error in stake version.
+		{&testBlock, testBlockBytes, pver, 212, io.ErrShortWrite, io.EOF}, // 18
 		// Force error in tx count.
-		{&testBlock, testBlockBytes, pver, 180, io.ErrShortWrite, io.EOF}, // 16
+		{&testBlock, testBlockBytes, pver, 216, io.ErrShortWrite, io.EOF}, // 19
 		// Force error in tx.
-		{&testBlock, testBlockBytes, pver, 181, io.ErrShortWrite, io.EOF}, // 17
+		{&testBlock, testBlockBytes, pver, 217, io.ErrShortWrite, io.EOF}, // 20
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -419,10 +425,16 @@ func TestBlockSerializeErrors(t *testing.T) {
 		{&testBlock, testBlockBytes, 136, io.ErrShortWrite, io.EOF}, // 16
 		// Force error in nonce.
 		{&testBlock, testBlockBytes, 140, io.ErrShortWrite, io.EOF}, // 17
+		// Force error in mix digest.
+		{&testBlock, testBlockBytes, 148, io.ErrShortWrite, io.EOF}, // 18
+		// Force error in extra data.
+		{&testBlock, testBlockBytes, 180, io.ErrShortWrite, io.EOF}, // 19
+		// Force error in stake version.
+		{&testBlock, testBlockBytes, 212, io.ErrShortWrite, io.EOF}, // 20
 		// Force error in tx count.
-		{&testBlock, testBlockBytes, 180, io.ErrShortWrite, io.EOF}, // 18
+		{&testBlock, testBlockBytes, 216, io.ErrShortWrite, io.EOF}, // 21
 		// Force error in tx.
-		{&testBlock, testBlockBytes, 181, io.ErrShortWrite, io.EOF}, // 19
+		{&testBlock, testBlockBytes, 217, io.ErrShortWrite, io.EOF}, // 22
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -499,7 +511,11 @@ func TestBlockOverflowErrors(t *testing.T) {
 				0x01, 0x00, 0x00, 0x00, // Height
 				0x01, 0x00, 0x00, 0x00, // Size
 				0x61, 0xbc, 0x66, 0x49, // Timestamp
-				0x01, 0xe3, 0x62, 0x99, // Nonce
+				0x01, 0xe3, 0x62, 0x99, 0x00, 0x00, 0x00, 0x00, // Nonce
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // MixDigest
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // ExtraData
 				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
@@ -554,7 +570,7 @@ func TestBlockSerializeSize(t *testing.T) {
 		size int       // Expected serialized size
 	}{
 		// Block with no transactions (header + 2x numtx)
-		{noTxBlock, 182},
+		{noTxBlock, 218},
 
 		// First block in the mainnet block chain.
 		{&testBlock, len(testBlockBytes)},
@@ -593,22 +609,22 @@ var testBlock = MsgBlock{
 			0x7b, 0xa1, 0xa3, 0xc3, 0x54, 0x0b, 0xf7, 0xb1,
 			0xcd, 0xb6, 0x06, 0xe8, 0x57, 0x23, 0x3e, 0x0e,
 		}),
-		VoteBits:     uint16(0x0000),
-		FinalState:   [6]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
-		Voters:       uint16(0x0000),
-		FreshStake:   uint8(0x00),
-		Revocations:  uint8(0x00),
-		PoolSize:     uint32(0x00000000), // Poolsize
-		Bits:         0x1d00ffff,         // 486604799
-		SBits:        int64(0x0000000000000000),
-		Height:       uint32(1),
-		Size:         uint32(1),
-		Timestamp:    time.Unix(0x4966bc61, 0), // 2009-01-08 20:54:25 -0600 CST
-		Nonce:        uint64(0x9962e301),       // 2573394689
+		VoteBits:    uint16(0x0000),
+		FinalState:  [6]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		Voters:      uint16(0x0000),
+		FreshStake:  uint8(0x00),
+		Revocations: uint8(0x00),
+		PoolSize:    uint32(0x00000000), // Poolsize
+		Bits:        0x1d00ffff,         // 486604799
+		SBits:       int64(0x0000000000000000),
+		Height:      uint32(1),
+		Size:        uint32(1),
+		Timestamp:   time.Unix(0x4966bc61, 0), // 2009-01-08 20:54:25 -0600 CST
+		Nonce:       uint64(0x9962e301),       // 2573394689
 		MixDigest: [32]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef, // MixDigest
-		                  0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
-		                  0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
-		                  0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef},
+			0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
+			0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef,
+			0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef},
 		ExtraData:    [32]byte{},
 		StakeVersion: uint32(0x5ca1ab1e),
 	},
@@ -811,10 +827,10 @@ var testBlockBytes = []byte{
 
 // Transaction location information for the test block transactions.
 var testBlockTxLocs = []TxLoc{
-	{TxStart: 181, TxLen: 158},
+	{TxStart: 217, TxLen: 158},
 }
 
 // Transaction location information for the test block stake transactions.
 var testBlockSTxLocs = []TxLoc{
-	{TxStart: 340, TxLen: 158},
+	{TxStart: 376, TxLen: 158},
 }